@@ -0,0 +1,103 @@
+// Package active ranks unlabelled pool samples for annotation,
+// so labelling effort goes to the samples a model learns most
+// from. Strategies score samples by the uncertainty of a fitted
+// classifier of package ml, committees by the disagreement of
+// their members; higher scores are more informative.
+package active
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Strategy scores every sample of pool by a fitted model
+type Strategy func(model ml.Estimator, pool [][]float64) []float64
+
+// multiclass is a classifier with probabilities of
+// every class, such as ml.SoftmaxRegression
+type multiclass interface {
+	Probabilities(X []float64) []float64
+}
+
+// probabilities returns probabilities of every class of X,
+// binary classifiers estimating the probability of true
+func probabilities(model ml.Estimator, X []float64) []float64 {
+	if mc, ok := model.(multiclass); ok {
+		return mc.Probabilities(X)
+	}
+	p := model.Estimate(X)
+	return []float64{1 - p, p}
+}
+
+// LeastConfident scores samples by 1 minus the
+// probability of their most probable class
+func LeastConfident(model ml.Estimator, pool [][]float64) []float64 {
+	scores := make([]float64, len(pool))
+	for i, x := range pool {
+		prob := probabilities(model, x)
+		best := 0.0
+		for _, p := range prob {
+			best = math.Max(best, p)
+		}
+		scores[i] = 1 - best
+	}
+	return scores
+}
+
+// Margin scores samples by 1 minus the difference of
+// probabilities of their two most probable classes
+func Margin(model ml.Estimator, pool [][]float64) []float64 {
+	scores := make([]float64, len(pool))
+	for i, x := range pool {
+		prob := append([]float64(nil), probabilities(model, x)...)
+		sort.Sort(sort.Reverse(sort.Float64Slice(prob)))
+		scores[i] = 1 - (prob[0] - prob[1])
+	}
+	return scores
+}
+
+// Entropy scores samples by entropy of probabilities
+// of their classes
+func Entropy(model ml.Estimator, pool [][]float64) []float64 {
+	scores := make([]float64, len(pool))
+	for i, x := range pool {
+		scores[i] = entropy(probabilities(model, x))
+	}
+	return scores
+}
+
+// entropy returns -Σ p·log p
+func entropy(prob []float64) float64 {
+	h := 0.0
+	for _, p := range prob {
+		if p > 0 {
+			h -= p * math.Log(p)
+		}
+	}
+	return h
+}
+
+// Top returns indices of the k highest scores, highest first
+func Top(scores []float64, k int) []int {
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+	if k < len(idx) {
+		idx = idx[:k]
+	}
+	return idx
+}
+
+// Query returns indices of the k samples of pool
+// most informative to model by strategy
+func Query(strategy Strategy, model ml.Estimator, pool [][]float64, k int) ([]int, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("active: query of %d samples", k)
+	}
+	return Top(strategy(model, pool), k), nil
+}
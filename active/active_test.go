@@ -0,0 +1,95 @@
+package active
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// fixed estimates the probability of true as the
+// first feature, or as p when set, and Fit takes p
+// as the mean of output
+type fixed struct {
+	p    float64
+	rows int
+}
+
+func (f *fixed) Fit(features [][]float64, output []float64) error {
+	f.rows = len(features)
+	f.p = 0
+	for _, y := range output {
+		f.p += y / float64(len(output))
+	}
+	return nil
+}
+
+func (f *fixed) Estimate(X []float64) float64 {
+	if f.p > 0 {
+		return f.p
+	}
+	return X[0]
+}
+
+func near(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	for i := range want {
+		if len(got) != len(want) || math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Errorf("%s %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	pool := [][]float64{{0.5}, {0.9}, {0.1}, {0.7}}
+	model := &fixed{}
+
+	near(t, "least confident", LeastConfident(model, pool), []float64{0.5, 0.1, 0.1, 0.3})
+	near(t, "margin", Margin(model, pool), []float64{1, 0.2, 0.2, 0.6})
+	h := func(p float64) float64 { return -p*math.Log(p) - (1-p)*math.Log(1-p) }
+	near(t, "entropy", Entropy(model, pool), []float64{math.Log(2), h(0.9), h(0.1), h(0.7)})
+
+	got, err := Query(Margin, model, pool, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("query %v, want %v", got, want)
+	}
+	if _, err := Query(Margin, model, pool, 0); err == nil {
+		t.Error("no error of a query of no samples")
+	}
+}
+
+func TestCommittee(t *testing.T) {
+	// members voting 0.2 and 0.8 split the votes and
+	// diverge from their mean of 0.5 equally
+	c := &Committee{Members: []ml.Estimator{&fixed{p: 0.2}, &fixed{p: 0.8}}}
+	pool := [][]float64{{0}}
+	near(t, "vote entropy", c.VoteEntropy(pool), []float64{math.Log(2)})
+	kl := 0.2*math.Log(0.2/0.5) + 0.8*math.Log(0.8/0.5)
+	near(t, "KL disagreement", c.KLDisagreement(pool), []float64{kl})
+
+	agreeing := &Committee{Members: []ml.Estimator{&fixed{p: 0.7}, &fixed{p: 0.7}}}
+	near(t, "vote entropy of agreeing members", agreeing.VoteEntropy(pool), []float64{0})
+	near(t, "KL disagreement of agreeing members", agreeing.KLDisagreement(pool), []float64{0})
+
+	features := [][]float64{{1}, {2}, {3}}
+	output := []float64{1, 0, 1}
+	newFixed := func() ml.Estimator { return &fixed{} }
+	c, err := NewCommittee(newFixed, features, output, 3, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range c.Members {
+		if m.(*fixed).rows != 3 {
+			t.Errorf("member fitted on %d rows, want a bootstrap sample of 3", m.(*fixed).rows)
+		}
+	}
+	if _, err := NewCommittee(newFixed, features, output, 1, nil); err == nil {
+		t.Error("no error of a committee of 1")
+	}
+}
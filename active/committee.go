@@ -0,0 +1,100 @@
+package active
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Committee is a set of classifiers whose disagreement on a
+// sample scores it, query by committee of Seung et al. (1992)
+type Committee struct {
+	Members []ml.Estimator
+}
+
+// NewCommittee returns a Committee of size members from
+// newEstimator, each fitted on a bootstrap sample of
+// features and output drawn from r, nil r uses the global
+// source of math/rand
+func NewCommittee(newEstimator func() ml.Estimator, features [][]float64, output []float64, size int, r *rand.Rand) (*Committee, error) {
+	if len(features) == 0 || len(features) != len(output) {
+		return nil, fmt.Errorf("active: got %d rows of features and %d outputs", len(features), len(output))
+	}
+	if size < 2 {
+		return nil, fmt.Errorf("active: committee needs at least 2 members, got %d", size)
+	}
+	r = ml.RandOf(r)
+
+	c := &Committee{}
+	n := len(features)
+	for m := 0; m < size; m++ {
+		X := make([][]float64, n)
+		y := make([]float64, n)
+		for i := range X {
+			k := r.Intn(n)
+			X[i], y[i] = features[k], output[k]
+		}
+		model := newEstimator()
+		if err := model.Fit(X, y); err != nil {
+			return nil, fmt.Errorf("active: member %d: %v", m, err)
+		}
+		c.Members = append(c.Members, model)
+	}
+	return c, nil
+}
+
+// VoteEntropy scores samples of pool by entropy of the
+// fractions of members voting for every class
+func (c *Committee) VoteEntropy(pool [][]float64) []float64 {
+	scores := make([]float64, len(pool))
+	for i, x := range pool {
+		votes := map[int]float64{}
+		for _, m := range c.Members {
+			prob := probabilities(m, x)
+			best := 0
+			for k, p := range prob {
+				if p > prob[best] {
+					best = k
+				}
+			}
+			votes[best]++
+		}
+		var fractions []float64
+		for _, v := range votes {
+			fractions = append(fractions, v/float64(len(c.Members)))
+		}
+		scores[i] = entropy(fractions)
+	}
+	return scores
+}
+
+// KLDisagreement scores samples of pool by the mean
+// Kullback-Leibler divergence of probabilities of every
+// member from the mean probabilities of the committee
+func (c *Committee) KLDisagreement(pool [][]float64) []float64 {
+	scores := make([]float64, len(pool))
+	for i, x := range pool {
+		var members [][]float64
+		var consensus []float64
+		for _, m := range c.Members {
+			prob := probabilities(m, x)
+			if consensus == nil {
+				consensus = make([]float64, len(prob))
+			}
+			for k, p := range prob {
+				consensus[k] += p / float64(len(c.Members))
+			}
+			members = append(members, prob)
+		}
+		for _, prob := range members {
+			for k, p := range prob {
+				if p > 0 {
+					scores[i] += p * math.Log(p/consensus[k]) / float64(len(members))
+				}
+			}
+		}
+	}
+	return scores
+}
@@ -0,0 +1,250 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// BreuschPagan tests residuals of a regression on features
+// for heteroskedasticity, with Koenker's studentized
+// statistic nR² of squared residuals regressed on features.
+// A small p-value means the variance of residuals depends
+// on the features. A bias column is added when missing.
+func BreuschPagan(features [][]float64, residuals []float64) (*StatTest, error) {
+	if _, err := checkRows(features, residuals); err != nil {
+		return nil, err
+	}
+	return auxiliaryTest(features, residuals, false)
+}
+
+// White tests residuals of a regression on features for
+// heteroskedasticity like BreuschPagan, also regressing
+// squared residuals on squares and products of features
+// so it detects nonlinear forms of heteroskedasticity
+func White(features [][]float64, residuals []float64) (*StatTest, error) {
+	if _, err := checkRows(features, residuals); err != nil {
+		return nil, err
+	}
+	return auxiliaryTest(features, residuals, true)
+}
+
+// auxiliaryTest regresses squared residuals on non-constant
+// columns of features, with their squares and products
+// when cross is true, and returns the test of nR²
+func auxiliaryTest(features [][]float64, residuals []float64, cross bool) (*StatTest, error) {
+	var cols [][]float64
+	for j := range features[0] {
+		c := column(features, j)
+		if stat.Variance(c, nil) > 0 {
+			cols = append(cols, c)
+		}
+	}
+	if cross {
+		k := len(cols)
+		for a := 0; a < k; a++ {
+			for b := a; b < k; b++ {
+				c := make([]float64, len(features))
+				for i := range c {
+					c[i] = cols[a][i] * cols[b][i]
+				}
+				cols = append(cols, c)
+			}
+		}
+	}
+
+	// drop constant and repeated columns, e.g.
+	// squares of binary features
+	kept := [][]float64{}
+	for _, c := range cols {
+		if !(stat.Variance(c, nil) > 0) {
+			continue
+		}
+		repeated := false
+		for _, k := range kept {
+			if floats.Equal(c, k) {
+				repeated = true
+				break
+			}
+		}
+		if !repeated {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("ml: features have no varying columns")
+	}
+
+	aux := make([][]float64, len(features))
+	for i := range aux {
+		aux[i] = make([]float64, len(kept)+1)
+		aux[i][0] = 1
+		for j, c := range kept {
+			aux[i][j+1] = c[i]
+		}
+	}
+	squared := make([]float64, len(residuals))
+	for i, e := range residuals {
+		squared[i] = e * e
+	}
+
+	theta, err := leastSquares(aux, squared)
+	if err != nil {
+		return nil, err
+	}
+	estimates := make([]float64, len(aux))
+	for i, x := range aux {
+		estimates[i] = floats.Dot(x, theta)
+	}
+	lm := float64(len(aux)) * R2(squared, estimates)
+	df := float64(len(kept))
+	return &StatTest{
+		Statistic: lm,
+		DF:        df,
+		PValue:    distuv.ChiSquared{K: df}.Survival(lm),
+	}, nil
+}
+
+// leastSquares returns thetas of ordinary least squares
+// of output on features
+func leastSquares(features [][]float64, output []float64) ([]float64, error) {
+	inv, err := gramInverse(features)
+	if err != nil {
+		return nil, err
+	}
+	var xty mat.VecDense
+	xty.MulVec(denseOf(features).T(), mat.NewVecDense(len(output), output))
+	var theta mat.VecDense
+	theta.MulVec(inv, &xty)
+	return theta.RawVector().Data, nil
+}
+
+// JarqueBera tests whether residuals are normal from their
+// skewness and kurtosis. A small p-value means they are not.
+// The statistic is asymptotically chi squared, so small
+// samples are better tested with ShapiroWilk.
+func JarqueBera(residuals []float64) (*StatTest, error) {
+	n := float64(len(residuals))
+	if n < 3 {
+		return nil, fmt.Errorf("ml: Jarque-Bera needs 3 residuals, got %d", len(residuals))
+	}
+	m := stat.Mean(residuals, nil)
+	var m2, m3, m4 float64
+	for _, e := range residuals {
+		d := e - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	m2, m3, m4 = m2/n, m3/n, m4/n
+	if m2 == 0 {
+		return nil, fmt.Errorf("ml: residuals are constant")
+	}
+	skew := m3 / math.Pow(m2, 1.5)
+	kurt := m4 / (m2 * m2)
+	jb := n / 6 * (skew*skew + (kurt-3)*(kurt-3)/4)
+	return &StatTest{
+		Statistic: jb,
+		DF:        2,
+		PValue:    distuv.ChiSquared{K: 2}.Survival(jb),
+	}, nil
+}
+
+// ShapiroWilk tests whether 3 to 5000 residuals are normal,
+// with Royston's approximation of the coefficients and the
+// p-value. A small p-value means they are not. The
+// statistic W is in (0, 1], near 1 for normal residuals.
+func ShapiroWilk(residuals []float64) (*StatTest, error) {
+	n := len(residuals)
+	if n < 3 || n > 5000 {
+		return nil, fmt.Errorf("ml: Shapiro-Wilk needs 3 to 5000 residuals, got %d", n)
+	}
+	x := append([]float64(nil), residuals...)
+	sort.Float64s(x)
+	if x[0] == x[n-1] {
+		return nil, fmt.Errorf("ml: residuals are constant")
+	}
+
+	a := shapiroWilkCoefficients(n)
+	m := stat.Mean(x, nil)
+	num, ss := 0.0, 0.0
+	for i, v := range x {
+		num += a[i] * v
+		ss += (v - m) * (v - m)
+	}
+	w := math.Min(num*num/ss, 1)
+
+	var p float64
+	fn := float64(n)
+	normal := distuv.UnitNormal
+	switch {
+	case n == 3:
+		p = 6 / math.Pi * (math.Asin(math.Sqrt(w)) - math.Asin(math.Sqrt(0.75)))
+		p = math.Max(p, 0)
+	case n <= 11:
+		gamma := 0.459*fn - 2.273
+		mu := 0.5440 - 0.39978*fn + 0.025054*fn*fn - 0.0006714*fn*fn*fn
+		sigma := math.Exp(1.3822 - 0.77857*fn + 0.062767*fn*fn - 0.0020322*fn*fn*fn)
+		z := (-math.Log(gamma-math.Log1p(-w)) - mu) / sigma
+		p = normal.Survival(z)
+	default:
+		ln := math.Log(fn)
+		mu := -1.5861 - 0.31082*ln - 0.083751*ln*ln + 0.0038915*ln*ln*ln
+		sigma := math.Exp(-0.4803 - 0.082676*ln + 0.0030302*ln*ln)
+		z := (math.Log1p(-w) - mu) / sigma
+		p = normal.Survival(z)
+	}
+	return &StatTest{Statistic: w, PValue: p}, nil
+}
+
+// shapiroWilkCoefficients returns Royston's
+// coefficients of n ordered values
+func shapiroWilkCoefficients(n int) []float64 {
+	a := make([]float64, n)
+	if n == 3 {
+		a[0], a[2] = -math.Sqrt(0.5), math.Sqrt(0.5)
+		return a
+	}
+
+	fn := float64(n)
+	m := make([]float64, n)
+	sum := 0.0
+	for i := range m {
+		m[i] = distuv.UnitNormal.Quantile((float64(i+1) - 0.375) / (fn + 0.25))
+		sum += m[i] * m[i]
+	}
+	u := 1 / math.Sqrt(fn)
+	poly := func(c0 float64, c ...float64) float64 {
+		v, pow := c0, u
+		for _, k := range c {
+			v += k * pow
+			pow *= u
+		}
+		return v
+	}
+
+	last := poly(m[n-1]/math.Sqrt(sum), 0.221157, -0.147981, -2.071190, 4.434685, -2.706056)
+	var phi float64
+	if n > 5 {
+		next := poly(m[n-2]/math.Sqrt(sum), 0.042981, -0.293762, -1.752461, 5.682633, -3.582633)
+		phi = (sum - 2*m[n-1]*m[n-1] - 2*m[n-2]*m[n-2]) / (1 - 2*last*last - 2*next*next)
+		a[n-2], a[1] = next, -next
+	} else {
+		phi = (sum - 2*m[n-1]*m[n-1]) / (1 - 2*last*last)
+	}
+	a[n-1], a[0] = last, -last
+
+	lo, hi := 1, n-1
+	if n > 5 {
+		lo, hi = 2, n-2
+	}
+	for i := lo; i < hi; i++ {
+		a[i] = m[i] / math.Sqrt(phi)
+	}
+	return a
+}
@@ -0,0 +1,88 @@
+package ml
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// weights of 11 men, skewed by the heaviest
+var weights = []float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236}
+
+func checkTest(t *testing.T, name string, got *StatTest, err error, statistic, df, p, tol float64) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if math.Abs(got.Statistic-statistic) > tol || got.DF != df || math.Abs(got.PValue-p) > tol {
+		t.Errorf("%s: got %+v, want statistic %v, df %v and p-value %v", name, got, statistic, df, p)
+	}
+}
+
+func TestShapiroWilk(t *testing.T) {
+	// shapiro.test of R gives W = 0.78881, p-value = 0.006704
+	sw, err := ShapiroWilk(weights)
+	checkTest(t, "weights", sw, err, 0.78881, 0, 0.006704, 5e-6)
+
+	// 3 values have the exact p-value
+	// 6/π(asin√W - asin√¾) with W = 27/28
+	sw, err = ShapiroWilk([]float64{1, 2, 4})
+	checkTest(t, "3 values", sw, err, 27.0/28, 0, 0.6368868450289692, 1e-12)
+
+	// expected normal order statistics are not rejected
+	normal := make([]float64, 50)
+	for i := range normal {
+		normal[i] = distuv.UnitNormal.Quantile((float64(i) + 0.5) / 50)
+	}
+	if sw, err = ShapiroWilk(normal); err != nil || sw.Statistic < 0.99 || sw.PValue < 0.5 {
+		t.Errorf("normal quantiles: got %+v, %v, want W near 1", sw, err)
+	}
+
+	for _, x := range [][]float64{{1, 2}, {3, 3, 3}, make([]float64, 5001)} {
+		if _, err = ShapiroWilk(x); err == nil {
+			t.Errorf("no error of %d residuals", len(x))
+		}
+	}
+}
+
+func TestJarqueBera(t *testing.T) {
+	// n/6(S² + (K-3)²/4) of skewness 1.68 and kurtosis
+	// 4.99, the p-value of 2 df is exp(-JB/2)
+	jb, err := JarqueBera(weights)
+	checkTest(t, "weights", jb, err, 6.982848237344646, 2, 0.030457466224581887, 1e-9)
+
+	if _, err = JarqueBera([]float64{1, 1, 1}); err == nil {
+		t.Error("no error of constant residuals")
+	}
+}
+
+func TestBreuschPaganAndWhite(t *testing.T) {
+	// residuals growing in spread with x
+	features := make([][]float64, 10)
+	for i := range features {
+		features[i] = []float64{1, float64(i + 1)}
+	}
+	residuals := []float64{0.5, -1, 1.2, -2, 2.5, -3.1, 3.3, -4.2, 4.8, -5.5}
+
+	// nR² of e² regressed on x, lmtest::bptest
+	bp, err := BreuschPagan(features, residuals)
+	checkTest(t, "Breusch-Pagan", bp, err, 9.061703802783015, 1, 0.002610186422936504, 1e-9)
+
+	// nR² of e² regressed on x and x²
+	white, err := White(features, residuals)
+	checkTest(t, "White", white, err, 9.934428297898247, 2, 0.006962517578800234, 1e-9)
+
+	// the bias column may be left out
+	noBias := make([][]float64, len(features))
+	for i, x := range features {
+		noBias[i] = x[1:]
+	}
+	bp, err = BreuschPagan(noBias, residuals)
+	checkTest(t, "Breusch-Pagan without bias", bp, err, 9.061703802783015, 1, 0.002610186422936504, 1e-9)
+
+	constant := [][]float64{{1}, {1}, {1}}
+	if _, err = BreuschPagan(constant, []float64{1, 2, 3}); err == nil {
+		t.Error("no error of features without varying columns")
+	}
+}
@@ -0,0 +1,328 @@
+package ml
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Task is the kind of problem of AutoFit
+type Task int
+
+const (
+	// RegressionTask estimates continuous output
+	RegressionTask Task = iota
+	// ClassificationTask estimates probability
+	// of binary output
+	ClassificationTask
+)
+
+// AutoCandidate is an estimator tried by AutoML
+type AutoCandidate struct {
+	Name string
+	// New returns an unfitted estimator, given the
+	// time left so it can limit its training, zero
+	// is no limit
+	New func(timeLeft time.Duration) Estimator
+}
+
+// LeaderboardEntry is the cross-validation score of
+// a candidate. Err is set when it failed and Skipped
+// when the time budget ran out before all its folds.
+type LeaderboardEntry struct {
+	Name     string
+	Score    float64
+	Std      float64
+	Duration time.Duration
+	Err      error
+	Skipped  bool
+}
+
+// AutoML cross validates Candidates within TimeBudget
+// and refits the best of them on all rows
+type AutoML struct {
+	Task       Task
+	Candidates []AutoCandidate
+	// CV defaults to 5 folds shuffled by Rand
+	CV Splitter
+	// Rand seeds the default folds,
+	// nil uses the global source
+	Rand *rand.Rand
+	// Metric defaults to R2 for regression and
+	// Accuracy for classification
+	Metric     Metric
+	TimeBudget time.Duration
+	Logger     Logger
+
+	// Best is the best candidate fitted on all rows
+	Best     Estimator
+	BestName string
+	// Leaderboard holds every candidate, best first
+	Leaderboard []LeaderboardEntry
+}
+
+// NewAutoML returns new pointer of AutoML of the
+// DefaultCandidates of task
+func NewAutoML(task Task, timeBudget time.Duration) *AutoML {
+	return &AutoML{
+		Task:       task,
+		Candidates: DefaultCandidates(task),
+		TimeBudget: timeBudget,
+	}
+}
+
+// AutoFit tries DefaultCandidates of task under cross
+// validation within timeBudget, zero is no limit, and
+// returns the best fitted estimator with the leaderboard
+func AutoFit(features [][]float64, output []float64, task Task, timeBudget time.Duration) (Estimator, []LeaderboardEntry, error) {
+	a := NewAutoML(task, timeBudget)
+	if err := a.Fit(features, output); err != nil {
+		return nil, a.Leaderboard, err
+	}
+	return a.Best, a.Leaderboard, nil
+}
+
+// DefaultCandidates returns pipelines of linear models,
+// with and without standardized features, for task
+func DefaultCandidates(task Task) []AutoCandidate {
+	limit := func(timeLeft time.Duration) Option {
+		return func(o *options) error {
+			if timeLeft > 0 {
+				o.setting().MaxDuration = timeLeft
+			}
+			return nil
+		}
+	}
+	if task == ClassificationTask {
+		logistic := func(timeLeft time.Duration) Estimator {
+			return NewPipeline(NewLogisticRegression(limit(timeLeft)), NewBiasTransformer())
+		}
+		scaled := func(timeLeft time.Duration) Estimator {
+			return NewPipeline(NewLogisticRegression(limit(timeLeft)), NewStandardScaler(), NewBiasTransformer())
+		}
+		calibrated := func(method CalibrationMethod) func(time.Duration) Estimator {
+			return func(timeLeft time.Duration) Estimator {
+				c := NewCalibratedClassifier(nil, method)
+				// inner folds and the final fit share timeLeft
+				share := timeLeft / time.Duration(c.CV.(KFold).K+1)
+				if timeLeft > 0 && share <= 0 {
+					share = time.Nanosecond
+				}
+				c.NewEstimator = func() Estimator { return scaled(share) }
+				return c
+			}
+		}
+		return []AutoCandidate{
+			{Name: "logistic", New: logistic},
+			{Name: "scaled logistic", New: scaled},
+			{Name: "scaled logistic, sigmoid calibrated", New: calibrated(SigmoidCalibration)},
+			{Name: "scaled logistic, isotonic calibrated", New: calibrated(IsotonicCalibration)},
+		}
+	}
+	return []AutoCandidate{
+		{Name: "linear", New: func(timeLeft time.Duration) Estimator {
+			return NewPipeline(NewLinearRegression(limit(timeLeft)), NewBiasTransformer())
+		}},
+		{Name: "scaled linear", New: func(timeLeft time.Duration) Estimator {
+			return NewPipeline(NewLinearRegression(limit(timeLeft)), NewStandardScaler(), NewBiasTransformer())
+		}},
+	}
+}
+
+// Fit scores every candidate while time is left, then
+// fits the best one on features and output. Candidates
+// are scored on the same folds, each fold with its share
+// of half the time left, the other half is for the rest.
+func (a *AutoML) Fit(features [][]float64, output []float64) error {
+	if len(a.Candidates) == 0 {
+		return fmt.Errorf("ml: no candidates")
+	}
+	if len(features) != len(output) {
+		return fmt.Errorf("ml: got %d rows of features and %d outputs", len(features), len(output))
+	}
+	metric := a.Metric
+	if metric == nil {
+		metric = R2
+		if a.Task == ClassificationTask {
+			metric = Accuracy
+		}
+	}
+	cv := a.CV
+	if cv == nil {
+		cv = KFold{K: 5, Shuffle: true, Seed: RandOf(a.Rand).Int63()}
+	}
+	log := a.Logger
+	if log == nil {
+		log = packageLogger()
+	}
+
+	start := time.Now()
+	timeLeft := func() time.Duration {
+		if a.TimeBudget <= 0 {
+			return 0
+		}
+		return a.TimeBudget - time.Since(start)
+	}
+	outOfTime := func() bool {
+		return a.TimeBudget > 0 && timeLeft() <= 0
+	}
+
+	folds, err := cv.Split(len(features))
+	if err != nil {
+		return err
+	}
+	a.Best, a.BestName = nil, ""
+	a.Leaderboard = a.Leaderboard[:0]
+	for _, c := range a.Candidates {
+		entry := LeaderboardEntry{Name: c.Name, Score: math.NaN(), Std: math.NaN()}
+		if outOfTime() {
+			entry.Skipped = true
+			a.Leaderboard = append(a.Leaderboard, entry)
+			continue
+		}
+
+		began := time.Now()
+		// the refit of the best candidate needs time too
+		deadline := began.Add(timeLeft() / 2)
+		scores := make([]float64, 0, len(folds))
+		var err error
+		for f, fold := range folds {
+			if f > 0 && outOfTime() {
+				entry.Skipped = true
+				log.Warn("ml: candidate ran out of time", "candidate", c.Name, "folds", f)
+				break
+			}
+			var left time.Duration
+			if a.TimeBudget > 0 {
+				left = time.Until(deadline) / time.Duration(len(folds)-f)
+				if left <= 0 {
+					left = time.Nanosecond
+				}
+			}
+			var score float64
+			score, err = crossValidateFold(context.Background(), func() Estimator { return c.New(left) }, features, output, f, fold, metric)
+			if err != nil {
+				break
+			}
+			scores = append(scores, score)
+		}
+		entry.Duration = time.Since(began)
+		switch {
+		case err != nil:
+			entry.Err = err
+			log.Warn("ml: candidate failed", "candidate", c.Name, "err", err)
+		case !entry.Skipped:
+			entry.Score, entry.Std = stat.MeanStdDev(scores, nil)
+			log.Info("ml: candidate scored", "candidate", c.Name, "score", entry.Score, "duration", entry.Duration)
+		}
+		a.Leaderboard = append(a.Leaderboard, entry)
+	}
+
+	sort.SliceStable(a.Leaderboard, func(i, j int) bool {
+		x, y := a.Leaderboard[i].Score, a.Leaderboard[j].Score
+		return x > y || (!math.IsNaN(x) && math.IsNaN(y))
+	})
+	best := a.Leaderboard[0]
+	if math.IsNaN(best.Score) {
+		return fmt.Errorf("ml: no candidate could be scored")
+	}
+
+	for _, c := range a.Candidates {
+		if c.Name != best.Name {
+			continue
+		}
+		left := timeLeft()
+		if a.TimeBudget > 0 && left <= 0 {
+			// no time is left, but a model is
+			// better than none
+			left = best.Duration
+		}
+		estimator := c.New(left)
+		if err := estimator.Fit(features, output); err != nil {
+			return fmt.Errorf("ml: refitting %s: %v", c.Name, err)
+		}
+		a.Best, a.BestName = estimator, c.Name
+		break
+	}
+	return nil
+}
+
+// autoMLState is the saved part of AutoML, errors of the
+// leaderboard are saved as their messages
+type autoMLState struct {
+	Task        Task
+	CV          Splitter
+	TimeBudget  time.Duration
+	Best        Estimator
+	BestName    string
+	Leaderboard []leaderboardState
+}
+
+type leaderboardState struct {
+	Name     string
+	Score    float64
+	Std      float64
+	Duration time.Duration
+	Err      string
+	Skipped  bool
+}
+
+// GobEncode saves the best estimator and the leaderboard.
+// Candidates, Metric, Logger and Rand are not saved, set
+// them again before refitting.
+func (a *AutoML) GobEncode() ([]byte, error) {
+	s := autoMLState{
+		Task:       a.Task,
+		CV:         a.CV,
+		TimeBudget: a.TimeBudget,
+		Best:       a.Best,
+		BestName:   a.BestName,
+	}
+	for _, e := range a.Leaderboard {
+		entry := leaderboardState{
+			Name:     e.Name,
+			Score:    e.Score,
+			Std:      e.Std,
+			Duration: e.Duration,
+			Skipped:  e.Skipped,
+		}
+		if e.Err != nil {
+			entry.Err = e.Err.Error()
+		}
+		s.Leaderboard = append(s.Leaderboard, entry)
+	}
+	return encodeGob(&s)
+}
+
+// GobDecode restores a saved AutoML
+func (a *AutoML) GobDecode(data []byte) error {
+	var s autoMLState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	a.Task, a.CV, a.TimeBudget = s.Task, s.CV, s.TimeBudget
+	a.Best, a.BestName = s.Best, s.BestName
+	a.Leaderboard = nil
+	for _, e := range s.Leaderboard {
+		entry := LeaderboardEntry{
+			Name:     e.Name,
+			Score:    e.Score,
+			Std:      e.Std,
+			Duration: e.Duration,
+			Skipped:  e.Skipped,
+		}
+		if e.Err != "" {
+			entry.Err = errors.New(e.Err)
+		}
+		a.Leaderboard = append(a.Leaderboard, entry)
+	}
+	return nil
+}
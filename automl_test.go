@@ -0,0 +1,110 @@
+package ml
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// recorder is a mean estimator recording its train
+// rows and waiting sleep on every fit
+type recorder struct {
+	sleep time.Duration
+	fits  *[]string
+	mean  float64
+}
+
+func (r *recorder) Fit(features [][]float64, output []float64) error {
+	time.Sleep(r.sleep)
+	*r.fits = append(*r.fits, fmt.Sprint(features))
+	r.mean = mean(output)
+	return nil
+}
+
+func (r *recorder) Estimate(X []float64) float64 {
+	return r.mean
+}
+
+func TestAutoMLSplitsBudget(t *testing.T) {
+	features, output := lineData(20, 2)
+	var (
+		fits  []string
+		lefts []time.Duration
+	)
+	a := &AutoML{
+		Candidates: []AutoCandidate{{Name: "mean", New: func(timeLeft time.Duration) Estimator {
+			lefts = append(lefts, timeLeft)
+			return &recorder{fits: &fits}
+		}}},
+		CV:         KFold{K: 5},
+		TimeBudget: 10 * time.Second,
+	}
+	if err := a.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if len(lefts) != 6 {
+		t.Fatalf("%d estimators, want 5 folds and a refit", len(lefts))
+	}
+	// a fold shares the rest of half the budget with the
+	// folds after it, time a fold left over rolls on
+	for f, left := range lefts[:5] {
+		if left <= 0 || left*time.Duration(5-f) > 5*time.Second {
+			t.Errorf("fold %d budget %v, want at most 5s/%d", f, left, 5-f)
+		}
+	}
+	if refit := lefts[5]; refit < 9*time.Second {
+		t.Errorf("refit got %v, want the time left", refit)
+	}
+}
+
+func TestAutoMLDeadlineBetweenFolds(t *testing.T) {
+	features, output := lineData(20, 2)
+	var fits []string
+	a := &AutoML{
+		Candidates: []AutoCandidate{{Name: "slow", New: func(timeLeft time.Duration) Estimator {
+			return &recorder{sleep: 30 * time.Millisecond, fits: &fits}
+		}}},
+		CV:         KFold{K: 5},
+		TimeBudget: 50 * time.Millisecond,
+	}
+	if err := a.Fit(features, output); err == nil {
+		t.Error("no error when no candidate was scored")
+	}
+	// 30ms folds pass the 50ms deadline after about 2
+	if len(fits) == 0 || len(fits) >= 5 || !a.Leaderboard[0].Skipped {
+		t.Errorf("%d folds fitted and entry %+v, want to stop between folds", len(fits), a.Leaderboard[0])
+	}
+}
+
+func TestAutoMLSameFolds(t *testing.T) {
+	features, output := lineData(20, 2)
+	run := func(seed int64) []string {
+		var fits []string
+		newRecorder := func(timeLeft time.Duration) Estimator { return &recorder{fits: &fits} }
+		a := &AutoML{
+			Candidates: []AutoCandidate{{Name: "a", New: newRecorder}, {Name: "b", New: newRecorder}},
+			Rand:       rand.New(rand.NewSource(seed)),
+		}
+		if err := a.Fit(features, output); err != nil {
+			t.Fatal(err)
+		}
+		return fits
+	}
+
+	fits := run(1)
+	if len(fits) != 11 {
+		t.Fatalf("%d fits, want 5 folds of 2 candidates and a refit", len(fits))
+	}
+	for f := 0; f < 5; f++ {
+		if fits[f] != fits[5+f] {
+			t.Errorf("candidates trained on different rows of fold %d", f)
+		}
+	}
+	if again := run(1); fmt.Sprint(again) != fmt.Sprint(fits) {
+		t.Error("the same Rand seed gave different folds")
+	}
+	if other := run(2); fmt.Sprint(other) == fmt.Sprint(fits) {
+		t.Error("another Rand seed gave the same folds")
+	}
+}
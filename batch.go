@@ -0,0 +1,120 @@
+package ml
+
+import (
+	"fmt"
+	"sync"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BatchEstimator estimates many rows at once,
+// faster than calling Estimate on every row. It
+// returns an error of rows it cannot estimate,
+// such as rows of the wrong length.
+type BatchEstimator interface {
+	PredictBatch(X [][]float64) ([]float64, error)
+}
+
+// dot returns X·θ of every row of X, or the
+// hypothesis of every row when it is a custom one.
+// Rows are not copied into a matrix as the copy
+// costs more than the products it would speed up.
+func (l *Linear) dot(X [][]float64) ([]float64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]float64, len(X))
+	if !l.IsLinear() {
+		for i, x := range X {
+			out[i] = l.Hypothesis(x, l.Theta)
+		}
+		return out, nil
+	}
+
+	m := len(l.Theta)
+	for i, x := range X {
+		if len(x) != m {
+			return nil, fmt.Errorf("ml: row %d has %d columns, expected %d", i, len(x), m)
+		}
+		out[i] = floats.Dot(x, l.Theta)
+	}
+	return out, nil
+}
+
+// dotMatrix is dot of the rows of X with a single
+// matrix-vector product
+func (l *Linear) dotMatrix(X mat.Matrix) []float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	n, _ := X.Dims()
+	if n == 0 {
+		return nil
+	}
+	if !l.IsLinear() {
+		_, m := X.Dims()
+		row := getRow(m)
+		defer putRow(row)
+
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = l.Hypothesis(mat.Row(*row, i, X), l.Theta)
+		}
+		return out
+	}
+
+	out := make([]float64, n)
+	mat.NewVecDense(n, out).MulVec(X, mat.NewVecDense(len(l.Theta), l.Theta))
+	return out
+}
+
+// PredictBatch returns predicted value of every row of X
+func (l *LinearRegression) PredictBatch(X [][]float64) ([]float64, error) {
+	return l.dot(X)
+}
+
+// PredictMatrix returns predicted value of every row of X
+func (l *LinearRegression) PredictMatrix(X mat.Matrix) []float64 {
+	return l.dotMatrix(X)
+}
+
+// PredictBatch returns probability of every row
+// of X being true
+func (l *LogisticRegression) PredictBatch(X [][]float64) ([]float64, error) {
+	z, err := l.dot(X)
+	if err != nil {
+		return nil, err
+	}
+	return sigmoidAll(z), nil
+}
+
+// PredictMatrix returns probability of every row
+// of X being true
+func (l *LogisticRegression) PredictMatrix(X mat.Matrix) []float64 {
+	return sigmoidAll(l.dotMatrix(X))
+}
+
+func sigmoidAll(z []float64) []float64 {
+	for i, v := range z {
+		z[i] = sigmoid(v)
+	}
+	return z
+}
+
+// rowPool holds row buffers of batch scoring
+var rowPool = sync.Pool{
+	New: func() any { return new([]float64) },
+}
+
+// getRow returns a buffer of n values from rowPool
+func getRow(n int) *[]float64 {
+	row := rowPool.Get().(*[]float64)
+	if cap(*row) < n {
+		*row = make([]float64, n)
+	}
+	*row = (*row)[:n]
+	return row
+}
+
+func putRow(row *[]float64) {
+	rowPool.Put(row)
+}
@@ -0,0 +1,68 @@
+package ml
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// squaredHypothesis is a custom hypothesis, which makes
+// batch scoring convert rows through the row pool
+func squaredHypothesis(X, theta []float64) float64 {
+	z := linearHypothesis(X, theta)
+	return z * z
+}
+
+func TestPredictBatchWrongLength(t *testing.T) {
+	linear := NewLinearRegression()
+	linear.Theta = []float64{1, 2}
+	logistic := NewLogisticRegression()
+	logistic.Theta = []float64{1, 2}
+	inner := NewLinearRegression()
+	inner.Theta = []float64{1, 2}
+
+	// the pipeline adds the bias column
+	cases := []struct {
+		model BatchEstimator
+		row   []float64
+	}{
+		{linear, []float64{1, 2}},
+		{logistic, []float64{1, 2}},
+		{NewPipeline(inner, NewBiasTransformer()), []float64{2}},
+	}
+	for _, c := range cases {
+		if _, err := c.model.PredictBatch([][]float64{c.row}); err != nil {
+			t.Errorf("%T: %v", c.model, err)
+		}
+		long := append(c.row, 3)
+		if estimates, err := c.model.PredictBatch([][]float64{c.row, long}); err == nil {
+			t.Errorf("%T estimated %v of a row of %d columns", c.model, estimates, len(long))
+		}
+	}
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	features, _, theta := benchData(2000, 50)
+	l := NewLinearRegression()
+	l.Theta = theta
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.PredictBatch(features)
+	}
+}
+
+func BenchmarkPredictMatrixCustomHypothesis(b *testing.B) {
+	features, _, theta := benchData(2000, 50)
+	X := mat.NewDense(len(features), len(theta), nil)
+	for i, x := range features {
+		X.SetRow(i, x)
+	}
+	l := NewLinearRegression()
+	l.Theta, l.Hypothesis = theta, squaredHypothesis
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.PredictMatrix(X)
+	}
+}
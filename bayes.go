@@ -0,0 +1,296 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Acquisition chooses the next params of a BayesSearch
+// from the surrogate model of the objective
+type Acquisition int
+
+const (
+	// ExpectedImprovement picks params of the highest
+	// expected improvement over the best score
+	ExpectedImprovement Acquisition = iota
+	// UpperConfidenceBound picks params of the highest
+	// mean plus Kappa standard deviations
+	UpperConfidenceBound
+)
+
+// BayesSearch maximizes an objective, usually a
+// CVObjective, with a Gaussian process surrogate of
+// the objective. After Initial random trials, every trial
+// evaluates the params maximizing the Acquisition, so
+// fewer trials are needed than with random or grid search.
+type BayesSearch struct {
+	Space     SearchSpace
+	Objective Objective
+	// Budget is the number of trials
+	Budget int
+	// MaxDuration stops the search after the running
+	// trial, zero is no limit
+	MaxDuration time.Duration
+	// Initial random trials, defaults to 5
+	Initial     int
+	Acquisition Acquisition
+	// Kappa of UpperConfidenceBound, defaults to 2
+	Kappa float64
+	// Candidates scored by the acquisition every
+	// trial, defaults to 1000
+	Candidates int
+	Rand       *rand.Rand
+	Logger     Logger
+
+	// Trials are evaluated trials in order
+	Trials []Trial
+}
+
+// NewBayesSearch returns new pointer of BayesSearch
+// of budget trials
+func NewBayesSearch(space SearchSpace, objective Objective, budget int) *BayesSearch {
+	return &BayesSearch{
+		Space:      space,
+		Objective:  objective,
+		Budget:     budget,
+		Initial:    5,
+		Kappa:      2,
+		Candidates: 1000,
+	}
+}
+
+// Search runs the trials and returns the best one
+func (b *BayesSearch) Search() (Trial, error) {
+	if err := b.Space.check(); err != nil {
+		return Trial{}, err
+	}
+	if b.Budget < 1 {
+		return Trial{}, fmt.Errorf("ml: budget of %d trials", b.Budget)
+	}
+	r := RandOf(b.Rand)
+	log := b.Logger
+	if log == nil {
+		log = packageLogger()
+	}
+	start := time.Now()
+	b.Trials = b.Trials[:0]
+
+	var (
+		X [][]float64
+		y []float64
+	)
+	for t := 0; t < b.Budget; t++ {
+		if b.MaxDuration > 0 && time.Since(start) > b.MaxDuration {
+			log.Warn("ml: search ran out of time", "trials", t)
+			break
+		}
+
+		var u []float64
+		if t < b.Initial || len(y) < 2 {
+			u = randomUnit(r, len(b.Space))
+		} else {
+			u = b.next(r, X, y)
+		}
+
+		p := b.Space.unit(u)
+		score, err := b.Objective(p)
+		if err != nil {
+			return Trial{}, err
+		}
+		b.Trials = append(b.Trials, Trial{Params: p, Score: score})
+		log.Debug("ml: trial", "trial", t, "params", p, "score", score)
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		// the surrogate sees rounded params
+		X = append(X, b.Space.toUnit(p))
+		y = append(y, score)
+	}
+
+	return bestTrial(b.Trials)
+}
+
+// next returns the unit point of candidates
+// maximizing the acquisition
+func (b *BayesSearch) next(r *rand.Rand, X [][]float64, y []float64) []float64 {
+	gp := fitGP(X, y)
+	if gp == nil {
+		return randomUnit(r, len(b.Space))
+	}
+
+	best, incumbent := math.Inf(-1), 0
+	for i, v := range y {
+		if v > best {
+			best, incumbent = v, i
+		}
+	}
+	best = (best - gp.mean) / gp.std
+
+	candidates := b.Candidates
+	if candidates < 1 {
+		candidates = 1000
+	}
+	var (
+		next  []float64
+		score = math.Inf(-1)
+	)
+	for c := 0; c < candidates; c++ {
+		var u []float64
+		if c%4 == 0 {
+			// a quarter of candidates are close
+			// to the best params so far
+			u = make([]float64, len(X[incumbent]))
+			for j, v := range X[incumbent] {
+				u[j] = math.Min(math.Max(v+0.05*r.NormFloat64(), 0), 1)
+			}
+		} else {
+			u = randomUnit(r, len(b.Space))
+		}
+
+		mu, sigma := gp.predict(u)
+		var a float64
+		switch b.Acquisition {
+		case UpperConfidenceBound:
+			a = mu + b.Kappa*sigma
+		default:
+			a = expectedImprovement(mu, sigma, best)
+		}
+		if a > score {
+			next, score = u, a
+		}
+	}
+	return next
+}
+
+func randomUnit(r *rand.Rand, n int) []float64 {
+	u := make([]float64, n)
+	for j := range u {
+		u[j] = r.Float64()
+	}
+	return u
+}
+
+// bestTrial returns the trial of the highest score
+func bestTrial(trials []Trial) (Trial, error) {
+	best := -1
+	for i, t := range trials {
+		if math.IsNaN(t.Score) {
+			continue
+		}
+		if best < 0 || t.Score > trials[best].Score {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Trial{}, fmt.Errorf("ml: no trial has a score")
+	}
+	return trials[best], nil
+}
+
+// expectedImprovement of a normal score of mean mu and
+// standard deviation sigma over best
+func expectedImprovement(mu, sigma, best float64) float64 {
+	const xi = 0.01
+	if sigma <= 0 {
+		return math.Max(mu-best-xi, 0)
+	}
+	z := (mu - best - xi) / sigma
+	cdf := 0.5 * math.Erfc(-z/math.Sqrt2)
+	pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	return (mu-best-xi)*cdf + sigma*pdf
+}
+
+// gaussianProcess is a Gaussian process regression with
+// a Matérn 5/2 kernel of standardized scores
+type gaussianProcess struct {
+	X           [][]float64
+	alpha       *mat.VecDense
+	chol        mat.Cholesky
+	lengthScale float64
+	mean, std   float64
+}
+
+// fitGP fits a Gaussian process to scores y of unit
+// points X, choosing length scale and noise of the
+// highest marginal likelihood. It returns nil when no
+// kernel matrix can be factorized.
+func fitGP(X [][]float64, y []float64) *gaussianProcess {
+	n := len(y)
+	mean := 0.0
+	for _, v := range y {
+		mean += v
+	}
+	mean /= float64(n)
+	std := 0.0
+	for _, v := range y {
+		std += (v - mean) * (v - mean)
+	}
+	std = math.Sqrt(std / float64(n))
+	if std == 0 {
+		std = 1
+	}
+	z := mat.NewVecDense(n, nil)
+	for i, v := range y {
+		z.SetVec(i, (v-mean)/std)
+	}
+
+	var (
+		best     *gaussianProcess
+		bestLike = math.Inf(-1)
+	)
+	for _, l := range []float64{0.05, 0.1, 0.2, 0.4, 0.8, 1.6} {
+		for _, noise := range []float64{1e-6, 1e-3, 1e-2, 1e-1} {
+			gp := &gaussianProcess{X: X, lengthScale: l, mean: mean, std: std}
+			K := mat.NewSymDense(n, nil)
+			for i := 0; i < n; i++ {
+				for j := i; j < n; j++ {
+					K.SetSym(i, j, gp.kernel(X[i], X[j]))
+				}
+				K.SetSym(i, i, 1+noise)
+			}
+			if !gp.chol.Factorize(K) {
+				continue
+			}
+			gp.alpha = mat.NewVecDense(n, nil)
+			if err := gp.chol.SolveVecTo(gp.alpha, z); err != nil {
+				continue
+			}
+			like := -0.5*mat.Dot(z, gp.alpha) - 0.5*gp.chol.LogDet()
+			if like > bestLike {
+				best, bestLike = gp, like
+			}
+		}
+	}
+	return best
+}
+
+func (gp *gaussianProcess) kernel(a, b []float64) float64 {
+	d := 0.0
+	for j := range a {
+		d += (a[j] - b[j]) * (a[j] - b[j])
+	}
+	r := math.Sqrt(5*d) / gp.lengthScale
+	return (1 + r + r*r/3) * math.Exp(-r)
+}
+
+// predict returns standardized mean and
+// standard deviation of the score at u
+func (gp *gaussianProcess) predict(u []float64) (float64, float64) {
+	n := len(gp.X)
+	k := mat.NewVecDense(n, nil)
+	for i, x := range gp.X {
+		k.SetVec(i, gp.kernel(u, x))
+	}
+	mu := mat.Dot(k, gp.alpha)
+
+	v := mat.NewVecDense(n, nil)
+	if err := gp.chol.SolveVecTo(v, k); err != nil {
+		return mu, 0
+	}
+	variance := 1 - mat.Dot(k, v)
+	return mu, math.Sqrt(math.Max(variance, 0))
+}
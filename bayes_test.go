@@ -0,0 +1,90 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestExpectedImprovement(t *testing.T) {
+	// (μ-best-ξ)Φ(z) + σφ(z) with z = (μ-best-ξ)/σ, ξ = 0.01
+	cases := []struct{ mu, sigma, best, want float64 }{
+		{1, 1, 0, 1.074914161991277},
+		{0, 1, 0, 0.3939622273492285},
+		{0, 0.5, 1, 0.004023177636087026},
+		{2, 0, 1, 0.99},
+		{0, 0, 1, 0},
+	}
+	for _, c := range cases {
+		if got := expectedImprovement(c.mu, c.sigma, c.best); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("EI(%v, %v, %v) = %v, want %v", c.mu, c.sigma, c.best, got, c.want)
+		}
+	}
+}
+
+func TestSearchSpaceUnit(t *testing.T) {
+	space := SearchSpace{
+		{Name: "rate", Min: 1e-3, Max: 10, Log: true},
+		{Name: "depth", Min: 1, Max: 10, Integer: true},
+	}
+	p := space.unit([]float64{0.5, 0.5})
+	if math.Abs(p["rate"]-0.1) > 1e-12 || p["depth"] != 6 {
+		t.Errorf("midpoint params %v, want rate 0.1 and depth 6", p)
+	}
+	u := space.toUnit(Params{"rate": 1, "depth": 10})
+	if math.Abs(u[0]-0.75) > 1e-12 || u[1] != 1 {
+		t.Errorf("unit point %v, want [0.75 1]", u)
+	}
+}
+
+func TestGaussianProcessInterpolates(t *testing.T) {
+	var (
+		X [][]float64
+		y []float64
+	)
+	for i := 0; i <= 10; i++ {
+		x := float64(i) / 10
+		X = append(X, []float64{x})
+		y = append(y, math.Sin(6*x))
+	}
+	gp := fitGP(X, y)
+	if gp == nil {
+		t.Fatal("no kernel matrix was factorized")
+	}
+	for i, x := range X {
+		mu, sigma := gp.predict(x)
+		if got := mu*gp.std + gp.mean; math.Abs(got-y[i]) > 0.05 || sigma > 0.1 {
+			t.Errorf("at %v: mean %v sd %v, want %v and small sd", x[0], got, sigma, y[i])
+		}
+	}
+	if _, sigma := gp.predict([]float64{3}); sigma < 0.9 {
+		t.Errorf("far from the data sd is %v, want near 1", sigma)
+	}
+}
+
+func TestBayesSearchFindsOptimum(t *testing.T) {
+	space := SearchSpace{{Name: "x", Min: 0, Max: 1}, {Name: "y", Min: -1, Max: 1}}
+	calls := 0
+	objective := func(p Params) (float64, error) {
+		calls++
+		dx, dy := p["x"]-0.3, p["y"]+0.4
+		return -dx*dx - dy*dy, nil
+	}
+
+	for _, acq := range []Acquisition{ExpectedImprovement, UpperConfidenceBound} {
+		calls = 0
+		search := NewBayesSearch(space, objective, 25)
+		search.Acquisition = acq
+		search.Rand = rand.New(rand.NewSource(1))
+		best, err := search.Search()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 25 || len(search.Trials) != 25 {
+			t.Errorf("acquisition %v: %d calls and %d trials, want 25", acq, calls, len(search.Trials))
+		}
+		if best.Score < -1e-3 {
+			t.Errorf("acquisition %v: best %v scores %v, want the optimum x 0.3 y -0.4", acq, best.Params, best.Score)
+		}
+	}
+}
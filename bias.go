@@ -0,0 +1,25 @@
+package ml
+
+// BiasTransformer prepends a bias column of ones to
+// features, so a linear model learns an intercept as
+// its first theta. BiasTransformer is a Transformer.
+type BiasTransformer struct{}
+
+// NewBiasTransformer returns new pointer of BiasTransformer
+func NewBiasTransformer() *BiasTransformer {
+	return &BiasTransformer{}
+}
+
+// Fit does nothing, the bias column needs no training
+func (b *BiasTransformer) Fit(features [][]float64, output []float64) error {
+	return nil
+}
+
+// Transform returns features with a leading column of ones
+func (b *BiasTransformer) Transform(features [][]float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, row := range features {
+		out[i] = append([]float64{1}, row...)
+	}
+	return out
+}
@@ -0,0 +1,225 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// StatFunc computes statistics of the rows idx of a
+// dataset, rows may appear more than once
+type StatFunc func(idx []int) []float64
+
+// Interval is a confidence interval
+type Interval struct {
+	Lower float64
+	Upper float64
+}
+
+// BootstrapResult holds bootstrap replicates of statistics
+type BootstrapResult struct {
+	// Estimate is the statistics on all rows
+	Estimate []float64
+	// Replicates are the statistics of every resample
+	Replicates [][]float64
+
+	size int
+	stat StatFunc
+}
+
+// Bootstrap computes stat on n resamples drawn with
+// replacement from size rows, concurrently. stat must be
+// safe for concurrent use and return the same number of
+// statistics for every resample, NaN when it fails.
+func Bootstrap(n, size int, stat StatFunc) *BootstrapResult {
+	return BootstrapRand(nil, n, size, stat)
+}
+
+// BootstrapRand is Bootstrap drawing resamples from r,
+// nil uses the global source. Every resample has its own
+// seed drawn from r, so results do not depend on the
+// order workers run in.
+func BootstrapRand(r *rand.Rand, n, size int, stat StatFunc) *BootstrapResult {
+	r = RandOf(r)
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = r.Int63()
+	}
+
+	all := make([]int, size)
+	for i := range all {
+		all[i] = i
+	}
+
+	b := &BootstrapResult{
+		Estimate:   stat(all),
+		Replicates: make([][]float64, n),
+		size:       size,
+		stat:       stat,
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				rng := rand.New(rand.NewSource(seeds[k]))
+				idx := make([]int, size)
+				for i := range idx {
+					idx[i] = rng.Intn(size)
+				}
+				b.Replicates[k] = stat(idx)
+			}
+		}()
+	}
+
+	for r := 0; r < n; r++ {
+		jobs <- r
+	}
+	close(jobs)
+	wg.Wait()
+
+	return b
+}
+
+// Percentile returns percentile intervals of every
+// statistic at confidence level 1-alpha
+func (b *BootstrapResult) Percentile(alpha float64) []Interval {
+	intervals := make([]Interval, len(b.Estimate))
+	for k := range intervals {
+		r := b.replicates(k)
+		intervals[k] = Interval{
+			Lower: quantile(alpha/2, r),
+			Upper: quantile(1-alpha/2, r),
+		}
+	}
+	return intervals
+}
+
+// BCa returns bias-corrected and accelerated intervals of
+// every statistic at confidence level 1-alpha. The
+// acceleration is estimated by jackknife, computing the
+// statistics once per row.
+func (b *BootstrapResult) BCa(alpha float64) []Interval {
+	jackknife := make([][]float64, b.size)
+	idx := make([]int, b.size-1)
+	for i := range jackknife {
+		idx = idx[:0]
+		for k := 0; k < b.size; k++ {
+			if k != i {
+				idx = append(idx, k)
+			}
+		}
+		jackknife[i] = b.stat(idx)
+	}
+
+	normal := distuv.UnitNormal
+	intervals := make([]Interval, len(b.Estimate))
+	for k := range intervals {
+		r := b.replicates(k)
+		if len(r) == 0 {
+			intervals[k] = Interval{math.NaN(), math.NaN()}
+			continue
+		}
+
+		below := 0.0
+		for _, v := range r {
+			if v < b.Estimate[k] {
+				below++
+			}
+		}
+		// half a replicate keeps z0 finite when no or
+		// every replicate is below the estimate
+		n := float64(len(r))
+		z0 := normal.Quantile(math.Max(0.5, math.Min(n-0.5, below)) / n)
+
+		jack := make([]float64, len(jackknife))
+		for i := range jackknife {
+			jack[i] = jackknife[i][k]
+		}
+		jackMean := stat.Mean(jack, nil)
+		num, den := 0.0, 0.0
+		for _, v := range jack {
+			d := jackMean - v
+			num += d * d * d
+			den += d * d
+		}
+		a := 0.0
+		if den > 0 {
+			a = num / (6 * math.Pow(den, 1.5))
+		}
+
+		adjust := func(p float64) float64 {
+			z := normal.Quantile(p)
+			return normal.CDF(z0 + (z0+z)/(1-a*(z0+z)))
+		}
+
+		intervals[k] = Interval{
+			Lower: quantile(adjust(alpha/2), r),
+			Upper: quantile(adjust(1-alpha/2), r),
+		}
+	}
+
+	return intervals
+}
+
+// replicates returns sorted finite replicates of statistic k
+func (b *BootstrapResult) replicates(k int) []float64 {
+	r := make([]float64, 0, len(b.Replicates))
+	for _, rep := range b.Replicates {
+		if !math.IsNaN(rep[k]) {
+			r = append(r, rep[k])
+		}
+	}
+	sort.Float64s(r)
+	return r
+}
+
+// quantile returns quantile p of sorted x
+func quantile(p float64, x []float64) float64 {
+	if len(x) == 0 || math.IsNaN(p) {
+		return math.NaN()
+	}
+	return stat.Quantile(math.Max(0, math.Min(1, p)), stat.LinInterp, x, nil)
+}
+
+// MetricStat returns a StatFunc computing metric
+// over rows of output and estimates
+func MetricStat(output, estimates []float64, metric Metric) StatFunc {
+	return func(idx []int) []float64 {
+		y := make([]float64, len(idx))
+		e := make([]float64, len(idx))
+		for k, i := range idx {
+			y[k] = output[i]
+			e[k] = estimates[i]
+		}
+		return []float64{metric(y, e)}
+	}
+}
+
+// CoefficientStat returns a StatFunc fitting a LinearModel
+// on rows of features and output and returning its
+// coefficients, all NaN when the fit fails
+func CoefficientStat(newEstimator func() Estimator, features [][]float64, output []float64) StatFunc {
+	return func(idx []int) []float64 {
+		X, y := subset(features, output, idx)
+
+		model, ok := newEstimator().(LinearModel)
+		if ok && model.Fit(X, y) == nil {
+			return append([]float64(nil), model.Coefficients()...)
+		}
+
+		nan := make([]float64, len(features[0]))
+		for j := range nan {
+			nan[j] = math.NaN()
+		}
+		return nan
+	}
+}
@@ -0,0 +1,91 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// meanStat returns a StatFunc of the mean of rows of x
+func meanStat(x []float64) StatFunc {
+	return func(idx []int) []float64 {
+		sum := 0.0
+		for _, i := range idx {
+			sum += x[i]
+		}
+		return []float64{sum / float64(len(idx))}
+	}
+}
+
+func TestBCaOfMean(t *testing.T) {
+	// the jackknife acceleration of the mean is
+	// Σd³ / 6(Σd²)^1.5 = 180 / 6·50^1.5
+	x := []float64{1, 2, 3, 10}
+	replicates := make([][]float64, 999)
+	sorted := make([]float64, 999)
+	for i := range replicates {
+		sorted[i] = float64(i+1) / 100
+		replicates[i] = []float64{sorted[i]}
+	}
+	b := &BootstrapResult{
+		Estimate:   []float64{4},
+		Replicates: replicates,
+		size:       len(x),
+		stat:       meanStat(x),
+	}
+
+	// 399 replicates of 0.01 to 3.99 are below 4
+	a := 180 / (6 * math.Pow(50, 1.5))
+	z0 := distuv.UnitNormal.Quantile(399.0 / 999)
+	bca := func(p float64) float64 {
+		z := distuv.UnitNormal.Quantile(p)
+		return quantile(distuv.UnitNormal.CDF(z0+(z0+z)/(1-a*(z0+z))), sorted)
+	}
+
+	got := b.BCa(0.1)[0]
+	if want := (Interval{bca(0.05), bca(0.95)}); math.Abs(got.Lower-want.Lower) > 1e-9 || math.Abs(got.Upper-want.Upper) > 1e-9 {
+		t.Errorf("interval %+v, want %+v", got, want)
+	}
+}
+
+func TestBCaOfSymmetricReplicates(t *testing.T) {
+	// symmetric rows have no acceleration and half the
+	// replicates below the estimate have no bias, so
+	// BCa is the percentile interval
+	x := []float64{-2, -1, 0, 1, 2}
+	replicates := make([][]float64, 100)
+	for i := range replicates {
+		replicates[i] = []float64{float64(i) - 49.5}
+	}
+	b := &BootstrapResult{Estimate: []float64{0}, Replicates: replicates, size: len(x), stat: meanStat(x)}
+
+	bca, percentile := b.BCa(0.05)[0], b.Percentile(0.05)[0]
+	if math.Abs(bca.Lower-percentile.Lower) > 1e-9 || math.Abs(bca.Upper-percentile.Upper) > 1e-9 {
+		t.Errorf("BCa %+v, want percentile %+v", bca, percentile)
+	}
+}
+
+func TestBCaEstimateOutsideReplicates(t *testing.T) {
+	// the minimum of a resample is never below the
+	// minimum of all rows
+	x := make([]float64, 30)
+	r := rand.New(rand.NewSource(1))
+	for i := range x {
+		x[i] = r.NormFloat64()
+	}
+	minimum := func(idx []int) []float64 {
+		m := math.Inf(1)
+		for _, i := range idx {
+			m = math.Min(m, x[i])
+		}
+		return []float64{m}
+	}
+
+	b := BootstrapRand(rand.New(rand.NewSource(2)), 200, len(x), minimum)
+	got := b.BCa(0.05)[0]
+	if math.IsNaN(got.Lower) || math.IsInf(got.Lower, 0) || math.IsNaN(got.Upper) || got.Lower > got.Upper {
+		t.Errorf("interval %+v of no replicate below the estimate", got)
+	}
+}
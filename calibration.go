@@ -0,0 +1,204 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// CalibrationMethod is the calibrator
+// used by CalibratedClassifier
+type CalibrationMethod int
+
+const (
+	// SigmoidCalibration fits Platt scaling
+	SigmoidCalibration CalibrationMethod = iota
+	// IsotonicCalibration fits an IsotonicRegression,
+	// it needs more data than sigmoid calibration
+	IsotonicCalibration
+)
+
+// CalibratedClassifier calibrates probabilities of a
+// classifier. The calibrator is fitted on out-of-fold
+// estimates, then the classifier is refitted on all rows.
+type CalibratedClassifier struct {
+	NewEstimator func() Estimator
+	Method       CalibrationMethod
+	// CV splits rows to get held-out estimates,
+	// defaults to 3 folds
+	CV Splitter
+
+	// Estimator is the classifier fitted on all rows
+	Estimator Estimator
+	// Calibrator maps a score of Estimator,
+	// as a single column, to a probability
+	Calibrator Estimator
+}
+
+// NewCalibratedClassifier returns new pointer
+// of CalibratedClassifier
+func NewCalibratedClassifier(newEstimator func() Estimator, method CalibrationMethod) *CalibratedClassifier {
+	return &CalibratedClassifier{
+		NewEstimator: newEstimator,
+		Method:       method,
+		CV:           KFold{K: 3},
+	}
+}
+
+// Fit fits the calibrator on held-out estimates
+// then fits the classifier on features and output
+func (c *CalibratedClassifier) Fit(features [][]float64, output []float64) error {
+	scores, err := CrossValEstimate(c.NewEstimator, features, output, c.CV)
+	if err != nil {
+		return err
+	}
+
+	switch c.Method {
+	case SigmoidCalibration:
+		c.Calibrator = &PlattScaling{}
+	case IsotonicCalibration:
+		c.Calibrator = NewIsotonicRegression()
+	default:
+		return fmt.Errorf("ml: unknown calibration method %d", c.Method)
+	}
+
+	if err = c.Calibrator.Fit(columnOf(scores), output); err != nil {
+		return err
+	}
+
+	c.Estimator = c.NewEstimator()
+	return c.Estimator.Fit(features, output)
+}
+
+// Estimate returns calibrated probability of X being true
+func (c *CalibratedClassifier) Estimate(X []float64) float64 {
+	return c.Calibrator.Estimate([]float64{c.Estimator.Estimate(X)})
+}
+
+// PlattScaling maps a score s to probability
+// sigmoid(A*s+B). It is an Estimator over a
+// single column of scores.
+type PlattScaling struct {
+	A float64
+	B float64
+}
+
+// Fit fits A and B by maximum likelihood using
+// Platt's smoothed targets to avoid overfitting
+func (p *PlattScaling) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 || len(features) != len(output) {
+		return fmt.Errorf("ml: got %d scores and %d outputs", len(features), len(output))
+	}
+
+	positives := 0.0
+	for _, y := range output {
+		positives += y
+	}
+	negatives := float64(len(output)) - positives
+	hi := (positives + 1) / (positives + 2)
+	lo := 1 / (negatives + 2)
+
+	targets := make([]float64, len(output))
+	for i, y := range output {
+		targets[i] = lo
+		if y == 1 {
+			targets[i] = hi
+		}
+	}
+
+	prob := optimize.Problem{
+		Func: func(ab []float64) float64 {
+			loss := 0.0
+			for i, X := range features {
+				z := ab[0]*X[0] + ab[1]
+				// log(1+exp(z)) - t*z, stable for large |z|
+				loss += math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z))) - targets[i]*z
+			}
+			return loss
+		},
+		Grad: func(grad, ab []float64) {
+			grad[0], grad[1] = 0, 0
+			for i, X := range features {
+				d := sigmoid(ab[0]*X[0]+ab[1]) - targets[i]
+				grad[0] += d * X[0]
+				grad[1] += d
+			}
+		},
+	}
+
+	init := []float64{0, math.Log((positives + 1) / (negatives + 1))}
+	result, err := optimize.Minimize(prob, init, nil, &optimize.BFGS{})
+	if err = numopt.CheckStall(result, err); err != nil {
+		return fmt.Errorf("ml: platt scaling: %w", err)
+	}
+
+	p.A, p.B = result.X[0], result.X[1]
+	return nil
+}
+
+// Estimate returns calibrated probability of score X[0]
+func (p *PlattScaling) Estimate(X []float64) float64 {
+	return sigmoid(p.A*X[0] + p.B)
+}
+
+// BrierScore returns mean squared difference of
+// probabilities and output, lower is better
+func BrierScore(output, probabilities []float64) float64 {
+	return MeanSquaredError(output, probabilities)
+}
+
+// Reliability is a reliability (calibration) curve.
+// A calibrated classifier has Fraction close to
+// Predicted in every bin.
+type Reliability struct {
+	// Predicted is mean probability of every bin
+	Predicted []float64
+	// Fraction is fraction of true output of every bin
+	Fraction []float64
+	// Count is number of rows of every bin
+	Count []int
+}
+
+// ReliabilityCurve bins probabilities into bins of equal
+// width. Empty bins are left out of the curve.
+func ReliabilityCurve(output, probabilities []float64, bins int) *Reliability {
+	sumP := make([]float64, bins)
+	sumY := make([]float64, bins)
+	count := make([]int, bins)
+
+	for i, p := range probabilities {
+		b := int(p * float64(bins))
+		if b >= bins {
+			b = bins - 1
+		}
+		if b < 0 {
+			b = 0
+		}
+		sumP[b] += p
+		sumY[b] += output[i]
+		count[b]++
+	}
+
+	r := &Reliability{}
+	for b := range count {
+		if count[b] == 0 {
+			continue
+		}
+		r.Predicted = append(r.Predicted, sumP[b]/float64(count[b]))
+		r.Fraction = append(r.Fraction, sumY[b]/float64(count[b]))
+		r.Count = append(r.Count, count[b])
+	}
+
+	return r
+}
+
+// columnOf returns x as a single column of features
+func columnOf(x []float64) [][]float64 {
+	features := make([][]float64, len(x))
+	for i, v := range x {
+		features[i] = []float64{v}
+	}
+	return features
+}
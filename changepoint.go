@@ -0,0 +1,362 @@
+package ml
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SegmentCost measures how badly a single distribution
+// fits a segment of series, lower is better
+type SegmentCost interface {
+	// Fit prepares the cost of series
+	Fit(series [][]float64)
+	// Cost returns cost of series[start:end]
+	Cost(start, end int) float64
+}
+
+// NormalMeanCost is the cost of a change in mean: sum of
+// squared deviations from the segment mean
+type NormalMeanCost struct {
+	sum, squares [][]float64
+}
+
+type normalMeanState struct {
+	Sum, Squares [][]float64
+}
+
+// GobEncode saves the cumulative sums of the last Fit
+func (c *NormalMeanCost) GobEncode() ([]byte, error) {
+	return encodeGob(&normalMeanState{c.sum, c.squares})
+}
+
+// GobDecode restores a saved NormalMeanCost
+func (c *NormalMeanCost) GobDecode(data []byte) error {
+	var s normalMeanState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	c.sum, c.squares = s.Sum, s.Squares
+	return nil
+}
+
+// NormalMeanVarCost is the cost of a change in mean and
+// variance: segment length times log of its variance.
+// Short segments have tiny variances, so use it with a
+// MinSize of at least a few observations.
+type NormalMeanVarCost struct {
+	NormalMeanCost
+}
+
+// Fit computes cumulative sums of series
+func (c *NormalMeanCost) Fit(series [][]float64) {
+	c.sum = make([][]float64, len(series)+1)
+	c.squares = make([][]float64, len(series)+1)
+	dims := 0
+	if len(series) > 0 {
+		dims = len(series[0])
+	}
+	c.sum[0] = make([]float64, dims)
+	c.squares[0] = make([]float64, dims)
+	for t, x := range series {
+		c.sum[t+1] = make([]float64, dims)
+		c.squares[t+1] = make([]float64, dims)
+		for j, v := range x {
+			c.sum[t+1][j] = c.sum[t][j] + v
+			c.squares[t+1][j] = c.squares[t][j] + v*v
+		}
+	}
+}
+
+// Cost returns sum of squared deviations of every column
+func (c *NormalMeanCost) Cost(start, end int) float64 {
+	n := float64(end - start)
+	cost := 0.0
+	for j := range c.sum[0] {
+		s := c.sum[end][j] - c.sum[start][j]
+		cost += c.squares[end][j] - c.squares[start][j] - s*s/n
+	}
+	return cost
+}
+
+// Cost returns segment length times log variance
+// of every column
+func (c *NormalMeanVarCost) Cost(start, end int) float64 {
+	n := float64(end - start)
+	cost := 0.0
+	for j := range c.sum[0] {
+		s := c.sum[end][j] - c.sum[start][j]
+		variance := (c.squares[end][j] - c.squares[start][j] - s*s/n) / n
+		cost += n * math.Log(math.Max(variance, minVariance))
+	}
+	return cost
+}
+
+// PELT detects change points by pruned exact linear
+// time search, minimizing total segment cost plus
+// Penalty per change point
+type PELT struct {
+	Cost    SegmentCost
+	Penalty float64
+	// MinSize is the minimum length of a segment
+	MinSize int
+}
+
+// NewPELT returns new pointer of PELT detecting
+// changes in mean
+func NewPELT(penalty float64) *PELT {
+	return &PELT{
+		Cost:    &NormalMeanCost{},
+		Penalty: penalty,
+		MinSize: 2,
+	}
+}
+
+// Detect returns indices where new segments of series
+// start, in increasing order
+func (p *PELT) Detect(series [][]float64) ([]int, error) {
+	n := len(series)
+	minSize := p.MinSize
+	if minSize < 1 {
+		minSize = 1
+	}
+	if n < minSize {
+		return nil, fmt.Errorf("ml: series of %d observations is shorter than MinSize %d", n, minSize)
+	}
+	p.Cost.Fit(series)
+
+	best := make([]float64, n+1)
+	last := make([]int, n+1)
+	best[0] = -p.Penalty
+	candidates := []int{0}
+
+	for t := 1; t <= n; t++ {
+		best[t] = math.Inf(1)
+		for _, s := range candidates {
+			if t-s < minSize {
+				continue
+			}
+			if v := best[s] + p.Cost.Cost(s, t) + p.Penalty; v < best[t] {
+				best[t], last[t] = v, s
+			}
+		}
+
+		// a start that cannot beat the best segmentation
+		// now never will, since costs only add up
+		kept := candidates[:0]
+		for _, s := range candidates {
+			if t-s < minSize || best[s]+p.Cost.Cost(s, t) <= best[t] {
+				kept = append(kept, s)
+			}
+		}
+		candidates = kept
+		if !math.IsInf(best[t], 1) {
+			candidates = append(candidates, t)
+		}
+	}
+
+	var points []int
+	for t := last[n]; t > 0; t = last[t] {
+		points = append(points, t)
+	}
+	sort.Ints(points)
+
+	return points, nil
+}
+
+// BinarySegmentation detects change points by greedily
+// splitting the segment with the largest cost reduction,
+// while the reduction exceeds Penalty
+type BinarySegmentation struct {
+	Cost    SegmentCost
+	Penalty float64
+	MinSize int
+	// MaxChanges limits number of change points,
+	// zero means no limit
+	MaxChanges int
+}
+
+// NewBinarySegmentation returns new pointer of
+// BinarySegmentation detecting changes in mean
+func NewBinarySegmentation(penalty float64) *BinarySegmentation {
+	return &BinarySegmentation{
+		Cost:    &NormalMeanCost{},
+		Penalty: penalty,
+		MinSize: 2,
+	}
+}
+
+// Detect returns indices where new segments of series
+// start, in increasing order
+func (b *BinarySegmentation) Detect(series [][]float64) ([]int, error) {
+	n := len(series)
+	minSize := b.MinSize
+	if minSize < 1 {
+		minSize = 1
+	}
+	if n < minSize {
+		return nil, fmt.Errorf("ml: series of %d observations is shorter than MinSize %d", n, minSize)
+	}
+	b.Cost.Fit(series)
+
+	bounds := []int{0, n}
+	for b.MaxChanges == 0 || len(bounds)-2 < b.MaxChanges {
+		gain, split := 0.0, -1
+		for i := 0; i+1 < len(bounds); i++ {
+			start, end := bounds[i], bounds[i+1]
+			whole := b.Cost.Cost(start, end)
+			for s := start + minSize; s <= end-minSize; s++ {
+				if g := whole - b.Cost.Cost(start, s) - b.Cost.Cost(s, end); g > gain {
+					gain, split = g, s
+				}
+			}
+		}
+		if split < 0 || gain <= b.Penalty {
+			break
+		}
+
+		bounds = append(bounds, split)
+		sort.Ints(bounds)
+	}
+
+	return bounds[1 : len(bounds)-1], nil
+}
+
+// CUSUM detects shifts in mean of a stream online with
+// two-sided cumulative sums of standardized observations,
+// signalling when any column's sum exceeds Threshold
+type CUSUM struct {
+	// Target and Scale standardize observations, when nil
+	// they are estimated from the first Warmup observations
+	Target []float64
+	Scale  []float64
+	Warmup int
+	// Slack is the shift in standard deviations
+	// ignored by the sums
+	Slack     float64
+	Threshold float64
+
+	High, Low []float64
+	seen      int
+	warmup    [][]float64
+}
+
+// NewCUSUM returns new pointer of CUSUM with
+// slack 0.5 and threshold 5
+func NewCUSUM(warmup int) *CUSUM {
+	return &CUSUM{
+		Warmup:    warmup,
+		Slack:     0.5,
+		Threshold: 5,
+	}
+}
+
+// Update adds observation x and returns whether a change
+// is signalled, after which the sums restart
+func (c *CUSUM) Update(x []float64) bool {
+	c.seen++
+	if c.Target == nil || c.Scale == nil {
+		c.warmup = append(c.warmup, append([]float64(nil), x...))
+		if len(c.warmup) < c.Warmup || len(c.warmup) < 2 {
+			return false
+		}
+		c.estimate()
+		return false
+	}
+	if c.High == nil {
+		c.High = make([]float64, len(x))
+		c.Low = make([]float64, len(x))
+	}
+
+	alarm := false
+	for j, v := range x {
+		z := (v - c.Target[j]) / c.Scale[j]
+		c.High[j] = math.Max(0, c.High[j]+z-c.Slack)
+		c.Low[j] = math.Max(0, c.Low[j]-z-c.Slack)
+		if c.High[j] > c.Threshold || c.Low[j] > c.Threshold {
+			alarm = true
+		}
+	}
+	if alarm {
+		c.Reset()
+	}
+
+	return alarm
+}
+
+// Reset restarts the sums, re-estimating Target and
+// Scale when they were estimated
+func (c *CUSUM) Reset() {
+	c.High, c.Low = nil, nil
+	if c.warmup != nil {
+		c.Target, c.Scale, c.warmup = nil, nil, nil
+	}
+}
+
+func (c *CUSUM) estimate() {
+	dims := len(c.warmup[0])
+	c.Target = make([]float64, dims)
+	c.Scale = make([]float64, dims)
+	for j := range c.Target {
+		col := column(c.warmup, j)
+		m := mean(col)
+		variance := 0.0
+		for _, v := range col {
+			variance += (v - m) * (v - m)
+		}
+		c.Target[j] = m
+		c.Scale[j] = math.Sqrt(math.Max(variance/float64(len(col)-1), minVariance))
+	}
+}
+
+type cusumState struct {
+	Target, Scale    []float64
+	Warmup           int
+	Slack, Threshold float64
+	High, Low        []float64
+	Seen             int
+	WarmupRows       [][]float64
+}
+
+// GobEncode saves the sums and the warmup observations,
+// so a loaded CUSUM continues the stream
+func (c *CUSUM) GobEncode() ([]byte, error) {
+	return encodeGob(&cusumState{
+		Target:     c.Target,
+		Scale:      c.Scale,
+		Warmup:     c.Warmup,
+		Slack:      c.Slack,
+		Threshold:  c.Threshold,
+		High:       c.High,
+		Low:        c.Low,
+		Seen:       c.seen,
+		WarmupRows: c.warmup,
+	})
+}
+
+// GobDecode restores a saved CUSUM
+func (c *CUSUM) GobDecode(data []byte) error {
+	var s cusumState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	c.Target, c.Scale, c.Warmup = s.Target, s.Scale, s.Warmup
+	c.Slack, c.Threshold = s.Slack, s.Threshold
+	c.High, c.Low = s.High, s.Low
+	c.seen, c.warmup = s.Seen, s.WarmupRows
+	return nil
+}
+
+// Detect runs the stream over series and returns indices
+// where changes are signalled
+func (c *CUSUM) Detect(series [][]float64) []int {
+	var points []int
+	for t, x := range series {
+		if c.Update(x) {
+			points = append(points, t)
+		}
+	}
+	return points
+}
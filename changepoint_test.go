@@ -0,0 +1,70 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// shiftedSeries returns a unit noise series of means 0,
+// 5 and -3 changing at 50 and 100
+func shiftedSeries() [][]float64 {
+	rnd := rand.New(rand.NewSource(1))
+	series := make([][]float64, 150)
+	for i := range series {
+		m := 0.0
+		switch {
+		case i >= 100:
+			m = -3
+		case i >= 50:
+			m = 5
+		}
+		series[i] = []float64{m + rnd.NormFloat64()}
+	}
+	return series
+}
+
+func TestNormalMeanCost(t *testing.T) {
+	// squared deviations of 1, 2, 3 from 2
+	c := &NormalMeanCost{}
+	c.Fit([][]float64{{0}, {1}, {2}, {3}})
+	if got := c.Cost(1, 4); math.Abs(got-2) > 1e-12 {
+		t.Errorf("cost %v, want 2", got)
+	}
+}
+
+func TestDetectChangesInMean(t *testing.T) {
+	series := shiftedSeries()
+	penalty := 3 * math.Log(float64(len(series)))
+	for name, d := range map[string]interface {
+		Detect([][]float64) ([]int, error)
+	}{
+		"PELT":                NewPELT(penalty),
+		"binary segmentation": NewBinarySegmentation(penalty),
+	} {
+		points, err := d.Detect(series)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(points) != 2 || points[0] != 50 || points[1] != 100 {
+			t.Errorf("%s detected %v, want [50 100]", name, points)
+		}
+	}
+}
+
+func TestCUSUM(t *testing.T) {
+	// a shift of 2 standard deviations adds 1.5 over the
+	// slack every step, passing 5 on the 4th step
+	series := make([][]float64, 28)
+	for i := range series {
+		series[i] = []float64{0}
+		if i >= 20 {
+			series[i][0] = 2
+		}
+	}
+	c := NewCUSUM(0)
+	c.Target, c.Scale = []float64{0}, []float64{1}
+	if got := c.Detect(series); len(got) != 2 || got[0] != 23 || got[1] != 27 {
+		t.Errorf("signalled %v, want [23 27]", got)
+	}
+}
@@ -0,0 +1,125 @@
+package ml
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Checkpoint is the training state written to
+// Linear.Checkpoint. BFGS rebuilds its Hessian
+// estimate after a resume, so only thetas are kept,
+// but SGD with Adam keeps its moments too.
+type Checkpoint struct {
+	Iteration int
+	Theta     []float64
+	Loss      float64
+	// Adam is nil without Adam
+	Adam *AdamState
+}
+
+// AdamState holds the moments of Adam
+// after T steps
+type AdamState struct {
+	M, V []float64
+	T    int
+}
+
+// ReadCheckpoint returns the last complete checkpoint
+// of r. A checkpoint cut short by a preempted job is
+// ignored.
+func ReadCheckpoint(r io.Reader) (*Checkpoint, error) {
+	dec := gob.NewDecoder(r)
+
+	var last *Checkpoint
+	for {
+		var c Checkpoint
+		if err := dec.Decode(&c); err != nil {
+			if last == nil {
+				return nil, fmt.Errorf("ml: no checkpoint: %v", err)
+			}
+			return last, nil
+		}
+		last = &c
+	}
+}
+
+// ResumeFrom sets thetas to the last checkpoint of r,
+// the next Fit or Minimize continues from them
+func (l *Linear) ResumeFrom(r io.Reader) error {
+	c, err := ReadCheckpoint(r)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.Theta = c.Theta
+	l.resume = c
+	l.mu.Unlock()
+	l.logger().Info("ml: resuming training", "iteration", c.Iteration, "loss", c.Loss)
+
+	return nil
+}
+
+// checkpointRecorder writes a Checkpoint every
+// every major iterations
+type checkpointRecorder struct {
+	enc    *gob.Encoder
+	every  int
+	offset int
+	iter   int
+}
+
+func newCheckpointRecorder(w io.Writer, every, offset int) *checkpointRecorder {
+	if every <= 0 {
+		every = 1
+	}
+	return &checkpointRecorder{enc: gob.NewEncoder(w), every: every, offset: offset}
+}
+
+func (r *checkpointRecorder) Init() error {
+	return nil
+}
+
+func (r *checkpointRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if op&optimize.MajorIteration == 0 {
+		return nil
+	}
+	r.iter++
+	if r.iter%r.every != 0 {
+		return nil
+	}
+
+	c := Checkpoint{
+		Iteration: r.offset + r.iter,
+		Theta:     append([]float64(nil), loc.X...),
+		Loss:      loc.F,
+	}
+	if err := r.enc.Encode(&c); err != nil {
+		return fmt.Errorf("ml: checkpoint: %v", err)
+	}
+	return nil
+}
+
+// recorders calls every recorder in order
+type recorders []optimize.Recorder
+
+func (rs recorders) Init() error {
+	for _, r := range rs {
+		if err := r.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs recorders) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	for _, r := range rs {
+		if err := r.Record(loc, op, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
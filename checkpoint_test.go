@@ -0,0 +1,97 @@
+package ml
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// adamRun trains Adam full batch on lineData, so row
+// order does not change the steps
+func adamRun(model *LinearRegression, epochs int) *SGD {
+	s := NewAdam(model)
+	s.Step = 0.1
+	s.Epochs = epochs
+	s.BatchSize = 100
+	s.Rand = rand.New(rand.NewSource(1))
+	return s
+}
+
+func closeThetas(a, b []float64) bool {
+	for j := range a {
+		if math.Abs(a[j]-b[j]) > 1e-12 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestAdamResumeFrom(t *testing.T) {
+	features, output := lineData(100, 2)
+	source := Slices{features, output}
+
+	straight := NewLinearRegression()
+	if err := adamRun(straight, 6).Fit(source); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	first := NewLinearRegression()
+	first.Checkpoint = &buf
+	if err := adamRun(first, 3).Fit(source); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadCheckpoint(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Iteration != 3 || c.Adam == nil || c.Adam.T != 3 || len(c.Adam.M) != 2 || len(c.Adam.V) != 2 {
+		t.Fatalf("checkpoint %+v, want Adam moments after 3 steps", c)
+	}
+
+	resumed := NewLinearRegression()
+	if err := resumed.ResumeFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := adamRun(resumed, 3).Fit(source); err != nil {
+		t.Fatal(err)
+	}
+	if !closeThetas(resumed.Theta, straight.Theta) {
+		t.Errorf("resumed thetas %v, want %v of training straight", resumed.Theta, straight.Theta)
+	}
+}
+
+func TestAdamWarmStart(t *testing.T) {
+	features, output := lineData(100, 2)
+	source := Slices{features, output}
+
+	straight := NewLinearRegression()
+	if err := adamRun(straight, 6).Fit(source); err != nil {
+		t.Fatal(err)
+	}
+
+	warm := NewLinearRegression()
+	warm.WarmStart = true
+	s := adamRun(warm, 3)
+	for i := 0; i < 2; i++ {
+		if err := s.Fit(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !closeThetas(warm.Theta, straight.Theta) {
+		t.Errorf("warm started thetas %v, want %v of training straight", warm.Theta, straight.Theta)
+	}
+
+	// without WarmStart moments start over too
+	cold := NewLinearRegression()
+	s = adamRun(cold, 3)
+	for i := 0; i < 2; i++ {
+		if err := s.Fit(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.Adam.t != 3 {
+		t.Errorf("%d Adam steps, want 3 of the last fit", s.Adam.t)
+	}
+}
@@ -0,0 +1,204 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+// ConditionalLogistic is a logistic regression conditional
+// on the number of cases of every stratum, such as matched
+// case-control sets, so stratum intercepts drop out of the
+// likelihood. Features have no bias column, constant
+// columns are not estimable. Strata of any number of cases
+// are fitted by the exact likelihood.
+type ConditionalLogistic struct {
+	// Strata are strata of every training row
+	Strata []int
+
+	Theta []float64
+	// StdErrors come from the observed information
+	StdErrors []float64
+
+	loglik     float64
+	samples    int
+	covariance [][]float64
+}
+
+// NewConditionalLogistic returns new pointer of
+// ConditionalLogistic of rows in strata
+func NewConditionalLogistic(strata []int) *ConditionalLogistic {
+	return &ConditionalLogistic{Strata: strata}
+}
+
+// Fit trains the model on features and output, 1 for
+// cases and 0 for controls
+func (c *ConditionalLogistic) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if len(c.Strata) != len(features) {
+		return fmt.Errorf("ml: got %d strata for %d rows", len(c.Strata), len(features))
+	}
+	for i, y := range output {
+		if y != 0 && y != 1 {
+			return fmt.Errorf("ml: output %d is %v, not 0 or 1", i, y)
+		}
+	}
+	for j := 0; j < p; j++ {
+		if stat.Variance(column(features, j), nil) == 0 {
+			return fmt.Errorf("ml: column %d is constant, it drops out of the conditional likelihood", j)
+		}
+	}
+
+	// rows of strata with both cases and controls
+	var strata [][]int
+	index := map[int]int{}
+	cases := map[int]int{}
+	for i, s := range c.Strata {
+		k, ok := index[s]
+		if !ok {
+			k = len(strata)
+			index[s] = k
+			strata = append(strata, nil)
+		}
+		strata[k] = append(strata[k], i)
+		cases[k] += int(output[i])
+	}
+	informative := strata[:0]
+	for k, rows := range strata {
+		if cases[k] > 0 && cases[k] < len(rows) {
+			informative = append(informative, rows)
+		}
+	}
+	if len(informative) == 0 {
+		return fmt.Errorf("ml: no stratum has both cases and controls")
+	}
+
+	ll := func(theta, grad []float64) float64 {
+		return conditionalLikelihood(features, output, informative, theta, grad)
+	}
+	theta, err := maximizeLikelihood(ll, make([]float64, p), len(output))
+	if err != nil {
+		return fmt.Errorf("ml: conditional logistic: %v", err)
+	}
+	c.Theta = theta
+	c.loglik = ll(theta, nil)
+	c.samples = len(output)
+
+	c.covariance, err = observedCovariance(ll, theta)
+	if err != nil {
+		c.StdErrors = nil
+		return nil
+	}
+	c.StdErrors = make([]float64, p)
+	for j := range c.StdErrors {
+		c.StdErrors[j] = math.Sqrt(c.covariance[j][j])
+	}
+	return nil
+}
+
+// conditionalLikelihood returns log-likelihood of theta,
+// filling grad when not nil. The denominator of a stratum
+// of m cases sums exp(θ·x) over every m rows, built up one
+// row at a time as in Gail, Lubin and Rubinstein (1981).
+func conditionalLikelihood(features [][]float64, output []float64, strata [][]int, theta, grad []float64) float64 {
+	p := len(theta)
+	for j := range grad {
+		grad[j] = 0
+	}
+	ll := 0.0
+	for _, rows := range strata {
+		m := 0
+		eta := make([]float64, len(rows))
+		for r, i := range rows {
+			eta[r] = floats.Dot(features[i], theta)
+			m += int(output[i])
+		}
+		// shift by the largest η against overflow
+		shift := floats.Max(eta)
+
+		// B[k] sums exp(η) of every k rows so far
+		B := make([]float64, m+1)
+		dB := make([][]float64, m+1)
+		for k := range dB {
+			dB[k] = make([]float64, p)
+		}
+		B[0] = 1
+		for r, i := range rows {
+			w := math.Exp(eta[r] - shift)
+			for k := min(m, r+1); k >= 1; k-- {
+				if grad != nil {
+					floats.AddScaled(dB[k], w, dB[k-1])
+					floats.AddScaled(dB[k], w*B[k-1], features[i])
+				}
+				B[k] += w * B[k-1]
+			}
+			if output[i] == 1 {
+				ll += eta[r] - shift
+				if grad != nil {
+					floats.Add(grad, features[i])
+				}
+			}
+		}
+		ll -= math.Log(B[m])
+		if grad != nil {
+			floats.AddScaled(grad, -1/B[m], dB[m])
+		}
+	}
+	return ll
+}
+
+// Estimate returns odds ratio exp(θ·X) of X against
+// a row of zero features of the same stratum
+func (c *ConditionalLogistic) Estimate(X []float64) float64 {
+	return math.Exp(floats.Dot(X, c.Theta))
+}
+
+// Probability returns the probability of every row of a
+// stratum of one case being the case
+func (c *ConditionalLogistic) Probability(stratum [][]float64) []float64 {
+	prob := make([]float64, len(stratum))
+	for r, x := range stratum {
+		prob[r] = floats.Dot(x, c.Theta)
+	}
+	shift := floats.Max(prob)
+	for r := range prob {
+		prob[r] = math.Exp(prob[r] - shift)
+	}
+	floats.Scale(1/floats.Sum(prob), prob)
+	return prob
+}
+
+// Coefficients returns thetas
+func (c *ConditionalLogistic) Coefficients() []float64 {
+	return c.Theta
+}
+
+// CoefficientCovariance returns covariance of thetas,
+// the inverse observed information of the training rows
+func (c *ConditionalLogistic) CoefficientCovariance() ([][]float64, error) {
+	if c.covariance == nil {
+		return nil, fmt.Errorf("ml: covariance needs a model fitted with a regular information matrix")
+	}
+	return c.covariance, nil
+}
+
+// LogLikelihood returns conditional log-likelihood
+// of the training data
+func (c *ConditionalLogistic) LogLikelihood() float64 {
+	return c.loglik
+}
+
+// NumParams returns the number of thetas
+func (c *ConditionalLogistic) NumParams() int {
+	return len(c.Theta)
+}
+
+// NumSamples returns the number of training rows
+func (c *ConditionalLogistic) NumSamples() int {
+	return c.samples
+}
@@ -0,0 +1,86 @@
+package ml
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Cloner is a model copying itself. A clone shares no
+// mutable state with the original, so it can be fitted
+// or used by its own goroutine.
+type Cloner interface {
+	Clone() Estimator
+}
+
+// Clone returns an independent copy of model, made by its
+// Clone method or else by a Save and Load round trip, which
+// needs a registered model. Use it to fit copies of a model
+// concurrently, e.g. in hyperparameter search.
+func Clone(model Estimator) (Estimator, error) {
+	if c, ok := model.(Cloner); ok {
+		return c.Clone(), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Save(&buf, model); err != nil {
+		return nil, fmt.Errorf("ml: cannot clone %T: %v", model, err)
+	}
+	v, err := Load(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("ml: cannot clone %T: %v", model, err)
+	}
+
+	clone, ok := v.(Estimator)
+	if !ok {
+		return nil, fmt.Errorf("ml: cannot clone %T: loaded %T", model, v)
+	}
+	return clone, nil
+}
+
+// cloneInto copies thetas, settings and hypothesis to c.
+// Training data, result and checkpoint writer are left
+// out, metrics and logger are shared as they are safe
+// for concurrent use.
+func (l *Linear) cloneInto(c *Linear) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c.FeatureNames = l.FeatureNames
+	c.Theta = append([]float64(nil), l.Theta...)
+	c.LearningRate = l.LearningRate
+	c.Hypothesis = l.Hypothesis
+	c.Metrics, c.metricLabels = l.Metrics, l.metricLabels
+	c.Logger = l.Logger
+	c.CheckpointEvery = l.CheckpointEvery
+	c.WarmStart = l.WarmStart
+	c.Loss = l.Loss
+	c.optionErr = l.optionErr
+	if l.Setting != nil {
+		s := *l.Setting
+		if k := s.Constraints; k != nil {
+			s.Constraints = &Constraints{
+				Lower:    append([]float64(nil), k.Lower...),
+				Upper:    append([]float64(nil), k.Upper...),
+				Equality: k.Equality,
+				Values:   k.Values,
+			}
+		}
+		c.Setting = &s
+	}
+}
+
+// Clone returns an independent copy of the model
+func (l *LinearRegression) Clone() Estimator {
+	c := &LinearRegression{}
+	l.cloneInto(&c.Linear)
+	return c
+}
+
+// Clone returns an independent copy of the model
+func (l *LogisticRegression) Clone() Estimator {
+	l.mu.RLock()
+	c := &LogisticRegression{TrueDegree: l.TrueDegree, trueDegreeSet: l.trueDegreeSet}
+	l.mu.RUnlock()
+	l.cloneInto(&c.Linear)
+	return c
+}
@@ -0,0 +1,129 @@
+package ml
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func lineData(n int, slope float64) ([][]float64, []float64) {
+	features := make([][]float64, n)
+	output := make([]float64, n)
+	for i := range features {
+		x := float64(i) / float64(n)
+		features[i] = []float64{1, x}
+		output[i] = 1 + slope*x
+	}
+	return features, output
+}
+
+// TestConcurrentFitEstimateClone runs under go test -race
+func TestConcurrentFitEstimateClone(t *testing.T) {
+	features, output := lineData(50, 2)
+	model := NewLinearRegression()
+	if err := model.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for g := 0; g < 4; g++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if v := model.Estimate([]float64{1, 0.5}); math.IsNaN(v) {
+					t.Error("NaN estimate")
+					return
+				}
+				model.PredictBatch(features[:5])
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- model.Fit(features, output)
+		}()
+		go func(slope float64) {
+			defer wg.Done()
+			c, err := Clone(model)
+			if err != nil {
+				errs <- err
+				return
+			}
+			f, o := lineData(50, slope)
+			errs <- c.Fit(f, o)
+		}(float64(g + 3))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := model.Estimate([]float64{1, 1}); math.Abs(got-3) > 1e-4 {
+		t.Errorf("clones changed the model, estimate %v, want 3", got)
+	}
+}
+
+func TestCloneIndependent(t *testing.T) {
+	features, output := lineData(20, 2)
+	model := NewLogisticRegression()
+	model.Theta = []float64{0.5, -1}
+	model.TrueDegree = 0.7
+
+	v, err := Clone(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := v.(*LogisticRegression)
+	c.Theta[0] = 10
+	if model.Theta[0] != 0.5 || c.TrueDegree != 0.7 {
+		t.Errorf("clone shares thetas or lost TrueDegree")
+	}
+
+	for i := range output {
+		output[i] = float64(i % 2)
+	}
+	if err := c.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if c.Features == nil || model.Features != nil {
+		t.Errorf("training data of the clone leaked to the model")
+	}
+}
+
+// TestConcurrentFitClonesOfMethod runs under go test -race
+func TestConcurrentFitClonesOfMethod(t *testing.T) {
+	model := NewLinearRegression(WithMethod(func() optimize.Method { return &optimize.NelderMead{} }))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(slope float64) {
+			defer wg.Done()
+			c, err := Clone(model)
+			if err != nil {
+				errs <- err
+				return
+			}
+			f, o := lineData(50, slope)
+			if err = c.Fit(f, o); err != nil {
+				errs <- err
+				return
+			}
+			if got := c.(*LinearRegression).Theta[1]; math.Abs(got-slope) > 1e-3 {
+				t.Errorf("slope %v, want %v", got, slope)
+			}
+		}(float64(g + 1))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
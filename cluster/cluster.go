@@ -0,0 +1,71 @@
+// Package cluster groups rows of features without output.
+// Clusterers are ml.Estimator, Fit ignores output and
+// Estimate returns the cluster of a row, so they can end
+// a pipeline; Labels hold clusters of the training rows.
+package cluster
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Distance returns the distance between rows a and b
+type Distance func(a, b []float64) float64
+
+// Euclidean is the euclidean Distance
+func Euclidean(a, b []float64) float64 {
+	return floats.Distance(a, b, 2)
+}
+
+// Manhattan is the city block Distance
+func Manhattan(a, b []float64) float64 {
+	return floats.Distance(a, b, 1)
+}
+
+// checkFeatures returns an error unless features has
+// at least k rows of equal length
+func checkFeatures(features [][]float64, k int) error {
+	if k < 1 {
+		return fmt.Errorf("cluster: need at least 1 cluster, got %d", k)
+	}
+	if len(features) < k {
+		return fmt.Errorf("cluster: %d clusters of %d rows", k, len(features))
+	}
+	for i, row := range features {
+		if len(row) != len(features[0]) {
+			return fmt.Errorf("cluster: row %d has %d columns, expected %d", i, len(row), len(features[0]))
+		}
+	}
+	return nil
+}
+
+// nearest returns index of the row of centers
+// nearest to X by distance, and the distance
+func nearest(X []float64, centers [][]float64, distance Distance) (int, float64) {
+	best, d := 0, math.Inf(1)
+	for k, c := range centers {
+		if v := distance(X, c); v < d {
+			best, d = k, v
+		}
+	}
+	return best, d
+}
+
+// Distances returns the matrix of distance
+// between every pair of rows of features
+func Distances(features [][]float64, distance Distance) [][]float64 {
+	n := len(features)
+	d := make([][]float64, n)
+	for i := range d {
+		d[i] = make([]float64, n)
+	}
+	for i := range d {
+		for j := i + 1; j < n; j++ {
+			d[i][j] = distance(features[i], features[j])
+			d[j][i] = d[i][j]
+		}
+	}
+	return d
+}
@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// blobs returns two tight blobs of 10 rows, 10 times
+// scale apart
+func blobs(scale float64) [][]float64 {
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, 20)
+	for i := range features {
+		c := float64(i/10) * 10
+		features[i] = []float64{scale * (c + 0.1*r.NormFloat64()), scale * (c + 0.1*r.NormFloat64())}
+	}
+	return features
+}
+
+func twoClusters(t *testing.T, name string, labels []int) {
+	t.Helper()
+	for i, l := range labels {
+		if l != labels[i/10*10] || (i >= 10) == (l == labels[0]) {
+			t.Errorf("%s: labels %v, want the two blobs", name, labels)
+			return
+		}
+	}
+}
+
+func TestAutoParamsNotKept(t *testing.T) {
+	// auto gamma and bandwidth are estimated on every fit,
+	// those of small rows do not fit rows 1000 times larger
+	s := NewSpectralClustering(2)
+	s.Rand = rand.New(rand.NewSource(1))
+	m := NewMeanShift(0)
+	for _, scale := range []float64{1, 1000} {
+		features := blobs(scale)
+		if err := s.Fit(features, nil); err != nil {
+			t.Fatalf("spectral of scale %v: %v", scale, err)
+		}
+		twoClusters(t, "spectral", s.Labels)
+		if err := m.Fit(features, nil); err != nil {
+			t.Fatalf("mean shift of scale %v: %v", scale, err)
+		}
+		twoClusters(t, "mean shift", m.Labels)
+	}
+	if s.Gamma != 0 || m.Bandwidth != 0 {
+		t.Errorf("gamma %v and bandwidth %v were written back", s.Gamma, m.Bandwidth)
+	}
+}
@@ -0,0 +1,147 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/floats"
+)
+
+// FuzzyCMeans clusters rows with soft memberships of every
+// cluster (Bezdek, 1981), minimizing Σ uᵢₖᵐ|xᵢ-cₖ|² of
+// memberships u summing to one per row and fuzzifier m.
+// It is also an ml.Transformer of rows to memberships.
+type FuzzyCMeans struct {
+	Clusters int
+	// M is the fuzzifier, above 1, defaults to 2;
+	// memberships harden as it nears 1
+	M float64
+	// MaxIterations defaults to 300
+	MaxIterations int
+	// Tolerance on the change of memberships,
+	// defaults to 1e-5
+	Tolerance float64
+	// Rand draws the initial memberships, nil uses the
+	// global source
+	Rand *rand.Rand
+
+	Centers [][]float64
+	// Memberships of training rows
+	Memberships [][]float64
+	// Labels are clusters of highest
+	// membership of training rows
+	Labels []int
+}
+
+// NewFuzzyCMeans returns new pointer of
+// FuzzyCMeans of clusters
+func NewFuzzyCMeans(clusters int) *FuzzyCMeans {
+	return &FuzzyCMeans{Clusters: clusters, M: 2, MaxIterations: 300, Tolerance: 1e-5}
+}
+
+func (f *FuzzyCMeans) fuzzifier() float64 {
+	if f.M <= 1 {
+		return 2
+	}
+	return f.M
+}
+
+// Fit clusters rows of features, output is ignored
+func (f *FuzzyCMeans) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, f.Clusters); err != nil {
+		return err
+	}
+	iterations := f.MaxIterations
+	if iterations <= 0 {
+		iterations = 300
+	}
+	tolerance := f.Tolerance
+	if tolerance <= 0 {
+		tolerance = 1e-5
+	}
+	m := f.fuzzifier()
+	rng := ml.RandOf(f.Rand)
+	K, p := f.Clusters, len(features[0])
+
+	// random memberships
+	u := make([][]float64, len(features))
+	for i := range u {
+		u[i] = make([]float64, K)
+		for k := range u[i] {
+			u[i][k] = rng.Float64()
+		}
+		floats.Scale(1/floats.Sum(u[i]), u[i])
+	}
+	f.Centers = make([][]float64, K)
+	for k := range f.Centers {
+		f.Centers[k] = make([]float64, p)
+	}
+	for it := 0; it < iterations; it++ {
+		for k, c := range f.Centers {
+			for j := range c {
+				c[j] = 0
+			}
+			total := 0.0
+			for i, x := range features {
+				w := math.Pow(u[i][k], m)
+				floats.AddScaled(c, w, x)
+				total += w
+			}
+			floats.Scale(1/total, c)
+		}
+		moved := 0.0
+		for i, x := range features {
+			next := f.membership(x)
+			moved = math.Max(moved, floats.Distance(next, u[i], math.Inf(1)))
+			u[i] = next
+		}
+		if moved < tolerance {
+			break
+		}
+	}
+	f.Memberships = u
+	f.Labels = make([]int, len(u))
+	for i, row := range u {
+		f.Labels[i] = floats.MaxIdx(row)
+	}
+	return nil
+}
+
+// membership returns memberships of X of every
+// cluster, 1/Σₗ(dₖ/dₗ)^(2/(m-1)) of distances d
+func (f *FuzzyCMeans) membership(X []float64) []float64 {
+	u := make([]float64, len(f.Centers))
+	d := make([]float64, len(f.Centers))
+	for k, c := range f.Centers {
+		d[k] = floats.Distance(X, c, 2)
+		if d[k] == 0 {
+			// X is at the center
+			u[k] = 1
+			return u
+		}
+	}
+	power := 2 / (f.fuzzifier() - 1)
+	for k := range u {
+		s := 0.0
+		for l := range d {
+			s += math.Pow(d[k]/d[l], power)
+		}
+		u[k] = 1 / s
+	}
+	return u
+}
+
+// Transform returns memberships of every row of features
+func (f *FuzzyCMeans) Transform(features [][]float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, x := range features {
+		out[i] = f.membership(x)
+	}
+	return out
+}
+
+// Estimate returns the cluster of highest membership of X
+func (f *FuzzyCMeans) Estimate(X []float64) float64 {
+	return float64(floats.MaxIdx(f.membership(X)))
+}
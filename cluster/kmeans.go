@@ -0,0 +1,139 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/floats"
+)
+
+// KMeans clusters rows around K centers by Lloyd's
+// algorithm from k-means++ seeds (Arthur and Vassilvitskii,
+// 2007), keeping the best of Restarts runs
+type KMeans struct {
+	K int
+	// Restarts defaults to 10
+	Restarts int
+	// MaxIterations per run, defaults to 300
+	MaxIterations int
+	// Rand draws the initial centers, nil uses the
+	// global source
+	Rand *rand.Rand
+
+	Centers [][]float64
+	Labels  []int
+	// Inertia is the total squared distance
+	// of training rows to their centers
+	Inertia float64
+}
+
+// NewKMeans returns new pointer of KMeans of k clusters
+func NewKMeans(k int) *KMeans {
+	return &KMeans{K: k, Restarts: 10, MaxIterations: 300}
+}
+
+// Fit clusters rows of features, output is ignored
+func (m *KMeans) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, m.K); err != nil {
+		return err
+	}
+	restarts := m.Restarts
+	if restarts <= 0 {
+		restarts = 10
+	}
+	iterations := m.MaxIterations
+	if iterations <= 0 {
+		iterations = 300
+	}
+	rng := ml.RandOf(m.Rand)
+	n, p := len(features), len(features[0])
+
+	m.Inertia = math.Inf(1)
+	labels := make([]int, n)
+	for run := 0; run < restarts; run++ {
+		// k-means++ seeds, drawn by squared distance
+		// to the nearest seed so far
+		centers := [][]float64{append([]float64(nil), features[rng.Intn(n)]...)}
+		d2 := make([]float64, n)
+		for i := range d2 {
+			d2[i] = math.Inf(1)
+		}
+		for len(centers) < m.K {
+			last := centers[len(centers)-1]
+			total := 0.0
+			for i, x := range features {
+				v := floats.Distance(x, last, 2)
+				d2[i] = math.Min(d2[i], v*v)
+				total += d2[i]
+			}
+			next := rng.Intn(n)
+			if total > 0 {
+				u := rng.Float64() * total
+				for i, v := range d2 {
+					if u -= v; u <= 0 {
+						next = i
+						break
+					}
+				}
+			}
+			centers = append(centers, append([]float64(nil), features[next]...))
+		}
+
+		inertia := 0.0
+		for it := 0; it < iterations; it++ {
+			changed := it == 0
+			inertia = 0
+			for i, x := range features {
+				k, d := nearest(x, centers, Euclidean)
+				if labels[i] != k {
+					labels[i], changed = k, true
+				}
+				inertia += d * d
+			}
+			if !changed {
+				break
+			}
+			counts := make([]float64, m.K)
+			sums := make([][]float64, m.K)
+			for k := range sums {
+				sums[k] = make([]float64, p)
+			}
+			for i, x := range features {
+				floats.Add(sums[labels[i]], x)
+				counts[labels[i]]++
+			}
+			for k := range centers {
+				// empty clusters keep their center
+				if counts[k] > 0 {
+					floats.ScaleTo(centers[k], 1/counts[k], sums[k])
+				}
+			}
+		}
+		if inertia < m.Inertia {
+			m.Inertia = inertia
+			m.Centers = centers
+			m.Labels = append([]int(nil), labels...)
+		}
+	}
+	return nil
+}
+
+// Estimate returns the cluster of the center nearest X
+func (m *KMeans) Estimate(X []float64) float64 {
+	k, _ := nearest(X, m.Centers, Euclidean)
+	return float64(k)
+}
+
+// Transform returns distances of every row of
+// features to every center
+func (m *KMeans) Transform(features [][]float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, x := range features {
+		out[i] = make([]float64, len(m.Centers))
+		for k, c := range m.Centers {
+			out[i][k] = Euclidean(x, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// MeanShift clusters rows by moving every row to the
+// mean of training rows within Bandwidth of it until it
+// settles on a mode of their density; rows of the same
+// mode form a cluster, so the number of clusters is found
+// and clusters may be of any shape (Comaniciu and Meer,
+// 2002)
+type MeanShift struct {
+	// Bandwidth is the radius of the flat kernel, zero
+	// is EstimateBandwidth of 0.3 of the rows of every Fit
+	Bandwidth float64
+	// MaxIterations per row, defaults to 300
+	MaxIterations int
+
+	// Centers are modes of the clusters,
+	// most rows first
+	Centers [][]float64
+	Labels  []int
+}
+
+// NewMeanShift returns new pointer of MeanShift of
+// bandwidth, zero estimates it on Fit
+func NewMeanShift(bandwidth float64) *MeanShift {
+	return &MeanShift{Bandwidth: bandwidth, MaxIterations: 300}
+}
+
+// EstimateBandwidth returns the mean distance of rows to
+// their nearest quantile of rows, of at most 1000 rows
+func EstimateBandwidth(features [][]float64, quantile float64) float64 {
+	rows := features[:min(len(features), 1000)]
+	k := max(1, int(quantile*float64(len(rows))))
+	total := 0.0
+	d := make([]float64, len(rows))
+	for _, x := range rows {
+		for j, y := range rows {
+			d[j] = Euclidean(x, y)
+		}
+		sort.Float64s(d)
+		// d[0] is the row itself
+		total += d[min(k, len(d)-1)]
+	}
+	return total / float64(len(rows))
+}
+
+// Fit clusters rows of features, output is ignored
+func (m *MeanShift) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, 1); err != nil {
+		return err
+	}
+	bandwidth := m.Bandwidth
+	if bandwidth <= 0 {
+		bandwidth = EstimateBandwidth(features, 0.3)
+		if bandwidth <= 0 {
+			return fmt.Errorf("cluster: bandwidth of identical rows")
+		}
+	}
+	iterations := m.MaxIterations
+	if iterations <= 0 {
+		iterations = 300
+	}
+	p := len(features[0])
+
+	// modes of every row, and number of rows within them
+	var modes [][]float64
+	var sizes []int
+	for _, x := range features {
+		mode := append([]float64(nil), x...)
+		mean := make([]float64, p)
+		size := 0
+		for it := 0; it < iterations; it++ {
+			for j := range mean {
+				mean[j] = 0
+			}
+			size = 0
+			for _, y := range features {
+				if Euclidean(mode, y) <= bandwidth {
+					floats.Add(mean, y)
+					size++
+				}
+			}
+			floats.Scale(1/float64(size), mean)
+			shift := Euclidean(mean, mode)
+			copy(mode, mean)
+			if shift < 1e-3*bandwidth {
+				break
+			}
+		}
+		modes = append(modes, mode)
+		sizes = append(sizes, size)
+	}
+
+	// merge modes within bandwidth, denser first
+	order := make([]int, len(modes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] > sizes[order[b]] })
+	m.Centers = nil
+	for _, i := range order {
+		if _, d := nearest(modes[i], m.Centers, Euclidean); d > bandwidth {
+			m.Centers = append(m.Centers, modes[i])
+		}
+	}
+	m.Labels = make([]int, len(features))
+	for i, x := range features {
+		m.Labels[i], _ = nearest(x, m.Centers, Euclidean)
+	}
+	return nil
+}
+
+// Estimate returns the cluster of the center nearest X
+func (m *MeanShift) Estimate(X []float64) float64 {
+	k, _ := nearest(X, m.Centers, Euclidean)
+	return float64(k)
+}
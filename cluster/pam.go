@@ -0,0 +1,305 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// PAM is k-medoids clustering by Partitioning Around
+// Medoids (Kaufman and Rousseeuw, 1990): clusters are
+// centered on K training rows, medoids, chosen greedily
+// then improved by swapping a medoid with another row
+// while the total distance of rows to their medoids
+// decreases. Any distance works, see FitDistances for
+// precomputed ones.
+type PAM struct {
+	K int
+	// Distance of rows, defaults to Euclidean,
+	// it is not saved
+	Distance Distance
+	// MaxIterations of swaps, defaults to 100
+	MaxIterations int
+
+	// Medoids are the rows centering the clusters,
+	// Indices their rows in training
+	Medoids [][]float64
+	Indices []int
+	// Labels are clusters of training rows
+	Labels []int
+	// Cost is the total distance of training
+	// rows to their medoids
+	Cost float64
+}
+
+// NewPAM returns new pointer of PAM of k clusters
+func NewPAM(k int) *PAM {
+	return &PAM{K: k, Distance: Euclidean, MaxIterations: 100}
+}
+
+func (p *PAM) distance() Distance {
+	if p.Distance == nil {
+		return Euclidean
+	}
+	return p.Distance
+}
+
+// Fit clusters rows of features, output is ignored
+func (p *PAM) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, p.K); err != nil {
+		return err
+	}
+	if err := p.FitDistances(Distances(features, p.distance())); err != nil {
+		return err
+	}
+	p.Medoids = make([][]float64, len(p.Indices))
+	for k, i := range p.Indices {
+		p.Medoids[k] = append([]float64(nil), features[i]...)
+	}
+	return nil
+}
+
+// FitDistances clusters rows of a matrix of distances
+// between them, such as of Distances or of edit distances
+// of strings, setting Indices, Labels and Cost. Medoids
+// are not known, so Estimate needs Fit instead.
+func (p *PAM) FitDistances(d [][]float64) error {
+	n := len(d)
+	if p.K < 1 || p.K > n {
+		return fmt.Errorf("cluster: %d clusters of %d rows", p.K, n)
+	}
+	for i, row := range d {
+		if len(row) != n {
+			return fmt.Errorf("cluster: distance row %d has %d columns, expected %d", i, len(row), n)
+		}
+	}
+	iterations := p.MaxIterations
+	if iterations <= 0 {
+		iterations = 100
+	}
+	p.Medoids = nil
+	p.Indices, p.Cost = pam(d, p.K, iterations)
+	p.Labels = make([]int, n)
+	for i := range p.Labels {
+		p.Labels[i] = nearestMedoid(d[i], p.Indices)
+	}
+	return nil
+}
+
+// pam returns k medoids of distances d and their cost
+func pam(d [][]float64, k, iterations int) ([]int, float64) {
+	n := len(d)
+	isMedoid := make([]bool, n)
+	// near and second are distances of every row to
+	// its nearest and second nearest medoids
+	near := make([]float64, n)
+	second := make([]float64, n)
+	for i := range near {
+		near[i] = math.Inf(1)
+	}
+
+	// BUILD, adding the row which decreases cost most
+	var medoids []int
+	for len(medoids) < k {
+		best, gain := -1, -1.0
+		for h := 0; h < n; h++ {
+			if isMedoid[h] {
+				continue
+			}
+			g := 0.0
+			for j := 0; j < n; j++ {
+				if math.IsInf(near[j], 1) {
+					g -= d[j][h]
+				} else if d[j][h] < near[j] {
+					g += near[j] - d[j][h]
+				}
+			}
+			if best < 0 || g > gain {
+				best, gain = h, g
+			}
+		}
+		medoids = append(medoids, best)
+		isMedoid[best] = true
+		for j := range near {
+			near[j] = math.Min(near[j], d[j][best])
+		}
+	}
+
+	update := func() {
+		for j := 0; j < n; j++ {
+			near[j], second[j] = math.Inf(1), math.Inf(1)
+			for _, m := range medoids {
+				if v := d[j][m]; v < near[j] {
+					near[j], second[j] = v, near[j]
+				} else if v < second[j] {
+					second[j] = v
+				}
+			}
+		}
+	}
+
+	// SWAP, of the medoid and row of least change of cost
+	for it := 0; it < iterations; it++ {
+		update()
+		bestM, bestH, change := -1, -1, -1e-12
+		for mi, m := range medoids {
+			for h := 0; h < n; h++ {
+				if isMedoid[h] {
+					continue
+				}
+				c := 0.0
+				for j := 0; j < n; j++ {
+					djh := d[j][h]
+					if d[j][m] == near[j] {
+						// j loses its medoid m
+						c += math.Min(djh, second[j]) - near[j]
+					} else if djh < near[j] {
+						c += djh - near[j]
+					}
+				}
+				if c < change {
+					bestM, bestH, change = mi, h, c
+				}
+			}
+		}
+		if bestM < 0 {
+			break
+		}
+		isMedoid[medoids[bestM]] = false
+		isMedoid[bestH] = true
+		medoids[bestM] = bestH
+	}
+	update()
+	cost := 0.0
+	for _, v := range near {
+		cost += v
+	}
+	return medoids, cost
+}
+
+// nearestMedoid returns the cluster of medoids
+// nearest to a row of distances d
+func nearestMedoid(d []float64, medoids []int) int {
+	best := 0
+	for k, m := range medoids {
+		if d[m] < d[medoids[best]] {
+			best = k
+		}
+	}
+	return best
+}
+
+// Estimate returns the cluster of the medoid nearest X
+func (p *PAM) Estimate(X []float64) float64 {
+	k, _ := nearest(X, p.Medoids, p.distance())
+	return float64(k)
+}
+
+// CLARA clusters large data by PAM of random Samples of
+// SampleSize rows, keeping the medoids of least cost over
+// every row (Kaufman and Rousseeuw, 1990), so distances
+// of only SampleSize rows are held at a time
+type CLARA struct {
+	K int
+	// Distance of rows, defaults to Euclidean,
+	// it is not saved
+	Distance Distance
+	// Samples defaults to 5 and SampleSize
+	// to 40+2K rows
+	Samples    int
+	SampleSize int
+	// Rand draws the samples, nil uses the global source
+	Rand *rand.Rand
+
+	Medoids [][]float64
+	Labels  []int
+	Cost    float64
+}
+
+// NewCLARA returns new pointer of CLARA of k clusters
+func NewCLARA(k int) *CLARA {
+	return &CLARA{K: k, Distance: Euclidean, Samples: 5, SampleSize: 40 + 2*k}
+}
+
+func (c *CLARA) distance() Distance {
+	if c.Distance == nil {
+		return Euclidean
+	}
+	return c.Distance
+}
+
+// Fit clusters rows of features, output is ignored
+func (c *CLARA) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, c.K); err != nil {
+		return err
+	}
+	samples := c.Samples
+	if samples <= 0 {
+		samples = 5
+	}
+	size := c.SampleSize
+	if size <= 0 {
+		size = 40 + 2*c.K
+	}
+	size = min(max(size, c.K), len(features))
+	rng := ml.RandOf(c.Rand)
+	distance := c.distance()
+
+	c.Cost = math.Inf(1)
+	// best are training rows of the best medoids,
+	// kept in every later sample
+	var best []int
+	for s := 0; s < samples; s++ {
+		idx := append([]int(nil), best...)
+		in := map[int]bool{}
+		for _, i := range best {
+			in[i] = true
+		}
+		for _, i := range rng.Perm(len(features)) {
+			if len(idx) == size {
+				break
+			}
+			if !in[i] {
+				idx = append(idx, i)
+			}
+		}
+		rows := make([][]float64, size)
+		for r, i := range idx {
+			rows[r] = features[i]
+		}
+		medoids, _ := pam(Distances(rows, distance), c.K, 100)
+		centers := make([][]float64, c.K)
+		for k, m := range medoids {
+			centers[k] = rows[m]
+		}
+		cost := 0.0
+		for _, x := range features {
+			_, d := nearest(x, centers, distance)
+			cost += d
+		}
+		if cost < c.Cost {
+			c.Cost = cost
+			best = best[:0]
+			for _, m := range medoids {
+				best = append(best, idx[m])
+			}
+		}
+	}
+	c.Medoids = make([][]float64, c.K)
+	for k, i := range best {
+		c.Medoids[k] = append([]float64(nil), features[i]...)
+	}
+	c.Labels = make([]int, len(features))
+	for i, x := range features {
+		c.Labels[i], _ = nearest(x, c.Medoids, distance)
+	}
+	return nil
+}
+
+// Estimate returns the cluster of the medoid nearest X
+func (c *CLARA) Estimate(X []float64) float64 {
+	k, _ := nearest(X, c.Medoids, c.distance())
+	return float64(k)
+}
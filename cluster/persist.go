@@ -0,0 +1,66 @@
+package cluster
+
+import "github.com/maxrafiandy/ml"
+
+// clusterers are registered so they can be saved as
+// pipeline steps, their Distance and Decay funcs
+// and Rand are not saved
+func init() {
+	ml.RegisterModel("cluster.PAM", &PAM{})
+	ml.RegisterModel("cluster.CLARA", &CLARA{})
+	ml.RegisterModel("cluster.FuzzyCMeans", &FuzzyCMeans{})
+	ml.RegisterModel("cluster.KMeans", &KMeans{})
+	ml.RegisterModel("cluster.SpectralClustering", &SpectralClustering{})
+	ml.RegisterModel("cluster.MeanShift", &MeanShift{})
+	ml.RegisterModel("cluster.SOM", &SOM{})
+}
+
+// GobEncode encodes the model without Rand
+func (c *CLARA) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(c)
+}
+
+// GobDecode decodes a model of GobEncode
+func (c *CLARA) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(c, data)
+}
+
+// GobEncode encodes the model without Rand
+func (f *FuzzyCMeans) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(f)
+}
+
+// GobDecode decodes a model of GobEncode
+func (f *FuzzyCMeans) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(f, data)
+}
+
+// GobEncode encodes the model without Rand
+func (m *KMeans) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(m)
+}
+
+// GobDecode decodes a model of GobEncode
+func (m *KMeans) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(m, data)
+}
+
+// GobEncode encodes the model without Rand
+func (s *SpectralClustering) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(s)
+}
+
+// GobDecode decodes a model of GobEncode
+func (s *SpectralClustering) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(s, data)
+}
+
+// GobEncode encodes the model without Rand
+func (s *SOM) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(s)
+}
+
+// GobDecode decodes a model of GobEncode
+func (s *SOM) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(s, data)
+}
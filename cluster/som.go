@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/floats"
+)
+
+// Decay returns a value decayed from start to end
+// at progress from 0 to 1 of training
+type Decay func(start, end, progress float64) float64
+
+// LinearDecay decays linearly from start to end
+func LinearDecay(start, end, progress float64) float64 {
+	return start + (end-start)*progress
+}
+
+// ExponentialDecay decays geometrically from start to end
+func ExponentialDecay(start, end, progress float64) float64 {
+	return start * math.Pow(end/start, progress)
+}
+
+// SOM is a self-organizing map (Kohonen, 1982): a Rows by
+// Columns grid of units with weights in feature space,
+// trained online so neighboring units learn similar rows.
+// Every row pulls its best matching unit and the units
+// around it, by a gaussian of their grid distance of
+// radius decaying from Radius to FinalRadius, at a learning
+// rate decaying from LearningRate to FinalLearningRate.
+// Clusters are units, numbered row by row of the grid.
+type SOM struct {
+	Rows    int
+	Columns int
+	// Epochs over training rows, defaults to 100
+	Epochs int
+	// LearningRate defaults to 0.5 and
+	// FinalLearningRate to 0.01
+	LearningRate      float64
+	FinalLearningRate float64
+	// Radius defaults to half the larger side of
+	// the grid and FinalRadius to 0.5
+	Radius      float64
+	FinalRadius float64
+	// Decay of the radius and learning rate, defaults
+	// to ExponentialDecay, it is not saved
+	Decay Decay
+	// Rand draws the initial weights and the order of
+	// rows, nil uses the global source
+	Rand *rand.Rand
+
+	// Weights of every unit
+	Weights [][]float64
+	// Labels are units of training rows
+	Labels []int
+}
+
+// NewSOM returns new pointer of SOM of a rows by columns grid
+func NewSOM(rows, columns int) *SOM {
+	return &SOM{
+		Rows:              rows,
+		Columns:           columns,
+		Epochs:            100,
+		LearningRate:      0.5,
+		FinalLearningRate: 0.01,
+		Radius:            float64(max(rows, columns)) / 2,
+		FinalRadius:       0.5,
+		Decay:             ExponentialDecay,
+	}
+}
+
+// Fit trains the map on rows of features, output is ignored
+func (s *SOM) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, 1); err != nil {
+		return err
+	}
+	if s.Rows < 1 || s.Columns < 1 {
+		return fmt.Errorf("cluster: SOM grid of %d by %d units", s.Rows, s.Columns)
+	}
+	epochs := s.Epochs
+	if epochs <= 0 {
+		epochs = 100
+	}
+	rate, finalRate := s.LearningRate, s.FinalLearningRate
+	if rate <= 0 {
+		rate = 0.5
+	}
+	if finalRate <= 0 {
+		finalRate = 0.01
+	}
+	radius, finalRadius := s.Radius, s.FinalRadius
+	if radius <= 0 {
+		radius = float64(max(s.Rows, s.Columns)) / 2
+	}
+	if finalRadius <= 0 {
+		finalRadius = 0.5
+	}
+	decay := s.Decay
+	if decay == nil {
+		decay = ExponentialDecay
+	}
+	rng := ml.RandOf(s.Rand)
+	units := s.Rows * s.Columns
+
+	s.Weights = make([][]float64, units)
+	for u := range s.Weights {
+		s.Weights[u] = append([]float64(nil), features[rng.Intn(len(features))]...)
+	}
+	steps := float64(epochs * len(features))
+	step := 0
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, i := range rng.Perm(len(features)) {
+			x := features[i]
+			progress := float64(step) / steps
+			step++
+			alpha := decay(rate, finalRate, progress)
+			sigma := decay(radius, finalRadius, progress)
+			bmu, _ := nearest(x, s.Weights, Euclidean)
+			br, bc := bmu/s.Columns, bmu%s.Columns
+			for u, w := range s.Weights {
+				dr, dc := float64(u/s.Columns-br), float64(u%s.Columns-bc)
+				g := math.Exp(-(dr*dr + dc*dc) / (2 * sigma * sigma))
+				if g < 1e-4 {
+					continue
+				}
+				for j := range w {
+					w[j] += alpha * g * (x[j] - w[j])
+				}
+			}
+		}
+	}
+	s.Labels = make([]int, len(features))
+	for i, x := range features {
+		s.Labels[i], _ = nearest(x, s.Weights, Euclidean)
+	}
+	return nil
+}
+
+// Estimate returns the best matching unit of X
+func (s *SOM) Estimate(X []float64) float64 {
+	u, _ := nearest(X, s.Weights, Euclidean)
+	return float64(u)
+}
+
+// Position returns row and column of the grid
+// of the best matching unit of X
+func (s *SOM) Position(X []float64) (int, int) {
+	u, _ := nearest(X, s.Weights, Euclidean)
+	return u / s.Columns, u % s.Columns
+}
+
+// UMatrix returns the unified distance matrix of the grid,
+// the mean distance of weights of every unit to those of
+// its up to 4 grid neighbors. High values are borders
+// between clusters when plotted as an image.
+func (s *SOM) UMatrix() [][]float64 {
+	out := make([][]float64, s.Rows)
+	for r := range out {
+		out[r] = make([]float64, s.Columns)
+		for c := range out[r] {
+			w := s.Weights[r*s.Columns+c]
+			total, n := 0.0, 0.0
+			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				rr, cc := r+d[0], c+d[1]
+				if rr < 0 || rr >= s.Rows || cc < 0 || cc >= s.Columns {
+					continue
+				}
+				total += floats.Distance(w, s.Weights[rr*s.Columns+cc], 2)
+				n++
+			}
+			if n > 0 {
+				out[r][c] = total / n
+			}
+		}
+	}
+	return out
+}
+
+// QuantizationError returns the mean distance of
+// rows of features to their best matching units
+func (s *SOM) QuantizationError(features [][]float64) float64 {
+	total := 0.0
+	for _, x := range features {
+		_, d := nearest(x, s.Weights, Euclidean)
+		total += d
+	}
+	return total / float64(len(features))
+}
@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SpectralClustering clusters rows by k-means of the
+// leading eigenvectors of the normalized affinity
+// D^-½WD^-½ of rows, rows of eigenvectors scaled to
+// unit length (Ng, Jordan and Weiss, 2002), so clusters
+// may be of any connected shape, such as rings
+type SpectralClustering struct {
+	K int
+	// Gamma of the RBF affinity exp(-γ|x-x'|²),
+	// zero is 1/median of squared distances of
+	// the rows of every Affinity
+	Gamma float64
+	// Neighbors, when set, makes the affinity 1 between
+	// rows and their nearest neighbors instead
+	Neighbors int
+	// Rand draws the k-means centers of the embedding,
+	// nil uses the global source
+	Rand *rand.Rand
+
+	Labels []int
+	// Features of training rows, which
+	// Estimate assigns new rows by
+	Features [][]float64
+}
+
+// NewSpectralClustering returns new pointer of
+// SpectralClustering of k clusters
+func NewSpectralClustering(k int) *SpectralClustering {
+	return &SpectralClustering{K: k}
+}
+
+// Affinity returns the affinity matrix of rows of features
+func (s *SpectralClustering) Affinity(features [][]float64) [][]float64 {
+	n := len(features)
+	d := Distances(features, Euclidean)
+	w := make([][]float64, n)
+	for i := range w {
+		w[i] = make([]float64, n)
+	}
+	if s.Neighbors > 0 {
+		idx := make([]int, n)
+		for i := range d {
+			for j := range idx {
+				idx[j] = j
+			}
+			sort.SliceStable(idx, func(a, b int) bool { return d[i][idx[a]] < d[i][idx[b]] })
+			// skip the row itself, the graph is
+			// made symmetric
+			for _, j := range idx[1:min(s.Neighbors+1, n)] {
+				w[i][j], w[j][i] = 1, 1
+			}
+		}
+		return w
+	}
+	gamma := s.Gamma
+	if gamma <= 0 {
+		var sq []float64
+		for i := range d {
+			for j := i + 1; j < n; j++ {
+				sq = append(sq, d[i][j]*d[i][j])
+			}
+		}
+		sort.Float64s(sq)
+		gamma = 1
+		if len(sq) > 0 && sq[len(sq)/2] > 0 {
+			gamma = 1 / sq[len(sq)/2]
+		}
+	}
+	for i := range w {
+		for j := i + 1; j < n; j++ {
+			w[i][j] = math.Exp(-gamma * d[i][j] * d[i][j])
+			w[j][i] = w[i][j]
+		}
+	}
+	return w
+}
+
+// Fit clusters rows of features, output is ignored
+func (s *SpectralClustering) Fit(features [][]float64, output []float64) error {
+	if err := checkFeatures(features, s.K); err != nil {
+		return err
+	}
+	n := len(features)
+	w := s.Affinity(features)
+	degree := make([]float64, n)
+	for i, row := range w {
+		degree[i] = floats.Sum(row)
+		if degree[i] == 0 {
+			return fmt.Errorf("cluster: row %d has no affinity to other rows", i)
+		}
+	}
+	sym := mat.NewSymDense(n, nil)
+	for i := range w {
+		for j := i; j < n; j++ {
+			sym.SetSym(i, j, w[i][j]/math.Sqrt(degree[i]*degree[j]))
+		}
+	}
+	var eig mat.EigenSym
+	if !eig.Factorize(sym, true) {
+		return fmt.Errorf("cluster: eigen decomposition of affinity failed")
+	}
+	var vectors mat.Dense
+	eig.VectorsTo(&vectors)
+
+	// eigenvalues ascend, take the last K
+	embedding := make([][]float64, n)
+	for i := range embedding {
+		embedding[i] = make([]float64, s.K)
+		for k := 0; k < s.K; k++ {
+			embedding[i][k] = vectors.At(i, n-1-k)
+		}
+		if norm := floats.Norm(embedding[i], 2); norm > 0 {
+			floats.Scale(1/norm, embedding[i])
+		}
+	}
+	km := NewKMeans(s.K)
+	km.Rand = s.Rand
+	if err := km.Fit(embedding, nil); err != nil {
+		return err
+	}
+	s.Labels = km.Labels
+	s.Features = features
+	return nil
+}
+
+// Estimate returns the cluster of the training
+// row nearest X, as the embedding is only known
+// of training rows
+func (s *SpectralClustering) Estimate(X []float64) float64 {
+	i, _ := nearest(X, s.Features, Euclidean)
+	return float64(s.Labels[i])
+}
@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// rings returns n rows on each of two circles of
+// radius 1 and 5 around the origin
+func rings(n int) [][]float64 {
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, 2*n)
+	for i := range features {
+		radius := 1.0
+		if i >= n {
+			radius = 5
+		}
+		a := 2 * math.Pi * float64(i%n) / float64(n)
+		features[i] = []float64{radius*math.Cos(a) + 0.05*r.NormFloat64(), radius*math.Sin(a) + 0.05*r.NormFloat64()}
+	}
+	return features
+}
+
+// sameClusters reports whether labels split rows into
+// the consecutive groups of size, in any numbering
+func sameClusters(labels []int, size int) bool {
+	of := map[int]int{}
+	for i, l := range labels {
+		g, ok := of[l]
+		if !ok {
+			g = i / size
+			of[l] = g
+		}
+		if g != i/size {
+			return false
+		}
+	}
+	return len(of) == (len(labels)+size-1)/size
+}
+
+func TestKMeans(t *testing.T) {
+	// the centers of two pairs of rows, each 1 away
+	features := [][]float64{{0, 0}, {0, 2}, {10, 0}, {10, 2}}
+	m := NewKMeans(2)
+	if err := m.Fit(features, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !sameClusters(m.Labels, 2) || math.Abs(m.Inertia-4) > 1e-12 {
+		t.Fatalf("labels %v of inertia %v, want the pairs of inertia 4", m.Labels, m.Inertia)
+	}
+	k := int(m.Estimate([]float64{9, 1}))
+	if c := m.Centers[k]; c[0] != 10 || c[1] != 1 {
+		t.Errorf("nearest center %v, want [10 1]", c)
+	}
+	if d := m.Transform([][]float64{{10, 1}})[0]; d[k] != 0 || d[1-k] != 10 {
+		t.Errorf("distances %v to centers %v", d, m.Centers)
+	}
+
+	if err := NewKMeans(5).Fit(features, nil); err == nil {
+		t.Error("no error of more clusters than rows")
+	}
+}
+
+func TestSpectralClusteringOfRings(t *testing.T) {
+	// k-means cannot split the rings, the nearest
+	// neighbor graph of each ring is connected
+	features := rings(40)
+	s := NewSpectralClustering(2)
+	s.Neighbors = 5
+	if err := s.Fit(features, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !sameClusters(s.Labels, 40) {
+		t.Errorf("labels %v, want the two rings", s.Labels)
+	}
+	if got, want := int(s.Estimate([]float64{0, 4.9})), s.Labels[40]; got != want {
+		t.Errorf("row near the outer ring in cluster %d, want %d", got, want)
+	}
+}
+
+func TestMeanShiftFindsModes(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var features [][]float64
+	for _, c := range []float64{0, 10, 20} {
+		for i := 0; i < 15; i++ {
+			features = append(features, []float64{c + 0.3*r.NormFloat64(), 0.3 * r.NormFloat64()})
+		}
+	}
+	m := NewMeanShift(2)
+	if err := m.Fit(features, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Centers) != 3 || !sameClusters(m.Labels, 15) {
+		t.Errorf("%d centers of labels %v, want the 3 blobs", len(m.Centers), m.Labels)
+	}
+}
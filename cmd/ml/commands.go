@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// model is what train saves: the estimator with the
+// columns it reads, so predict needs no flags about them
+type model struct {
+	Features  []string
+	Target    string
+	Kind      string
+	Estimator ml.Estimator
+}
+
+func init() {
+	ml.RegisterModel("cmd/ml.model", &model{})
+}
+
+func (m *model) classifier() bool {
+	return m.Kind == "logistic"
+}
+
+// threshold returns DecisionThreshold of logistic models
+func (m *model) threshold() float64 {
+	if p, ok := m.Estimator.(*ml.Pipeline); ok {
+		if l, ok := p.Estimator.(*ml.LogisticRegression); ok {
+			return l.DecisionThreshold()
+		}
+	}
+	return 0.5
+}
+
+func loadModel(path string) (*model, error) {
+	v, err := ml.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(*model)
+	if !ok {
+		return nil, fmt.Errorf("%s holds a %T, not a model saved by ml train", path, v)
+	}
+	return m, nil
+}
+
+func train(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	data := fs.String("data", "", "training CSV `file`")
+	target := fs.String("target", "", "target column")
+	features := fs.String("features", "", "comma separated feature columns, defaults to all but target")
+	kind := fs.String("model", "linear", "linear or logistic")
+	scale := fs.Bool("scale", false, "standardize features")
+	folds := fs.Int("cv", 0, "print mean score of k-fold cross validation")
+	seed := fs.Int64("seed", 0, "seed of the cross validation shuffle, 0 is random")
+	out := fs.String("out", "model.bin", "output model `file`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" || *target == "" {
+		return fmt.Errorf("train needs -data and -target")
+	}
+
+	if *kind != "linear" && *kind != "logistic" {
+		return fmt.Errorf("unknown model %q, expected linear or logistic", *kind)
+	}
+
+	t, err := readTable(*data)
+	if err != nil {
+		return err
+	}
+
+	m := &model{Target: *target, Kind: *kind}
+	if *features != "" {
+		m.Features = strings.Split(*features, ",")
+	} else {
+		for _, h := range t.Header {
+			if h != *target {
+				m.Features = append(m.Features, h)
+			}
+		}
+	}
+
+	X, err := t.floats(m.Features)
+	if err != nil {
+		return err
+	}
+	y, err := t.column(*target)
+	if err != nil {
+		return err
+	}
+
+	newEstimator := func() ml.Estimator {
+		var e ml.Estimator = ml.NewLinearRegression()
+		if *kind == "logistic" {
+			e = ml.NewLogisticRegression()
+		}
+		steps := []ml.Transformer{ml.NewBiasTransformer()}
+		if *scale {
+			steps = append([]ml.Transformer{ml.NewStandardScaler()}, steps...)
+		}
+		return ml.NewPipeline(e, steps...)
+	}
+
+	if *folds > 1 {
+		metric, name := ml.Metric(ml.R2), "r2"
+		if m.classifier() {
+			metric, name = ml.Accuracy, "accuracy"
+		}
+		scores, err := ml.CrossValidate(newEstimator, X, y, ml.KFold{K: *folds, Shuffle: true, Seed: *seed}, metric)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("cv %s: %.6g\n", name, mean(scores))
+	}
+
+	m.Estimator = newEstimator()
+	if err = m.Estimator.Fit(X, y); err != nil {
+		return err
+	}
+	if err = ml.SaveFile(*out, m); err != nil {
+		return err
+	}
+
+	fmt.Printf("trained %s model on %d rows of %d features, saved to %s\n", *kind, len(X), len(m.Features), *out)
+	return nil
+}
+
+func predict(args []string) error {
+	fs := flag.NewFlagSet("predict", flag.ContinueOnError)
+	path := fs.String("model", "model.bin", "model `file` saved by train")
+	data := fs.String("data", "", "CSV `file` to score")
+	out := fs.String("out", "", "output CSV `file`, defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return fmt.Errorf("predict needs -data")
+	}
+
+	m, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	t, err := readTable(*data)
+	if err != nil {
+		return err
+	}
+	X, err := t.floats(m.Features)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cw := csv.NewWriter(w)
+	header := append(append([]string(nil), t.Header...), "prediction")
+	if m.classifier() {
+		header = append(header, "label")
+	}
+	cw.Write(header)
+
+	threshold := m.threshold()
+	for i, x := range X {
+		e := m.Estimator.Estimate(x)
+		row := append(append([]string(nil), t.Rows[i]...), strconv.FormatFloat(e, 'g', -1, 64))
+		if m.classifier() {
+			row = append(row, strconv.FormatBool(e >= threshold))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func evaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	path := fs.String("model", "model.bin", "model `file` saved by train")
+	data := fs.String("data", "", "CSV `file` with the target column")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return fmt.Errorf("evaluate needs -data")
+	}
+
+	m, err := loadModel(*path)
+	if err != nil {
+		return err
+	}
+	t, err := readTable(*data)
+	if err != nil {
+		return err
+	}
+	X, err := t.floats(m.Features)
+	if err != nil {
+		return err
+	}
+	y, err := t.column(m.Target)
+	if err != nil {
+		return err
+	}
+
+	estimates := make([]float64, len(X))
+	for i, x := range X {
+		estimates[i] = m.Estimator.Estimate(x)
+	}
+
+	fmt.Printf("model     %s\nrows      %d\n", m.Kind, len(X))
+	if !m.classifier() {
+		fmt.Printf("mse       %.6g\nr2        %.6g\n", ml.MeanSquaredError(y, estimates), ml.R2(y, estimates))
+		return nil
+	}
+
+	c := ml.NewConfusion(y, estimates, m.threshold())
+	fmt.Printf("threshold %.6g\naccuracy  %.6g\nf1        %.6g\nbrier     %.6g\n",
+		m.threshold(),
+		float64(c.TruePositive+c.TrueNegative)/float64(len(y)),
+		ml.F1(c),
+		ml.BrierScore(y, estimates))
+	fmt.Printf("confusion tp=%v fp=%v tn=%v fn=%v\n", c.TruePositive, c.FalsePositive, c.TrueNegative, c.FalseNegative)
+	return nil
+}
+
+func mean(x []float64) float64 {
+	s := 0.0
+	for _, v := range x {
+		s += v
+	}
+	return s / float64(len(x))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// table is a CSV file with a header row
+type table struct {
+	Header []string
+	Rows   [][]string
+}
+
+func readTable(path string) (*table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%s has no rows", path)
+	}
+
+	return &table{Header: records[0], Rows: records[1:]}, nil
+}
+
+// index returns position of column name
+func (t *table) index(name string) (int, error) {
+	for i, h := range t.Header {
+		if h == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no column %q", name)
+}
+
+// floats returns the named columns as rows of numbers
+func (t *table) floats(names []string) ([][]float64, error) {
+	cols := make([]int, len(names))
+	for k, name := range names {
+		j, err := t.index(name)
+		if err != nil {
+			return nil, err
+		}
+		cols[k] = j
+	}
+
+	out := make([][]float64, len(t.Rows))
+	for i, row := range t.Rows {
+		out[i] = make([]float64, len(cols))
+		for k, j := range cols {
+			v, err := strconv.ParseFloat(row[j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d column %q: %v", i+1, t.Header[j], err)
+			}
+			out[i][k] = v
+		}
+	}
+	return out, nil
+}
+
+// column returns the named column as numbers
+func (t *table) column(name string) ([]float64, error) {
+	rows, err := t.floats([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(rows))
+	for i, r := range rows {
+		out[i] = r[0]
+	}
+	return out, nil
+}
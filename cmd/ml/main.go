@@ -0,0 +1,57 @@
+// Command ml trains, scores and evaluates models of
+// github.com/maxrafiandy/ml on CSV files with a header
+// row.
+//
+// Usage:
+//
+//	ml train -data train.csv -target y [-model linear|logistic] [-scale] [-out model.bin]
+//	ml predict -model model.bin -data new.csv [-out predictions.csv]
+//	ml evaluate -model model.bin -data test.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `usage: ml <command> [flags]
+
+commands:
+  train     fit a model on a CSV file and save it
+  predict   score a CSV file with a saved model
+  evaluate  print metrics of a saved model on a CSV file
+
+run "ml <command> -h" for the flags of a command
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "train":
+		err = train(args)
+	case "predict":
+		err = predict(args)
+	case "evaluate":
+		err = evaluate(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "ml: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ml:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,283 @@
+// Package codegen writes fitted models as standalone Go
+// functions with hard-coded parameters, so services can
+// score without importing this module or gonum.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/maxrafiandy/ml"
+	"github.com/maxrafiandy/ml/featureselect"
+)
+
+// Generate writes a Go source file of package pkg with
+// func name(x []float64) float64 returning what Estimate
+// of model returns. Logistic models also get
+// func nameLabel(x []float64) bool applying TrueDegree.
+//
+// Supported are linear and logistic regression, isotonic
+// regression, Platt scaling, calibrated classifiers, RFE
+// and sequential selectors, and pipelines of StandardScaler,
+// BiasTransformer and featureselect steps.
+func Generate(w io.Writer, model ml.Estimator, pkg, name string) error {
+	if name == "" {
+		return fmt.Errorf("codegen: empty function name")
+	}
+	g := &generator{name: name}
+	out, err := g.estimator(model, "x")
+	if err != nil {
+		return err
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by github.com/maxrafiandy/ml/codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	if g.math {
+		fmt.Fprintf(&src, "import \"math\"\n\n")
+	}
+	src.Write(g.decls.Bytes())
+	fmt.Fprintf(&src, "// %s returns the estimate of x\n", name)
+	fmt.Fprintf(&src, "func %s(x []float64) float64 {\n", name)
+	src.Write(g.body.Bytes())
+	fmt.Fprintf(&src, "return %s\n}\n", out)
+
+	if g.threshold != nil {
+		fmt.Fprintf(&src, "\n// %sLabel returns whether x is true\n", name)
+		fmt.Fprintf(&src, "func %sLabel(x []float64) bool {\nreturn %s(x) >= %s\n}\n", name, name, g.float(*g.threshold))
+	}
+	if g.interpolate {
+		fmt.Fprintf(&src, "\n%s", strings.Replace(interpolateSource, "interpolate", g.ident("interpolate"), -1))
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("codegen: formatting: %v", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// generator writes statements computing a model into body
+// and parameters into package level declarations
+type generator struct {
+	name        string
+	body        bytes.Buffer
+	decls       bytes.Buffer
+	vars        int
+	math        bool
+	interpolate bool
+	threshold   *float64
+}
+
+// ident returns an unexported package level
+// identifier unique to the generated function
+func (g *generator) ident(s string) string {
+	return strings.ToLower(g.name[:1]) + g.name[1:] + strings.ToUpper(s[:1]) + s[1:]
+}
+
+// local returns a new local variable name
+func (g *generator) local(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+// array declares a package level array of values
+func (g *generator) array(prefix string, values []float64) string {
+	g.vars++
+	name := g.ident(prefix) + strconv.Itoa(g.vars)
+	fmt.Fprintf(&g.decls, "var %s = [...]float64{", name)
+	for i, v := range values {
+		if i > 0 {
+			g.decls.WriteString(", ")
+		}
+		g.decls.WriteString(g.float(v))
+	}
+	g.decls.WriteString("}\n\n")
+	return name
+}
+
+func (g *generator) columns(cols []int) string {
+	g.vars++
+	name := g.ident("columns") + strconv.Itoa(g.vars)
+	fmt.Fprintf(&g.decls, "var %s = [...]int{", name)
+	for i, c := range cols {
+		if i > 0 {
+			g.decls.WriteString(", ")
+		}
+		g.decls.WriteString(strconv.Itoa(c))
+	}
+	g.decls.WriteString("}\n\n")
+	return name
+}
+
+// estimator writes model reading vector in and returns
+// the expression of its estimate
+func (g *generator) estimator(model ml.Estimator, in string) (string, error) {
+	switch m := model.(type) {
+	case *ml.LinearRegression:
+		return g.linear(&m.Linear, in)
+
+	case *ml.LogisticRegression:
+		z, err := g.linear(&m.Linear, in)
+		if err != nil {
+			return "", err
+		}
+		threshold := m.DecisionThreshold()
+		g.threshold = &threshold
+		g.math = true
+		return fmt.Sprintf("1 / (1 + math.Exp(-%s))", z), nil
+
+	case *ml.PlattScaling:
+		g.math = true
+		return fmt.Sprintf("1 / (1 + math.Exp(-(%s*%s[0] + %s)))", g.float(m.A), in, g.float(m.B)), nil
+
+	case *ml.IsotonicRegression:
+		if len(m.X) == 0 {
+			return "", fmt.Errorf("codegen: cannot generate an unfitted isotonic regression")
+		}
+		g.interpolate = true
+		xs, ys := g.array("knotsX", m.X), g.array("knotsY", m.Y)
+		return fmt.Sprintf("%s(%s[:], %s[:], %s[%d])", g.ident("interpolate"), xs, ys, in, m.Column), nil
+
+	case *ml.CalibratedClassifier:
+		if m.Estimator == nil || m.Calibrator == nil {
+			return "", fmt.Errorf("codegen: cannot generate an unfitted calibrated classifier")
+		}
+		score, err := g.estimator(m.Estimator, in)
+		if err != nil {
+			return "", err
+		}
+		// the classifier threshold does not apply
+		// to calibrated probabilities
+		g.threshold = nil
+		s := g.local("score")
+		fmt.Fprintf(&g.body, "%s := []float64{%s}\n", s, score)
+		p, err := g.estimator(m.Calibrator, s)
+		if err != nil {
+			return "", err
+		}
+		half := 0.5
+		g.threshold = &half
+		return p, nil
+
+	case *ml.RFE:
+		return g.selected(m.Selected, m.Estimator, in)
+
+	case *ml.SequentialSelector:
+		return g.selected(m.Selected, m.Estimator, in)
+
+	case *ml.Pipeline:
+		for _, step := range m.Steps {
+			var err error
+			if in, err = g.transformer(step, in); err != nil {
+				return "", err
+			}
+		}
+		return g.estimator(m.Estimator, in)
+	}
+
+	return "", fmt.Errorf("codegen: %T is not supported", model)
+}
+
+func (g *generator) linear(l *ml.Linear, in string) (string, error) {
+	if len(l.Theta) == 0 {
+		return "", fmt.Errorf("codegen: cannot generate an unfitted model")
+	}
+	if !l.IsLinear() {
+		return "", fmt.Errorf("codegen: cannot generate a custom hypothesis")
+	}
+
+	theta := g.array("theta", l.Theta)
+	z := g.local("z")
+	fmt.Fprintf(&g.body, "%s := 0.0\nfor j, t := range %s {\n%s += t * %s[j]\n}\n", z, theta, z, in)
+	return z, nil
+}
+
+func (g *generator) selected(cols []int, model ml.Estimator, in string) (string, error) {
+	if model == nil {
+		return "", fmt.Errorf("codegen: cannot generate an unfitted selector")
+	}
+	return g.estimator(model, g.pick(cols, in))
+}
+
+// pick writes selection of cols of vector in
+func (g *generator) pick(cols []int, in string) string {
+	c := g.columns(cols)
+	out := g.local("x")
+	fmt.Fprintf(&g.body, "%s := make([]float64, len(%s))\nfor k, j := range %s {\n%s[k] = %s[j]\n}\n", out, c, c, out, in)
+	return out
+}
+
+// transformer writes step reading vector in and
+// returns the name of the transformed vector
+func (g *generator) transformer(step ml.Transformer, in string) (string, error) {
+	switch s := step.(type) {
+	case *ml.BiasTransformer:
+		out := g.local("x")
+		fmt.Fprintf(&g.body, "%s := append([]float64{1}, %s...)\n", out, in)
+		return out, nil
+
+	case *ml.StandardScaler:
+		if len(s.Mean) == 0 {
+			return "", fmt.Errorf("codegen: cannot generate an unfitted scaler")
+		}
+		mean, scale := g.array("mean", s.Mean), g.array("scale", s.Scale)
+		out := g.local("x")
+		fmt.Fprintf(&g.body, "%s := make([]float64, len(%s))\nfor j := range %s {\n%s[j] = (%s[j] - %s[j]) / %s[j]\n}\n", out, in, out, out, in, mean, scale)
+		return out, nil
+
+	case *featureselect.SelectKBest:
+		return g.pick(s.Selected, in), nil
+
+	case *featureselect.VarianceThreshold:
+		return g.pick(s.Selected, in), nil
+
+	case *ml.RFE:
+		return g.pick(s.Selected, in), nil
+
+	case *ml.SequentialSelector:
+		return g.pick(s.Selected, in), nil
+	}
+
+	return "", fmt.Errorf("codegen: %T is not supported", step)
+}
+
+// float formats v so it parses back to the same value
+func (g *generator) float(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		g.math = true
+		return "math.NaN()"
+	case math.IsInf(v, 0):
+		g.math = true
+		return fmt.Sprintf("math.Inf(%d)", int(math.Copysign(1, v)))
+	}
+
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+const interpolateSource = `// interpolate returns the piecewise linear function
+// through knots x, y at v, constant outside of x
+func interpolate(x, y []float64, v float64) float64 {
+	if v <= x[0] {
+		return y[0]
+	}
+	for i := 1; i < len(x); i++ {
+		if v <= x[i] {
+			t := (v - x[i-1]) / (x[i] - x[i-1])
+			return y[i-1] + t*(y[i]-y[i-1])
+		}
+	}
+	return y[len(y)-1]
+}
+`
@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// run builds the generated source of package main with a
+// main printing the estimates of rows by function name,
+// and returns them
+func run(t *testing.T, source []byte, name string, rows [][]float64) []float64 {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go tool to build the generated code")
+	}
+	dir := t.TempDir()
+
+	var main bytes.Buffer
+	main.WriteString("package main\n\nimport \"fmt\"\n\nfunc main() {\n")
+	for _, x := range rows {
+		main.WriteString("fmt.Println(" + name + "([]float64{")
+		for j, v := range x {
+			if j > 0 {
+				main.WriteString(", ")
+			}
+			main.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		main.WriteString("}))\n")
+	}
+	main.WriteString("}\n")
+	files := map[string][]byte{
+		"go.mod":   []byte("module generated\n"),
+		"model.go": source,
+		"main.go":  main.Bytes(),
+	}
+	for file, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running generated code: %v\n%s\n%s", err, out, source)
+	}
+	var got []float64
+	for _, line := range strings.Fields(string(out)) {
+		v, err := strconv.ParseFloat(line, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	return got
+}
+
+func TestGeneratedEstimates(t *testing.T) {
+	features := [][]float64{{1, 1, 10}, {1, 2, 30}, {1, 3, 20}, {1, 4, 50}, {1, 5, 40}, {1, 6, 70}}
+	output := []float64{3, 6, 6, 10, 10, 14}
+	labels := []float64{0, 0, 1, 0, 1, 1}
+
+	linear := ml.NewLinearRegression()
+	logistic := ml.NewLogisticRegression()
+	isotonic := ml.NewIsotonicRegression()
+	isotonic.Column = 1
+	scaled := ml.NewPipeline(ml.NewLinearRegression(), ml.NewStandardScaler(0))
+	for _, fit := range []struct {
+		model  ml.Estimator
+		output []float64
+	}{{linear, output}, {logistic, labels}, {isotonic, output}, {scaled, output}} {
+		if err := fit.model.Fit(features, fit.output); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows := append(features, []float64{1, 2.5, 25}, []float64{1, 9, -5})
+	for name, model := range map[string]ml.Estimator{
+		"linear":   linear,
+		"logistic": logistic,
+		"isotonic": isotonic,
+		"pipeline": scaled,
+	} {
+		var src bytes.Buffer
+		if err := Generate(&src, model, "main", "Score"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got := run(t, src.Bytes(), "Score", rows)
+		if len(got) != len(rows) {
+			t.Fatalf("%s: got %d estimates of %d rows", name, len(got), len(rows))
+		}
+		for i, x := range rows {
+			if want := model.Estimate(x); math.Abs(got[i]-want) > 1e-12*math.Max(1, math.Abs(want)) {
+				t.Errorf("%s: generated %v of row %v, Estimate %v", name, got[i], x, want)
+			}
+		}
+	}
+}
+
+// constant is an estimator codegen does not know
+type constant float64
+
+func (c constant) Fit(features [][]float64, output []float64) error { return nil }
+
+func (c constant) Estimate(X []float64) float64 { return float64(c) }
+
+func TestGenerateErrors(t *testing.T) {
+	var src bytes.Buffer
+	if err := Generate(&src, ml.NewLinearRegression(), "main", "Score"); err == nil {
+		t.Error("no error of an unfitted model")
+	}
+	if err := Generate(&src, constant(1), "main", "Score"); err == nil {
+		t.Error("no error of an unsupported model")
+	}
+	if err := Generate(&src, ml.NewLinearRegression(), "main", ""); err == nil {
+		t.Error("no error of an empty name")
+	}
+}
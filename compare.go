@@ -0,0 +1,108 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// StatTest is the result of a statistical test
+type StatTest struct {
+	Statistic float64
+	// DF is degrees of freedom of the statistic,
+	// zero for exact tests
+	DF     float64
+	PValue float64
+}
+
+// PairedTTest5x2 compares two estimators with Dietterich's
+// 5x2cv paired t-test. Rows are shuffled and split in two
+// halves 5 times, both estimators are fitted on each half
+// and scored on the other. A small p-value means the
+// difference in metric score is significant, a positive
+// statistic means estimator a scores higher.
+func PairedTTest5x2(newA, newB func() Estimator, features [][]float64, output []float64, metric Metric) (*StatTest, error) {
+	return PairedTTest5x2Rand(nil, newA, newB, features, output, metric)
+}
+
+// PairedTTest5x2Rand is PairedTTest5x2 shuffling rows
+// with rnd, nil uses the global source
+func PairedTTest5x2Rand(rnd *rand.Rand, newA, newB func() Estimator, features [][]float64, output []float64, metric Metric) (*StatTest, error) {
+	var first, variance float64
+
+	rnd = RandOf(rnd)
+	for r := 0; r < 5; r++ {
+		var diffs [2]float64
+		folds, err := (KFold{K: 2, Shuffle: true, Seed: rnd.Int63()}).Split(len(features))
+		if err != nil {
+			return nil, err
+		}
+		for f, fold := range folds {
+			_, a, err := scoreFold(newA(), features, output, fold.Train, fold.Test, metric)
+			if err != nil {
+				return nil, fmt.Errorf("ml: replication %d fold %d: %v", r, f, err)
+			}
+			_, b, err := scoreFold(newB(), features, output, fold.Train, fold.Test, metric)
+			if err != nil {
+				return nil, fmt.Errorf("ml: replication %d fold %d: %v", r, f, err)
+			}
+			diffs[f] = a - b
+		}
+
+		if r == 0 {
+			first = diffs[0]
+		}
+		m := (diffs[0] + diffs[1]) / 2
+		variance += (diffs[0]-m)*(diffs[0]-m) + (diffs[1]-m)*(diffs[1]-m)
+	}
+
+	t := first / math.Sqrt(variance/5)
+	return &StatTest{
+		Statistic: t,
+		DF:        5,
+		PValue:    2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: 5}.Survival(math.Abs(t)),
+	}, nil
+}
+
+// McNemar compares predictions of two classifiers on the
+// same rows. Predictions are rounded like Accuracy does.
+// Only rows where exactly one classifier is right count;
+// with fewer than 25 of them the exact binomial test is
+// used, otherwise the continuity corrected chi-squared.
+func McNemar(output, a, b []float64) *StatTest {
+	var onlyA, onlyB float64
+	for i, y := range output {
+		rightA := math.Round(a[i]) == y
+		rightB := math.Round(b[i]) == y
+		switch {
+		case rightA && !rightB:
+			onlyA++
+		case rightB && !rightA:
+			onlyB++
+		}
+	}
+
+	n := onlyA + onlyB
+	if n == 0 {
+		return &StatTest{PValue: 1}
+	}
+
+	if n < 25 {
+		k := math.Min(onlyA, onlyB)
+		p := 2 * distuv.Binomial{N: n, P: 0.5}.CDF(k)
+		return &StatTest{
+			Statistic: k,
+			PValue:    math.Min(1, p),
+		}
+	}
+
+	d := math.Abs(onlyA-onlyB) - 1
+	chi2 := d * d / n
+	return &StatTest{
+		Statistic: chi2,
+		DF:        1,
+		PValue:    distuv.ChiSquared{K: 1}.Survival(chi2),
+	}
+}
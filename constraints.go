@@ -0,0 +1,356 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Constraints bound thetas during training, e.g. a
+// price coefficient that must not be positive or shares
+// that sum to one. Fit and Minimize minimize with
+// projected gradient descent instead of BFGS, and SGD
+// projects thetas after every step.
+type Constraints struct {
+	// Lower and Upper bound every theta, missing
+	// or infinite bounds are no bound
+	Lower []float64
+	Upper []float64
+	// Equality rows a and Values b constrain
+	// thetas to a·θ = b
+	Equality [][]float64
+	Values   []float64
+
+	// gram factorizes AAᵀ of Equality
+	gram *mat.Cholesky
+}
+
+// WithBounds bounds thetas by lower and upper, nil or
+// infinite bounds are no bound
+func WithBounds(lower, upper []float64) Option {
+	return func(o *options) error {
+		c, err := o.constraints(nil)
+		if err != nil {
+			return err
+		}
+		c.grow(max(len(lower), len(upper)))
+		for j, v := range lower {
+			c.Lower[j] = math.Max(c.Lower[j], v)
+		}
+		for j, v := range upper {
+			c.Upper[j] = math.Min(c.Upper[j], v)
+		}
+		return nil
+	}
+}
+
+// WithEquality constrains thetas to a·θ = b for
+// every row a of equality and value b of values
+func WithEquality(equality [][]float64, values []float64) Option {
+	return func(o *options) error {
+		if len(equality) == 0 || len(equality) != len(values) {
+			return fmt.Errorf("ml: got %d equality rows and %d values", len(equality), len(values))
+		}
+		c, err := o.constraints(nil)
+		if err != nil {
+			return err
+		}
+		for i, a := range equality {
+			c.Equality = append(c.Equality, append([]float64(nil), a...))
+			c.Values = append(c.Values, values[i])
+		}
+		return nil
+	}
+}
+
+// WithNonNegative constrains thetas of cols to be >= 0
+func WithNonNegative(cols ...int) Option {
+	return func(o *options) error {
+		c, err := o.constraints(cols)
+		if err != nil {
+			return err
+		}
+		for _, j := range cols {
+			c.Lower[j] = math.Max(c.Lower[j], 0)
+		}
+		return nil
+	}
+}
+
+// WithNonPositive constrains thetas of cols to be <= 0
+func WithNonPositive(cols ...int) Option {
+	return func(o *options) error {
+		c, err := o.constraints(cols)
+		if err != nil {
+			return err
+		}
+		for _, j := range cols {
+			c.Upper[j] = math.Min(c.Upper[j], 0)
+		}
+		return nil
+	}
+}
+
+// constraints returns Constraints of the setting,
+// with bounds of every column of cols
+func (o *options) constraints(cols []int) (*Constraints, error) {
+	s := o.setting()
+	if s.Constraints == nil {
+		s.Constraints = &Constraints{}
+	}
+	c := s.Constraints
+	for _, j := range cols {
+		if j < 0 {
+			return nil, fmt.Errorf("ml: constraint of column %d", j)
+		}
+		c.grow(j + 1)
+	}
+	return c, nil
+}
+
+// grow extends bounds to n columns without bounds
+func (c *Constraints) grow(n int) {
+	for len(c.Lower) < n {
+		c.Lower = append(c.Lower, math.Inf(-1))
+	}
+	for len(c.Upper) < n {
+		c.Upper = append(c.Upper, math.Inf(1))
+	}
+}
+
+// check returns an error unless bounds fit n thetas
+func (c *Constraints) check(n int) error {
+	if len(c.Lower) > n || len(c.Upper) > n {
+		return fmt.Errorf("ml: constraints of %d columns for %d thetas", max(len(c.Lower), len(c.Upper)), n)
+	}
+	c.grow(n)
+	for j := range c.Lower {
+		if !(c.Lower[j] <= c.Upper[j]) {
+			return fmt.Errorf("ml: theta %d has lower bound %v above upper bound %v", j, c.Lower[j], c.Upper[j])
+		}
+	}
+
+	c.gram = nil
+	if len(c.Equality) == 0 {
+		return nil
+	}
+	if len(c.Values) != len(c.Equality) {
+		return fmt.Errorf("ml: got %d equality rows and %d values", len(c.Equality), len(c.Values))
+	}
+	for _, a := range c.Equality {
+		if len(a) != n {
+			return fmt.Errorf("ml: equality of %d columns for %d thetas", len(a), n)
+		}
+	}
+	gram := mat.NewSymDense(len(c.Equality), nil)
+	gram.SymOuterK(1, denseOf(c.Equality))
+	c.gram = &mat.Cholesky{}
+	if !c.gram.Factorize(gram) {
+		c.gram = nil
+		return fmt.Errorf("ml: equality rows are linearly dependent")
+	}
+	return nil
+}
+
+// project moves theta to the closest feasible thetas,
+// by Dykstra's alternating projections when there are
+// both bounds and equalities
+func (c *Constraints) project(theta []float64) {
+	if c.gram == nil {
+		c.clamp(theta)
+		return
+	}
+	if !c.bounded() {
+		c.affine(theta)
+		return
+	}
+
+	n := len(theta)
+	p, q := make([]float64, n), make([]float64, n)
+	y, prev := make([]float64, n), make([]float64, n)
+	for it := 0; it < 1000; it++ {
+		copy(prev, theta)
+		for j := range y {
+			y[j] = theta[j] + p[j]
+		}
+		copy(theta, y)
+		c.clamp(theta)
+		for j := range p {
+			p[j] = y[j] - theta[j]
+		}
+		for j := range y {
+			y[j] = theta[j] + q[j]
+		}
+		copy(theta, y)
+		c.affine(theta)
+		for j := range q {
+			q[j] = y[j] - theta[j]
+		}
+		if floats.Distance(theta, prev, math.Inf(1)) <= 1e-12*math.Max(1, floats.Norm(theta, math.Inf(1))) {
+			break
+		}
+	}
+}
+
+// bounded tells whether any theta has a finite bound
+func (c *Constraints) bounded() bool {
+	for j := range c.Lower {
+		if !math.IsInf(c.Lower[j], -1) || !math.IsInf(c.Upper[j], 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// clamp moves theta within bounds
+func (c *Constraints) clamp(theta []float64) {
+	for j := range theta {
+		if j < len(c.Lower) {
+			theta[j] = math.Max(theta[j], c.Lower[j])
+		}
+		if j < len(c.Upper) {
+			theta[j] = math.Min(theta[j], c.Upper[j])
+		}
+	}
+}
+
+// affine moves theta to θ - Aᵀ(AAᵀ)⁻¹(Aθ - b),
+// the closest thetas of the equalities
+func (c *Constraints) affine(theta []float64) {
+	A := denseOf(c.Equality)
+	var r, z, d mat.VecDense
+	r.MulVec(A, mat.NewVecDense(len(theta), theta))
+	r.SubVec(&r, mat.NewVecDense(len(c.Values), c.Values))
+	if err := c.gram.SolveVecTo(&z, &r); err != nil {
+		return
+	}
+	d.MulVec(A.T(), &z)
+	floats.Sub(theta, d.RawVector().Data)
+}
+
+// projectedGradient is projected gradient descent with
+// Barzilai-Borwein steps and a backtracking line search
+// along the projection. Major iterations report the
+// projected gradient, zero at the constrained minimum,
+// so GradientThreshold of the settings applies.
+type projectedGradient struct {
+	project func(theta []float64)
+	status  optimize.Status
+}
+
+func (p *projectedGradient) Uses(has optimize.Available) (optimize.Available, error) {
+	if !has.Grad {
+		return optimize.Available{}, fmt.Errorf("ml: constraints need gradients")
+	}
+	return optimize.Available{Grad: true}, nil
+}
+
+func (p *projectedGradient) Init(dim, tasks int) int {
+	p.status = optimize.NotTerminated
+	return 1
+}
+
+func (p *projectedGradient) Status() (optimize.Status, error) {
+	return p.status, nil
+}
+
+func (p *projectedGradient) Run(operations chan<- optimize.Task, results <-chan optimize.Task, tasks []optimize.Task) {
+	defer close(operations)
+	task := tasks[0]
+	dim := len(task.X)
+	if task.Gradient == nil {
+		task.Gradient = make([]float64, dim)
+	}
+
+	// send sends task as op and returns false
+	// when the optimizer stopped
+	send := func(op optimize.Operation) bool {
+		task.Op = op
+		operations <- task
+		result := <-results
+		if result.Op == optimize.PostIteration {
+			for range results {
+			}
+			return false
+		}
+		task = result
+		return true
+	}
+
+	x := append([]float64(nil), task.X...)
+	p.project(x)
+	copy(task.X, x)
+	if !send(optimize.FuncEvaluation | optimize.GradEvaluation) {
+		return
+	}
+	f, grad := task.F, append([]float64(nil), task.Gradient...)
+
+	step := 1 / math.Max(floats.Norm(grad, 2), 1)
+	next := make([]float64, dim)
+	for {
+		// report x with the projected gradient
+		// x - P(x - g), then restore the gradient
+		copy(task.X, x)
+		task.F = f
+		for j := range next {
+			next[j] = x[j] - grad[j]
+		}
+		p.project(next)
+		for j := range next {
+			task.Gradient[j] = x[j] - next[j]
+		}
+		if !send(optimize.MajorIteration) {
+			return
+		}
+
+		var nf float64
+		for {
+			for j := range next {
+				next[j] = x[j] - step*grad[j]
+			}
+			p.project(next)
+			// sufficient decrease along the projection
+			decrease := 0.0
+			for j := range next {
+				decrease += grad[j] * (next[j] - x[j])
+			}
+			if decrease >= 0 || step < 1e-30 {
+				// no feasible descent is left
+				p.status = optimize.MethodConverge
+				task.Op = optimize.MethodDone
+				operations <- task
+				for range results {
+				}
+				return
+			}
+			copy(task.X, next)
+			if !send(optimize.FuncEvaluation | optimize.GradEvaluation) {
+				return
+			}
+			nf = task.F
+			if nf <= f+1e-4*decrease {
+				break
+			}
+			step /= 2
+		}
+
+		// Barzilai-Borwein step of the next iteration
+		ss, sy := 0.0, 0.0
+		for j := range next {
+			s, y := next[j]-x[j], task.Gradient[j]-grad[j]
+			ss += s * s
+			sy += s * y
+		}
+		if sy > 0 {
+			step = ss / sy
+		} else {
+			step *= 2
+		}
+		copy(x, next)
+		copy(grad, task.Gradient)
+		f = nf
+	}
+}
@@ -0,0 +1,131 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Convergence reports how training of a model ended,
+// also when Fit returned an error
+type Convergence struct {
+	// Iterations are major iterations of the
+	// optimizer or epochs of SGD
+	Iterations  int
+	Evaluations int
+	// GradientNorm is the norm of the last gradient,
+	// NaN for gradient free methods
+	GradientNorm float64
+	Loss         float64
+	// Losses is the loss of every iteration
+	Losses []float64
+	// StopReason tells why training stopped
+	StopReason string
+	// Converged is false when training stopped on a limit
+	// of iterations, evaluations or time, or on an error.
+	// A stalled line search converged when the gradient
+	// norm is within 1e-6 of the loss, SGD converged when
+	// its last epoch improved the loss by less than 0.1%.
+	Converged bool
+	// Exhausted is true when training used all
+	// of MajorIteration or Epochs
+	Exhausted bool
+	Runtime   time.Duration
+}
+
+// Convergence returns the report of the last
+// training, nil before training
+func (l *Linear) Convergence() *Convergence {
+	return l.convergence
+}
+
+// Warning returns a warning when training stopped
+// without converging, empty otherwise
+func (c *Convergence) Warning() string {
+	switch {
+	case c == nil || c.Converged:
+		return ""
+	case c.Exhausted:
+		return fmt.Sprintf("ml: training used all %d iterations without converging (loss %.6g, gradient norm %.3g), "+
+			"raise the iterations, scale the features or loosen the threshold", c.Iterations, c.Loss, c.GradientNorm)
+	}
+	return fmt.Sprintf("ml: training stopped without converging after %d iterations: %s", c.Iterations, c.StopReason)
+}
+
+// String returns a one line summary
+func (c *Convergence) String() string {
+	return fmt.Sprintf("%s after %d iterations, %d evaluations, loss %.6g, gradient norm %.3g, converged %t",
+		c.StopReason, c.Iterations, c.Evaluations, c.Loss, c.GradientNorm, c.Converged)
+}
+
+// convergenceRecorder records loss and gradient
+// norm of every major iteration
+type convergenceRecorder struct {
+	c *Convergence
+}
+
+func (r convergenceRecorder) Init() error {
+	return nil
+}
+
+func (r convergenceRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if op&optimize.MajorIteration != 0 {
+		r.c.Losses = append(r.c.Losses, loc.F)
+		if loc.Gradient != nil {
+			r.c.GradientNorm = floats.Norm(loc.Gradient, 2)
+		}
+	}
+	if stats != nil {
+		r.c.Iterations, r.c.Evaluations = stats.MajorIterations, stats.FuncEvaluations
+		r.c.Runtime = stats.Runtime
+	}
+	return nil
+}
+
+// finish sets the end of an optimizer run
+// of result and err
+func (c *Convergence) finish(result *optimize.Result, err error, limit int) {
+	if result != nil {
+		c.Iterations, c.Evaluations = result.MajorIterations, result.FuncEvaluations
+		c.Runtime, c.Loss = result.Runtime, result.F
+		if result.Gradient != nil {
+			c.GradientNorm = floats.Norm(result.Gradient, 2)
+		}
+	} else if n := len(c.Losses); n > 0 {
+		c.Loss = c.Losses[n-1]
+	}
+
+	switch {
+	case err != nil:
+		c.StopReason = err.Error()
+	case result != nil:
+		c.StopReason = result.Status.String()
+	}
+	if result != nil && err == nil {
+		switch result.Status {
+		case optimize.GradientThreshold, optimize.FunctionThreshold,
+			optimize.FunctionConvergence, optimize.StepConvergence,
+			optimize.MethodConverge:
+			c.Converged = true
+		}
+	}
+	c.Exhausted = !c.Converged && limit > 0 && c.Iterations >= limit
+}
+
+// stall sets the end of a line search that could not
+// improve, which converged when the gradient is tiny
+// relative to the loss
+func (c *Convergence) stall(err error) {
+	c.StopReason = err.Error()
+	c.Converged = numopt.Converged(c.GradientNorm, c.Loss)
+}
+
+// newConvergence returns a report of no
+// iterations with unknown gradient norm
+func newConvergence() *Convergence {
+	return &Convergence{GradientNorm: math.NaN(), Loss: math.NaN()}
+}
@@ -0,0 +1,164 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// CostMatrix holds the cost of predicting class j of a row
+// of actual class i at [i][j], e.g. {{0, 1}, {20, 0}} when
+// false negatives cost 20 times false positives. Binary
+// classes are 0 for false and 1 for true.
+type CostMatrix [][]float64
+
+// multiclass is a classifier with probabilities
+// of every class, such as SoftmaxRegression
+type multiclass interface {
+	Probabilities(X []float64) []float64
+}
+
+// check returns an error unless the matrix is square
+// of at least 2 classes
+func (m CostMatrix) check() error {
+	if len(m) < 2 {
+		return fmt.Errorf("ml: cost matrix needs at least 2 classes, got %d", len(m))
+	}
+	for i, row := range m {
+		if len(row) != len(m) {
+			return fmt.Errorf("ml: cost matrix row %d has %d columns, expected %d", i, len(row), len(m))
+		}
+	}
+	return nil
+}
+
+// ConfusionMatrix counts rows of actual class i
+// predicted as class j at [i][j]
+func ConfusionMatrix(output, predictions []float64, classes int) ([][]float64, error) {
+	if len(output) != len(predictions) {
+		return nil, fmt.Errorf("ml: got %d outputs and %d predictions", len(output), len(predictions))
+	}
+	counts := make([][]float64, classes)
+	for i := range counts {
+		counts[i] = make([]float64, classes)
+	}
+	for i, y := range output {
+		a, p := int(y), int(predictions[i])
+		if a < 0 || a >= classes || float64(a) != y || p < 0 || p >= classes || float64(p) != predictions[i] {
+			return nil, fmt.Errorf("ml: row %d of class %v predicted %v, not classes of %d", i, y, predictions[i], classes)
+		}
+		counts[a][p]++
+	}
+	return counts, nil
+}
+
+// ExpectedCost returns the mean cost per row of
+// predicted classes against output
+func (m CostMatrix) ExpectedCost(output, predictions []float64) (float64, error) {
+	if err := m.check(); err != nil {
+		return 0, err
+	}
+	counts, err := ConfusionMatrix(output, predictions, len(m))
+	if err != nil {
+		return 0, err
+	}
+	cost := 0.0
+	for i, row := range counts {
+		for j, c := range row {
+			cost += c * m[i][j]
+		}
+	}
+	return cost / float64(len(output)), nil
+}
+
+// Threshold returns the probability of true above which
+// predicting true costs less, for binary matrices and
+// calibrated probabilities
+func (m CostMatrix) Threshold() float64 {
+	fp, tn := m[0][1], m[0][0]
+	fn, tp := m[1][0], m[1][1]
+	return (fp - tn) / (fp - tn + fn - tp)
+}
+
+// Decide returns the class of least expected cost
+// given probabilities of every class
+func (m CostMatrix) Decide(probabilities []float64) int {
+	best, cost := 0, math.Inf(1)
+	for j := range m {
+		c := 0.0
+		for i, p := range probabilities {
+			c += p * m[i][j]
+		}
+		if c < cost {
+			best, cost = j, c
+		}
+	}
+	return best
+}
+
+// ThresholdMetric returns a ThresholdMetric of negated
+// expected cost per row of a binary matrix, for
+// OptimizeThreshold and TuneThreshold
+func (m CostMatrix) ThresholdMetric() ThresholdMetric {
+	return func(c Confusion) float64 {
+		n := c.TruePositive + c.FalsePositive + c.TrueNegative + c.FalseNegative
+		return -(m[0][0]*c.TrueNegative + m[0][1]*c.FalsePositive +
+			m[1][0]*c.FalseNegative + m[1][1]*c.TruePositive) / n
+	}
+}
+
+// Metric returns a Metric of negated expected cost per
+// row. Estimates of binary matrices are probabilities
+// of true decided at Threshold, otherwise classes.
+func (m CostMatrix) Metric() Metric {
+	return func(output, estimates []float64) float64 {
+		cost, err := m.ExpectedCost(output, m.predictions(estimates))
+		if err != nil {
+			return math.Inf(-1)
+		}
+		return -cost
+	}
+}
+
+// predictions returns classes of estimates
+func (m CostMatrix) predictions(estimates []float64) []float64 {
+	if len(m) != 2 {
+		return estimates
+	}
+	threshold := m.Threshold()
+	out := make([]float64, len(estimates))
+	for i, e := range estimates {
+		if e >= threshold {
+			out[i] = 1
+		}
+	}
+	return out
+}
+
+// ExpectedCosts returns expected cost per row of every
+// fitted model on features and output. Binary models
+// predict true above Threshold, models with probabilities
+// of every class, such as SoftmaxRegression, the class
+// of least expected cost, others their estimated class.
+func ExpectedCosts(models []Estimator, features [][]float64, output []float64, cost CostMatrix) ([]float64, error) {
+	if err := cost.check(); err != nil {
+		return nil, err
+	}
+	costs := make([]float64, len(models))
+	for k, model := range models {
+		var predictions []float64
+		if mc, ok := model.(multiclass); ok {
+			predictions = make([]float64, len(features))
+			for i, x := range features {
+				predictions[i] = float64(cost.Decide(mc.Probabilities(x)))
+			}
+		} else {
+			predictions = cost.predictions(estimateAll(model, features))
+		}
+		c, err := cost.ExpectedCost(output, predictions)
+		if err != nil {
+			return nil, fmt.Errorf("ml: model %d: %v", k, err)
+		}
+		costs[k] = c
+	}
+	return costs, nil
+}
@@ -0,0 +1,136 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LikelihoodModel is a fitted model exposing the
+// log-likelihood of its training data
+type LikelihoodModel interface {
+	LogLikelihood() float64
+	// NumParams is the number of estimated parameters
+	NumParams() int
+	// NumSamples is the number of training rows
+	NumSamples() int
+}
+
+// Criterion scores a fitted model, lower is better
+type Criterion func(m LikelihoodModel) float64
+
+// AIC returns Akaike information criterion 2k-2ll
+func AIC(m LikelihoodModel) float64 {
+	return 2*float64(m.NumParams()) - 2*m.LogLikelihood()
+}
+
+// BIC returns Bayesian information criterion k*ln(n)-2ll
+func BIC(m LikelihoodModel) float64 {
+	return float64(m.NumParams())*math.Log(float64(m.NumSamples())) - 2*m.LogLikelihood()
+}
+
+// NumSamples returns the number of training rows
+// of the last Fit
+func (l *Linear) NumSamples() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.samples
+}
+
+// LogLikelihood returns Gaussian log-likelihood of the
+// training data with the maximum likelihood variance.
+// It is NaN unless the model was trained by Fit or Fit32
+// with the squared loss, other losses have no likelihood.
+func (l *LinearRegression) LogLikelihood() float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if _, isSquared := l.Loss.(SquaredLoss); l.samples == 0 || l.Loss != nil && !isSquared {
+		return math.NaN()
+	}
+	n := float64(l.samples)
+	rss := 2 * n * l.loss
+	return -n / 2 * (math.Log(2*math.Pi*rss/n) + 1)
+}
+
+// NumParams returns the number of thetas plus the variance
+func (l *LinearRegression) NumParams() int {
+	return len(l.Theta) + 1
+}
+
+// LogLikelihood returns Bernoulli log-likelihood of the
+// training data. It is NaN unless the model was trained
+// by Fit or Fit32 with the log loss.
+func (l *LogisticRegression) LogLikelihood() float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if _, isLog := l.Loss.(LogLoss); l.samples == 0 || l.Loss != nil && !isLog {
+		return math.NaN()
+	}
+	return -float64(l.samples) * l.loss
+}
+
+// NumParams returns the number of thetas
+func (l *LogisticRegression) NumParams() int {
+	return len(l.Theta)
+}
+
+// Candidate is a model family or column subset
+// compared by SelectByCriterion
+type Candidate struct {
+	Name string
+	// NewEstimator must return a LikelihoodModel
+	NewEstimator func() Estimator
+	// Columns used by the candidate, nil uses all
+	Columns []int
+}
+
+// RankedCandidate is a fitted candidate with its score
+type RankedCandidate struct {
+	Candidate
+	Model     Estimator
+	Criterion float64
+}
+
+// SelectByCriterion fits every candidate on features and
+// output and returns them ranked by criterion, best first
+func SelectByCriterion(candidates []Candidate, features [][]float64, output []float64, criterion Criterion) ([]RankedCandidate, error) {
+	ranked := make([]RankedCandidate, len(candidates))
+
+	for c, candidate := range candidates {
+		X := features
+		if candidate.Columns != nil {
+			X = selectColumns(features, candidate.Columns)
+		}
+
+		model := candidate.NewEstimator()
+		if err := model.Fit(X, output); err != nil {
+			return nil, fmt.Errorf("ml: candidate %q: %v", candidate.Name, err)
+		}
+
+		lm, ok := model.(LikelihoodModel)
+		if !ok {
+			return nil, fmt.Errorf("ml: candidate %q: %T is not a LikelihoodModel", candidate.Name, model)
+		}
+		if err := checkLikelihood(lm); err != nil {
+			return nil, fmt.Errorf("ml: candidate %q: %v", candidate.Name, err)
+		}
+
+		ranked[c] = RankedCandidate{
+			Candidate: candidate,
+			Model:     model,
+			Criterion: criterion(lm),
+		}
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool { return ranked[a].Criterion < ranked[b].Criterion })
+	return ranked, nil
+}
+
+// checkLikelihood returns an error when m has no
+// log-likelihood, e.g. of a custom loss
+func checkLikelihood(m LikelihoodModel) error {
+	if math.IsNaN(m.LogLikelihood()) {
+		return fmt.Errorf("%T has no log-likelihood, it needs its default loss and a Fit", m)
+	}
+	return nil
+}
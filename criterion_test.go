@@ -0,0 +1,73 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogLikelihoodAfterFit32(t *testing.T) {
+	// y = 2.2 + 0.6x leaves a residual sum of
+	// squares of 2.4 over 5 rows
+	features := [][]float64{{1, 1}, {1, 2}, {1, 3}, {1, 4}, {1, 5}}
+	output := []float64{2, 4, 5, 4, 5}
+	want := -2.5 * (math.Log(2*math.Pi*2.4/5) + 1)
+
+	model := NewLinearRegression()
+	if !math.IsNaN(model.LogLikelihood()) {
+		t.Error("log-likelihood of an unfitted model is not NaN")
+	}
+	if err := model.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if got := model.LogLikelihood(); math.Abs(got-want) > 1e-6 || model.NumSamples() != 5 {
+		t.Errorf("log-likelihood %v of %d rows, want %v of 5", got, model.NumSamples(), want)
+	}
+	if got, aic := AIC(model), 2*3-2*want; math.Abs(got-aic) > 1e-6 {
+		t.Errorf("AIC %v, want %v", got, aic)
+	}
+
+	features32 := make([][]float32, len(features))
+	output32 := make([]float32, len(output))
+	for i, x := range features {
+		features32[i] = []float32{float32(x[0]), float32(x[1])}
+		output32[i] = float32(output[i])
+	}
+	model32 := NewLinearRegression()
+	if err := model32.Fit32(features32, output32); err != nil {
+		t.Fatal(err)
+	}
+	if got := model32.LogLikelihood(); math.Abs(got-want) > 1e-6 || model32.NumSamples() != 5 {
+		t.Errorf("log-likelihood %v of %d rows after Fit32, want %v of 5", got, model32.NumSamples(), want)
+	}
+}
+
+func TestLogLikelihoodOfCustomLoss(t *testing.T) {
+	features, output := relevantData(30)
+	for i := range output {
+		output[i] += float64(i%3-1) / 10
+	}
+
+	squared := NewLinearRegression(WithLoss(SquaredLoss{}))
+	custom := NewLinearRegression(WithLoss(HuberLoss{Delta: 1}))
+	for _, m := range []*LinearRegression{squared, custom} {
+		if err := m.Fit(features, output); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if math.IsNaN(squared.LogLikelihood()) {
+		t.Error("squared loss has no log-likelihood")
+	}
+	if !math.IsNaN(custom.LogLikelihood()) {
+		t.Error("huber loss has a log-likelihood")
+	}
+
+	candidates := []Candidate{
+		{Name: "huber", NewEstimator: func() Estimator { return NewLinearRegression(WithLoss(HuberLoss{Delta: 1})) }},
+	}
+	if _, err := SelectByCriterion(candidates, features, output, AIC); err == nil {
+		t.Error("no error of selecting a custom loss by AIC")
+	}
+	if _, err := LikelihoodRatioTest(custom, squared); err == nil {
+		t.Error("no error of a likelihood ratio test of a custom loss")
+	}
+}
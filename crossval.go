@@ -0,0 +1,203 @@
+package ml
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// Fold holds row indices of a train and test split
+type Fold struct {
+	Train []int
+	Test  []int
+}
+
+// Splitter splits n rows into folds
+type Splitter interface {
+	Split(n int) ([]Fold, error)
+}
+
+// KFold splits rows into K consecutive folds,
+// each used once as test rows. Rows are shuffled
+// first when Shuffle is set.
+type KFold struct {
+	K       int
+	Shuffle bool
+	// Seed seeds the shuffle so every Split returns
+	// the same folds, zero uses the global source
+	Seed int64
+}
+
+// Split returns K folds of n rows, K must be
+// at least 2 and at most n
+func (k KFold) Split(n int) ([]Fold, error) {
+	if k.K < 2 || k.K > n {
+		return nil, fmt.Errorf("ml: cannot split %d rows into %d folds", n, k.K)
+	}
+
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	if k.Shuffle {
+		r := RandOf(nil)
+		if k.Seed != 0 {
+			r = rand.New(rand.NewSource(k.Seed))
+		}
+		r.Shuffle(n, func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+	}
+
+	folds := make([]Fold, k.K)
+	start := 0
+	for f := range folds {
+		size := n / k.K
+		if f < n%k.K {
+			size++
+		}
+
+		folds[f].Test = append([]int(nil), idx[start:start+size]...)
+		folds[f].Train = append(append([]int(nil), idx[:start]...), idx[start+size:]...)
+		start += size
+	}
+
+	return folds, nil
+}
+
+// CrossValidate fits a new estimator on train rows of every
+// fold and returns its metric score on the fold test rows
+func CrossValidate(newEstimator func() Estimator, features [][]float64, output []float64, splitter Splitter, metric Metric) ([]float64, error) {
+	return CrossValidateContext(context.Background(), newEstimator, features, output, splitter, metric)
+}
+
+// CrossValidateContext is CrossValidate with a context
+// for tracing, every fold has its own span
+func CrossValidateContext(ctx context.Context, newEstimator func() Estimator, features [][]float64, output []float64, splitter Splitter, metric Metric) ([]float64, error) {
+	if len(features) != len(output) {
+		return nil, fmt.Errorf("ml: got %d rows of features and %d outputs", len(features), len(output))
+	}
+
+	folds, err := splitter.Split(len(features))
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(folds))
+
+	ctx, span := StartSpan(ctx, "ml.CrossValidate", "rows", len(features), "folds", len(folds))
+	defer span.End()
+
+	for f, fold := range folds {
+		score, err := crossValidateFold(ctx, newEstimator, features, output, f, fold, metric)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		scores[f] = score
+	}
+
+	return scores, nil
+}
+
+func crossValidateFold(ctx context.Context, newEstimator func() Estimator, features [][]float64, output []float64, f int, fold Fold, metric Metric) (float64, error) {
+	ctx, span := StartSpan(ctx, "ml.CrossValidate.fold", "fold", f, "train", len(fold.Train), "test", len(fold.Test))
+	defer span.End()
+
+	if len(fold.Train) == 0 || len(fold.Test) == 0 {
+		return 0, fmt.Errorf("ml: fold %d has no train or test rows", f)
+	}
+
+	estimator := newEstimator()
+	X, y := subset(features, output, fold.Train)
+	if err := fitContext(ctx, estimator, X, y); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("ml: fold %d: %v", f, err)
+	}
+
+	X, y = subset(features, output, fold.Test)
+	score := metric(y, estimateAll(estimator, X))
+	span.SetAttributes("score", score)
+
+	return score, nil
+}
+
+// CrossValEstimate returns out-of-fold estimates: every
+// row is estimated by an estimator fitted on the train rows
+// of the fold where it is a test row
+func CrossValEstimate(newEstimator func() Estimator, features [][]float64, output []float64, splitter Splitter) ([]float64, error) {
+	return CrossValEstimateContext(context.Background(), newEstimator, features, output, splitter)
+}
+
+// CrossValEstimateContext is CrossValEstimate with
+// a context for tracing
+func CrossValEstimateContext(ctx context.Context, newEstimator func() Estimator, features [][]float64, output []float64, splitter Splitter) ([]float64, error) {
+	if len(features) != len(output) {
+		return nil, fmt.Errorf("ml: got %d rows of features and %d outputs", len(features), len(output))
+	}
+
+	ctx, span := StartSpan(ctx, "ml.CrossValEstimate", "rows", len(features))
+	defer span.End()
+
+	folds, err := splitter.Split(len(features))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	estimates := make([]float64, len(features))
+	for f, fold := range folds {
+		estimator := newEstimator()
+		X, y := subset(features, output, fold.Train)
+		if err := fitContext(ctx, estimator, X, y); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("ml: fold %d: %v", f, err)
+		}
+
+		for _, i := range fold.Test {
+			estimates[i] = estimator.Estimate(features[i])
+		}
+	}
+
+	return estimates, nil
+}
+
+// subset returns rows idx of features and output
+func subset(features [][]float64, output []float64, idx []int) ([][]float64, []float64) {
+	X := make([][]float64, len(idx))
+	y := make([]float64, len(idx))
+	for k, i := range idx {
+		X[k] = features[i]
+		y[k] = output[i]
+	}
+	return X, y
+}
+
+// predictAll returns estimate of every row of features,
+// in a batch when estimator is a BatchEstimator
+func predictAll(estimator Estimator, features [][]float64) ([]float64, error) {
+	if b, ok := estimator.(BatchEstimator); ok {
+		return b.PredictBatch(features)
+	}
+	estimates := make([]float64, len(features))
+	for i, X := range features {
+		estimates[i] = estimator.Estimate(X)
+	}
+	return estimates, nil
+}
+
+// estimateAll is predictAll of rows the estimator
+// was fitted on, it panics of rows of the wrong
+// length as Estimate does
+func estimateAll(estimator Estimator, features [][]float64) []float64 {
+	estimates, err := predictAll(estimator, features)
+	if err != nil {
+		panic(err)
+	}
+	return estimates
+}
+
+func mean(x []float64) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
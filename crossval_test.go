@@ -0,0 +1,89 @@
+package ml
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestKFoldSplit(t *testing.T) {
+	folds, err := KFold{K: 3, Shuffle: true, Seed: 1}.Split(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every row is a test row once, folds
+	// differ in size by at most one
+	var test []int
+	for f, fold := range folds {
+		if size := len(fold.Test); size != 3 && size != 4 {
+			t.Errorf("fold %d has %d test rows", f, size)
+		}
+		if len(fold.Train)+len(fold.Test) != 10 {
+			t.Errorf("fold %d has %d train and %d test rows of 10", f, len(fold.Train), len(fold.Test))
+		}
+		test = append(test, fold.Test...)
+	}
+	sort.Ints(test)
+	for i, j := range test {
+		if i != j {
+			t.Fatalf("test rows %v, want every row once", test)
+		}
+	}
+
+	for _, k := range []KFold{{K: 0}, {K: 1}, {K: 11}, {K: -2}} {
+		if _, err := k.Split(10); err == nil {
+			t.Errorf("no error of %d folds of 10 rows", k.K)
+		}
+	}
+	features, output := lineData(10, 1)
+	newEstimator := func() Estimator { return NewLinearRegression() }
+	if _, err := CrossValidate(newEstimator, features, output, KFold{K: 20}, R2); err == nil {
+		t.Error("no error of cross-validating 10 rows in 20 folds")
+	}
+}
+
+func TestWindowSplitErrors(t *testing.T) {
+	if _, err := (ExpandingWindow{K: 0}).Split(10); err == nil {
+		t.Error("no error of 0 folds")
+	}
+	if _, err := (SlidingWindow{K: 3, TestSize: 5}).Split(10); err != nil {
+		t.Errorf("error of 1 fold with train rows: %v", err)
+	}
+	if _, err := (SlidingWindow{K: 1, TestSize: 10}).Split(10); err == nil {
+		t.Error("no error of folds without train rows")
+	}
+}
+
+func TestSeededShuffles(t *testing.T) {
+	features, output := relevantData(40)
+	for i := range output {
+		output[i] += float64(i%5-2) / 10
+	}
+
+	newA := func() Estimator { return NewLinearRegression() }
+	newB := func() Estimator { return NewElasticNet(0.5, 1) }
+	a, err := PairedTTest5x2Rand(rand.New(rand.NewSource(1)), newA, newB, features, output, R2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := PairedTTest5x2Rand(rand.New(rand.NewSource(1)), newA, newB, features, output, R2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *a != *b {
+		t.Errorf("5x2cv tests of one seed differ, %+v and %+v", a, b)
+	}
+
+	var errs []float64
+	for i := 0; i < 2; i++ {
+		e := NewElasticNetCV(0.5)
+		e.Rand = rand.New(rand.NewSource(1))
+		if err := e.Fit(features, output); err != nil {
+			t.Fatal(err)
+		}
+		errs = append(errs, e.Errors...)
+	}
+	if half := len(errs) / 2; !sameFloats(errs[:half], errs[half:]) {
+		t.Error("errors of cross validated paths of one seed differ")
+	}
+}
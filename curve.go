@@ -0,0 +1,113 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Curve holds train and validation scores of a learning
+// curve or a validation curve, one score per fold at
+// every point
+type Curve struct {
+	// Points are train sizes of a learning curve or
+	// parameter values of a validation curve
+	Points     []float64
+	Train      [][]float64
+	Validation [][]float64
+}
+
+// Mean returns mean train and validation score
+// over folds at every point
+func (c *Curve) Mean() (train, validation []float64) {
+	train = make([]float64, len(c.Points))
+	validation = make([]float64, len(c.Points))
+	for p := range c.Points {
+		train[p] = mean(c.Train[p])
+		validation[p] = mean(c.Validation[p])
+	}
+	return train, validation
+}
+
+// LearningCurve fits estimators on increasing fractions of
+// the train rows of every fold and scores them on the rows
+// they were trained on and on the fold test rows. A large
+// gap between both means overfitting, two low scores mean
+// underfitting.
+func LearningCurve(newEstimator func() Estimator, features [][]float64, output []float64, fractions []float64, splitter Splitter, metric Metric) (*Curve, error) {
+	folds, err := splitter.Split(len(features))
+	if err != nil {
+		return nil, err
+	}
+	c := newCurve(len(fractions))
+
+	for p, fraction := range fractions {
+		if fraction <= 0 || fraction > 1 {
+			return nil, fmt.Errorf("ml: fraction must be in (0, 1], got %v", fraction)
+		}
+
+		for f, fold := range folds {
+			n := int(math.Round(fraction * float64(len(fold.Train))))
+			if n < 1 {
+				n = 1
+			}
+			train, test, err := scoreFold(newEstimator(), features, output, fold.Train[:n], fold.Test, metric)
+			if err != nil {
+				return nil, fmt.Errorf("ml: fraction %v fold %d: %v", fraction, f, err)
+			}
+
+			c.Train[p] = append(c.Train[p], train)
+			c.Validation[p] = append(c.Validation[p], test)
+			if f == 0 {
+				c.Points[p] = float64(n)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// ValidationCurve cross-validates an estimator built by
+// newEstimator for every parameter value in params
+func ValidationCurve(newEstimator func(param float64) Estimator, features [][]float64, output []float64, params []float64, splitter Splitter, metric Metric) (*Curve, error) {
+	folds, err := splitter.Split(len(features))
+	if err != nil {
+		return nil, err
+	}
+	c := newCurve(len(params))
+
+	for p, param := range params {
+		c.Points[p] = param
+		for f, fold := range folds {
+			train, test, err := scoreFold(newEstimator(param), features, output, fold.Train, fold.Test, metric)
+			if err != nil {
+				return nil, fmt.Errorf("ml: param %v fold %d: %v", param, f, err)
+			}
+
+			c.Train[p] = append(c.Train[p], train)
+			c.Validation[p] = append(c.Validation[p], test)
+		}
+	}
+
+	return c, nil
+}
+
+func newCurve(points int) *Curve {
+	return &Curve{
+		Points:     make([]float64, points),
+		Train:      make([][]float64, points),
+		Validation: make([][]float64, points),
+	}
+}
+
+// scoreFold fits estimator on train rows and returns
+// its score on train rows and on test rows
+func scoreFold(estimator Estimator, features [][]float64, output []float64, train, test []int, metric Metric) (float64, float64, error) {
+	X, y := subset(features, output, train)
+	if err := estimator.Fit(X, y); err != nil {
+		return 0, 0, err
+	}
+	trainScore := metric(y, estimateAll(estimator, X))
+
+	X, y = subset(features, output, test)
+	return trainScore, metric(y, estimateAll(estimator, X)), nil
+}
@@ -0,0 +1,35 @@
+// Package datasets provides small datasets bundled with
+// the package so tutorials and examples run without
+// downloading anything: Fisher's iris data, and synthetic
+// digits and housing data generated from fixed seeds.
+// Mapped reads datasets larger than RAM from disk.
+package datasets
+
+// Dataset holds features and output of a dataset
+// together with the names of its columns
+type Dataset struct {
+	Features     [][]float64
+	Output       []float64
+	FeatureNames []string
+	// TargetNames names each class of a classification
+	// dataset, indexed by output value. It is empty for
+	// regression datasets.
+	TargetNames []string
+}
+
+func newDataset(rows [][]float64, featureNames, targetNames []string) *Dataset {
+	d := &Dataset{
+		Features:     make([][]float64, len(rows)),
+		Output:       make([]float64, len(rows)),
+		FeatureNames: append([]string(nil), featureNames...),
+		TargetNames:  append([]string(nil), targetNames...),
+	}
+
+	n := len(featureNames)
+	for i, row := range rows {
+		d.Features[i] = append([]float64(nil), row[:n]...)
+		d.Output[i] = row[n]
+	}
+
+	return d
+}
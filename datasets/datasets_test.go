@@ -0,0 +1,59 @@
+package datasets
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIris(t *testing.T) {
+	d := LoadIris()
+	if len(d.Features) != 150 || len(d.Output) != 150 || len(d.FeatureNames) != 4 || len(d.TargetNames) != 3 {
+		t.Fatalf("iris of %d rows, %d outputs, %d features and %d targets", len(d.Features), len(d.Output), len(d.FeatureNames), len(d.TargetNames))
+	}
+
+	// Fisher's data has 50 rows of every species
+	// and these column means
+	counts := map[float64]int{}
+	means := make([]float64, 4)
+	for i, x := range d.Features {
+		counts[d.Output[i]]++
+		for j, v := range x {
+			means[j] += v / 150
+		}
+	}
+	if counts[0] != 50 || counts[1] != 50 || counts[2] != 50 {
+		t.Errorf("rows of species %v, want 50 of each", counts)
+	}
+	for j, want := range []float64{5.8433, 3.0573, 3.758, 1.1993} {
+		if math.Abs(means[j]-want) > 1e-4 {
+			t.Errorf("mean of %s %v, want %v", d.FeatureNames[j], means[j], want)
+		}
+	}
+}
+
+func TestSyntheticDatasets(t *testing.T) {
+	for name, load := range map[string]func() *Dataset{
+		"digits":  LoadSyntheticDigits,
+		"housing": LoadSyntheticHousing,
+	} {
+		d := load()
+		if len(d.Features) == 0 || len(d.Features) != len(d.Output) {
+			t.Fatalf("%s of %d rows and %d outputs", name, len(d.Features), len(d.Output))
+		}
+		for i, x := range d.Features {
+			if len(x) != len(d.FeatureNames) {
+				t.Fatalf("%s row %d has %d columns, want %d", name, i, len(x), len(d.FeatureNames))
+			}
+		}
+		// the seeds are fixed and every load is a copy
+		again := load()
+		if !reflect.DeepEqual(d, again) {
+			t.Errorf("%s differs between loads", name)
+		}
+		d.Features[0][0]++
+		if again.Features[0][0] == d.Features[0][0] {
+			t.Errorf("%s loads share rows", name)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package datasets
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/maxrafiandy/ml/internal/mmap"
+)
+
+// mappedMagic starts a file of Mapped, followed by a
+// little endian uint32 version and uint32 number of
+// features. Rows follow as little endian float64
+// features then output, so the number of rows is
+// known from the file size.
+const (
+	mappedMagic   = "GOMLDATA"
+	mappedVersion = 1
+	mappedHeader  = 16
+)
+
+// Mapped is a dataset in a binary file mapped into
+// memory, so it can be larger than RAM: the operating
+// system pages rows in as they are read. Write one
+// with WriteMapped or ConvertCSV. Mapped is safe for
+// concurrent use until Close.
+type Mapped struct {
+	data     []byte
+	features int
+	rows     int
+	close    func() error
+}
+
+// OpenMapped maps the dataset file at path
+func OpenMapped(path string) (*Mapped, error) {
+	data, closer, err := mmap.Map(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMapped(data)
+	if err != nil {
+		closer()
+		return nil, fmt.Errorf("datasets: %s: %v", path, err)
+	}
+	m.close = closer
+	return m, nil
+}
+
+func newMapped(data []byte) (*Mapped, error) {
+	if len(data) < mappedHeader || string(data[:8]) != mappedMagic {
+		return nil, fmt.Errorf("not a mapped dataset")
+	}
+	if v := binary.LittleEndian.Uint32(data[8:]); v != mappedVersion {
+		return nil, fmt.Errorf("unsupported version %d", v)
+	}
+
+	features := int(binary.LittleEndian.Uint32(data[12:]))
+	width := 8 * (features + 1)
+	if (len(data)-mappedHeader)%width != 0 {
+		return nil, fmt.Errorf("truncated rows")
+	}
+
+	return &Mapped{
+		data:     data,
+		features: features,
+		rows:     (len(data) - mappedHeader) / width,
+	}, nil
+}
+
+// Len returns number of rows
+func (m *Mapped) Len() int {
+	return m.rows
+}
+
+// Width returns number of features
+func (m *Mapped) Width() int {
+	return m.features
+}
+
+// Rows returns copies of features and output of
+// rows start to end, excluding end
+func (m *Mapped) Rows(start, end int) ([][]float64, []float64) {
+	if start < 0 || end > m.rows || start > end {
+		panic(fmt.Sprintf("datasets: rows %d:%d out of range of %d rows", start, end, m.rows))
+	}
+
+	width := m.features + 1
+	values := make([]float64, (end-start)*width)
+	offset := mappedHeader + 8*start*width
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(m.data[offset+8*i:]))
+	}
+
+	features := make([][]float64, end-start)
+	output := make([]float64, end-start)
+	for i := range features {
+		row := values[i*width : (i+1)*width]
+		features[i] = row[:m.features:m.features]
+		output[i] = row[m.features]
+	}
+	return features, output
+}
+
+// Close unmaps the file, rows read before
+// stay valid
+func (m *Mapped) Close() error {
+	if m.close == nil {
+		return nil
+	}
+	err := m.close()
+	m.close, m.data = nil, nil
+	return err
+}
+
+// mappedWriter writes rows of a mapped dataset
+type mappedWriter struct {
+	w        *bufio.Writer
+	features int
+	buf      [8]byte
+}
+
+func newMappedWriter(w io.Writer, features int) (*mappedWriter, error) {
+	mw := &mappedWriter{w: bufio.NewWriter(w), features: features}
+
+	var header [mappedHeader]byte
+	copy(header[:], mappedMagic)
+	binary.LittleEndian.PutUint32(header[8:], mappedVersion)
+	binary.LittleEndian.PutUint32(header[12:], uint32(features))
+	_, err := mw.w.Write(header[:])
+	return mw, err
+}
+
+func (mw *mappedWriter) write(features []float64, output float64) error {
+	if len(features) != mw.features {
+		return fmt.Errorf("datasets: got %d features, expected %d", len(features), mw.features)
+	}
+	for _, v := range features {
+		if err := mw.value(v); err != nil {
+			return err
+		}
+	}
+	return mw.value(output)
+}
+
+func (mw *mappedWriter) value(v float64) error {
+	binary.LittleEndian.PutUint64(mw.buf[:], math.Float64bits(v))
+	_, err := mw.w.Write(mw.buf[:])
+	return err
+}
+
+// WriteMapped writes features and output in the
+// format of OpenMapped
+func WriteMapped(w io.Writer, features [][]float64, output []float64) error {
+	if len(features) != len(output) {
+		return fmt.Errorf("datasets: got %d rows of features and %d outputs", len(features), len(output))
+	}
+
+	width := 0
+	if len(features) > 0 {
+		width = len(features[0])
+	}
+	mw, err := newMappedWriter(w, width)
+	if err != nil {
+		return err
+	}
+	for i, x := range features {
+		if err = mw.write(x, output[i]); err != nil {
+			return err
+		}
+	}
+	return mw.w.Flush()
+}
+
+// ConvertCSV streams CSV of r, which starts with a header
+// row, to w in the format of OpenMapped. Column target is
+// the output and every other column a feature. It returns
+// the feature names and the number of rows written.
+func ConvertCSV(w io.Writer, r io.Reader, target string) ([]string, int, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, 0, fmt.Errorf("datasets: reading header: %v", err)
+	}
+	// later reads reuse the record
+	header = append([]string(nil), header...)
+	col := -1
+	var names []string
+	for j, name := range header {
+		if name == target {
+			col = j
+		} else {
+			names = append(names, name)
+		}
+	}
+	if col < 0 {
+		return nil, 0, fmt.Errorf("datasets: no column %q", target)
+	}
+
+	mw, err := newMappedWriter(w, len(names))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	features := make([]float64, len(names))
+	rows := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, rows, fmt.Errorf("datasets: row %d: %v", rows+1, err)
+		}
+
+		var output float64
+		k := 0
+		for j, field := range record {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, rows, fmt.Errorf("datasets: row %d column %q: %v", rows+1, header[j], err)
+			}
+			if j == col {
+				output = v
+			} else {
+				features[k] = v
+				k++
+			}
+		}
+		if err = mw.write(features, output); err != nil {
+			return nil, rows, err
+		}
+		rows++
+	}
+
+	return names, rows, mw.w.Flush()
+}
@@ -0,0 +1,46 @@
+package datasets
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertCSV(t *testing.T) {
+	in := "a,y,b\n1,10,2\n3,20,4\n"
+	var buf bytes.Buffer
+	names, rows, err := ConvertCSV(&buf, strings.NewReader(in), "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b"}) || rows != 2 {
+		t.Fatalf("names %v of %d rows", names, rows)
+	}
+
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMapped(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	features, output := m.Rows(0, m.Len())
+	if !reflect.DeepEqual(features, [][]float64{{1, 2}, {3, 4}}) || !reflect.DeepEqual(output, []float64{10, 20}) {
+		t.Errorf("read %v and %v", features, output)
+	}
+}
+
+func TestConvertCSVNamesBadColumn(t *testing.T) {
+	// the bad value is read after rows that
+	// reuse the record of the header
+	in := "alpha,y,beta\n1,10,2\n3,20,x\n"
+	_, _, err := ConvertCSV(&bytes.Buffer{}, strings.NewReader(in), "y")
+	if err == nil || !strings.Contains(err.Error(), `column "beta"`) {
+		t.Errorf("error %v, want one naming column beta", err)
+	}
+}
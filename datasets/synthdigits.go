@@ -0,0 +1,74 @@
+package datasets
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	digitsPerClass = 50
+	digitsSeed     = 1797
+	digitWidth     = 5
+	digitHeight    = 7
+)
+
+// digitGlyphs are 5x7 templates of the digits 0 to 9
+var digitGlyphs = [10][digitHeight]string{
+	{".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	{"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	{".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	{"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	{"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	{"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	{"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	{"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	{".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	{".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+}
+
+// LoadSyntheticDigits returns a generated digit classification
+// dataset of 500 images, 50 per digit. Each image is 5x7
+// pixels flattened row by row with intensities from 0 to 16.
+// Images are generated from fixed templates with a fixed
+// seed, adding intensity noise and a few flipped pixels.
+// It is not the UCI or scikit-learn digits dataset, so
+// scores on it do not compare with published ones.
+func LoadSyntheticDigits() *Dataset {
+	r := rand.New(rand.NewSource(digitsSeed))
+	rows := make([][]float64, 0, 10*digitsPerClass)
+
+	for i := 0; i < digitsPerClass; i++ {
+		for digit, glyph := range digitGlyphs {
+			row := make([]float64, 0, digitWidth*digitHeight+1)
+			for _, line := range glyph {
+				for _, c := range line {
+					on := c == '#'
+					if r.Float64() < 0.05 {
+						on = !on
+					}
+
+					v := float64(r.Intn(4))
+					if on {
+						v = float64(12 + r.Intn(5))
+					}
+					row = append(row, v)
+				}
+			}
+			rows = append(rows, append(row, float64(digit)))
+		}
+	}
+
+	names := make([]string, 0, digitWidth*digitHeight)
+	for y := 0; y < digitHeight; y++ {
+		for x := 0; x < digitWidth; x++ {
+			names = append(names, fmt.Sprintf("pixel %d,%d", y, x))
+		}
+	}
+
+	targets := make([]string, len(digitGlyphs))
+	for i := range targets {
+		targets[i] = fmt.Sprint(i)
+	}
+
+	return newDataset(rows, names, targets)
+}
@@ -0,0 +1,71 @@
+package datasets
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	housingSamples = 200
+	housingSeed    = 1978
+)
+
+// LoadSyntheticHousing returns a generated house price
+// regression dataset of 200 districts. The data is generated
+// from a fixed seed so every call returns the same values.
+// Prices are in thousands and depend mostly on rooms, lower
+// status population and crime rate, with a little noise.
+// Its columns resemble those of the Boston housing data but
+// it is not that dataset, so scores on it do not compare
+// with published ones.
+func LoadSyntheticHousing() *Dataset {
+	r := rand.New(rand.NewSource(housingSeed))
+	rows := make([][]float64, housingSamples)
+
+	for i := range rows {
+		crime := math.Exp(r.NormFloat64()*1.2 - 1)
+		rooms := 6.3 + r.NormFloat64()*0.7
+		age := math.Min(100, math.Max(3, 68+r.NormFloat64()*28))
+		distance := math.Max(1.1, 3.8+r.NormFloat64()*2)
+		tax := 250 + r.Float64()*450
+		pupilTeacher := 13 + r.Float64()*9
+		lowerStatus := math.Max(1.7, 12.6+r.NormFloat64()*7-2*(rooms-6.3))
+
+		price := 22 +
+			5.2*(rooms-6.3) -
+			0.55*(lowerStatus-12.6) -
+			0.3*math.Log(crime) -
+			0.02*(age-68) -
+			0.45*(distance-3.8) -
+			0.01*(tax-475) -
+			0.6*(pupilTeacher-17.5) +
+			r.NormFloat64()*2.5
+		price = math.Min(50, math.Max(5, price))
+
+		rows[i] = []float64{
+			round(crime, 4),
+			round(rooms, 3),
+			round(age, 1),
+			round(distance, 4),
+			math.Round(tax),
+			round(pupilTeacher, 1),
+			round(lowerStatus, 2),
+			round(price, 1),
+		}
+	}
+
+	return newDataset(rows, []string{
+		"crime rate",
+		"rooms",
+		"age",
+		"distance",
+		"tax",
+		"pupil teacher ratio",
+		"lower status",
+	}, nil)
+}
+
+func round(x float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(x*p) / p
+}
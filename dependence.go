@@ -0,0 +1,81 @@
+package ml
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Dependence holds partial dependence and individual
+// conditional expectation (ICE) curves of a column
+type Dependence struct {
+	Column int
+	Grid   []float64
+	// Average is the partial dependence, the mean
+	// of ICE curves at every grid value
+	Average []float64
+	// ICE has the estimates of every row of features
+	// with the column set to every grid value
+	ICE [][]float64
+}
+
+// PartialDependence computes partial dependence and ICE
+// curves of a fitted model for column col over grid.
+// A nil grid uses DependenceGrid with 20 points.
+func PartialDependence(model Estimator, features [][]float64, col int, grid []float64) *Dependence {
+	if grid == nil {
+		grid = DependenceGrid(features, col, 20)
+	}
+
+	d := &Dependence{
+		Column:  col,
+		Grid:    grid,
+		Average: make([]float64, len(grid)),
+		ICE:     make([][]float64, len(features)),
+	}
+
+	for i, row := range features {
+		X := append([]float64(nil), row...)
+		d.ICE[i] = make([]float64, len(grid))
+		for g, v := range grid {
+			X[col] = v
+			d.ICE[i][g] = model.Estimate(X)
+			d.Average[g] += d.ICE[i][g]
+		}
+	}
+
+	for g := range d.Average {
+		d.Average[g] /= float64(len(features))
+	}
+
+	return d
+}
+
+// DependenceGrid returns up to points evenly spaced
+// quantiles of column col between its 5th and 95th
+// percentiles, or its distinct values when there
+// are fewer of them
+func DependenceGrid(features [][]float64, col, points int) []float64 {
+	values := column(features, col)
+	sort.Float64s(values)
+
+	var distinct []float64
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			distinct = append(distinct, v)
+		}
+	}
+	if len(distinct) <= points {
+		return distinct
+	}
+
+	grid := make([]float64, 0, points)
+	for g := 0; g < points; g++ {
+		q := 0.05 + 0.9*float64(g)/float64(points-1)
+		v := stat.Quantile(q, stat.LinInterp, values, nil)
+		if len(grid) == 0 || v != grid[len(grid)-1] {
+			grid = append(grid, v)
+		}
+	}
+	return grid
+}
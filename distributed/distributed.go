@@ -0,0 +1,264 @@
+// Package distributed trains linear models on data split
+// across workers. A Coordinator sends thetas to every
+// worker each round and averages their gradients or
+// trained thetas, weighted by rows, into shared thetas.
+// Workers run in process or behind HTTP in other
+// processes.
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Mode is what workers send back each round
+type Mode int
+
+const (
+	// ParameterAveraging averages thetas trained
+	// locally by SGD from the shared thetas
+	ParameterAveraging Mode = iota
+	// GradientAveraging averages full gradients at the
+	// shared thetas and takes one descent step
+	GradientAveraging
+)
+
+// Request starts a round on a worker
+type Request struct {
+	Round int       `json:"round"`
+	Mode  Mode      `json:"mode"`
+	Theta []float64 `json:"theta"`
+}
+
+// Update is the answer of a worker to a Request
+type Update struct {
+	// Theta are the locally trained thetas
+	// of ParameterAveraging
+	Theta []float64 `json:"theta,omitempty"`
+	// Gradient is the mean gradient of GradientAveraging
+	Gradient []float64 `json:"gradient,omitempty"`
+	// Rows is number of rows of the worker,
+	// the weight of its update
+	Rows int `json:"rows"`
+	// Loss is mean loss over the rows of the worker
+	Loss float64 `json:"loss"`
+}
+
+// Worker runs rounds on its shard of the data.
+// Implementations must be safe for concurrent use.
+type Worker interface {
+	Round(ctx context.Context, req *Request) (*Update, error)
+}
+
+// LocalWorker is a Worker training a model of NewModel on
+// Source with SGD
+type LocalWorker struct {
+	NewModel func() ml.SGDModel
+	Source   ml.RowSource
+	// Epochs of SGD of every ParameterAveraging round
+	Epochs    int
+	Step      float64
+	BatchSize int
+
+	mu sync.Mutex
+}
+
+// NewLocalWorker returns new pointer of LocalWorker
+// running one epoch with step size 0.01 every round
+func NewLocalWorker(newModel func() ml.SGDModel, source ml.RowSource) *LocalWorker {
+	return &LocalWorker{
+		NewModel: newModel,
+		Source:   source,
+		Epochs:   1,
+		Step:     0.01,
+	}
+}
+
+// Round trains or computes the gradient at req.Theta.
+// Rounds of a LocalWorker run one at a time.
+func (w *LocalWorker) Round(ctx context.Context, req *Request) (*Update, error) {
+	if len(req.Theta) != w.Source.Width() {
+		return nil, fmt.Errorf("distributed: got %d thetas for %d features", len(req.Theta), w.Source.Width())
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	model := w.NewModel()
+	update := &Update{Rows: w.Source.Len()}
+
+	switch req.Mode {
+	case GradientAveraging:
+		update.Gradient, update.Loss = ml.Gradient(model, w.Source, req.Theta, 0)
+	case ParameterAveraging:
+		sgd := ml.NewSGD(model)
+		sgd.Theta = req.Theta
+		sgd.Epochs = max(w.Epochs, 1)
+		sgd.Step = w.Step
+		sgd.BatchSize = w.BatchSize
+		if err := sgd.FitContext(ctx, w.Source); err != nil {
+			return nil, err
+		}
+		update.Theta = model.Coefficients()
+		update.Loss = sgd.Losses[len(sgd.Losses)-1]
+	default:
+		return nil, fmt.Errorf("distributed: unknown mode %d", req.Mode)
+	}
+
+	return update, nil
+}
+
+// Coordinator trains shared thetas on Workers
+type Coordinator struct {
+	Workers []Worker
+	Mode    Mode
+	// Rounds is the maximum number of rounds
+	Rounds int
+	// Step is the step size of GradientAveraging
+	Step float64
+	// Tol stops training when no theta moves more
+	// than Tol in a round
+	Tol float64
+	// Logger receives progress of rounds, nil is silent
+	Logger ml.Logger
+
+	// Theta are the shared thetas
+	Theta []float64
+	// Losses is the mean loss of every round
+	Losses []float64
+}
+
+// NewCoordinator returns new pointer of Coordinator
+// averaging parameters for up to 100 rounds
+func NewCoordinator(workers []Worker) *Coordinator {
+	return &Coordinator{
+		Workers: workers,
+		Mode:    ParameterAveraging,
+		Rounds:  100,
+		Step:    0.1,
+		Tol:     1e-6,
+	}
+}
+
+// Train runs rounds starting from theta until thetas
+// converge or Rounds is reached, and returns them.
+// A failed worker fails the round and Train.
+func (c *Coordinator) Train(ctx context.Context, theta []float64) ([]float64, error) {
+	if len(c.Workers) == 0 {
+		return nil, fmt.Errorf("distributed: no workers")
+	}
+
+	c.Theta = append([]float64(nil), theta...)
+	c.Losses = c.Losses[:0]
+	for round := 1; round <= c.Rounds; round++ {
+		updates, err := c.round(ctx, &Request{Round: round, Mode: c.Mode, Theta: c.Theta})
+		if err != nil {
+			return nil, fmt.Errorf("distributed: round %d: %v", round, err)
+		}
+
+		next, loss, err := c.average(updates)
+		if err != nil {
+			return nil, fmt.Errorf("distributed: round %d: %v", round, err)
+		}
+
+		moved := 0.0
+		for j := range next {
+			moved = math.Max(moved, math.Abs(next[j]-c.Theta[j]))
+		}
+		c.Theta = next
+		c.Losses = append(c.Losses, loss)
+		if c.Logger != nil {
+			c.Logger.Info("distributed: round finished", "round", round, "loss", loss, "moved", moved)
+		}
+
+		if moved < c.Tol {
+			break
+		}
+	}
+
+	return c.Theta, nil
+}
+
+// round sends req to every worker concurrently
+func (c *Coordinator) round(ctx context.Context, req *Request) ([]*Update, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make([]*Update, len(c.Workers))
+	errs := make([]error, len(c.Workers))
+	var wg sync.WaitGroup
+	for i, w := range c.Workers {
+		wg.Add(1)
+		go func(i int, w Worker) {
+			defer wg.Done()
+			updates[i], errs[i] = w.Round(ctx, req)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, w)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("worker %d: %v", i, err)
+		}
+	}
+	return updates, nil
+}
+
+// Average returns the means of values and losses
+// weighted by rows, every values having width values
+func Average(values [][]float64, rows []int, losses []float64, width int) ([]float64, float64, error) {
+	total := 0
+	for _, n := range rows {
+		total += n
+	}
+	if total == 0 {
+		return nil, 0, fmt.Errorf("updates have no rows")
+	}
+
+	mean := make([]float64, width)
+	loss := 0.0
+	for i, v := range values {
+		if len(v) != width {
+			return nil, 0, fmt.Errorf("update %d has %d values, expected %d", i, len(v), width)
+		}
+
+		w := float64(rows[i]) / float64(total)
+		for j := range mean {
+			mean[j] += w * v[j]
+		}
+		loss += w * losses[i]
+	}
+	return mean, loss, nil
+}
+
+// average returns the next thetas and mean loss
+// of updates weighted by their rows
+func (c *Coordinator) average(updates []*Update) ([]float64, float64, error) {
+	values := make([][]float64, len(updates))
+	rows := make([]int, len(updates))
+	losses := make([]float64, len(updates))
+	for i, u := range updates {
+		values[i], rows[i], losses[i] = u.Theta, u.Rows, u.Loss
+		if c.Mode == GradientAveraging {
+			values[i] = u.Gradient
+		}
+	}
+	mean, loss, err := Average(values, rows, losses, len(c.Theta))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if c.Mode == GradientAveraging {
+		for j := range mean {
+			mean[j] = c.Theta[j] - c.Step*mean[j]
+		}
+	}
+	return mean, loss, nil
+}
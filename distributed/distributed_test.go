@@ -0,0 +1,101 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// shard returns n rows of y = 1 + 2x from offset
+func shard(n int, offset float64) ml.Slices {
+	s := ml.Slices{}
+	for i := 0; i < n; i++ {
+		x := offset + float64(i)/float64(n)
+		s.Features = append(s.Features, []float64{1, x})
+		s.Output = append(s.Output, 1+2*x)
+	}
+	return s
+}
+
+// failing is a Worker failing every round
+type failing struct{}
+
+func (failing) Round(ctx context.Context, req *Request) (*Update, error) {
+	return nil, errors.New("worker is down")
+}
+
+func TestGradientAveragingIsGradientDescent(t *testing.T) {
+	// gradients of shards weighted by rows are the
+	// gradient of all rows
+	newModel := func() ml.SGDModel { return ml.NewLinearRegression() }
+	shards := []ml.Slices{shard(30, 0), shard(10, 1), shard(20, -1)}
+	var all ml.Slices
+	var workers []Worker
+	for _, s := range shards {
+		all.Features = append(all.Features, s.Features...)
+		all.Output = append(all.Output, s.Output...)
+		workers = append(workers, NewLocalWorker(newModel, s))
+	}
+
+	c := NewCoordinator(workers)
+	c.Mode, c.Rounds, c.Tol = GradientAveraging, 5, 0
+	got, err := c.Train(context.Background(), []float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0, 0}
+	for round := 0; round < 5; round++ {
+		grad, _ := ml.Gradient(newModel(), all, want, 0)
+		for j := range want {
+			want[j] -= c.Step * grad[j]
+		}
+	}
+	for j := range want {
+		if math.Abs(got[j]-want[j]) > 1e-12 {
+			t.Fatalf("thetas %v, want %v of gradient descent", got, want)
+		}
+	}
+	if len(c.Losses) != 5 || c.Losses[4] >= c.Losses[0] {
+		t.Errorf("losses %v, want 5 decreasing", c.Losses)
+	}
+}
+
+func TestHTTPWorker(t *testing.T) {
+	// a worker behind HTTP trains like in process,
+	// full batches make SGD independent of the shuffle
+	newModel := func() ml.SGDModel { return ml.NewLinearRegression() }
+	s := shard(20, 0)
+	local := NewLocalWorker(newModel, s)
+	local.BatchSize = len(s.Output)
+	server := httptest.NewServer(&Handler{Worker: local})
+	defer server.Close()
+
+	train := func(w Worker) []float64 {
+		c := NewCoordinator([]Worker{w})
+		c.Rounds = 3
+		theta, err := c.Train(context.Background(), []float64{0, 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return theta
+	}
+	remote, inProcess := train(&Client{URL: server.URL}), train(local)
+	for j := range remote {
+		if math.Abs(remote[j]-inProcess[j]) > 1e-12 {
+			t.Fatalf("thetas %v over HTTP, %v in process", remote, inProcess)
+		}
+	}
+
+	if _, err := (&Client{URL: server.URL}).Round(context.Background(), &Request{Theta: []float64{0}}); err == nil {
+		t.Error("no error of thetas of the wrong width")
+	}
+	c := NewCoordinator([]Worker{local, failing{}})
+	if _, err := c.Train(context.Background(), []float64{0, 0}); err == nil {
+		t.Error("no error of a failing worker")
+	}
+}
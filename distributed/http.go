@@ -0,0 +1,89 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Handler serves a Worker to a Client in another
+// process: POST a JSON Request, get a JSON Update
+// or {"error": ".."}.
+type Handler struct {
+	Worker Worker
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP runs a round of the worker
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(errorResponse{Error: fmt.Sprintf("method %s not allowed", r.Method)})
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: fmt.Sprintf("invalid JSON: %v", err)})
+		return
+	}
+
+	update, err := h.Worker.Round(r.Context(), &req)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(update)
+}
+
+// Client is a Worker served by a Handler at URL
+type Client struct {
+	URL string
+	// HTTP defaults to http.DefaultClient
+	HTTP *http.Client
+}
+
+// Round posts req to the worker
+func (c *Client) Round(ctx context.Context, req *Request) (*Update, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("%s: %s", c.URL, e.Error)
+		}
+		return nil, fmt.Errorf("%s: %s", c.URL, resp.Status)
+	}
+
+	var update Update
+	if err = json.NewDecoder(resp.Body).Decode(&update); err != nil {
+		return nil, fmt.Errorf("%s: invalid update: %v", c.URL, err)
+	}
+	return &update, nil
+}
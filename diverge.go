@@ -0,0 +1,100 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// DivergenceError is returned when the loss of training
+// becomes NaN, infinite or explodes. The model keeps
+// Theta, the last thetas of a finite loss.
+type DivergenceError struct {
+	// Iteration is the SGD epoch or the
+	// evaluation of the optimizer
+	Iteration int
+	Loss      float64
+	Theta     []float64
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("ml: training diverged at iteration %d with loss %v, keeping the last finite thetas; "+
+		"lower the step or learning rate, scale the features or clip gradients", e.Iteration, e.Loss)
+}
+
+// WithGradientClipping scales SGD and Adam gradients
+// down to norm when their norm is larger
+func WithGradientClipping(norm float64) Option {
+	return func(o *options) error {
+		if !(norm > 0) {
+			return fmt.Errorf("ml: clip norm %v should be positive", norm)
+		}
+		o.setting().ClipNorm = norm
+		return nil
+	}
+}
+
+// clipGradient scales grad down to norm,
+// zero norm is no clipping
+func clipGradient(grad []float64, norm float64) {
+	if norm <= 0 {
+		return
+	}
+	if n := floats.Norm(grad, 2); n > norm {
+		floats.Scale(norm/n, grad)
+	}
+}
+
+// divergenceRatio returns DivergenceRatio of setting,
+// defaulting to 1e6
+func divergenceRatio(setting *LinearSetting) float64 {
+	if setting == nil || setting.DivergenceRatio <= 0 {
+		return 1e6
+	}
+	return setting.DivergenceRatio
+}
+
+// watchdog keeps the thetas of the lowest finite
+// loss evaluated by an optimizer
+type watchdog struct {
+	evaluations int
+	best        float64
+	theta       []float64
+	bad         float64
+	badAt       int
+}
+
+// watch returns prob with Func recorded by w
+func (w *watchdog) watch(prob optimize.Problem) optimize.Problem {
+	w.best = math.Inf(1)
+	f := prob.Func
+	prob.Func = func(x []float64) float64 {
+		v := f(x)
+		w.evaluations++
+		switch {
+		case math.IsNaN(v) || math.IsInf(v, 0):
+			if w.badAt == 0 {
+				w.bad, w.badAt = v, w.evaluations
+			}
+		case v < w.best:
+			w.best = v
+			w.theta = append(w.theta[:0], x...)
+		}
+		return v
+	}
+	return prob
+}
+
+// diverged returns a DivergenceError when the optimizer
+// failed or ended at a non-finite loss after evaluating one
+func (w *watchdog) diverged(result *optimize.Result, err error) *DivergenceError {
+	if w.badAt == 0 || w.theta == nil {
+		return nil
+	}
+	if err == nil && result != nil && !math.IsNaN(result.F) && !math.IsInf(result.F, 0) {
+		return nil
+	}
+	return &DivergenceError{Iteration: w.badAt, Loss: w.bad, Theta: append([]float64(nil), w.theta...)}
+}
@@ -0,0 +1,401 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+// ElasticNet is a linear regression minimizing
+//
+//	1/(2n)·Σ(y-θ·x)² + λ·(α·|θ|₁ + (1-α)/2·|θ|²)
+//
+// by coordinate descent, λ being Lambda and α L1Ratio. Lasso
+// is L1Ratio 1 and ridge L1Ratio 0. Constant columns, such
+// as the bias column, are not penalized. Penalties depend on
+// the scale of columns, so standardize features first.
+type ElasticNet struct {
+	Lambda  float64
+	L1Ratio float64
+	// MaxIterations are passes over the columns,
+	// defaults to 1000
+	MaxIterations int
+	// Tolerance stops when no theta moves more,
+	// relative to the output, defaults to 1e-7
+	Tolerance float64
+	// WarmStart makes Fit start from current thetas
+	WarmStart bool
+
+	Theta []float64
+	// Iterations are passes of the last Fit
+	Iterations int
+}
+
+// NewElasticNet returns new pointer of ElasticNet
+func NewElasticNet(lambda, l1Ratio float64) *ElasticNet {
+	return &ElasticNet{Lambda: lambda, L1Ratio: l1Ratio, MaxIterations: 1000, Tolerance: 1e-7}
+}
+
+// NewLasso returns new pointer of ElasticNet
+// with the L1 penalty only
+func NewLasso(lambda float64) *ElasticNet {
+	return NewElasticNet(lambda, 1)
+}
+
+// NewRidge returns new pointer of ElasticNet
+// with the L2 penalty only
+func NewRidge(lambda float64) *ElasticNet {
+	return NewElasticNet(lambda, 0)
+}
+
+// Fit trains the model on features and output
+func (e *ElasticNet) Fit(features [][]float64, output []float64) error {
+	if err := checkPenalty(e.Lambda, e.L1Ratio); err != nil {
+		return err
+	}
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	cd := newCoordinateDescent(features, output)
+	if !e.WarmStart || len(e.Theta) != p {
+		e.Theta = make([]float64, p)
+	}
+	e.Iterations = cd.run(e.Theta, e.Lambda, e.L1Ratio, e.MaxIterations, e.Tolerance)
+	if e.Iterations == e.maxIterations() {
+		packageLogger().Warn("ml: elastic net did not converge", "iterations", e.Iterations, "lambda", e.Lambda)
+	}
+	return nil
+}
+
+func (e *ElasticNet) maxIterations() int {
+	if e.MaxIterations <= 0 {
+		return 1000
+	}
+	return e.MaxIterations
+}
+
+// Estimate returns predicted value of X
+func (e *ElasticNet) Estimate(X []float64) float64 {
+	return floats.Dot(X, e.Theta)
+}
+
+// Coefficients returns thetas
+func (e *ElasticNet) Coefficients() []float64 {
+	return e.Theta
+}
+
+func checkPenalty(lambda, l1Ratio float64) error {
+	if !(lambda >= 0) {
+		return fmt.Errorf("ml: lambda %v should not be negative", lambda)
+	}
+	if !(l1Ratio >= 0 && l1Ratio <= 1) {
+		return fmt.Errorf("ml: L1 ratio %v should be in [0, 1]", l1Ratio)
+	}
+	return nil
+}
+
+// RegularizationPath holds coefficients of elastic nets
+// of every lambda, largest lambda first
+type RegularizationPath struct {
+	L1Ratio      float64
+	Lambdas      []float64
+	Coefficients [][]float64
+	// Iterations are passes over the columns
+	// of every lambda
+	Iterations []int
+}
+
+// ElasticNetPath fits elastic nets of l1Ratio for every
+// lambda, from the largest, each starting from thetas of
+// the previous one so the whole path costs little more
+// than a single fit. Nil lambdas use LambdaGrid of 100
+// lambdas.
+func ElasticNetPath(features [][]float64, output []float64, l1Ratio float64, lambdas []float64) (*RegularizationPath, error) {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return nil, err
+	}
+	if lambdas == nil {
+		lambdas = LambdaGrid(features, output, l1Ratio, 100, 1e-3)
+	}
+	lambdas = append([]float64(nil), lambdas...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(lambdas)))
+	for _, lambda := range lambdas {
+		if err := checkPenalty(lambda, l1Ratio); err != nil {
+			return nil, err
+		}
+	}
+
+	path := &RegularizationPath{
+		L1Ratio:      l1Ratio,
+		Lambdas:      lambdas,
+		Coefficients: make([][]float64, len(lambdas)),
+		Iterations:   make([]int, len(lambdas)),
+	}
+	cd := newCoordinateDescent(features, output)
+	theta := make([]float64, p)
+	for k, lambda := range lambdas {
+		path.Iterations[k] = cd.run(theta, lambda, l1Ratio, 1000, 1e-7)
+		path.Coefficients[k] = append([]float64(nil), theta...)
+	}
+	return path, nil
+}
+
+// LambdaGrid returns n lambdas evenly spaced on a log scale
+// from the smallest lambda of zero penalized coefficients of
+// lasso, scaled by 1/l1Ratio, down to eps times the lasso
+// one. Ridge uses l1Ratio 0.001 for the largest lambda,
+// like glmnet.
+func LambdaGrid(features [][]float64, output []float64, l1Ratio float64, n int, eps float64) []float64 {
+	if _, err := checkRows(features, output); err != nil || n < 1 {
+		return nil
+	}
+	cd := newCoordinateDescent(features, output)
+	theta := make([]float64, len(cd.cols))
+	// unpenalized columns only, e.g. the intercept
+	cd.run(theta, math.Inf(1), 1, 1000, 1e-7)
+
+	rows := float64(len(output))
+	top := 0.0
+	for j, c := range cd.cols {
+		if cd.penalized[j] {
+			top = math.Max(top, math.Abs(floats.Dot(c, cd.residual))/rows)
+		}
+	}
+	if top == 0 {
+		top = 1
+	}
+	// the smallest lambda does not scale with
+	// l1Ratio, so ridge paths reach small penalties
+	lo := top * eps
+	top /= math.Max(l1Ratio, 1e-3)
+
+	lambdas := make([]float64, n)
+	for k := range lambdas {
+		if n == 1 {
+			lambdas[k] = top
+			break
+		}
+		lambdas[k] = top * math.Pow(lo/top, float64(k)/float64(n-1))
+	}
+	return lambdas
+}
+
+// coordinateDescent solves elastic nets
+// of columns of features
+type coordinateDescent struct {
+	cols      [][]float64
+	output    []float64
+	residual  []float64
+	penalized []bool
+	// norms are mean squares of columns
+	norms []float64
+	scale float64
+}
+
+func newCoordinateDescent(features [][]float64, output []float64) *coordinateDescent {
+	p := len(features[0])
+	cd := &coordinateDescent{
+		cols:      make([][]float64, p),
+		output:    output,
+		residual:  make([]float64, len(output)),
+		penalized: make([]bool, p),
+		norms:     make([]float64, p),
+		scale:     math.Max(stat.Variance(output, nil), 1e-12),
+	}
+	for j := range cd.cols {
+		cd.cols[j] = column(features, j)
+		cd.penalized[j] = stat.Variance(cd.cols[j], nil) > 0
+		cd.norms[j] = floats.Dot(cd.cols[j], cd.cols[j]) / float64(len(output))
+	}
+	return cd
+}
+
+// run updates theta in place to the minimum of lambda and
+// l1Ratio and returns the passes over the columns
+func (cd *coordinateDescent) run(theta []float64, lambda, l1Ratio float64, maxIterations int, tolerance float64) int {
+	if maxIterations <= 0 {
+		maxIterations = 1000
+	}
+	if tolerance <= 0 {
+		tolerance = 1e-7
+	}
+	rows := float64(len(cd.output))
+	copy(cd.residual, cd.output)
+	for j, c := range cd.cols {
+		floats.AddScaled(cd.residual, -theta[j], c)
+	}
+
+	for it := 1; it <= maxIterations; it++ {
+		moved := 0.0
+		for j, c := range cd.cols {
+			if cd.norms[j] == 0 {
+				continue
+			}
+			old := theta[j]
+			rho := floats.Dot(c, cd.residual)/rows + cd.norms[j]*old
+			switch {
+			case !cd.penalized[j]:
+				theta[j] = rho / cd.norms[j]
+			case math.IsInf(lambda, 1):
+				theta[j] = 0
+			default:
+				theta[j] = softThreshold(rho, lambda*l1Ratio) / (cd.norms[j] + lambda*(1-l1Ratio))
+			}
+			if d := theta[j] - old; d != 0 {
+				floats.AddScaled(cd.residual, -d, c)
+				moved = math.Max(moved, cd.norms[j]*d*d)
+			}
+		}
+		if moved <= tolerance*cd.scale {
+			return it
+		}
+	}
+	return maxIterations
+}
+
+func softThreshold(x, t float64) float64 {
+	switch {
+	case x > t:
+		return x - t
+	case x < -t:
+		return x + t
+	}
+	return 0
+}
+
+// ElasticNetCV chooses Lambda of an ElasticNet by the mean
+// squared error of cross-validated regularization paths,
+// then refits it on all rows
+type ElasticNetCV struct {
+	L1Ratio float64
+	// Lambdas default to LambdaGrid of 100 lambdas
+	Lambdas []float64
+	// CV defaults to 5 folds shuffled by Rand
+	CV Splitter
+	// Rand seeds the default folds,
+	// nil uses the global source
+	Rand *rand.Rand
+	// OneSE chooses the largest lambda within one
+	// standard error of the best mean error, a
+	// simpler model scoring about as well
+	OneSE bool
+
+	// Lambda is the chosen lambda
+	Lambda float64
+	// Errors and StdErrors are the mean squared error of
+	// folds and its standard error, of every lambda of
+	// Path in order
+	Errors    []float64
+	StdErrors []float64
+	// Path is the regularization path of all rows
+	Path  *RegularizationPath
+	Model *ElasticNet
+}
+
+// NewElasticNetCV returns new pointer of ElasticNetCV
+func NewElasticNetCV(l1Ratio float64) *ElasticNetCV {
+	return &ElasticNetCV{L1Ratio: l1Ratio}
+}
+
+// NewLassoCV returns new pointer of ElasticNetCV
+// with the L1 penalty only
+func NewLassoCV() *ElasticNetCV {
+	return NewElasticNetCV(1)
+}
+
+// NewRidgeCV returns new pointer of ElasticNetCV
+// with the L2 penalty only
+func NewRidgeCV() *ElasticNetCV {
+	return NewElasticNetCV(0)
+}
+
+// Fit chooses lambda and fits the model on features and output
+func (e *ElasticNetCV) Fit(features [][]float64, output []float64) error {
+	path, err := ElasticNetPath(features, output, e.L1Ratio, e.Lambdas)
+	if err != nil {
+		return err
+	}
+	cv := e.CV
+	if cv == nil {
+		cv = KFold{K: 5, Shuffle: true, Seed: RandOf(e.Rand).Int63()}
+	}
+
+	folds, err := cv.Split(len(features))
+	if err != nil {
+		return err
+	}
+	errs := make([][]float64, len(path.Lambdas))
+	for f, fold := range folds {
+		trainX, trainY := subset(features, output, fold.Train)
+		testX, testY := subset(features, output, fold.Test)
+		p, err := ElasticNetPath(trainX, trainY, e.L1Ratio, path.Lambdas)
+		if err != nil {
+			return fmt.Errorf("ml: fold %d: %v", f, err)
+		}
+		for k, theta := range p.Coefficients {
+			estimates := make([]float64, len(testX))
+			for i, x := range testX {
+				estimates[i] = floats.Dot(x, theta)
+			}
+			errs[k] = append(errs[k], MeanSquaredError(testY, estimates))
+		}
+	}
+
+	e.Path = path
+	e.Errors = make([]float64, len(errs))
+	e.StdErrors = make([]float64, len(errs))
+	best := 0
+	for k, fold := range errs {
+		m, std := stat.MeanStdDev(fold, nil)
+		e.Errors[k], e.StdErrors[k] = m, std/math.Sqrt(float64(len(fold)))
+		if m < e.Errors[best] {
+			best = k
+		}
+	}
+	chosen := best
+	if e.OneSE {
+		// lambdas are largest first
+		for k := 0; k < best; k++ {
+			if e.Errors[k] <= e.Errors[best]+e.StdErrors[best] {
+				chosen = k
+				break
+			}
+		}
+	}
+
+	e.Lambda = path.Lambdas[chosen]
+	e.Model = NewElasticNet(e.Lambda, e.L1Ratio)
+	e.Model.Theta = append([]float64(nil), path.Coefficients[chosen]...)
+	e.Model.WarmStart = true
+	if err := e.Model.Fit(features, output); err != nil {
+		return err
+	}
+	e.Model.WarmStart = false
+	return nil
+}
+
+// Estimate returns predicted value of X
+func (e *ElasticNetCV) Estimate(X []float64) float64 {
+	return e.Model.Estimate(X)
+}
+
+// Coefficients returns thetas of the model
+func (e *ElasticNetCV) Coefficients() []float64 {
+	return e.Model.Coefficients()
+}
+
+// GobEncode encodes the model without Rand
+func (e *ElasticNetCV) GobEncode() ([]byte, error) {
+	return GobEncodeModel(e)
+}
+
+// GobDecode decodes a model of GobEncode
+func (e *ElasticNetCV) GobDecode(data []byte) error {
+	return GobDecodeModel(e, data)
+}
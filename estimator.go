@@ -0,0 +1,31 @@
+package ml
+
+// Estimator is a model that can be trained on features
+// and output, then estimates the output of a sample.
+// Classifiers estimate the probability of the sample
+// being true.
+//
+// Estimate of a fitted model of this package is safe for
+// concurrent use. LinearRegression and LogisticRegression
+// make Fit wait for running estimates and clones, and
+// them for a running Fit. Other models must not be fitted
+// together with Estimate; fit a Clone instead.
+type Estimator interface {
+	Fit(features [][]float64, output []float64) error
+	Estimate(X []float64) float64
+}
+
+// Transformer is a preprocessing step which learns
+// its parameters from features and output, then
+// transforms features
+type Transformer interface {
+	Fit(features [][]float64, output []float64) error
+	Transform(features [][]float64) [][]float64
+}
+
+// LinearModel is an estimator with one
+// coefficient for every feature column
+type LinearModel interface {
+	Estimator
+	Coefficients() []float64
+}
@@ -0,0 +1,307 @@
+// Package experiments records runs of experiments, their
+// parameters, metrics per step and artifacts, to a Backend
+// so runs of a hyperparameter sweep can be compared later.
+// FileBackend appends runs to logs in a local directory,
+// StorageBackend stores them as JSON in an ml.Storage and
+// MLflow in an MLflow tracking server. Writes to a run
+// that ended fail.
+package experiments
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Status of a run
+type Status string
+
+const (
+	// Running runs have not ended
+	Running Status = "RUNNING"
+	// Finished runs ended without error
+	Finished Status = "FINISHED"
+	// Failed runs ended with an error
+	Failed Status = "FAILED"
+)
+
+// Point is a value of a metric at a step,
+// e.g. the loss of an epoch
+type Point struct {
+	Step  int       `json:"step"`
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+// Run is a recorded run of an experiment
+type Run struct {
+	ID         string             `json:"id"`
+	Experiment string             `json:"experiment"`
+	Name       string             `json:"name,omitempty"`
+	Status     Status             `json:"status"`
+	Params     map[string]string  `json:"params,omitempty"`
+	Metrics    map[string][]Point `json:"metrics,omitempty"`
+	Artifacts  []string           `json:"artifacts,omitempty"`
+	Start      time.Time          `json:"start"`
+	End        time.Time          `json:"end"`
+}
+
+// Last returns the last value of metric,
+// NaN when it was not logged
+func (r *Run) Last(metric string) float64 {
+	points := r.Metrics[metric]
+	if len(points) == 0 {
+		return math.NaN()
+	}
+	return points[len(points)-1].Value
+}
+
+// Backend stores runs. Implementations must be
+// safe for concurrent use.
+type Backend interface {
+	// CreateRun stores a new run and sets its ID
+	CreateRun(run *Run) error
+	// LogParams adds params to run id
+	LogParams(id string, params map[string]string) error
+	// LogMetric adds a point of metric key to run id
+	LogMetric(id, key string, point Point) error
+	// LogArtifact stores data as artifact name of run id
+	LogArtifact(id, name string, data []byte) error
+	// EndRun sets status and end time of run id
+	EndRun(id string, status Status, end time.Time) error
+	// Runs returns runs of experiment, oldest first
+	Runs(experiment string) ([]*Run, error)
+}
+
+// Tracker starts runs recorded to Backend
+type Tracker struct {
+	Backend Backend
+	// Now returns timestamps, defaults to time.Now
+	Now func() time.Time
+}
+
+// New returns new pointer of Tracker recording to backend
+func New(backend Backend) *Tracker {
+	return &Tracker{Backend: backend, Now: time.Now}
+}
+
+func (t *Tracker) now() time.Time {
+	if t.Now == nil {
+		return time.Now().UTC()
+	}
+	return t.Now().UTC()
+}
+
+// Start starts a run of experiment with params,
+// name and params may be empty
+func (t *Tracker) Start(experiment, name string, params map[string]string) (*ActiveRun, error) {
+	if experiment == "" {
+		return nil, fmt.Errorf("experiments: empty experiment name")
+	}
+	run := &Run{
+		Experiment: experiment,
+		Name:       name,
+		Status:     Running,
+		Params:     map[string]string{},
+		Metrics:    map[string][]Point{},
+		Start:      t.now(),
+	}
+	if err := t.Backend.CreateRun(run); err != nil {
+		return nil, err
+	}
+
+	a := &ActiveRun{Run: run, tracker: t, steps: map[string]int{}, counters: map[string]float64{}}
+	if len(params) > 0 {
+		if err := a.Params(params); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Runs returns runs of experiment, oldest first
+func (t *Tracker) Runs(experiment string) ([]*Run, error) {
+	return t.Backend.Runs(experiment)
+}
+
+// Best returns finished runs of experiment sorted by the
+// last value of metric, best first. Runs without the
+// metric are left out.
+func (t *Tracker) Best(experiment, metric string, higherIsBetter bool) ([]*Run, error) {
+	runs, err := t.Backend.Runs(experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	var best []*Run
+	for _, r := range runs {
+		if r.Status == Finished && !math.IsNaN(r.Last(metric)) {
+			best = append(best, r)
+		}
+	}
+	sort.SliceStable(best, func(i, j int) bool {
+		if higherIsBetter {
+			return best[i].Last(metric) > best[j].Last(metric)
+		}
+		return best[i].Last(metric) < best[j].Last(metric)
+	})
+	return best, nil
+}
+
+// ActiveRun is a run being recorded. It implements
+// ml.Metrics so training loss of a model is recorded
+// per iteration, e.g. with ml.WithMetrics(run). It is
+// safe for concurrent use.
+type ActiveRun struct {
+	Run *Run
+
+	tracker  *Tracker
+	mu       sync.Mutex
+	steps    map[string]int
+	counters map[string]float64
+	// err is the first error of ml.Metrics
+	// methods, returned by End
+	err   error
+	ended bool
+}
+
+// Param records a parameter, formatted with %v
+func (a *ActiveRun) Param(key string, value interface{}) error {
+	return a.Params(map[string]string{key: fmt.Sprint(value)})
+}
+
+// Params records parameters
+func (a *ActiveRun) Params(params map[string]string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.active(); err != nil {
+		return err
+	}
+	if err := a.tracker.Backend.LogParams(a.Run.ID, params); err != nil {
+		return err
+	}
+	for k, v := range params {
+		a.Run.Params[k] = v
+	}
+	return nil
+}
+
+// Metric records value of metric key at step
+func (a *ActiveRun) Metric(key string, step int, value float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.metric(key, step, value)
+}
+
+// active returns an error of a run that ended
+func (a *ActiveRun) active() error {
+	if a.ended {
+		return fmt.Errorf("experiments: run %s already ended", a.Run.ID)
+	}
+	return nil
+}
+
+func (a *ActiveRun) metric(key string, step int, value float64) error {
+	if err := a.active(); err != nil {
+		return err
+	}
+	p := Point{Step: step, Value: value, Time: a.tracker.now()}
+	if err := a.tracker.Backend.LogMetric(a.Run.ID, key, p); err != nil {
+		return err
+	}
+	a.Run.Metrics[key] = append(a.Run.Metrics[key], p)
+	if step >= a.steps[key] {
+		a.steps[key] = step + 1
+	}
+	return nil
+}
+
+// Artifact records data as artifact name
+func (a *ActiveRun) Artifact(name string, data []byte) error {
+	if name == "" {
+		return fmt.Errorf("experiments: empty artifact name")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.active(); err != nil {
+		return err
+	}
+	if err := a.tracker.Backend.LogArtifact(a.Run.ID, name, data); err != nil {
+		return err
+	}
+	a.Run.Artifacts = append(a.Run.Artifacts, name)
+	return nil
+}
+
+// Model records model, saved with ml.Save,
+// as artifact name
+func (a *ActiveRun) Model(name string, model interface{}) error {
+	var buf bytes.Buffer
+	if err := ml.Save(&buf, model); err != nil {
+		return err
+	}
+	return a.Artifact(name, buf.Bytes())
+}
+
+// End ends the run, as failed when err is not nil.
+// It returns the first error recording ml.Metrics.
+func (a *ActiveRun) End(err error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.active(); err != nil {
+		return err
+	}
+	status := Finished
+	if err != nil {
+		status = Failed
+	}
+	end := a.tracker.now()
+	if err := a.tracker.Backend.EndRun(a.Run.ID, status, end); err != nil {
+		return err
+	}
+	a.ended = true
+	a.Run.Status, a.Run.End = status, end
+	return a.err
+}
+
+// Add increases counter name by value and records it
+// at the next step, it implements ml.Metrics
+func (a *ActiveRun) Add(name string, value float64, labels map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.counters[name] += value
+	a.record(name, a.counters[name])
+}
+
+// Set records value of name at the next step,
+// it implements ml.Metrics
+func (a *ActiveRun) Set(name string, value float64, labels map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.record(name, value)
+}
+
+// Observe records value of name at the next step,
+// it implements ml.Metrics
+func (a *ActiveRun) Observe(name string, value float64, labels map[string]string) {
+	a.Set(name, value, labels)
+}
+
+// record records value at the next step of name,
+// keeping the first error for End
+func (a *ActiveRun) record(name string, value float64) {
+	if err := a.metric(name, a.steps[name], value); err != nil && a.err == nil {
+		a.err = err
+	}
+}
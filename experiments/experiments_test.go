@@ -0,0 +1,134 @@
+package experiments
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func TestFileBackendWritesImmediately(t *testing.T) {
+	root := t.TempDir()
+	tracker := New(NewFileBackend(root))
+	run, err := tracker.Start("sweep", "lr=0.1", map[string]string{"lr": "0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step, loss := range []float64{3, 2, 1.5} {
+		if err := run.Metric("loss", step, loss); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// another backend of the directory, as after a
+	// crash, reads every metric of the running run
+	runs, err := NewFileBackend(root).Runs("sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != Running || runs[0].Last("loss") != 1.5 || runs[0].Params["lr"] != "0.1" {
+		t.Fatalf("read %+v", runs)
+	}
+
+	if err := run.Artifact("notes.txt", []byte("ok")); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.End(nil); err != nil {
+		t.Fatal(err)
+	}
+	best, err := tracker.Best("sweep", "loss", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(best) != 1 || best[0].Status != Finished || !reflect.DeepEqual(best[0].Artifacts, []string{"notes.txt"}) || len(best[0].Metrics["loss"]) != 3 {
+		t.Errorf("read %+v", best)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "sweep", run.Run.ID, "artifacts", "notes.txt")); err != nil || string(data) != "ok" {
+		t.Errorf("artifact %q, %v", data, err)
+	}
+}
+
+func TestFileBackendCutShortLog(t *testing.T) {
+	root := t.TempDir()
+	run, err := New(NewFileBackend(root)).Start("sweep", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Metric("loss", 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash in the middle of a line
+	f, err := os.OpenFile(filepath.Join(root, "sweep", run.Run.ID, "events.jsonl"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"kind":"metric","key":"lo`)
+	f.Close()
+
+	runs, err := NewFileBackend(root).Runs("sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || len(runs[0].Metrics["loss"]) != 1 {
+		t.Errorf("read %+v", runs)
+	}
+}
+
+func TestWritesToEndedRun(t *testing.T) {
+	backends := map[string]Backend{
+		"file":    NewFileBackend(t.TempDir()),
+		"storage": NewStorageBackend(ml.NewFileStorage(t.TempDir())),
+	}
+	for name, backend := range backends {
+		run, err := New(backend).Start("sweep", "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := run.End(errors.New("diverged")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := run.Metric("loss", 0, 1); err == nil {
+			t.Errorf("%s: no error of a metric of an ended run", name)
+		}
+		if err := run.Artifact("model.bin", nil); err == nil {
+			t.Errorf("%s: no error of an artifact of an ended run", name)
+		}
+		if err := backend.LogArtifact(run.Run.ID, "model.bin", nil); !errors.Is(err, ml.ErrNotFound) {
+			t.Errorf("%s: backend error %v of an ended run, want ml.ErrNotFound", name, err)
+		}
+		runs, err := backend.Runs("sweep")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) != 1 || runs[0].Status != Failed || len(runs[0].Metrics) != 0 || len(runs[0].Artifacts) != 0 {
+			t.Errorf("%s: read %+v", name, runs[0])
+		}
+	}
+}
+
+func TestStorageBackendWritesMetrics(t *testing.T) {
+	storage := ml.NewFileStorage(t.TempDir())
+	tracker := New(NewStorageBackend(storage))
+	tracker.Now = func() time.Time { return time.Unix(0, 0) }
+	run, err := tracker.Start("sweep", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Metric("loss", 0, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := NewStorageBackend(storage).Runs("sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Last("loss") != 2 {
+		t.Errorf("read %+v", runs)
+	}
+}
@@ -0,0 +1,273 @@
+package experiments
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// FileBackend stores runs in directory Root as append-only
+// logs of JSON events, <experiment>/<run>/events.jsonl, and
+// artifacts under <experiment>/<run>/artifacts/<name>.
+// Every call is written and synced before it returns, so a
+// sweep that crashes keeps every metric logged so far. It
+// needs no database server or driver. Only one FileBackend
+// should write a directory at a time.
+type FileBackend struct {
+	Root string
+
+	mu sync.Mutex
+	// runs holds logs of runs not ended yet
+	runs map[string]*fileRun
+}
+
+type fileRun struct {
+	experiment string
+	log        *os.File
+}
+
+// event is a line of a run log, Kind is
+// create, params, metric, artifact or end
+type event struct {
+	Kind   string            `json:"kind"`
+	Run    *Run              `json:"run,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Key    string            `json:"key,omitempty"`
+	Point  *Point            `json:"point,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Status Status            `json:"status,omitempty"`
+	End    *time.Time        `json:"end,omitempty"`
+}
+
+// NewFileBackend returns new pointer of
+// FileBackend storing in directory root
+func NewFileBackend(root string) *FileBackend {
+	return &FileBackend{Root: root, runs: map[string]*fileRun{}}
+}
+
+func (f *FileBackend) dir(experiment, id string) string {
+	return filepath.Join(f.Root, experiment, id)
+}
+
+// CreateRun starts the log of run with a random id
+func (f *FileBackend) CreateRun(run *Run) error {
+	if err := checkName(run.Experiment); err != nil {
+		return err
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dir := f.dir(run.Experiment, id)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(filepath.Join(dir, "events.jsonl"), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	stored := *run
+	stored.ID = id
+	stored.Params, stored.Metrics, stored.Artifacts = nil, nil, nil
+	r := &fileRun{experiment: run.Experiment, log: log}
+	if err = r.append(&event{Kind: "create", Run: &stored}); err != nil {
+		log.Close()
+		return err
+	}
+	if f.runs == nil {
+		f.runs = map[string]*fileRun{}
+	}
+	f.runs[id] = r
+	run.ID = id
+	return nil
+}
+
+// append writes e as a line of the log and syncs it
+func (r *fileRun) append(e *event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err = r.log.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return r.log.Sync()
+}
+
+// run returns the log of run id, runs
+// that ended cannot be written
+func (f *FileBackend) run(id string) (*fileRun, error) {
+	r, ok := f.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("experiments: %w: run %s is not active", ml.ErrNotFound, id)
+	}
+	return r, nil
+}
+
+// LogParams appends params of run id
+func (f *FileBackend) LogParams(id string, params map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, err := f.run(id)
+	if err != nil {
+		return err
+	}
+	return r.append(&event{Kind: "params", Params: params})
+}
+
+// LogMetric appends point of metric key of run id,
+// JSON cannot store NaN or infinities
+func (f *FileBackend) LogMetric(id, key string, point Point) error {
+	if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+		return fmt.Errorf("experiments: metric %s is %v", key, point.Value)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, err := f.run(id)
+	if err != nil {
+		return err
+	}
+	return r.append(&event{Kind: "metric", Key: key, Point: &point})
+}
+
+// LogArtifact writes data as artifact name of run id
+// and appends its name
+func (f *FileBackend) LogArtifact(id, name string, data []byte) error {
+	if strings.Contains(name, "..") || strings.HasPrefix(name, "/") {
+		return fmt.Errorf("experiments: invalid artifact name %q", name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, err := f.run(id)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(f.dir(r.experiment, id), "artifacts", filepath.FromSlash(name))
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(file, data, 0o644); err != nil {
+		return err
+	}
+	return r.append(&event{Kind: "artifact", Name: name})
+}
+
+// EndRun appends status and end time of run id
+// and closes its log
+func (f *FileBackend) EndRun(id string, status Status, end time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, err := f.run(id)
+	if err != nil {
+		return err
+	}
+	if err = r.append(&event{Kind: "end", Status: status, End: &end}); err != nil {
+		return err
+	}
+	delete(f.runs, id)
+	return r.log.Close()
+}
+
+// Runs replays logs of runs of experiment, oldest first.
+// A line cut short by a crash ends its log.
+func (f *FileBackend) Runs(experiment string) ([]*Run, error) {
+	if err := checkName(experiment); err != nil {
+		return nil, err
+	}
+	dirs, err := ioutil.ReadDir(filepath.Join(f.Root, experiment))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		file := filepath.Join(f.Root, experiment, d.Name(), "events.jsonl")
+		data, err := ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r, err := replay(data)
+		if err != nil {
+			return nil, fmt.Errorf("experiments: reading %s: %v", file, err)
+		}
+		runs = append(runs, r)
+	}
+	sortRuns(runs)
+	return runs, nil
+}
+
+// replay returns the run of log data
+func replay(data []byte) (*Run, error) {
+	var r *Run
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<30)
+	for sc.Scan() {
+		var e event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			break
+		}
+		if e.Kind == "create" && e.Run != nil {
+			r = e.Run
+			r.Params = map[string]string{}
+			r.Metrics = map[string][]Point{}
+			continue
+		}
+		if r == nil {
+			return nil, fmt.Errorf("log does not start with the run")
+		}
+		switch e.Kind {
+		case "params":
+			for k, v := range e.Params {
+				r.Params[k] = v
+			}
+		case "metric":
+			if e.Point != nil {
+				r.Metrics[e.Key] = append(r.Metrics[e.Key], *e.Point)
+			}
+		case "artifact":
+			r.Artifacts = append(r.Artifacts, e.Name)
+		case "end":
+			r.Status = e.Status
+			if e.End != nil {
+				r.End = *e.End
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("empty log")
+	}
+	return r, nil
+}
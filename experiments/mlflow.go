@@ -0,0 +1,322 @@
+package experiments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MLflow records runs in an MLflow tracking server with
+// its REST API 2.0. Experiments are created by name when
+// missing. Artifacts are uploaded through the artifact
+// proxy of the server, which needs --serve-artifacts.
+type MLflow struct {
+	// URL of the tracking server, e.g. http://localhost:5000
+	URL string
+	// HTTP defaults to http.DefaultClient
+	HTTP *http.Client
+	// Token, when set, is sent as a bearer token
+	Token string
+
+	mu          sync.Mutex
+	experiments map[string]string
+	// runs maps run ids to experiment ids
+	runs map[string]string
+}
+
+// NewMLflow returns new pointer of MLflow of the
+// tracking server at url
+func NewMLflow(url string) *MLflow {
+	return &MLflow{URL: strings.TrimSuffix(url, "/")}
+}
+
+type mlflowError struct {
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// call sends a request of method to endpoint of the
+// REST API and decodes the response into out
+func (m *MLflow) call(method, endpoint string, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, m.URL+"/api/2.0/mlflow/"+endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return m.do(req, out)
+}
+
+func (m *MLflow) do(req *http.Request, out interface{}) error {
+	if m.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	}
+	client := m.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e mlflowError
+		if json.Unmarshal(data, &e) == nil && e.ErrorCode != "" {
+			return fmt.Errorf("experiments: mlflow %s: %s", e.ErrorCode, e.Message)
+		}
+		return fmt.Errorf("experiments: mlflow %s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// experiment returns id of experiment name,
+// creating it when create is set
+func (m *MLflow) experiment(name string, create bool) (string, error) {
+	m.mu.Lock()
+	id, ok := m.experiments[name]
+	m.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var got struct {
+		Experiment struct {
+			ID string `json:"experiment_id"`
+		} `json:"experiment"`
+	}
+	err := m.call(http.MethodGet, "experiments/get-by-name?experiment_name="+url.QueryEscape(name), nil, &got)
+	switch {
+	case err == nil:
+		id = got.Experiment.ID
+	case create && strings.Contains(err.Error(), "RESOURCE_DOES_NOT_EXIST"):
+		var created struct {
+			ID string `json:"experiment_id"`
+		}
+		if err = m.call(http.MethodPost, "experiments/create", map[string]string{"name": name}, &created); err != nil {
+			return "", err
+		}
+		id = created.ID
+	default:
+		return "", err
+	}
+
+	m.mu.Lock()
+	if m.experiments == nil {
+		m.experiments = map[string]string{}
+	}
+	m.experiments[name] = id
+	m.mu.Unlock()
+	return id, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// CreateRun creates run in its experiment
+func (m *MLflow) CreateRun(run *Run) error {
+	experiment, err := m.experiment(run.Experiment, true)
+	if err != nil {
+		return err
+	}
+
+	var created struct {
+		Run mlflowRun `json:"run"`
+	}
+	req := map[string]interface{}{
+		"experiment_id": experiment,
+		"run_name":      run.Name,
+		"start_time":    millis(run.Start),
+	}
+	if err = m.call(http.MethodPost, "runs/create", req, &created); err != nil {
+		return err
+	}
+
+	run.ID = created.Run.Info.RunID
+	m.mu.Lock()
+	if m.runs == nil {
+		m.runs = map[string]string{}
+	}
+	m.runs[run.ID] = experiment
+	m.mu.Unlock()
+	return nil
+}
+
+// LogParams logs params of run id in one batch
+func (m *MLflow) LogParams(id string, params map[string]string) error {
+	type param struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	batch := struct {
+		RunID  string  `json:"run_id"`
+		Params []param `json:"params"`
+	}{RunID: id}
+	for k, v := range params {
+		batch.Params = append(batch.Params, param{k, v})
+	}
+	return m.call(http.MethodPost, "runs/log-batch", batch, nil)
+}
+
+// LogMetric logs point of metric key of run id
+func (m *MLflow) LogMetric(id, key string, point Point) error {
+	req := map[string]interface{}{
+		"run_id":    id,
+		"key":       key,
+		"value":     mlflowValue(point.Value),
+		"timestamp": millis(point.Time),
+		"step":      point.Step,
+	}
+	return m.call(http.MethodPost, "runs/log-metric", req, nil)
+}
+
+// mlflowValue returns v, or the strings MLflow
+// accepts for NaN and infinities
+func mlflowValue(v float64) interface{} {
+	switch s := strconv.FormatFloat(v, 'g', -1, 64); s {
+	case "NaN", "+Inf", "-Inf":
+		return strings.TrimPrefix(s, "+")
+	}
+	return v
+}
+
+// LogArtifact uploads data as artifact name of run id
+func (m *MLflow) LogArtifact(id, name string, data []byte) error {
+	m.mu.Lock()
+	experiment, ok := m.runs[id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("experiments: run %s was not created by this backend", id)
+	}
+
+	u := m.URL + "/api/2.0/mlflow-artifacts/artifacts/" + path.Join(experiment, id, "artifacts", name)
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return m.do(req, nil)
+}
+
+// EndRun sets status and end time of run id
+func (m *MLflow) EndRun(id string, status Status, end time.Time) error {
+	req := map[string]interface{}{
+		"run_id":   id,
+		"status":   string(status),
+		"end_time": millis(end),
+	}
+	if err := m.call(http.MethodPost, "runs/update", req, nil); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	delete(m.runs, id)
+	m.mu.Unlock()
+	return nil
+}
+
+type mlflowRun struct {
+	Info struct {
+		RunID     string `json:"run_id"`
+		RunName   string `json:"run_name"`
+		Status    string `json:"status"`
+		StartTime int64  `json:"start_time"`
+		EndTime   int64  `json:"end_time"`
+	} `json:"info"`
+	Data struct {
+		Metrics []struct {
+			Key       string  `json:"key"`
+			Value     float64 `json:"value"`
+			Timestamp int64   `json:"timestamp"`
+			Step      int     `json:"step"`
+		} `json:"metrics"`
+		Params []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"params"`
+	} `json:"data"`
+}
+
+// Runs searches runs of experiment. MLflow returns the
+// latest value of every metric only, use the
+// metrics/get-history endpoint for whole histories.
+func (m *MLflow) Runs(experiment string) ([]*Run, error) {
+	id, err := m.experiment(experiment, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*Run
+	token := ""
+	for {
+		req := map[string]interface{}{
+			"experiment_ids": []string{id},
+			"max_results":    1000,
+		}
+		if token != "" {
+			req["page_token"] = token
+		}
+		var resp struct {
+			Runs          []mlflowRun `json:"runs"`
+			NextPageToken string      `json:"next_page_token"`
+		}
+		if err = m.call(http.MethodPost, "runs/search", req, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp.Runs {
+			run := &Run{
+				ID:         r.Info.RunID,
+				Experiment: experiment,
+				Name:       r.Info.RunName,
+				Status:     Status(r.Info.Status),
+				Params:     map[string]string{},
+				Metrics:    map[string][]Point{},
+				Start:      time.Unix(0, r.Info.StartTime*int64(time.Millisecond)).UTC(),
+			}
+			if r.Info.EndTime > 0 {
+				run.End = time.Unix(0, r.Info.EndTime*int64(time.Millisecond)).UTC()
+			}
+			for _, p := range r.Data.Params {
+				run.Params[p.Key] = p.Value
+			}
+			for _, p := range r.Data.Metrics {
+				run.Metrics[p.Key] = append(run.Metrics[p.Key], Point{
+					Step:  p.Step,
+					Value: p.Value,
+					Time:  time.Unix(0, p.Timestamp*int64(time.Millisecond)).UTC(),
+				})
+			}
+			runs = append(runs, run)
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	sortRuns(runs)
+	return runs, nil
+}
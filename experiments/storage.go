@@ -0,0 +1,236 @@
+package experiments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// StorageBackend stores runs as JSON in an ml.Storage,
+// e.g. an ml.FileStorage of a local directory, under
+// <experiment>/<run>/run.json and artifacts under
+// <experiment>/<run>/artifacts/<name>. Every call writes
+// run.json again, which grows with the metrics logged, so
+// use FileBackend for metrics of every iteration on a
+// local disk. Only one StorageBackend should write a
+// Storage at a time.
+type StorageBackend struct {
+	Storage ml.Storage
+
+	mu   sync.Mutex
+	runs map[string]*Run
+}
+
+// NewStorageBackend returns new pointer of
+// StorageBackend storing in storage
+func NewStorageBackend(storage ml.Storage) *StorageBackend {
+	return &StorageBackend{Storage: storage, runs: map[string]*Run{}}
+}
+
+func runKey(experiment, id, file string) string {
+	return path.Join(experiment, id, file)
+}
+
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\") || name == "." || name == ".." {
+		return fmt.Errorf("experiments: invalid name %q", name)
+	}
+	return nil
+}
+
+// newID returns a random run id
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateRun stores run with a random id
+func (s *StorageBackend) CreateRun(run *Run) error {
+	if err := checkName(run.Experiment); err != nil {
+		return err
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.runs == nil {
+		s.runs = map[string]*Run{}
+	}
+	stored := *run
+	stored.ID = id
+	stored.Params = map[string]string{}
+	stored.Metrics = map[string][]Point{}
+	if err = s.put(&stored); err != nil {
+		return err
+	}
+	s.runs[id] = &stored
+	run.ID = id
+	return nil
+}
+
+// run returns active run id
+func (s *StorageBackend) run(id string) (*Run, error) {
+	r, ok := s.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("experiments: %w: run %s is not active", ml.ErrNotFound, id)
+	}
+	return r, nil
+}
+
+// LogParams adds params to run id and stores it
+func (s *StorageBackend) LogParams(id string, params map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.run(id)
+	if err != nil {
+		return err
+	}
+	for k, v := range params {
+		r.Params[k] = v
+	}
+	return s.put(r)
+}
+
+// LogMetric adds point to metric key of run id and
+// stores it, JSON cannot store NaN or infinities
+func (s *StorageBackend) LogMetric(id, key string, point Point) error {
+	if math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+		return fmt.Errorf("experiments: metric %s is %v", key, point.Value)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.run(id)
+	if err != nil {
+		return err
+	}
+	r.Metrics[key] = append(r.Metrics[key], point)
+	if err = s.put(r); err != nil {
+		r.Metrics[key] = r.Metrics[key][:len(r.Metrics[key])-1]
+		return err
+	}
+	return nil
+}
+
+// LogArtifact stores data as artifact name of run id
+func (s *StorageBackend) LogArtifact(id, name string, data []byte) error {
+	if strings.Contains(name, "..") || strings.HasPrefix(name, "/") {
+		return fmt.Errorf("experiments: invalid artifact name %q", name)
+	}
+
+	// the run cannot end while its artifact is written
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.run(id)
+	if err != nil {
+		return err
+	}
+	if err = s.write(runKey(r.Experiment, id, path.Join("artifacts", name)), data); err != nil {
+		return err
+	}
+	r.Artifacts = append(r.Artifacts, name)
+	return s.put(r)
+}
+
+// EndRun stores status of run id and forgets it,
+// later writes to it fail
+func (s *StorageBackend) EndRun(id string, status Status, end time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.run(id)
+	if err != nil {
+		return err
+	}
+	r.Status, r.End = status, end
+	if err = s.put(r); err != nil {
+		return err
+	}
+	delete(s.runs, id)
+	return nil
+}
+
+// Runs reads runs of experiment, oldest first
+func (s *StorageBackend) Runs(experiment string) ([]*Run, error) {
+	if err := checkName(experiment); err != nil {
+		return nil, err
+	}
+	keys, err := s.Storage.List(experiment + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, key := range keys {
+		// artifacts named run.json are deeper
+		if path.Base(key) != "run.json" || strings.Count(key, "/") != 2 {
+			continue
+		}
+		data, err := s.read(key)
+		if err != nil {
+			return nil, err
+		}
+		var r Run
+		if err = json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("experiments: reading %s: %v", key, err)
+		}
+		runs = append(runs, &r)
+	}
+	sortRuns(runs)
+	return runs, nil
+}
+
+func (s *StorageBackend) put(r *Run) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.write(runKey(r.Experiment, r.ID, "run.json"), data)
+}
+
+func (s *StorageBackend) write(key string, data []byte) error {
+	w, err := s.Storage.Create(key)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *StorageBackend) read(key string) ([]byte, error) {
+	rc, err := s.Storage.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
+
+// sortRuns sorts runs by start time, oldest first
+func sortRuns(runs []*Run) {
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Start.Before(runs[j].Start) })
+}
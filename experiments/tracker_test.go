@@ -0,0 +1,112 @@
+package experiments
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// sweep records finished runs of experiment "sweep"
+// with the losses of every run, and a failed run of
+// the lowest loss
+func sweep(t *testing.T, tracker *Tracker, losses ...[]float64) {
+	t.Helper()
+	for i, loss := range losses {
+		run, err := tracker.Start("sweep", "", map[string]string{"run": string(rune('a' + i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range loss {
+			run.Observe("loss", v, nil)
+		}
+		if err = run.End(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failed, err := tracker.Start("sweep", "failed", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = failed.Metric("loss", 0, -1); err != nil {
+		t.Fatal(err)
+	}
+	if err = failed.End(errors.New("diverged")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrackerBest(t *testing.T) {
+	clock := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := New(NewStorageBackend(ml.NewFileStorage(t.TempDir())))
+	tracker.Now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	// Best compares the last loss, not the lowest
+	sweep(t, tracker, []float64{3, 0.1, 2}, []float64{1.5, 0.5}, []float64{4, 1})
+
+	runs, err := tracker.Runs("sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 4 || runs[0].Params["run"] != "a" || runs[3].Status != Failed {
+		t.Fatalf("runs %+v, want 4 oldest first", runs)
+	}
+	loss := runs[0].Metrics["loss"]
+	if len(loss) != 3 || loss[1].Step != 1 || loss[1].Value != 0.1 {
+		t.Errorf("loss %+v, want steps 0 to 2", loss)
+	}
+
+	for _, c := range []struct {
+		higherIsBetter bool
+		want           string
+	}{
+		{false, "bca"},
+		{true, "acb"},
+	} {
+		best, err := tracker.Best("sweep", "loss", c.higherIsBetter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		for _, r := range best {
+			got += r.Params["run"]
+		}
+		if got != c.want {
+			t.Errorf("best of higherIsBetter %v %q, want %q without the failed run", c.higherIsBetter, got, c.want)
+		}
+	}
+
+	if best, err := tracker.Best("sweep", "accuracy", true); err != nil || len(best) != 0 {
+		t.Errorf("best of a metric never logged %v, %v", best, err)
+	}
+}
+
+func TestTrackerErrors(t *testing.T) {
+	tracker := New(NewStorageBackend(ml.NewFileStorage(t.TempDir())))
+	for _, name := range []string{"", "a/b", ".."} {
+		if _, err := tracker.Start(name, "", nil); err == nil {
+			t.Errorf("no error of experiment %q", name)
+		}
+	}
+
+	run, err := tracker.Start("sweep", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = run.Artifact("", nil); err == nil {
+		t.Error("no error of an empty artifact name")
+	}
+	if err = run.Artifact("../model", nil); err == nil {
+		t.Error("no error of an artifact outside the run")
+	}
+	if err = run.End(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = run.End(nil); err == nil {
+		t.Error("no error of ending a run twice")
+	}
+}
@@ -0,0 +1,43 @@
+package ml
+
+// contributions returns theta_j*x_j of every column
+func (l *Linear) contributions(X []float64) []float64 {
+	c := make([]float64, len(X))
+	for j, x := range X {
+		c[j] = l.Theta[j] * x
+	}
+	return c
+}
+
+// Explain returns contribution theta_j*x_j of every
+// column of X, which sum up to Predict(X). It assumes
+// the default linear hypothesis.
+func (l *LinearRegression) Explain(X []float64) []float64 {
+	return l.contributions(X)
+}
+
+// Explain returns contribution of every column of X to
+// the probability of X being true. Log-odds contributions
+// theta_j*x_j are scaled by the link so they sum up to
+// Probability(X)-0.5, the move away from even odds. It
+// assumes the default linear hypothesis.
+func (l *LogisticRegression) Explain(X []float64) []float64 {
+	c := l.contributions(X)
+
+	z := 0.0
+	for _, v := range c {
+		z += v
+	}
+
+	// slope of the sigmoid secant from 0 to z,
+	// which tends to 1/4 as z tends to 0
+	scale := 0.25
+	if z != 0 {
+		scale = (sigmoid(z) - 0.5) / z
+	}
+
+	for j := range c {
+		c[j] *= scale
+	}
+	return c
+}
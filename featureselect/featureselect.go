@@ -0,0 +1,110 @@
+// Package featureselect provides filter methods selecting
+// columns of features before training. Every selector is
+// an ml.Transformer so it can be used as a pipeline step.
+package featureselect
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Selection holds column indices chosen by a selector
+type Selection struct {
+	// Selected are the chosen column indices, ascending
+	Selected []int
+	// Keep are columns always selected regardless of
+	// their score, such as the bias column
+	Keep []int
+}
+
+// Transform returns features keeping only selected columns
+func (s *Selection) Transform(features [][]float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, row := range features {
+		out[i] = make([]float64, len(s.Selected))
+		for k, j := range s.Selected {
+			out[i][k] = row[j]
+		}
+	}
+	return out
+}
+
+// choose selects columns passing ok and keep columns
+func (s *Selection) choose(n int, ok func(j int) bool) {
+	keep := make(map[int]bool, len(s.Keep))
+	for _, j := range s.Keep {
+		keep[j] = true
+	}
+
+	// a new slice, Selected of an earlier fit may be
+	// held by the caller
+	s.Selected = nil
+	for j := 0; j < n; j++ {
+		if keep[j] || ok(j) {
+			s.Selected = append(s.Selected, j)
+		}
+	}
+}
+
+// SelectKBest selects the K columns with highest
+// univariate score
+type SelectKBest struct {
+	Score ScoreFunc
+	K     int
+	// Scores and PValues are computed by Fit.
+	// PValues is nil when Score has none.
+	Scores  []float64
+	PValues []float64
+	Selection
+}
+
+// NewSelectKBest returns new pointer of SelectKBest
+func NewSelectKBest(score ScoreFunc, k int) *SelectKBest {
+	return &SelectKBest{
+		Score: score,
+		K:     k,
+	}
+}
+
+// Fit scores every column and selects the best K
+func (s *SelectKBest) Fit(features [][]float64, output []float64) error {
+	if s.K < 0 {
+		return fmt.Errorf("featureselect: K must not be negative, got %d", s.K)
+	}
+
+	scores, pvalues, err := s.Score(features, output)
+	if err != nil {
+		return err
+	}
+	s.Scores = scores
+	s.PValues = pvalues
+
+	order := make([]int, len(scores))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return score(scores[order[a]]) > score(scores[order[b]])
+	})
+
+	best := make(map[int]bool, s.K)
+	for _, j := range order {
+		if len(best) == s.K {
+			break
+		}
+		best[j] = true
+	}
+
+	s.choose(len(scores), func(j int) bool { return best[j] })
+
+	return nil
+}
+
+// score ranks NaN below every other score
+func score(s float64) float64 {
+	if math.IsNaN(s) {
+		return math.Inf(-1)
+	}
+	return s
+}
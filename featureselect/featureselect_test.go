@@ -0,0 +1,76 @@
+package featureselect
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestChi2(t *testing.T) {
+	// column 0 is counted only in class 0, column 1 is
+	// spread evenly and column 2 is never counted
+	features := [][]float64{{2, 1, 0}, {2, 1, 0}, {0, 1, 0}, {0, 1, 0}}
+	output := []float64{0, 0, 1, 1}
+
+	scores, pvalues, err := Chi2(features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (4-2)²/2 + (0-2)²/2, the p-value of 1 df is erfc(√2)
+	want := []float64{4, 0, 0}
+	wantP := []float64{math.Erfc(math.Sqrt2), 1, 1}
+	for j := range want {
+		if math.Abs(scores[j]-want[j]) > 1e-12 || math.Abs(pvalues[j]-wantP[j]) > 1e-12 {
+			t.Errorf("column %d: score %v and p-value %v, want %v and %v", j, scores[j], pvalues[j], want[j], wantP[j])
+		}
+	}
+
+	if _, _, err = Chi2([][]float64{{-1}, {1}}, []float64{0, 1}); err == nil {
+		t.Error("no error of a negative feature")
+	}
+}
+
+func TestSelectKBest(t *testing.T) {
+	features := [][]float64{{2, 1, 0}, {2, 1, 0}, {0, 1, 0}, {0, 1, 0}}
+	output := []float64{0, 0, 1, 1}
+
+	s := NewSelectKBest(Chi2, 1)
+	s.Keep = []int{2}
+	if err := s.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	first := s.Selected
+	if !reflect.DeepEqual(first, []int{0, 2}) {
+		t.Fatalf("selected %v, want [0 2]", first)
+	}
+	if got := s.Transform(features[:1]); !reflect.DeepEqual(got, [][]float64{{2, 0}}) {
+		t.Errorf("transformed %v, want [[2 0]]", got)
+	}
+
+	// a later fit does not overwrite the columns
+	// selected before
+	s.K, s.Keep = 2, nil
+	if err := s.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, []int{0, 2}) {
+		t.Errorf("earlier selection changed to %v", first)
+	}
+
+	s.K = -1
+	if err := s.Fit(features, output); err == nil {
+		t.Error("no error of negative K")
+	}
+}
+
+func TestVarianceThreshold(t *testing.T) {
+	features := [][]float64{{1, 0, 5}, {1, 1, 7}, {1, 0, 9}}
+	v := NewVarianceThreshold(0.5)
+	if err := v.Fit(features, nil); err != nil {
+		t.Fatal(err)
+	}
+	// sample variances are 0, 1/3 and 4
+	if !reflect.DeepEqual(v.Selected, []int{2}) {
+		t.Errorf("selected %v of variances %v, want [2]", v.Selected, v.Variances)
+	}
+}
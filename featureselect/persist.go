@@ -0,0 +1,10 @@
+package featureselect
+
+import "github.com/maxrafiandy/ml"
+
+// selectors are registered so they can be saved as
+// pipeline steps, their Score func is not saved
+func init() {
+	ml.RegisterModel("featureselect.SelectKBest", &SelectKBest{})
+	ml.RegisterModel("featureselect.VarianceThreshold", &VarianceThreshold{})
+}
@@ -0,0 +1,260 @@
+package featureselect
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ScoreFunc scores every column of features against
+// output, higher is better. pvalues may be nil.
+type ScoreFunc func(features [][]float64, output []float64) (scores, pvalues []float64, err error)
+
+// Chi2 returns chi-squared statistic of every column
+// against class output. Features must be non-negative,
+// such as counts or frequencies.
+func Chi2(features [][]float64, output []float64) ([]float64, []float64, error) {
+	if err := validate(features, output); err != nil {
+		return nil, nil, err
+	}
+
+	classes := classIndex(output)
+	n := len(features[0])
+	observed := make([][]float64, len(classes))
+	for c := range observed {
+		observed[c] = make([]float64, n)
+	}
+	counts := make([]float64, len(classes))
+	totals := make([]float64, n)
+
+	for i, row := range features {
+		c := classes[output[i]]
+		counts[c]++
+		for j, x := range row {
+			if x < 0 {
+				return nil, nil, fmt.Errorf("featureselect: chi2 requires non-negative features, got %v at row %d column %d", x, i, j)
+			}
+			observed[c][j] += x
+			totals[j] += x
+		}
+	}
+
+	m := float64(len(features))
+	scores := make([]float64, n)
+	pvalues := make([]float64, n)
+	df := float64(len(classes) - 1)
+	for j := range scores {
+		// a column of zeros is independent of
+		// every class
+		if totals[j] == 0 {
+			pvalues[j] = 1
+			continue
+		}
+		for c := range observed {
+			expected := counts[c] / m * totals[j]
+			d := observed[c][j] - expected
+			scores[j] += d * d / expected
+		}
+		pvalues[j] = survival(distuv.ChiSquared{K: df}, scores[j])
+	}
+
+	return scores, pvalues, nil
+}
+
+// FClassif returns ANOVA F statistic of every
+// column between the classes of output
+func FClassif(features [][]float64, output []float64) ([]float64, []float64, error) {
+	if err := validate(features, output); err != nil {
+		return nil, nil, err
+	}
+
+	classes := classIndex(output)
+	k := len(classes)
+	m := len(features)
+	if k < 2 || m <= k {
+		return nil, nil, fmt.Errorf("featureselect: F test needs at least 2 classes and more rows than classes")
+	}
+
+	n := len(features[0])
+	scores := make([]float64, n)
+	pvalues := make([]float64, n)
+	f := distuv.F{D1: float64(k - 1), D2: float64(m - k)}
+
+	for j := range scores {
+		sums := make([]float64, k)
+		counts := make([]float64, k)
+		mean := 0.0
+		for i, row := range features {
+			c := classes[output[i]]
+			sums[c] += row[j]
+			counts[c]++
+			mean += row[j]
+		}
+		mean /= float64(m)
+
+		between, within := 0.0, 0.0
+		for c := range sums {
+			d := sums[c]/counts[c] - mean
+			between += counts[c] * d * d
+		}
+		for i, row := range features {
+			c := classes[output[i]]
+			d := row[j] - sums[c]/counts[c]
+			within += d * d
+		}
+
+		scores[j] = (between / f.D1) / (within / f.D2)
+		pvalues[j] = fSurvival(f, scores[j])
+	}
+
+	return scores, pvalues, nil
+}
+
+// FRegression returns F statistic of the univariate
+// linear regression of output on every column
+func FRegression(features [][]float64, output []float64) ([]float64, []float64, error) {
+	if err := validate(features, output); err != nil {
+		return nil, nil, err
+	}
+
+	m := len(features)
+	if m < 3 {
+		return nil, nil, fmt.Errorf("featureselect: F test needs at least 3 rows")
+	}
+
+	n := len(features[0])
+	scores := make([]float64, n)
+	pvalues := make([]float64, n)
+	f := distuv.F{D1: 1, D2: float64(m - 2)}
+	col := make([]float64, m)
+
+	for j := range scores {
+		for i, row := range features {
+			col[i] = row[j]
+		}
+		r := stat.Correlation(col, output, nil)
+		scores[j] = r * r / (1 - r*r) * f.D2
+		pvalues[j] = fSurvival(f, scores[j])
+	}
+
+	return scores, pvalues, nil
+}
+
+// MutualInfo returns mutual information in nats between
+// every column and class output, discretizing columns
+// into 10 equal frequency bins
+func MutualInfo(features [][]float64, output []float64) ([]float64, []float64, error) {
+	return MutualInfoBins(10)(features, output)
+}
+
+// MutualInfoBins returns a mutual information ScoreFunc
+// discretizing columns into the given number of bins
+func MutualInfoBins(bins int) ScoreFunc {
+	return func(features [][]float64, output []float64) ([]float64, []float64, error) {
+		if err := validate(features, output); err != nil {
+			return nil, nil, err
+		}
+		if bins < 2 {
+			return nil, nil, fmt.Errorf("featureselect: need at least 2 bins, got %d", bins)
+		}
+
+		classes := classIndex(output)
+		m := float64(len(features))
+		n := len(features[0])
+		scores := make([]float64, n)
+		col := make([]float64, len(features))
+
+		for j := range scores {
+			for i, row := range features {
+				col[i] = row[j]
+			}
+			binOf := discretize(col, bins)
+
+			joint := make(map[[2]int]float64)
+			px := make(map[int]float64)
+			py := make(map[int]float64)
+			for i := range col {
+				b, c := binOf[i], classes[output[i]]
+				joint[[2]int{b, c}]++
+				px[b]++
+				py[c]++
+			}
+
+			for key, count := range joint {
+				scores[j] += count / m * math.Log(count*m/(px[key[0]]*py[key[1]]))
+			}
+		}
+
+		return scores, nil, nil
+	}
+}
+
+// discretize assigns every value to one of
+// bins equal frequency bins
+func discretize(x []float64, bins int) []int {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+
+	edges := make([]float64, bins-1)
+	for k := range edges {
+		edges[k] = stat.Quantile(float64(k+1)/float64(bins), stat.Empirical, sorted, nil)
+	}
+
+	out := make([]int, len(x))
+	for i, v := range x {
+		out[i] = sort.SearchFloat64s(edges, v)
+	}
+	return out
+}
+
+// classIndex maps every distinct output value
+// to a class index
+func classIndex(output []float64) map[float64]int {
+	classes := make(map[float64]int)
+	for _, y := range output {
+		if _, ok := classes[y]; !ok {
+			classes[y] = len(classes)
+		}
+	}
+	return classes
+}
+
+func validate(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("featureselect: cannot score empty features")
+	}
+	if len(features) != len(output) {
+		return fmt.Errorf("featureselect: got %d rows of features and %d outputs", len(features), len(output))
+	}
+	return nil
+}
+
+// fSurvival returns upper tail of the F distribution
+// through the regularized incomplete beta function,
+// which keeps precision for very small p-values
+func fSurvival(f distuv.F, x float64) float64 {
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+	if x <= 0 {
+		return 1
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	return mathext.RegIncBeta(f.D2/2, f.D1/2, f.D2/(f.D2+f.D1*x))
+}
+
+func survival(d interface{ Survival(float64) float64 }, x float64) float64 {
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	return d.Survival(x)
+}
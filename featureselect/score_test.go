@@ -0,0 +1,54 @@
+package featureselect
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestFClassif(t *testing.T) {
+	// class means 2 and 5 around 3.5 give a between sum of
+	// squares of 13.5 over 1 df, within 4 over 4 df
+	features := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+	output := []float64{0, 0, 0, 1, 1, 1}
+	scores, pvalues, err := FClassif(features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 13.5
+	if math.Abs(scores[0]-want) > 1e-12 || math.Abs(pvalues[0]-(distuv.F{D1: 1, D2: 4}).Survival(want)) > 1e-12 {
+		t.Errorf("F %v of p-value %v, want %v", scores[0], pvalues[0], want)
+	}
+}
+
+func TestFRegression(t *testing.T) {
+	// r² of 0.6 over 3 residual df
+	features := [][]float64{{1}, {2}, {3}, {4}, {5}}
+	output := []float64{2, 4, 5, 4, 5}
+	scores, pvalues, err := FRegression(features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0.6 / 0.4 * 3
+	if math.Abs(scores[0]-want) > 1e-9 || math.Abs(pvalues[0]-(distuv.F{D1: 1, D2: 3}).Survival(want)) > 1e-9 {
+		t.Errorf("F %v of p-value %v, want %v", scores[0], pvalues[0], want)
+	}
+	if _, _, err = FRegression(features[:2], output[:2]); err == nil {
+		t.Error("no error of 2 rows")
+	}
+}
+
+func TestMutualInfo(t *testing.T) {
+	// a column splitting balanced classes has their
+	// entropy log 2, an independent one none
+	features := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	output := []float64{0, 0, 1, 1}
+	scores, _, err := MutualInfoBins(2)(features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(scores[0]-math.Log(2)) > 1e-12 || math.Abs(scores[1]) > 1e-12 {
+		t.Errorf("mutual information %v, want [log 2, 0]", scores)
+	}
+}
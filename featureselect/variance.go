@@ -0,0 +1,46 @@
+package featureselect
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// VarianceThreshold selects columns whose sample
+// variance is greater than Threshold
+type VarianceThreshold struct {
+	Threshold float64
+	// Variances are computed by Fit
+	Variances []float64
+	Selection
+}
+
+// NewVarianceThreshold returns new pointer of
+// VarianceThreshold. A zero threshold removes
+// constant columns.
+func NewVarianceThreshold(threshold float64) *VarianceThreshold {
+	return &VarianceThreshold{
+		Threshold: threshold,
+	}
+}
+
+// Fit computes variance of every column, output is ignored
+func (v *VarianceThreshold) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("featureselect: cannot fit empty features")
+	}
+
+	n := len(features[0])
+	v.Variances = make([]float64, n)
+	col := make([]float64, len(features))
+	for j := 0; j < n; j++ {
+		for i, row := range features {
+			col[i] = row[j]
+		}
+		v.Variances[j] = stat.Variance(col, nil)
+	}
+
+	v.choose(n, func(j int) bool { return v.Variances[j] > v.Threshold })
+
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package federated trains linear models by federated
+// averaging (FedAvg). Clients train locally on private
+// data and send back only the change of their thetas; a
+// Server averages the changes weighted by number of
+// samples into the global thetas.
+package federated
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/maxrafiandy/ml"
+	"github.com/maxrafiandy/ml/distributed"
+)
+
+// Delta is the change of thetas of a client
+// after local training
+type Delta struct {
+	Delta []float64 `json:"delta"`
+	// Samples trained on, the weight of Delta
+	Samples int `json:"samples"`
+	// Loss is mean local training loss
+	Loss float64 `json:"loss"`
+}
+
+// Client trains locally from global thetas.
+// Implementations must be safe for concurrent use.
+type Client interface {
+	Train(ctx context.Context, round int, theta []float64) (*Delta, error)
+}
+
+// LocalClient is a Client training a model of NewModel
+// on Source with SGD, as in a ParameterAveraging round of
+// its LocalWorker. Source never leaves the client.
+type LocalClient struct {
+	distributed.LocalWorker
+}
+
+// NewLocalClient returns new pointer of LocalClient
+// training one epoch with step size 0.01 every round
+func NewLocalClient(newModel func() ml.SGDModel, source ml.RowSource) *LocalClient {
+	c := &LocalClient{}
+	c.NewModel, c.Source = newModel, source
+	c.Epochs, c.Step = 1, 0.01
+	return c
+}
+
+// Train trains from theta and returns the change of thetas
+func (c *LocalClient) Train(ctx context.Context, round int, theta []float64) (*Delta, error) {
+	u, err := c.Round(ctx, &distributed.Request{Round: round, Mode: distributed.ParameterAveraging, Theta: theta})
+	if err != nil {
+		return nil, err
+	}
+
+	delta := append([]float64(nil), u.Theta...)
+	for j := range delta {
+		delta[j] -= theta[j]
+	}
+	return &Delta{Delta: delta, Samples: u.Rows, Loss: u.Loss}, nil
+}
+
+// Aggregate returns the mean of deltas weighted by
+// samples and their weighted mean loss
+func Aggregate(deltas []*Delta) ([]float64, float64, error) {
+	if len(deltas) == 0 {
+		return nil, 0, fmt.Errorf("federated: no deltas")
+	}
+
+	values := make([][]float64, len(deltas))
+	samples := make([]int, len(deltas))
+	losses := make([]float64, len(deltas))
+	for i, d := range deltas {
+		values[i], samples[i], losses[i] = d.Delta, d.Samples, d.Loss
+	}
+	mean, loss, err := distributed.Average(values, samples, losses, len(deltas[0].Delta))
+	if err != nil {
+		return nil, 0, fmt.Errorf("federated: %v", err)
+	}
+	return mean, loss, nil
+}
+
+// Server runs FedAvg rounds over Clients. Every round
+// a random Fraction of clients trains from the global
+// thetas, which then move by ServerStep times the
+// aggregated delta. Clients failing a round, e.g. devices
+// going offline, are left out as long as MinClients
+// succeed.
+type Server struct {
+	Clients []Client
+	// Fraction of clients of every round, at least one
+	Fraction float64
+	// MinClients is the least number of clients
+	// that must succeed in a round
+	MinClients int
+	// Rounds is number of rounds
+	Rounds int
+	// ServerStep scales aggregated deltas,
+	// 1 is plain FedAvg
+	ServerStep float64
+	// Rand samples clients, nil uses the global source
+	Rand *rand.Rand
+	// Logger receives progress of rounds, nil is silent
+	Logger ml.Logger
+
+	// Theta are the global thetas
+	Theta []float64
+	// Losses is mean local loss of every round
+	Losses []float64
+}
+
+// NewServer returns new pointer of Server running
+// 50 rounds of plain FedAvg with every client
+func NewServer(clients []Client) *Server {
+	return &Server{
+		Clients:    clients,
+		Fraction:   1,
+		MinClients: 1,
+		Rounds:     50,
+		ServerStep: 1,
+	}
+}
+
+// Train runs Rounds rounds starting from theta
+// and returns the global thetas
+func (s *Server) Train(ctx context.Context, theta []float64) ([]float64, error) {
+	if len(s.Clients) == 0 {
+		return nil, fmt.Errorf("federated: no clients")
+	}
+
+	r := ml.RandOf(s.Rand)
+	per := int(math.Ceil(s.Fraction * float64(len(s.Clients))))
+	per = min(max(per, 1), len(s.Clients))
+
+	s.Theta = append([]float64(nil), theta...)
+	s.Losses = s.Losses[:0]
+	for round := 1; round <= s.Rounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var clients []Client
+		for _, i := range r.Perm(len(s.Clients))[:per] {
+			clients = append(clients, s.Clients[i])
+		}
+
+		deltas, failed := s.round(ctx, round, clients)
+		if len(deltas) < max(s.MinClients, 1) {
+			return nil, fmt.Errorf("federated: round %d: %d of %d clients succeeded, need %d", round, len(deltas), len(clients), s.MinClients)
+		}
+
+		mean, loss, err := Aggregate(deltas)
+		if err != nil {
+			return nil, fmt.Errorf("federated: round %d: %v", round, err)
+		}
+		for j := range s.Theta {
+			s.Theta[j] += s.ServerStep * mean[j]
+		}
+		s.Losses = append(s.Losses, loss)
+		if s.Logger != nil {
+			s.Logger.Info("federated: round finished", "round", round, "clients", len(deltas), "failed", failed, "loss", loss)
+		}
+	}
+
+	return s.Theta, nil
+}
+
+// round trains clients concurrently, returning deltas
+// of clients that succeeded and number of failures
+func (s *Server) round(ctx context.Context, round int, clients []Client) ([]*Delta, int) {
+	deltas := make([]*Delta, len(clients))
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c Client) {
+			defer wg.Done()
+			d, err := c.Train(ctx, round, s.Theta)
+			if err != nil {
+				if s.Logger != nil {
+					s.Logger.Warn("federated: client failed", "round", round, "err", err)
+				}
+				return
+			}
+			if len(d.Delta) != len(s.Theta) {
+				if s.Logger != nil {
+					s.Logger.Warn("federated: client sent wrong delta", "round", round, "values", len(d.Delta))
+				}
+				return
+			}
+			deltas[i] = d
+		}(i, c)
+	}
+	wg.Wait()
+
+	var ok []*Delta
+	for _, d := range deltas {
+		if d != nil {
+			ok = append(ok, d)
+		}
+	}
+	return ok, len(clients) - len(ok)
+}
@@ -0,0 +1,80 @@
+package federated
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+	"github.com/maxrafiandy/ml/distributed"
+)
+
+func shard(n int, offset float64) ml.Slices {
+	s := ml.Slices{}
+	for i := 0; i < n; i++ {
+		x := offset + float64(i)/float64(n)
+		s.Features = append(s.Features, []float64{1, x})
+		s.Output = append(s.Output, 1+2*x)
+	}
+	return s
+}
+
+func TestAggregate(t *testing.T) {
+	mean, loss, err := Aggregate([]*Delta{
+		{Delta: []float64{1, 2}, Samples: 1, Loss: 4},
+		{Delta: []float64{4, -1}, Samples: 2, Loss: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mean[0] != 3 || mean[1] != 0 || loss != 2 {
+		t.Errorf("got %v and loss %v, want [3 0] and 2", mean, loss)
+	}
+	if _, _, err := Aggregate([]*Delta{{Delta: []float64{1}}, {Delta: []float64{1, 2}, Samples: 1}}); err == nil {
+		t.Error("expected an error for deltas of different widths")
+	}
+}
+
+// FedAvg of every client with ServerStep 1 is
+// parameter averaging of distributed
+func TestFedAvgIsParameterAveraging(t *testing.T) {
+	newModel := func() ml.SGDModel { return ml.NewLinearRegression() }
+	shards := []ml.Slices{shard(30, 0), shard(10, 1), shard(20, -1)}
+
+	var (
+		clients []Client
+		workers []distributed.Worker
+	)
+	for _, s := range shards {
+		c := NewLocalClient(newModel, s)
+		w := distributed.NewLocalWorker(newModel, s)
+		// full batches make SGD independent of the shuffle
+		c.BatchSize, w.BatchSize = len(s.Output), len(s.Output)
+		c.Step, w.Step = 0.1, 0.1
+		clients = append(clients, c)
+		workers = append(workers, w)
+	}
+
+	server := NewServer(clients)
+	server.Rounds = 5
+	fed, err := server.Train(context.Background(), []float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coordinator := distributed.NewCoordinator(workers)
+	coordinator.Rounds, coordinator.Tol = 5, 0
+	dist, err := coordinator.Train(context.Background(), []float64{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range fed {
+		if math.Abs(fed[j]-dist[j]) > 1e-9 {
+			t.Fatalf("FedAvg %v, parameter averaging %v", fed, dist)
+		}
+	}
+	if server.Losses[4] >= server.Losses[0] {
+		t.Errorf("loss did not fall: %v", server.Losses)
+	}
+}
@@ -0,0 +1,142 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Float is a floating point type of features. Models
+// keep float64 thetas whatever the type of features.
+type Float interface {
+	~float32 | ~float64
+}
+
+// dotOf returns θ·x summed in float64, with
+// floats.Dot for float64 x
+func dotOf[T Float](x []T, theta []float64) float64 {
+	if x64, ok := any(x).([]float64); ok {
+		return floats.Dot(x64, theta[:len(x64)])
+	}
+	sum := 0.0
+	for k, v := range x {
+		sum += theta[k] * float64(v)
+	}
+	return sum
+}
+
+// Float64s returns x converted to float64
+func Float64s[T Float](x []T) []float64 {
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// Float32s returns x converted to float32
+func Float32s[T Float](x []T) []float32 {
+	out := make([]float32, len(x))
+	for i, v := range x {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+// checkRows checks features and output have the same
+// number of rows of the same width, which it returns
+func checkRows[T Float](features [][]T, output []T) (int, error) {
+	if len(features) == 0 {
+		return 0, fmt.Errorf("ml: cannot fit empty features")
+	}
+	if len(features) != len(output) {
+		return 0, fmt.Errorf("ml: got %d rows of features and %d outputs", len(features), len(output))
+	}
+
+	n := len(features[0])
+	for i, x := range features {
+		if len(x) != n {
+			return 0, fmt.Errorf("ml: row %d has %d columns, expected %d", i, len(x), n)
+		}
+	}
+	return n, nil
+}
+
+// nonFinite counts NaN and infinite values
+func nonFinite[T Float](features [][]T, output []T) int {
+	bad := 0
+	for _, x := range features {
+		for _, v := range x {
+			if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+				bad++
+			}
+		}
+	}
+	for _, v := range output {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			bad++
+		}
+	}
+	return bad
+}
+
+// addScaled adds a*x to dst, with floats.AddScaled
+// for float64 x
+func addScaled[T Float](dst []float64, a float64, x []T) {
+	if x64, ok := any(x).([]float64); ok {
+		floats.AddScaled(dst[:len(x64)], a, x64)
+		return
+	}
+	for j, v := range x {
+		dst[j] += a * float64(v)
+	}
+}
+
+// squaredLoss returns Func of LinearRegression
+// with the default hypothesis
+func squaredLoss[T Float](features [][]T, output []T, theta []float64) float64 {
+	sum := 0.0
+	for i, x := range features {
+		d := dotOf(x, theta) - float64(output[i])
+		sum += d * d
+	}
+	return sum / (2 * float64(len(features)))
+}
+
+// squaredGrad sets grad to Grad of LinearRegression
+// with the default hypothesis
+func squaredGrad[T Float](grad []float64, features [][]T, output []T, theta []float64, rate float64) {
+	for j := range grad {
+		grad[j] = 0
+	}
+	for i, x := range features {
+		d := dotOf(x, theta) - float64(output[i])
+		addScaled(grad, d, x)
+	}
+	floats.Scale(rate/float64(len(features)), grad)
+}
+
+// logisticLoss returns Func of LogisticRegression
+// with the default hypothesis
+func logisticLoss[T Float](features [][]T, output []T, theta []float64) float64 {
+	sum := 0.0
+	for i, x := range features {
+		z := dotOf(x, theta)
+		sum += math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z))) - float64(output[i])*z
+	}
+	return sum / float64(len(features))
+}
+
+// logisticGrad sets grad to Grad of LogisticRegression
+// with the default hypothesis
+func logisticGrad[T Float](grad []float64, features [][]T, output []T, theta []float64, rate float64) {
+	for j := range grad {
+		grad[j] = 0
+	}
+	for i, x := range features {
+		d := sigmoid(dotOf(x, theta)) - float64(output[i])
+		addScaled(grad, d, x)
+	}
+	floats.Scale(rate/float64(len(features)), grad)
+}
@@ -0,0 +1,83 @@
+package ml
+
+import "context"
+
+// Fit32 is Fit on float32 features, which are kept as
+// float32 during training to halve their memory. Thetas
+// stay float64. It needs the default hypothesis.
+func (l *LinearRegression) Fit32(features [][]float32, output []float32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.prepare32(features, output); err != nil {
+		return err
+	}
+
+	_, err := l.minimize(context.Background(), l.problem(), l.Setting)
+	return err
+}
+
+// Estimate32 returns predicted value of float32 X
+func (l *LinearRegression) Estimate32(X []float32) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.hypothesis32(X)
+}
+
+// PredictBatch32 returns predicted value of
+// every float32 row of X
+func (l *LinearRegression) PredictBatch32(X [][]float32) []float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]float64, len(X))
+	for i, x := range X {
+		out[i] = l.hypothesis32(x)
+	}
+	return out
+}
+
+// Fit32 is Fit on float32 features, which are kept as
+// float32 during training to halve their memory. Thetas
+// stay float64. It needs the default hypothesis.
+func (l *LogisticRegression) Fit32(features [][]float32, output []float32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.prepare32(features, output); err != nil {
+		return err
+	}
+
+	_, err := l.minimize(context.Background(), l.problem(), l.Setting)
+	return err
+}
+
+// Estimate32 returns probability of float32 X being true
+func (l *LogisticRegression) Estimate32(X []float32) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sigmoid(l.hypothesis32(X))
+}
+
+// PredictBatch32 returns probability of every
+// float32 row of X being true
+func (l *LogisticRegression) PredictBatch32(X [][]float32) []float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]float64, len(X))
+	for i, x := range X {
+		out[i] = sigmoid(l.hypothesis32(x))
+	}
+	return out
+}
+
+// hypothesis32 returns the hypothesis of float32 X,
+// converting X for a custom hypothesis
+func (l *Linear) hypothesis32(X []float32) float64 {
+	if l.IsLinear() {
+		return dotOf(X, l.Theta)
+	}
+	row := getRow(len(X))
+	defer putRow(row)
+	for i, v := range X {
+		(*row)[i] = float64(v)
+	}
+	return l.Hypothesis(*row, l.Theta)
+}
@@ -0,0 +1,15 @@
+package ml
+
+import "testing"
+
+func BenchmarkEstimate32CustomHypothesis(b *testing.B) {
+	features, _, theta := benchData(1, 50)
+	x := Float32s(features[0])
+	l := NewLinearRegression()
+	l.Theta, l.Hypothesis = theta, squaredHypothesis
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Estimate32(x)
+	}
+}
@@ -0,0 +1,86 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// loopDot is the scalar loop floats.Dot replaced
+func loopDot(x, theta []float64) float64 {
+	sum := 0.0
+	for k, v := range x {
+		sum += theta[k] * v
+	}
+	return sum
+}
+
+func TestDotOf(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	x, theta := make([]float64, 37), make([]float64, 40)
+	for i := range theta {
+		theta[i] = r.NormFloat64()
+	}
+	for i := range x {
+		x[i] = r.NormFloat64()
+	}
+	want := loopDot(x, theta)
+	if got := dotOf(x, theta); math.Abs(got-want) > 1e-12 {
+		t.Errorf("dotOf float64 = %v, want %v", got, want)
+	}
+	if got := dotOf(Float32s(x), theta); math.Abs(got-want) > 1e-5 {
+		t.Errorf("dotOf float32 = %v, want %v", got, want)
+	}
+}
+
+// BenchmarkDot reports bytes of features read per second,
+// and dot products per second, of floats.Dot and of the
+// scalar loop on feature vectors of growing width
+func BenchmarkDot(b *testing.B) {
+	for _, width := range []int{10, 100, 1000, 10000} {
+		features, _, theta := benchData(200000/width, width)
+		for _, kernel := range []struct {
+			name string
+			dot  func(x, theta []float64) float64
+		}{
+			{"floats", linearHypothesis},
+			{"loop", loopDot},
+		} {
+			b.Run(fmt.Sprintf("width=%d/%s", width, kernel.name), func(b *testing.B) {
+				b.SetBytes(int64(8 * width * len(features)))
+				b.ReportAllocs()
+				sum := 0.0
+				for i := 0; i < b.N; i++ {
+					for _, x := range features {
+						sum += kernel.dot(x, theta)
+					}
+				}
+				b.ReportMetric(float64(b.N*len(features))/b.Elapsed().Seconds(), "dots/s")
+				if sum == 0 {
+					b.Fatal("no products")
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGradThroughput reports bytes of features read
+// per second by Grad, which adds residuals with
+// floats.AddScaled
+func BenchmarkGradThroughput(b *testing.B) {
+	for _, width := range []int{10, 100, 1000} {
+		features, output, theta := benchData(200000/width, width)
+		l := NewLinearRegression()
+		l.Features, l.Output = features, output
+		grad := make([]float64, width)
+		b.Run(fmt.Sprintf("width=%d", width), func(b *testing.B) {
+			b.SetBytes(int64(8 * width * len(features)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Grad(grad, theta)
+			}
+			b.ReportMetric(float64(b.N*len(features))/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Order of an ARIMA model: autoregressive terms P,
+// differences D and moving average terms Q
+type Order struct {
+	P int
+	D int
+	Q int
+}
+
+// ARIMA is a seasonal ARIMA(p,d,q)(P,D,Q)s model estimated
+// by conditional sum of squares. It is an ml.LikelihoodModel
+// so ml.AIC and ml.BIC compare fits.
+type ARIMA struct {
+	Order    Order
+	Seasonal Order
+	// Period of the season, ignored without
+	// seasonal terms
+	Period int
+	// Constant estimates the mean of the
+	// differenced series
+	Constant bool
+	// Condition is the least number of differenced
+	// steps used only as lagged values. Fits of every
+	// order with the same Condition sum the same errors,
+	// so their likelihoods compare.
+	Condition int
+
+	// Coefficients after Fit
+	AR         []float64
+	MA         []float64
+	SeasonalAR []float64
+	SeasonalMA []float64
+	Mean       float64
+	// Sigma2 is the innovation variance and Residuals
+	// the one-step-ahead errors of the differenced
+	// series, zero for conditioning steps
+	Sigma2    float64
+	Residuals []float64
+
+	series []float64
+	css    float64
+	used   int
+}
+
+// NewARIMA returns new pointer of ARIMA(p,d,q)
+// with a constant when d is 0
+func NewARIMA(p, d, q int) *ARIMA {
+	return &ARIMA{
+		Order:    Order{P: p, D: d, Q: q},
+		Constant: d == 0,
+	}
+}
+
+// NewSARIMA returns new pointer of seasonal ARIMA
+// without constant
+func NewSARIMA(order, seasonal Order, period int) *ARIMA {
+	return &ARIMA{
+		Order:    order,
+		Seasonal: seasonal,
+		Period:   period,
+	}
+}
+
+// NewAR returns new pointer of AR(p) with a constant
+func NewAR(p int) *ARIMA {
+	return NewARIMA(p, 0, 0)
+}
+
+// NewMA returns new pointer of MA(q) with a constant
+func NewMA(q int) *ARIMA {
+	return NewARIMA(0, 0, q)
+}
+
+// Fit estimates coefficients minimizing the conditional
+// sum of squared one-step-ahead errors
+func (a *ARIMA) Fit(series []float64) error {
+	if a.seasonal() && a.Period < 2 {
+		return fmt.Errorf("forecast: seasonal ARIMA needs a period of at least 2, got %d", a.Period)
+	}
+
+	w := a.difference(series)
+	if len(w) <= a.conditioning()+a.numCoefficients() {
+		return fmt.Errorf("forecast: series of %d observations is too short for %v%v", len(series), a.Order, a.Seasonal)
+	}
+
+	init := make([]float64, a.numCoefficients())
+	if a.Constant {
+		init[len(init)-1] = mean(w)
+	}
+
+	if len(init) > 0 {
+		prob := optimize.Problem{
+			Func: func(x []float64) float64 {
+				a.unpack(x)
+				return a.sumSquares(w, nil)
+			},
+		}
+
+		result, err := optimize.Minimize(prob, init, nil, &optimize.NelderMead{})
+		if err != nil {
+			return fmt.Errorf("forecast: %v", err)
+		}
+		init = result.X
+	}
+
+	a.unpack(init)
+	a.Residuals = make([]float64, len(w))
+	a.css = a.sumSquares(w, a.Residuals)
+	a.used = len(w) - a.conditioning()
+	a.series = append([]float64(nil), series...)
+
+	df := a.used - len(init)
+	if df < 1 {
+		df = 1
+	}
+	a.Sigma2 = a.css / float64(df)
+
+	if math.IsInf(a.css, 1) {
+		return fmt.Errorf("forecast: ARIMA estimation diverged")
+	}
+	return nil
+}
+
+// Forecast returns h steps ahead forecasts with
+// prediction intervals at level, such as 0.95
+func (a *ARIMA) Forecast(h int, level float64) *Prediction {
+	ar := a.integratedAR()
+	ma := a.polyMA()
+	offset := len(a.series) - len(a.Residuals)
+
+	// constant of the undifferenced recursion
+	c := 0.0
+	if a.Constant {
+		c = a.Mean
+		for _, phi := range a.polyAR() {
+			c -= phi * a.Mean
+		}
+	}
+
+	y := append([]float64(nil), a.series...)
+	e := make([]float64, len(y)+h)
+	for t, r := range a.Residuals {
+		e[t+offset] = r
+	}
+
+	mean := make([]float64, h)
+	for k := 0; k < h; k++ {
+		t := len(y)
+		v := c
+		for i, phi := range ar {
+			if t-i-1 >= 0 {
+				v += phi * y[t-i-1]
+			}
+		}
+		for i, theta := range ma {
+			if t-i-1 >= 0 {
+				v += theta * e[t-i-1]
+			}
+		}
+		y = append(y, v)
+		mean[k] = v
+	}
+
+	// psi weights of the MA(infinity) representation
+	psi := make([]float64, h)
+	variance := make([]float64, h)
+	sum := 0.0
+	for j := 0; j < h; j++ {
+		if j == 0 {
+			psi[j] = 1
+		} else {
+			if j-1 < len(ma) {
+				psi[j] = ma[j-1]
+			}
+			for i, phi := range ar {
+				if j-i-1 >= 0 {
+					psi[j] += phi * psi[j-i-1]
+				}
+			}
+		}
+		sum += psi[j] * psi[j]
+		variance[j] = a.Sigma2 * sum
+	}
+
+	return newPrediction(mean, variance, level)
+}
+
+// LogLikelihood returns conditional Gaussian log-likelihood
+func (a *ARIMA) LogLikelihood() float64 {
+	n := float64(a.used)
+	return -n / 2 * (math.Log(2*math.Pi*a.css/n) + 1)
+}
+
+// NumParams returns the number of coefficients plus
+// the innovation variance
+func (a *ARIMA) NumParams() int {
+	return a.numCoefficients() + 1
+}
+
+// NumSamples returns the number of errors in the
+// conditional sum of squares
+func (a *ARIMA) NumSamples() int {
+	return a.used
+}
+
+// LjungBox tests whether residuals are white noise up to
+// lags lags. A small p-value means the model leaves
+// autocorrelation unexplained.
+func (a *ARIMA) LjungBox(lags int) *ml.StatTest {
+	r := a.Residuals[a.conditioning():]
+	test := LjungBox(r, lags)
+
+	df := float64(lags - a.numCoefficients())
+	if a.Constant {
+		df++
+	}
+	if df < 1 {
+		df = 1
+	}
+	test.DF = df
+	test.PValue = distuv.ChiSquared{K: df}.Survival(test.Statistic)
+
+	return test
+}
+
+// LjungBox tests whether x is white noise up to lags lags
+func LjungBox(x []float64, lags int) *ml.StatTest {
+	n := float64(len(x))
+	m := mean(x)
+
+	var c0 float64
+	for _, v := range x {
+		c0 += (v - m) * (v - m)
+	}
+
+	q := 0.0
+	for k := 1; k <= lags && k < len(x); k++ {
+		ck := 0.0
+		for t := k; t < len(x); t++ {
+			ck += (x[t] - m) * (x[t-k] - m)
+		}
+		r := ck / c0
+		q += r * r / (n - float64(k))
+	}
+	q *= n * (n + 2)
+
+	return &ml.StatTest{
+		Statistic: q,
+		DF:        float64(lags),
+		PValue:    distuv.ChiSquared{K: float64(lags)}.Survival(q),
+	}
+}
+
+// SelectOrder fits template with every p up to maxP and q
+// up to maxQ and returns the fit with the lowest AIC.
+// Differences, seasonal terms and constant are taken
+// from template. Every order conditions on the first
+// steps of the largest one, so all are fitted on the
+// same errors.
+func SelectOrder(series []float64, template ARIMA, maxP, maxQ int) (*ARIMA, error) {
+	var best *ARIMA
+	bestAIC := math.Inf(1)
+
+	largest := template
+	largest.Order.P = maxP
+	condition := largest.conditioning()
+
+	for p := 0; p <= maxP; p++ {
+		for q := 0; q <= maxQ; q++ {
+			a := &ARIMA{
+				Order:     Order{P: p, D: template.Order.D, Q: q},
+				Seasonal:  template.Seasonal,
+				Period:    template.Period,
+				Constant:  template.Constant,
+				Condition: condition,
+			}
+			if err := a.Fit(series); err != nil {
+				continue
+			}
+			if aic := ml.AIC(a); aic < bestAIC {
+				best, bestAIC = a, aic
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("forecast: no ARIMA order could be fitted")
+	}
+	return best, nil
+}
+
+// sumSquares returns conditional sum of squared errors of
+// differenced series w, storing errors in residuals if set
+func (a *ARIMA) sumSquares(w, residuals []float64) float64 {
+	ar := a.polyAR()
+	ma := a.polyMA()
+	mu := 0.0
+	if a.Constant {
+		mu = a.Mean
+	}
+
+	e := residuals
+	if e == nil {
+		e = make([]float64, len(w))
+	}
+
+	sse := 0.0
+	for t := a.conditioning(); t < len(w); t++ {
+		v := w[t] - mu
+		for i, phi := range ar {
+			v -= phi * (w[t-i-1] - mu)
+		}
+		for i, theta := range ma {
+			if t-i-1 >= 0 {
+				v -= theta * e[t-i-1]
+			}
+		}
+		e[t] = v
+		sse += v * v
+	}
+
+	if math.IsNaN(sse) || math.IsInf(sse, 0) {
+		return math.Inf(1)
+	}
+	return sse
+}
+
+// difference returns series differenced D times at the
+// seasonal lag and d times at lag one
+func (a *ARIMA) difference(series []float64) []float64 {
+	w := append([]float64(nil), series...)
+	if a.seasonal() {
+		for k := 0; k < a.Seasonal.D; k++ {
+			w = diff(w, a.Period)
+		}
+	}
+	for k := 0; k < a.Order.D; k++ {
+		w = diff(w, 1)
+	}
+	return w
+}
+
+// integratedAR returns autoregressive coefficients of the
+// undifferenced series, the product of the AR polynomials
+// and the differencing polynomials
+func (a *ARIMA) integratedAR() []float64 {
+	// polynomials as coefficients of B^0, B^1, ...
+	poly := []float64{1}
+	mul := func(q []float64) {
+		out := make([]float64, len(poly)+len(q)-1)
+		for i, x := range poly {
+			for j, y := range q {
+				out[i+j] += x * y
+			}
+		}
+		poly = out
+	}
+
+	mul(lagPoly(a.AR, 1, -1))
+	if a.seasonal() {
+		mul(lagPoly(a.SeasonalAR, a.Period, -1))
+		for k := 0; k < a.Seasonal.D; k++ {
+			mul(lagPoly([]float64{1}, a.Period, -1))
+		}
+	}
+	for k := 0; k < a.Order.D; k++ {
+		mul([]float64{1, -1})
+	}
+
+	ar := make([]float64, len(poly)-1)
+	for i := range ar {
+		ar[i] = -poly[i+1]
+	}
+	return ar
+}
+
+func (a *ARIMA) polyAR() []float64 {
+	return polyMul(a.AR, a.SeasonalAR, a.Period, -1)
+}
+
+func (a *ARIMA) polyMA() []float64 {
+	return polyMul(a.MA, a.SeasonalMA, a.Period, 1)
+}
+
+// conditioning returns number of first differenced
+// steps used only as lagged values
+func (a *ARIMA) conditioning() int {
+	n := a.Order.P
+	if a.seasonal() {
+		n += a.Seasonal.P * a.Period
+	}
+	if a.Condition > n {
+		return a.Condition
+	}
+	return n
+}
+
+func (a *ARIMA) seasonal() bool {
+	return a.Seasonal.P > 0 || a.Seasonal.D > 0 || a.Seasonal.Q > 0
+}
+
+func (a *ARIMA) numCoefficients() int {
+	k := a.Order.P + a.Order.Q
+	if a.seasonal() {
+		k += a.Seasonal.P + a.Seasonal.Q
+	}
+	if a.Constant {
+		k++
+	}
+	return k
+}
+
+// unpack sets coefficients from optimizer parameters
+func (a *ARIMA) unpack(x []float64) {
+	take := func(n int) []float64 {
+		out := append([]float64(nil), x[:n]...)
+		x = x[n:]
+		return out
+	}
+
+	a.AR = take(a.Order.P)
+	a.MA = take(a.Order.Q)
+	a.SeasonalAR, a.SeasonalMA = nil, nil
+	if a.seasonal() {
+		a.SeasonalAR = take(a.Seasonal.P)
+		a.SeasonalMA = take(a.Seasonal.Q)
+	}
+	if a.Constant {
+		a.Mean = x[0]
+	}
+}
+
+// polyMul returns lag coefficients of the product of a
+// regular and a seasonal lag polynomial. sign is -1 for
+// autoregressive polynomials 1-sum(c_i*B^i), whose cross
+// terms are negated, and 1 for moving average ones.
+func polyMul(c, s []float64, period int, sign float64) []float64 {
+	n := len(c)
+	if len(s) > 0 {
+		n += len(s) * period
+	}
+	if n == 0 {
+		return nil
+	}
+
+	out := make([]float64, n)
+	copy(out, c)
+	for k, sv := range s {
+		lag := (k + 1) * period
+		out[lag-1] += sv
+		for i, cv := range c {
+			out[lag+i] += sign * sv * cv
+		}
+	}
+	return out
+}
+
+// lagPoly returns 1+sign*sum(c_i*B^(i*step))
+// as coefficients of B^0, B^1, ...
+func lagPoly(c []float64, step int, sign float64) []float64 {
+	poly := make([]float64, len(c)*step+1)
+	poly[0] = 1
+	for i, v := range c {
+		poly[(i+1)*step] = sign * v
+	}
+	return poly
+}
+
+func diff(x []float64, lag int) []float64 {
+	if len(x) <= lag {
+		return nil
+	}
+	out := make([]float64, len(x)-lag)
+	for t := range out {
+		out[t] = x[t+lag] - x[t]
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package forecast
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// simulate returns n steps of an ARMA process with
+// coefficients ar and ma, mean mu and unit innovations
+func simulate(n int, ar, ma []float64, mu float64, seed int64) []float64 {
+	r := rand.New(rand.NewSource(seed))
+	burn := 200
+	y := make([]float64, n+burn)
+	e := make([]float64, n+burn)
+	for t := range y {
+		e[t] = r.NormFloat64()
+		v := e[t]
+		for i, phi := range ar {
+			if t-i-1 >= 0 {
+				v += phi * y[t-i-1]
+			}
+		}
+		for i, theta := range ma {
+			if t-i-1 >= 0 {
+				v += theta * e[t-i-1]
+			}
+		}
+		y[t] = v
+	}
+	for t := range y {
+		y[t] += mu
+	}
+	return y[burn:]
+}
+
+func near(t *testing.T, name string, got, want []float64, tol float64) {
+	t.Helper()
+	for i := range want {
+		if len(got) != len(want) || math.Abs(got[i]-want[i]) > tol {
+			t.Errorf("%s %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestARIMAEstimatesCoefficients(t *testing.T) {
+	series := simulate(3000, []float64{0.6, -0.3}, nil, 5, 1)
+	ar := NewAR(2)
+	if err := ar.Fit(series); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "AR(2) coefficients", ar.AR, []float64{0.6, -0.3}, 0.05)
+	near(t, "AR(2) mean and variance", []float64{ar.Mean, ar.Sigma2}, []float64{5, 1}, 0.1)
+
+	series = simulate(3000, nil, []float64{0.5}, 0, 2)
+	ma := NewMA(1)
+	if err := ma.Fit(series); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "MA(1) coefficients", ma.MA, []float64{0.5}, 0.05)
+
+	// a random walk differences to white noise
+	walk := make([]float64, 1000)
+	for i, v := range simulate(1000, nil, nil, 0, 3) {
+		walk[i] = v
+		if i > 0 {
+			walk[i] += walk[i-1]
+		}
+	}
+	arima := NewARIMA(1, 1, 0)
+	if err := arima.Fit(walk); err != nil {
+		t.Fatal(err)
+	}
+	near(t, "ARIMA(1,1,0) of a random walk", arima.AR, []float64{0}, 0.1)
+}
+
+func TestARIMAForecast(t *testing.T) {
+	series := simulate(500, []float64{0.7}, nil, 2, 4)
+	a := NewAR(1)
+	if err := a.Fit(series); err != nil {
+		t.Fatal(err)
+	}
+
+	// AR(1) forecasts decay to the mean by φ every step,
+	// with variance σ²(1+φ²+...) of the horizon
+	phi, mu, last := a.AR[0], a.Mean, series[len(series)-1]
+	p := a.Forecast(3, 0.95)
+	want := []float64{
+		mu + phi*(last-mu),
+		mu + phi*phi*(last-mu),
+		mu + phi*phi*phi*(last-mu),
+	}
+	near(t, "forecasts", p.Mean, want, 1e-9)
+
+	half := 1.959963984540054 * math.Sqrt(a.Sigma2*(1+phi*phi))
+	near(t, "second interval", []float64{p.Lower[1], p.Upper[1]}, []float64{want[1] - half, want[1] + half}, 1e-9)
+}
+
+func TestSelectOrderSameErrors(t *testing.T) {
+	series := simulate(800, []float64{0.6, -0.3}, nil, 0, 5)
+	best, err := SelectOrder(series, ARIMA{Constant: true}, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every order sums the errors after the 3 lags
+	// of the largest
+	if best.NumSamples() != len(series)-3 {
+		t.Errorf("%d errors, want %d of every order", best.NumSamples(), len(series)-3)
+	}
+	if best.Order.P != 2 || best.Order.Q != 0 {
+		t.Errorf("selected %+v, want AR(2)", best.Order)
+	}
+}
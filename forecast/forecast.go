@@ -0,0 +1,45 @@
+// Package forecast provides univariate time series
+// forecasting models.
+package forecast
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Prediction holds a multi-step forecast with
+// its prediction interval
+type Prediction struct {
+	Mean  []float64
+	Lower []float64
+	Upper []float64
+}
+
+// newPrediction returns a prediction of mean with
+// normal intervals from the forecast variances
+func newPrediction(mean, variance []float64, level float64) *Prediction {
+	z := distuv.UnitNormal.Quantile(0.5 + level/2)
+	p := &Prediction{
+		Mean:  mean,
+		Lower: make([]float64, len(mean)),
+		Upper: make([]float64, len(mean)),
+	}
+	for h := range mean {
+		d := z * math.Sqrt(variance[h])
+		p.Lower[h] = mean[h] - d
+		p.Upper[h] = mean[h] + d
+	}
+	return p
+}
+
+// logistic maps the real line to (0, 1), it lets
+// unconstrained optimizers search smoothing parameters
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func logit(p float64) float64 {
+	p = math.Min(math.Max(p, 1e-6), 1-1e-6)
+	return math.Log(p / (1 - p))
+}
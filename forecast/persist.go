@@ -0,0 +1,76 @@
+package forecast
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func init() {
+	ml.RegisterModel("forecast.ARIMA", &ARIMA{})
+	ml.RegisterModel("forecast.ExponentialSmoothing", &ExponentialSmoothing{})
+}
+
+// arimaFields and smoothingFields have the exported
+// fields of the models without their gob methods
+type arimaFields ARIMA
+type smoothingFields ExponentialSmoothing
+
+// arimaState is the saved state of ARIMA, including
+// the fitted series needed to forecast
+type arimaState struct {
+	Model  *arimaFields
+	Series []float64
+	CSS    float64
+	Used   int
+}
+
+type smoothingState struct {
+	Model *smoothingFields
+	Steps int
+	Start int
+}
+
+// GobEncode saves the fitted model
+func (a *ARIMA) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(arimaState{
+		Model:  (*arimaFields)(a),
+		Series: a.series,
+		CSS:    a.css,
+		Used:   a.used,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode restores a saved ARIMA
+func (a *ARIMA) GobDecode(data []byte) error {
+	s := arimaState{Model: (*arimaFields)(a)}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	a.series, a.css, a.used = s.Series, s.CSS, s.Used
+	return nil
+}
+
+// GobEncode saves the fitted model
+func (e *ExponentialSmoothing) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(smoothingState{
+		Model: (*smoothingFields)(e),
+		Steps: e.steps,
+		Start: e.start,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode restores a saved ExponentialSmoothing
+func (e *ExponentialSmoothing) GobDecode(data []byte) error {
+	s := smoothingState{Model: (*smoothingFields)(e)}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	e.steps, e.start = s.Steps, s.Start
+	return nil
+}
@@ -0,0 +1,306 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Seasonality of exponential smoothing
+type Seasonality int
+
+const (
+	// NoSeason disables the seasonal component
+	NoSeason Seasonality = iota
+	// Additive season adds to the level
+	Additive
+	// Multiplicative season scales the level,
+	// the series must be positive
+	Multiplicative
+)
+
+// ExponentialSmoothing is simple, double (Holt, with trend)
+// or triple (Holt-Winters, with trend and season)
+// exponential smoothing
+type ExponentialSmoothing struct {
+	Trend    bool
+	Seasonal Seasonality
+	Period   int
+	// Alpha, Beta and Gamma smooth level, trend and
+	// season. Fit optimizes them unless Fixed is set.
+	Alpha float64
+	Beta  float64
+	Gamma float64
+	Fixed bool
+
+	// Level, Slope and Season are the final
+	// components after Fit
+	Level  float64
+	Slope  float64
+	Season []float64
+	// Residuals are one-step-ahead errors, zero
+	// for initialization steps, and Sigma2 their
+	// variance
+	Residuals []float64
+	Sigma2    float64
+
+	steps int
+	start int
+}
+
+// NewSES returns new pointer of simple
+// exponential smoothing
+func NewSES() *ExponentialSmoothing {
+	return &ExponentialSmoothing{Alpha: 0.5}
+}
+
+// NewHolt returns new pointer of double exponential
+// smoothing with a linear trend
+func NewHolt() *ExponentialSmoothing {
+	return &ExponentialSmoothing{
+		Trend: true,
+		Alpha: 0.5,
+		Beta:  0.1,
+	}
+}
+
+// NewHoltWinters returns new pointer of triple exponential
+// smoothing with a trend and a season of period steps
+func NewHoltWinters(period int, seasonal Seasonality) *ExponentialSmoothing {
+	return &ExponentialSmoothing{
+		Trend:    true,
+		Seasonal: seasonal,
+		Period:   period,
+		Alpha:    0.5,
+		Beta:     0.1,
+		Gamma:    0.1,
+	}
+}
+
+// Fit optimizes smoothing parameters minimizing the sum
+// of squared one-step-ahead errors, then smooths series
+func (e *ExponentialSmoothing) Fit(series []float64) error {
+	min := 2
+	if e.Seasonal != NoSeason {
+		if e.Period < 2 {
+			return fmt.Errorf("forecast: seasonal smoothing needs a period of at least 2, got %d", e.Period)
+		}
+		min = 2 * e.Period
+	}
+	if len(series) < min {
+		return fmt.Errorf("forecast: need at least %d observations, got %d", min, len(series))
+	}
+	if e.Seasonal == Multiplicative {
+		for _, y := range series {
+			if y <= 0 {
+				return fmt.Errorf("forecast: multiplicative season needs a positive series")
+			}
+		}
+	}
+
+	if !e.Fixed {
+		if err := e.optimize(series); err != nil {
+			return err
+		}
+	}
+
+	sse := e.smooth(series, e.Alpha, e.Beta, e.Gamma, true)
+	df := len(series) - e.start - e.numParams()
+	if df < 1 {
+		df = 1
+	}
+	e.Sigma2 = sse / float64(df)
+
+	return nil
+}
+
+// optimize searches parameters in (0, 1) on the logit scale
+func (e *ExponentialSmoothing) optimize(series []float64) error {
+	init := []float64{logit(e.Alpha)}
+	if e.Trend {
+		init = append(init, logit(e.Beta))
+	}
+	if e.Seasonal != NoSeason {
+		init = append(init, logit(e.Gamma))
+	}
+
+	params := func(x []float64) (alpha, beta, gamma float64) {
+		alpha = logistic(x[0])
+		k := 1
+		if e.Trend {
+			beta = logistic(x[k])
+			k++
+		}
+		if e.Seasonal != NoSeason {
+			gamma = logistic(x[k])
+		}
+		return alpha, beta, gamma
+	}
+
+	prob := optimize.Problem{
+		Func: func(x []float64) float64 {
+			alpha, beta, gamma := params(x)
+			return e.smooth(series, alpha, beta, gamma, false)
+		},
+	}
+
+	result, err := optimize.Minimize(prob, init, nil, &optimize.NelderMead{})
+	if err != nil {
+		return fmt.Errorf("forecast: %v", err)
+	}
+
+	e.Alpha, e.Beta, e.Gamma = params(result.X)
+	return nil
+}
+
+// smooth runs the recursions and returns the sum of squared
+// one-step-ahead errors, storing components when keep is set.
+// Components are initialized from the first steps, which
+// the recursions then skip: the first season and its
+// trend for seasonal models, the first step otherwise.
+func (e *ExponentialSmoothing) smooth(series []float64, alpha, beta, gamma float64, keep bool) float64 {
+	m := e.Period
+	level := series[0]
+	slope := 0.0
+	start := 1
+	var season []float64
+
+	switch {
+	case e.Seasonal != NoSeason:
+		first, second := mean(series[:m]), mean(series[m:2*m])
+		if e.Trend {
+			slope = (second - first) / float64(m)
+		}
+		// the first season mean sits in its middle step
+		level = first + slope*float64(m-1)/2
+		season = make([]float64, m)
+		for i := range season {
+			trend := first + slope*(float64(i)-float64(m-1)/2)
+			if e.Seasonal == Additive {
+				season[i] = series[i] - trend
+			} else {
+				season[i] = series[i] / trend
+			}
+		}
+		start = m
+	case e.Trend:
+		slope = series[1] - series[0]
+	}
+
+	var residuals []float64
+	if keep {
+		residuals = make([]float64, len(series))
+	}
+
+	sse := 0.0
+	for t := start; t < len(series); t++ {
+		y := series[t]
+
+		var s, fitted float64
+		switch e.Seasonal {
+		case Additive:
+			s = season[t%m]
+			fitted = level + slope + s
+		case Multiplicative:
+			s = season[t%m]
+			fitted = (level + slope) * s
+		default:
+			fitted = level + slope
+		}
+
+		err := y - fitted
+		sse += err * err
+		if keep {
+			residuals[t] = err
+		}
+
+		prev := level
+		switch e.Seasonal {
+		case Multiplicative:
+			level = alpha*y/s + (1-alpha)*(prev+slope)
+		default:
+			level = alpha*(y-s) + (1-alpha)*(prev+slope)
+		}
+		if e.Trend {
+			slope = beta*(level-prev) + (1-beta)*slope
+		}
+		switch e.Seasonal {
+		case Additive:
+			season[t%m] = gamma*(y-level) + (1-gamma)*s
+		case Multiplicative:
+			season[t%m] = gamma*y/level + (1-gamma)*s
+		}
+	}
+
+	if keep {
+		e.Level = level
+		e.Slope = slope
+		e.Season = season
+		e.Residuals = residuals
+		e.steps = len(series)
+		e.start = start
+	}
+
+	if math.IsNaN(sse) {
+		return math.Inf(1)
+	}
+	return sse
+}
+
+// Forecast returns h steps ahead forecasts with prediction
+// intervals at level, such as 0.95. Interval widths use
+// the additive error model variances, an approximation
+// for multiplicative seasons.
+func (e *ExponentialSmoothing) Forecast(h int, level float64) *Prediction {
+	mean := make([]float64, h)
+	variance := make([]float64, h)
+	m := e.Period
+
+	sum := 0.0
+	for k := 1; k <= h; k++ {
+		point := e.Level + float64(k)*e.Slope
+		if e.Seasonal != NoSeason {
+			s := e.Season[(e.steps+k-1)%m]
+			if e.Seasonal == Additive {
+				point += s
+			} else {
+				point *= s
+			}
+		}
+		mean[k-1] = point
+
+		variance[k-1] = e.Sigma2 * (1 + sum)
+
+		// error weight of the next step
+		c := e.Alpha
+		if e.Trend {
+			c += float64(k) * e.Alpha * e.Beta
+		}
+		if e.Seasonal != NoSeason && k%m == 0 {
+			c += e.Gamma * (1 - e.Alpha)
+		}
+		sum += c * c
+	}
+
+	return newPrediction(mean, variance, level)
+}
+
+func (e *ExponentialSmoothing) numParams() int {
+	k := 2
+	if e.Trend {
+		k += 2
+	}
+	if e.Seasonal != NoSeason {
+		k += e.Period
+	}
+	return k
+}
+
+func mean(x []float64) float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
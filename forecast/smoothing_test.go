@@ -0,0 +1,66 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSESForecast(t *testing.T) {
+	// levels 1, 1.5 and 2.25 leave errors 1 and 1.5
+	s := NewSES()
+	s.Fixed = true
+	if err := s.Fit([]float64{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Level != 2.25 || s.Sigma2 != 3.25 {
+		t.Errorf("level %v and variance %v, want 2.25 and 3.25", s.Level, s.Sigma2)
+	}
+
+	// every step adds α² of the error variance
+	p := s.Forecast(2, 0.95)
+	half := 1.959963984540054 * math.Sqrt(3.25*1.25)
+	if p.Mean[0] != 2.25 || p.Mean[1] != 2.25 || math.Abs(p.Upper[1]-2.25-half) > 1e-9 {
+		t.Errorf("forecasts %v up to %v, want 2.25 up to %v", p.Mean, p.Upper, 2.25+half)
+	}
+}
+
+func TestSmoothingOfExactSeries(t *testing.T) {
+	// a line, and a line with an additive season
+	// of period 4
+	season := []float64{2, -1, 0, -1}
+	line := func(t int) float64 { return 10 + 0.5*float64(t) }
+	cases := []struct {
+		name  string
+		model *ExponentialSmoothing
+		at    func(t int) float64
+	}{
+		{"Holt", NewHolt(), line},
+		{"additive Holt-Winters", NewHoltWinters(4, Additive), func(t int) float64 { return line(t) + season[t%4] }},
+	}
+	for _, c := range cases {
+		series := make([]float64, 24)
+		for i := range series {
+			series[i] = c.at(i)
+		}
+		c.model.Fixed = true
+		if err := c.model.Fit(series); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, r := range c.model.Residuals {
+			if math.Abs(r) > 1e-9 {
+				t.Fatalf("%s: residuals %v of an exact series", c.name, c.model.Residuals)
+			}
+		}
+		p := c.model.Forecast(6, 0.9)
+		for h, v := range p.Mean {
+			if want := c.at(24 + h); math.Abs(v-want) > 1e-9 {
+				t.Errorf("%s: forecast %v, want %v", c.name, p.Mean, want)
+				break
+			}
+		}
+	}
+
+	if err := NewHoltWinters(4, Multiplicative).Fit([]float64{1, 2, 0, 1, 2, 3, 4, 5}); err == nil {
+		t.Error("no error of a multiplicative season of a zero")
+	}
+}
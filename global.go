@@ -0,0 +1,471 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// GeneticAlgorithm is a real-coded genetic algorithm, an
+// optimize.Method needing neither gradients nor convexity.
+// Every generation keeps Elite best individuals and breeds
+// the rest from parents picked by tournaments, with blend
+// crossover and gaussian mutation. Individuals of a
+// generation are evaluated concurrently when
+// optimize.Settings allow it.
+type GeneticAlgorithm struct {
+	// Population defaults to 20, or 10 per dimension
+	// when larger
+	Population int
+	// Elite individuals survive unchanged, defaults to 2
+	Elite int
+	// Tournament is the number of individuals competing
+	// to be a parent, defaults to 3
+	Tournament int
+	// CrossoverRate defaults to 0.9
+	CrossoverRate float64
+	// MutationRate is the probability of mutating
+	// every gene, defaults to 1/dimension
+	MutationRate float64
+	// Sigma scales the first population around the
+	// start point and mutations, defaults to 1
+	Sigma float64
+	// Lower and Upper, when set, bound every gene
+	Lower []float64
+	Upper []float64
+	Rand  *rand.Rand
+
+	dim int
+	r   *rand.Rand
+}
+
+// Uses returns no need of gradients
+func (g *GeneticAlgorithm) Uses(has optimize.Available) (optimize.Available, error) {
+	return optimize.Available{}, nil
+}
+
+// Init initializes the population, it returns
+// the number of concurrent evaluations
+func (g *GeneticAlgorithm) Init(dim, tasks int) int {
+	g.dim = dim
+	g.r = RandOf(g.Rand)
+	return min(tasks, g.population())
+}
+
+func (g *GeneticAlgorithm) population() int {
+	if g.Population > 0 {
+		return g.Population
+	}
+	return max(20, 10*g.dim)
+}
+
+// Status never converges, the optimizer
+// settings stop the algorithm
+func (g *GeneticAlgorithm) Status() (optimize.Status, error) {
+	return optimize.NotTerminated, nil
+}
+
+// Run evaluates generations until the optimizer stops it
+func (g *GeneticAlgorithm) Run(operations chan<- optimize.Task, results <-chan optimize.Task, tasks []optimize.Task) {
+	n := g.population()
+	elite := g.Elite
+	if elite == 0 {
+		elite = 2
+	}
+	elite = min(elite, n)
+	tournament := g.Tournament
+	if tournament == 0 {
+		tournament = 3
+	}
+	crossover := g.CrossoverRate
+	if crossover == 0 {
+		crossover = 0.9
+	}
+	mutation := g.MutationRate
+	if mutation == 0 {
+		mutation = 1 / float64(g.dim)
+	}
+	sigma := g.Sigma
+	if sigma == 0 {
+		sigma = 1
+	}
+
+	p := newPopulation(n, g.dim)
+	x0 := tasks[0].X
+	for i, x := range p.xs {
+		for j := range x {
+			x[j] = x0[j]
+			if i > 0 {
+				x[j] += sigma * g.r.NormFloat64()
+			}
+		}
+		clamp(x, g.Lower, g.Upper)
+	}
+
+	p.run(operations, results, tasks, func() (bool, bool) {
+		order := p.order()
+		next := make([][]float64, n)
+		for k := 0; k < elite; k++ {
+			next[k] = append([]float64(nil), p.xs[order[k]]...)
+		}
+
+		pick := func() []float64 {
+			best := -1
+			for t := 0; t < tournament; t++ {
+				i := g.r.Intn(n)
+				if best < 0 || p.fs[i] < p.fs[best] || math.IsNaN(p.fs[best]) {
+					best = i
+				}
+			}
+			return p.xs[best]
+		}
+		for k := elite; k < n; k++ {
+			a, b := pick(), pick()
+			child := make([]float64, g.dim)
+			for j := range child {
+				child[j] = a[j]
+				if g.r.Float64() < crossover {
+					// BLX-0.5 blend crossover
+					lo, hi := math.Min(a[j], b[j]), math.Max(a[j], b[j])
+					d := hi - lo
+					child[j] = lo - 0.5*d + g.r.Float64()*2*d
+				}
+				if g.r.Float64() < mutation {
+					child[j] += sigma * g.r.NormFloat64()
+				}
+			}
+			clamp(child, g.Lower, g.Upper)
+			next[k] = child
+		}
+		p.xs = next
+		return true, true
+	})
+}
+
+// SimulatedAnnealing is an optimize.Method needing neither
+// gradients nor convexity. It moves to a gaussian step away
+// from the current point when it is better, or with
+// probability exp(-Δf/T) when it is worse, so it escapes
+// local minima while the temperature T is high. T is
+// multiplied by Cooling every Moves steps and the method
+// converges when T falls below MinTemperature.
+type SimulatedAnnealing struct {
+	// Temperature is the initial temperature,
+	// defaults to 1
+	Temperature float64
+	// Cooling defaults to 0.95
+	Cooling float64
+	// Moves per temperature, defaults to 10 per dimension
+	Moves int
+	// MinTemperature defaults to 1e-8 of Temperature
+	MinTemperature float64
+	// Step scales moves, defaults to 1. It shrinks with
+	// the square root of the temperature.
+	Step float64
+	// Lower and Upper, when set, bound every coordinate
+	Lower []float64
+	Upper []float64
+	Rand  *rand.Rand
+
+	dim       int
+	r         *rand.Rand
+	converged bool
+}
+
+// Uses returns no need of gradients
+func (s *SimulatedAnnealing) Uses(has optimize.Available) (optimize.Available, error) {
+	return optimize.Available{}, nil
+}
+
+// Init initializes the annealing, moves are evaluated
+// one at a time
+func (s *SimulatedAnnealing) Init(dim, tasks int) int {
+	s.dim = dim
+	s.r = RandOf(s.Rand)
+	s.converged = false
+	return 1
+}
+
+// Status returns MethodConverge once cold
+func (s *SimulatedAnnealing) Status() (optimize.Status, error) {
+	if s.converged {
+		return optimize.MethodConverge, nil
+	}
+	return optimize.NotTerminated, nil
+}
+
+// Run anneals until cold or stopped by the optimizer
+func (s *SimulatedAnnealing) Run(operations chan<- optimize.Task, results <-chan optimize.Task, tasks []optimize.Task) {
+	t0 := s.Temperature
+	if t0 == 0 {
+		t0 = 1
+	}
+	cooling := s.Cooling
+	if cooling == 0 {
+		cooling = 0.95
+	}
+	moves := s.Moves
+	if moves == 0 {
+		moves = 10 * s.dim
+	}
+	cold := s.MinTemperature
+	if cold == 0 {
+		cold = 1e-8 * t0
+	}
+	step := s.Step
+	if step == 0 {
+		step = 1
+	}
+
+	p := newPopulation(1, s.dim)
+	copy(p.xs[0], tasks[0].X)
+	clamp(p.xs[0], s.Lower, s.Upper)
+
+	var (
+		current  []float64
+		currentF = math.NaN()
+		t        = t0
+		move     = 0
+	)
+	p.run(operations, results, tasks, func() (bool, bool) {
+		x, f := p.xs[0], p.fs[0]
+		if current == nil || f <= currentF || math.IsNaN(currentF) ||
+			s.r.Float64() < math.Exp(-(f-currentF)/t) {
+			current, currentF = append([]float64(nil), x...), f
+		}
+
+		// a major iteration per temperature
+		move++
+		major := move%moves == 0
+		if major {
+			t *= cooling
+			if t < cold {
+				s.converged = true
+				return false, true
+			}
+		}
+
+		scale := step * math.Sqrt(t/t0)
+		next := make([]float64, s.dim)
+		for j := range next {
+			next[j] = current[j] + scale*s.r.NormFloat64()
+		}
+		clamp(next, s.Lower, s.Upper)
+		p.xs[0] = next
+		return true, major
+	})
+}
+
+// population holds points of a generation and runs the
+// reverse communication of optimize.Method: every point
+// is sent for evaluation, then the best point so far is
+// sent as a MajorIteration and the next generation starts
+type population struct {
+	xs    [][]float64
+	fs    []float64
+	bestX []float64
+	bestF float64
+}
+
+func newPopulation(n, dim int) *population {
+	p := &population{
+		xs:    make([][]float64, n),
+		fs:    make([]float64, n),
+		bestX: make([]float64, dim),
+		bestF: math.Inf(1),
+	}
+	for i := range p.xs {
+		p.xs[i] = make([]float64, dim)
+	}
+	return p
+}
+
+// order returns indices of xs, best first
+func (p *population) order() []int {
+	idx := make([]int, len(p.fs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		x, y := p.fs[idx[a]], p.fs[idx[b]]
+		return x < y || (!math.IsNaN(x) && math.IsNaN(y))
+	})
+	return idx
+}
+
+// record keeps f of point i when it is the best so far
+func (p *population) record(i int, f float64) bool {
+	p.fs[i] = f
+	if f < p.bestF {
+		p.bestF = f
+		copy(p.bestX, p.xs[i])
+		return true
+	}
+	return false
+}
+
+// run evaluates generations, calling next after every
+// one to replace xs, until next returns false or the
+// optimizer stops. A MajorIteration follows generations
+// for which next returns major.
+func (p *population) run(operations chan<- optimize.Task, results <-chan optimize.Task, tasks []optimize.Task, next func() (more, major bool)) {
+	n := len(p.xs)
+	sent, received := 0, 0
+	send := func(task optimize.Task) {
+		task.ID = sent
+		task.Op = optimize.FuncEvaluation
+		copy(task.X, p.xs[sent])
+		sent++
+		operations <- task
+	}
+	generation := func() {
+		sent, received = 0, 0
+		for i := range p.fs {
+			p.fs[i] = math.NaN()
+		}
+		for _, task := range tasks {
+			if sent < n {
+				send(task)
+			}
+		}
+	}
+
+	generation()
+	improved := false
+loop:
+	for result := range results {
+		switch result.Op {
+		case optimize.PostIteration:
+			break loop
+		case optimize.MajorIteration:
+			generation()
+		case optimize.FuncEvaluation:
+			received++
+			if p.record(result.ID, result.F) {
+				improved = true
+			}
+			if sent < n {
+				send(result)
+				continue
+			}
+			if received < n {
+				continue
+			}
+
+			more, major := next()
+			if more && !major {
+				// the task is free again
+				tasks[0] = result
+				generation()
+				continue
+			}
+			task := result
+			task.ID = -1
+			task.F = p.bestF
+			copy(task.X, p.bestX)
+			task.Op = optimize.MajorIteration
+			improved = false
+			if !more {
+				task.Op = optimize.MethodDone
+			}
+			operations <- task
+		default:
+			panic(fmt.Sprintf("ml: unexpected operation %v", result.Op))
+		}
+	}
+
+	// evaluations in flight when stopped
+	for result := range results {
+		if result.Op == optimize.FuncEvaluation && p.record(result.ID, result.F) {
+			improved = true
+		}
+	}
+	if improved {
+		task := tasks[0]
+		task.ID = -1
+		task.F = p.bestF
+		copy(task.X, p.bestX)
+		task.Op = optimize.MajorIteration
+		operations <- task
+	}
+	close(operations)
+}
+
+// clamp bounds x to lower and upper when set
+func clamp(x, lower, upper []float64) {
+	for j := range x {
+		if j < len(lower) && x[j] < lower[j] {
+			x[j] = lower[j]
+		}
+		if j < len(upper) && x[j] > upper[j] {
+			x[j] = upper[j]
+		}
+	}
+}
+
+// MethodSearch maximizes objective over space with a
+// method of optimize, e.g. GeneticAlgorithm or
+// SimulatedAnnealing, bounded to the space. It evaluates
+// at most budget params and returns the trials, best first.
+func MethodSearch(space SearchSpace, objective Objective, method optimize.Method, budget int) ([]Trial, error) {
+	if err := space.check(); err != nil {
+		return nil, err
+	}
+	lower, upper := make([]float64, len(space)), make([]float64, len(space))
+	for j := range upper {
+		upper[j] = 1
+	}
+	switch m := method.(type) {
+	case *GeneticAlgorithm:
+		m.Lower, m.Upper = lower, upper
+		if m.Sigma == 0 {
+			m.Sigma = 0.25
+		}
+	case *SimulatedAnnealing:
+		m.Lower, m.Upper = lower, upper
+		if m.Step == 0 {
+			m.Step = 0.25
+		}
+	}
+
+	var (
+		history []Trial
+		failed  error
+	)
+	prob := optimize.Problem{
+		Func: func(u []float64) float64 {
+			if failed != nil {
+				return math.NaN()
+			}
+			// methods not bounded to the space
+			u = append([]float64(nil), u...)
+			clamp(u, lower, upper)
+			p := space.unit(u)
+			score, err := objective(p)
+			if err != nil {
+				failed = err
+				return math.NaN()
+			}
+			history = append(history, Trial{Params: p, Score: score})
+			return -score
+		},
+	}
+
+	start := make([]float64, len(space))
+	for j := range start {
+		start[j] = 0.5
+	}
+	settings := &optimize.Settings{FuncEvaluations: budget, Concurrent: 1}
+	_, err := optimize.Minimize(prob, start, settings, method)
+	if failed != nil {
+		return nil, failed
+	}
+	if err != nil && len(history) == 0 {
+		return nil, err
+	}
+	sortTrials(history)
+	return history, nil
+}
@@ -0,0 +1,114 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// rastrigin has local minima at every integer point
+// and its global minimum 0 at the origin
+func rastrigin(x []float64) float64 {
+	f := 10 * float64(len(x))
+	for _, v := range x {
+		f += v*v - 10*math.Cos(2*math.Pi*v)
+	}
+	return f
+}
+
+func TestGeneticAlgorithmRastrigin(t *testing.T) {
+	lower, upper := []float64{-5.12, -5.12}, []float64{5.12, 5.12}
+	outside := 0
+	prob := optimize.Problem{Func: func(x []float64) float64 {
+		for j := range x {
+			if x[j] < lower[j] || x[j] > upper[j] {
+				outside++
+			}
+		}
+		return rastrigin(x)
+	}}
+	ga := &GeneticAlgorithm{Lower: lower, Upper: upper, Rand: rand.New(rand.NewSource(1))}
+	settings := &optimize.Settings{FuncEvaluations: 10000, Concurrent: 4}
+
+	// the start point is in the basin of the local
+	// minimum at (3, 3)
+	result, err := optimize.Minimize(prob, []float64{3, 3}, settings, ga)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// other local minima are at least 1
+	if result.F > 1e-2 || math.Hypot(result.X[0], result.X[1]) > 1e-2 {
+		t.Errorf("minimum %v at %v, want 0 at the origin", result.F, result.X)
+	}
+	if result.FuncEvaluations > 10000 {
+		t.Errorf("%d evaluations of 10000", result.FuncEvaluations)
+	}
+	if outside > 0 {
+		t.Errorf("%d evaluations outside the bounds", outside)
+	}
+}
+
+func TestSimulatedAnnealingEscapesLocalMinimum(t *testing.T) {
+	// (x²-1)² + x/2 has a local minimum near x = 0.93
+	// and the global one near x = -1.06
+	prob := optimize.Problem{Func: func(x []float64) float64 {
+		return (x[0]*x[0]-1)*(x[0]*x[0]-1) + x[0]/2
+	}}
+	sa := &SimulatedAnnealing{Rand: rand.New(rand.NewSource(1))}
+
+	// it stops once cold, not when f stops improving
+	settings := &optimize.Settings{Converger: optimize.NeverTerminate{}}
+	result, err := optimize.Minimize(prob, []float64{0.93}, settings, sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != optimize.MethodConverge {
+		t.Errorf("status %v, want MethodConverge once cold", result.Status)
+	}
+	if math.Abs(result.X[0]+1.0575) > 1e-2 {
+		t.Errorf("minimum at %v, want -1.06", result.X[0])
+	}
+
+	// cooling by 0.95 from 1 to 1e-8 takes 360 temperatures
+	// of 10 moves each
+	if want := 360 * 10; result.FuncEvaluations < want || result.FuncEvaluations > want+1 {
+		t.Errorf("%d evaluations, want %d", result.FuncEvaluations, want)
+	}
+}
+
+func TestMethodSearch(t *testing.T) {
+	space := SearchSpace{
+		{Name: "rate", Min: 1e-4, Max: 1, Log: true},
+		{Name: "depth", Min: 1, Max: 10, Integer: true},
+	}
+	// the best params are rate 0.01 and depth 7
+	objective := func(p Params) (float64, error) {
+		d := math.Log10(p["rate"]) + 2
+		return -d*d - math.Abs(p["depth"]-7), nil
+	}
+	methods := map[string]optimize.Method{
+		"genetic":   &GeneticAlgorithm{Rand: rand.New(rand.NewSource(1))},
+		"annealing": &SimulatedAnnealing{Rand: rand.New(rand.NewSource(1))},
+	}
+	for name, method := range methods {
+		trials, err := MethodSearch(space, objective, method, 500)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(trials) > 500 {
+			t.Errorf("%s: %d trials of 500", name, len(trials))
+		}
+		best := trials[0].Params
+		if best["depth"] != 7 || math.Abs(math.Log10(best["rate"])+2) > 0.1 {
+			t.Errorf("%s: best %v, want rate 0.01 and depth 7", name, best)
+		}
+		for _, tr := range trials {
+			if tr.Params["rate"] < 1e-4 || tr.Params["rate"] > 1 || tr.Params["depth"] < 1 || tr.Params["depth"] > 10 {
+				t.Errorf("%s: params %v outside the space", name, tr.Params)
+				break
+			}
+		}
+	}
+}
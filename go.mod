@@ -1,5 +1,10 @@
 module github.com/maxrafiandy/ml
 
-go 1.15
+go 1.21
 
 require gonum.org/v1/gonum v0.8.2
+
+require (
+	golang.org/x/exp v0.0.0-20190125153040-c74c464bbbf2 // indirect
+	golang.org/x/tools v0.0.0-20190206041539-40960b6deb8e // indirect
+)
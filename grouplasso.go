@@ -0,0 +1,191 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// GroupLasso is a linear regression minimizing
+//
+//	1/(2n)·Σ(y-θ·x)² + λ·((1-α)·Σ√pₘ·|θₘ|₂ + α·|θ|₁)
+//
+// over groups m of pₘ columns, λ being Lambda and α L1Ratio,
+// so whole groups, e.g. one-hot blocks of a category, are
+// selected or dropped together. L1Ratio above zero is the
+// sparse group lasso, also dropping columns within selected
+// groups. It is fitted by block coordinate descent with a
+// proximal step per group.
+type GroupLasso struct {
+	// Groups holds the group of every column, columns
+	// of negative groups are not penalized, e.g. the
+	// bias column
+	Groups  []int
+	Lambda  float64
+	L1Ratio float64
+	// MaxIterations are passes over the groups,
+	// defaults to 1000
+	MaxIterations int
+	// Tolerance stops when no theta moves more,
+	// relative to the output, defaults to 1e-7
+	Tolerance float64
+
+	Theta []float64
+	// Iterations are passes of the last Fit
+	Iterations int
+}
+
+// NewGroupLasso returns new pointer of GroupLasso
+// of columns in groups
+func NewGroupLasso(groups []int, lambda float64) *GroupLasso {
+	return &GroupLasso{Groups: groups, Lambda: lambda, MaxIterations: 1000, Tolerance: 1e-7}
+}
+
+// Fit trains the model on features and output
+func (g *GroupLasso) Fit(features [][]float64, output []float64) error {
+	if err := checkPenalty(g.Lambda, g.L1Ratio); err != nil {
+		return err
+	}
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if len(g.Groups) != p {
+		return fmt.Errorf("ml: got %d groups for %d columns", len(g.Groups), p)
+	}
+
+	blocks, free := g.blocks()
+	cd := newCoordinateDescent(features, output)
+	rows := float64(len(output))
+
+	// step sizes are inverse largest eigenvalues
+	// of XₘᵀXₘ/n of every group
+	lipschitz := make([]float64, len(blocks))
+	for m, b := range blocks {
+		gram := mat.NewSymDense(len(b), nil)
+		for a, j := range b {
+			for c := a; c < len(b); c++ {
+				gram.SetSym(a, c, floats.Dot(cd.cols[j], cd.cols[b[c]])/rows)
+			}
+		}
+		var eig mat.EigenSym
+		if !eig.Factorize(gram, false) {
+			return fmt.Errorf("ml: cannot factorize group %d", m)
+		}
+		lipschitz[m] = floats.Max(eig.Values(nil))
+	}
+
+	g.Theta = make([]float64, p)
+	theta := g.Theta
+	copy(cd.residual, output)
+	grad := make([]float64, p)
+	g.Iterations = g.maxIterations()
+	for it := 1; it <= g.maxIterations(); it++ {
+		moved := 0.0
+		for _, j := range free {
+			if cd.norms[j] == 0 {
+				continue
+			}
+			d := floats.Dot(cd.cols[j], cd.residual) / rows / cd.norms[j]
+			theta[j] += d
+			floats.AddScaled(cd.residual, -d, cd.cols[j])
+			moved = math.Max(moved, cd.norms[j]*d*d)
+		}
+
+		for m, b := range blocks {
+			if lipschitz[m] == 0 {
+				continue
+			}
+			t := 1 / lipschitz[m]
+			// gradient step of the block, then the
+			// proximal operator of its penalties
+			norm := 0.0
+			for _, j := range b {
+				grad[j] = theta[j] + t*floats.Dot(cd.cols[j], cd.residual)/rows
+				grad[j] = softThreshold(grad[j], t*g.Lambda*g.L1Ratio)
+				norm += grad[j] * grad[j]
+			}
+			norm = math.Sqrt(norm)
+			shrink := 0.0
+			if norm > 0 {
+				shrink = math.Max(0, 1-t*g.Lambda*(1-g.L1Ratio)*math.Sqrt(float64(len(b)))/norm)
+			}
+			for _, j := range b {
+				d := grad[j]*shrink - theta[j]
+				if d != 0 {
+					theta[j] += d
+					floats.AddScaled(cd.residual, -d, cd.cols[j])
+					moved = math.Max(moved, cd.norms[j]*d*d)
+				}
+			}
+		}
+
+		tolerance := g.Tolerance
+		if tolerance <= 0 {
+			tolerance = 1e-7
+		}
+		if moved <= tolerance*cd.scale {
+			g.Iterations = it
+			return nil
+		}
+	}
+	packageLogger().Warn("ml: group lasso did not converge", "iterations", g.Iterations, "lambda", g.Lambda)
+	return nil
+}
+
+// blocks returns columns of every group
+// and the unpenalized columns
+func (g *GroupLasso) blocks() ([][]int, []int) {
+	var (
+		blocks [][]int
+		free   []int
+	)
+	index := map[int]int{}
+	for j, m := range g.Groups {
+		if m < 0 {
+			free = append(free, j)
+			continue
+		}
+		k, ok := index[m]
+		if !ok {
+			k = len(blocks)
+			index[m] = k
+			blocks = append(blocks, nil)
+		}
+		blocks[k] = append(blocks[k], j)
+	}
+	return blocks, free
+}
+
+func (g *GroupLasso) maxIterations() int {
+	if g.MaxIterations <= 0 {
+		return 1000
+	}
+	return g.MaxIterations
+}
+
+// ActiveGroups returns the groups with
+// a non-zero coefficient, in column order
+func (g *GroupLasso) ActiveGroups() []int {
+	var active []int
+	seen := map[int]bool{}
+	for j, m := range g.Groups {
+		if m >= 0 && g.Theta[j] != 0 && !seen[m] {
+			seen[m] = true
+			active = append(active, m)
+		}
+	}
+	return active
+}
+
+// Estimate returns predicted value of X
+func (g *GroupLasso) Estimate(X []float64) float64 {
+	return floats.Dot(X, g.Theta)
+}
+
+// Coefficients returns thetas
+func (g *GroupLasso) Coefficients() []float64 {
+	return g.Theta
+}
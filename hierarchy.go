@@ -0,0 +1,185 @@
+package ml
+
+import (
+	"fmt"
+	"sort"
+)
+
+// HierarchicalClassifier classifies samples into a taxonomy
+// of labels, such as product categories. Every label has a
+// classifier against its siblings, trained on rows of its
+// parent. Prediction descends from the top, choosing the
+// most probable child, and stops at a label when no child
+// reaches Threshold. Rows may be labelled by any label, not
+// only leaves.
+type HierarchicalClassifier struct {
+	// Parents maps every label to its parent,
+	// "" for top labels
+	Parents      map[string]string
+	NewEstimator func() Estimator
+	// Threshold is the probability a child needs to
+	// descend into it, zero always descends to a leaf
+	Threshold float64
+
+	// Classifiers of every label
+	Classifiers map[string]Estimator
+	// Constant holds probabilities of labels whose parent
+	// rows were all or none of them, instead of Classifiers
+	Constant map[string]float64
+}
+
+// NewHierarchicalClassifier returns new pointer of
+// HierarchicalClassifier of taxonomy parents
+func NewHierarchicalClassifier(parents map[string]string, newEstimator func() Estimator) *HierarchicalClassifier {
+	return &HierarchicalClassifier{Parents: parents, NewEstimator: newEstimator, Threshold: 0.5}
+}
+
+// children returns children of every label, sorted
+func (h *HierarchicalClassifier) children() map[string][]string {
+	children := map[string][]string{}
+	for label, parent := range h.Parents {
+		children[parent] = append(children[parent], label)
+	}
+	for _, c := range children {
+		sort.Strings(c)
+	}
+	return children
+}
+
+// Path returns label and its ancestors, top first
+func (h *HierarchicalClassifier) Path(label string) []string {
+	var path []string
+	for l := label; l != ""; l = h.Parents[l] {
+		path = append([]string{l}, path...)
+	}
+	return path
+}
+
+// Fit trains classifiers of every label on features
+// of rows labelled by labels
+func (h *HierarchicalClassifier) Fit(features [][]float64, labels []string) error {
+	if len(features) == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+	if len(features) != len(labels) {
+		return fmt.Errorf("ml: got %d rows of features and %d labels", len(features), len(labels))
+	}
+	for label := range h.Parents {
+		if label == "" {
+			return fmt.Errorf("ml: empty label in taxonomy")
+		}
+		seen := map[string]bool{}
+		for l := label; l != ""; l = h.Parents[l] {
+			if seen[l] {
+				return fmt.Errorf("ml: taxonomy has a cycle at %q", l)
+			}
+			seen[l] = true
+			if _, ok := h.Parents[l]; !ok {
+				return fmt.Errorf("ml: parent %q is not in the taxonomy", l)
+			}
+		}
+	}
+
+	// rows of every label and its descendants
+	rows := map[string][]int{}
+	for i, label := range labels {
+		if _, ok := h.Parents[label]; !ok {
+			return fmt.Errorf("ml: label %q of row %d is not in the taxonomy", label, i)
+		}
+		rows[""] = append(rows[""], i)
+		for l := label; l != ""; l = h.Parents[l] {
+			rows[l] = append(rows[l], i)
+		}
+	}
+
+	h.Classifiers = map[string]Estimator{}
+	h.Constant = map[string]float64{}
+	for parent, children := range h.children() {
+		if len(rows[parent]) == 0 {
+			for _, child := range children {
+				h.Constant[child] = 0
+			}
+			continue
+		}
+		X := make([][]float64, len(rows[parent]))
+		for k, i := range rows[parent] {
+			X[k] = features[i]
+		}
+		for _, child := range children {
+			in := map[int]bool{}
+			for _, i := range rows[child] {
+				in[i] = true
+			}
+			y := make([]float64, len(X))
+			for k, i := range rows[parent] {
+				if in[i] {
+					y[k] = 1
+				}
+			}
+			if len(in) == 0 || len(in) == len(y) {
+				h.Constant[child] = y[0]
+				continue
+			}
+			model := h.NewEstimator()
+			if err := model.Fit(X, y); err != nil {
+				return fmt.Errorf("ml: label %q: %v", child, err)
+			}
+			h.Classifiers[child] = model
+		}
+	}
+	return nil
+}
+
+// probability returns probability of X of label
+// against its siblings
+func (h *HierarchicalClassifier) probability(X []float64, label string) float64 {
+	if model, ok := h.Classifiers[label]; ok {
+		return model.Estimate(X)
+	}
+	return h.Constant[label]
+}
+
+// Predict returns the path of predicted labels of X,
+// top first, ending where no child reaches Threshold
+func (h *HierarchicalClassifier) Predict(X []float64) []string {
+	children := h.children()
+	var path []string
+	for node := ""; len(children[node]) > 0; {
+		best, prob := "", -1.0
+		for _, child := range children[node] {
+			if p := h.probability(X, child); p > prob {
+				best, prob = child, p
+			}
+		}
+		if prob < h.Threshold {
+			break
+		}
+		path = append(path, best)
+		node = best
+	}
+	return path
+}
+
+// HierarchicalF1 returns the F1 score of predicted paths
+// against true paths, counting shared labels of the paths,
+// so predicting a parent of the true label is partly right
+func HierarchicalF1(paths, predictions [][]string) float64 {
+	shared, truth, predicted := 0.0, 0.0, 0.0
+	for i, path := range paths {
+		in := map[string]bool{}
+		for _, l := range path {
+			in[l] = true
+		}
+		for _, l := range predictions[i] {
+			if in[l] {
+				shared++
+			}
+		}
+		truth += float64(len(path))
+		predicted += float64(len(predictions[i]))
+	}
+	if truth+predicted == 0 {
+		return 0
+	}
+	return 2 * shared / (truth + predicted)
+}
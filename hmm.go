@@ -0,0 +1,472 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Emission is the observation model of HMM states
+type Emission interface {
+	// LogProbability returns log probability (or density)
+	// of observation x in state
+	LogProbability(state int, x []float64) float64
+	// Maximize re-estimates parameters from sequences
+	// weighted by posteriors of every state, as the
+	// M-step of Baum-Welch
+	Maximize(sequences, posteriors [][][]float64)
+}
+
+// HMM is a hidden Markov model with Start probabilities,
+// Transition probabilities Transition[i][j] from state i
+// to state j and an Emission of observations. A sequence
+// is a slice of observations.
+type HMM struct {
+	Start      []float64
+	Transition [][]float64
+	Emission   Emission
+	// Rand draws the initial emissions of Fit,
+	// nil uses the global source
+	Rand *rand.Rand
+}
+
+// DiscreteEmission emits symbols 0..len(Probs[i])-1, read
+// from the first value of an observation
+type DiscreteEmission struct {
+	Probs [][]float64
+
+	symbols int
+}
+
+// GaussianEmission emits observations from a normal
+// distribution with diagonal covariance in every state
+type GaussianEmission struct {
+	Means     [][]float64
+	Variances [][]float64
+}
+
+// minVariance keeps gaussian states from collapsing
+// onto a single observation
+const minVariance = 1e-6
+
+// NewHMM returns new pointer of HMM with uniform
+// start and transitions, random emissions break the
+// symmetry of states
+func NewHMM(states int, emission Emission) *HMM {
+	h := &HMM{
+		Start:      make([]float64, states),
+		Transition: make([][]float64, states),
+		Emission:   emission,
+	}
+	for i := range h.Start {
+		h.Start[i] = 1 / float64(states)
+		h.Transition[i] = make([]float64, states)
+		for j := range h.Transition[i] {
+			h.Transition[i][j] = 1 / float64(states)
+		}
+	}
+	return h
+}
+
+// NewDiscreteHMM returns new pointer of HMM with
+// discrete emissions of symbols, initialized
+// randomly on Fit
+func NewDiscreteHMM(states, symbols int) *HMM {
+	return NewHMM(states, &DiscreteEmission{symbols: symbols})
+}
+
+// NewGaussianHMM returns new pointer of HMM with gaussian
+// emissions, initialized from random observations on Fit
+func NewGaussianHMM(states int) *HMM {
+	return NewHMM(states, &GaussianEmission{})
+}
+
+func randomDistribution(r *rand.Rand, n int) []float64 {
+	p := make([]float64, n)
+	sum := 0.0
+	for i := range p {
+		p[i] = 1 + 0.1*r.Float64()
+		sum += p[i]
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p
+}
+
+// LogProbability returns log probability of symbol x[0]
+func (e *DiscreteEmission) LogProbability(state int, x []float64) float64 {
+	symbol := int(x[0])
+	if symbol < 0 || symbol >= len(e.Probs[state]) {
+		return math.Inf(-1)
+	}
+	return math.Log(e.Probs[state][symbol])
+}
+
+// Maximize sets symbol probabilities to their
+// expected frequencies
+func (e *DiscreteEmission) Maximize(sequences, posteriors [][][]float64) {
+	for i := range e.Probs {
+		counts := make([]float64, len(e.Probs[i]))
+		total := 0.0
+		for s, seq := range sequences {
+			for t, x := range seq {
+				symbol := int(x[0])
+				if symbol < 0 || symbol >= len(counts) {
+					continue
+				}
+				counts[symbol] += posteriors[s][t][i]
+				total += posteriors[s][t][i]
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		for k := range counts {
+			e.Probs[i][k] = counts[k] / total
+		}
+	}
+}
+
+// init sets probabilities to randomly perturbed uniform
+// distributions of symbols, or of symbols seen in
+// sequences when unknown
+func (e *DiscreteEmission) init(r *rand.Rand, states int, sequences [][][]float64) {
+	symbols := e.symbols
+	if symbols == 0 {
+		for _, seq := range sequences {
+			for _, x := range seq {
+				symbols = int(math.Max(float64(symbols), x[0]+1))
+			}
+		}
+	}
+
+	e.Probs = make([][]float64, states)
+	for i := range e.Probs {
+		e.Probs[i] = randomDistribution(r, symbols)
+	}
+}
+
+// LogProbability returns log density of x
+func (e *GaussianEmission) LogProbability(state int, x []float64) float64 {
+	logp := 0.0
+	for j, v := range x {
+		mean, variance := e.Means[state][j], e.Variances[state][j]
+		logp -= 0.5 * (math.Log(2*math.Pi*variance) + (v-mean)*(v-mean)/variance)
+	}
+	return logp
+}
+
+// Maximize sets means and variances to their
+// posterior weighted estimates
+func (e *GaussianEmission) Maximize(sequences, posteriors [][][]float64) {
+	for i := range e.Means {
+		dims := len(e.Means[i])
+		mean := make([]float64, dims)
+		total := 0.0
+		for s, seq := range sequences {
+			for t, x := range seq {
+				w := posteriors[s][t][i]
+				total += w
+				for j, v := range x {
+					mean[j] += w * v
+				}
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		for j := range mean {
+			mean[j] /= total
+		}
+
+		variance := make([]float64, dims)
+		for s, seq := range sequences {
+			for t, x := range seq {
+				w := posteriors[s][t][i]
+				for j, v := range x {
+					variance[j] += w * (v - mean[j]) * (v - mean[j])
+				}
+			}
+		}
+		for j := range variance {
+			variance[j] = math.Max(variance[j]/total, minVariance)
+		}
+
+		e.Means[i] = mean
+		e.Variances[i] = variance
+	}
+}
+
+// init sets means to random observations and variances
+// to the overall variance of every dimension
+func (e *GaussianEmission) init(r *rand.Rand, states int, sequences [][][]float64) {
+	var all [][]float64
+	for _, seq := range sequences {
+		all = append(all, seq...)
+	}
+	dims := len(all[0])
+
+	variance := make([]float64, dims)
+	for j := range variance {
+		col := column(all, j)
+		m := mean(col)
+		for _, v := range col {
+			variance[j] += (v - m) * (v - m)
+		}
+		variance[j] = math.Max(variance[j]/float64(len(col)), minVariance)
+	}
+
+	e.Means = make([][]float64, states)
+	e.Variances = make([][]float64, states)
+	for i, k := range r.Perm(len(all))[:states] {
+		e.Means[i] = append([]float64(nil), all[k]...)
+		e.Variances[i] = append([]float64(nil), variance...)
+	}
+}
+
+// emissions returns emission probabilities of every
+// observation, scaled by the largest one of the step,
+// and the log of the scales
+func (h *HMM) emissions(seq [][]float64) ([][]float64, []float64) {
+	b := make([][]float64, len(seq))
+	scale := make([]float64, len(seq))
+	for t, x := range seq {
+		b[t] = make([]float64, len(h.Start))
+		max := math.Inf(-1)
+		for i := range b[t] {
+			b[t][i] = h.Emission.LogProbability(i, x)
+			max = math.Max(max, b[t][i])
+		}
+		for i := range b[t] {
+			b[t][i] = math.Exp(b[t][i] - max)
+		}
+		scale[t] = max
+	}
+	return b, scale
+}
+
+// forwardBackward returns scaled forward and backward
+// probabilities, scaled emissions and log-likelihood
+func (h *HMM) forwardBackward(seq [][]float64) (alpha, beta, b [][]float64, c []float64, loglik float64) {
+	n := len(h.Start)
+	b, scale := h.emissions(seq)
+
+	alpha = make([][]float64, len(seq))
+	c = make([]float64, len(seq))
+	for t := range seq {
+		alpha[t] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			if t == 0 {
+				alpha[t][j] = h.Start[j] * b[t][j]
+				continue
+			}
+			sum := 0.0
+			for i := 0; i < n; i++ {
+				sum += alpha[t-1][i] * h.Transition[i][j]
+			}
+			alpha[t][j] = sum * b[t][j]
+		}
+		for _, a := range alpha[t] {
+			c[t] += a
+		}
+		for j := range alpha[t] {
+			alpha[t][j] /= c[t]
+		}
+		loglik += math.Log(c[t]) + scale[t]
+	}
+
+	beta = make([][]float64, len(seq))
+	for t := len(seq) - 1; t >= 0; t-- {
+		beta[t] = make([]float64, n)
+		for i := 0; i < n; i++ {
+			if t == len(seq)-1 {
+				beta[t][i] = 1
+				continue
+			}
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				sum += h.Transition[i][j] * b[t+1][j] * beta[t+1][j]
+			}
+			beta[t][i] = sum / c[t+1]
+		}
+	}
+
+	return alpha, beta, b, c, loglik
+}
+
+// LogLikelihood returns log probability of seq
+func (h *HMM) LogLikelihood(seq [][]float64) float64 {
+	if len(seq) == 0 {
+		return 0
+	}
+	_, _, _, _, loglik := h.forwardBackward(seq)
+	return loglik
+}
+
+// Posterior returns probabilities of every state at
+// every step of seq given the whole sequence
+func (h *HMM) Posterior(seq [][]float64) [][]float64 {
+	if len(seq) == 0 {
+		return nil
+	}
+	alpha, beta, _, _, _ := h.forwardBackward(seq)
+	gamma := make([][]float64, len(seq))
+	for t := range seq {
+		gamma[t] = make([]float64, len(h.Start))
+		for i := range gamma[t] {
+			gamma[t][i] = alpha[t][i] * beta[t][i]
+		}
+	}
+	return gamma
+}
+
+// Viterbi returns the most likely state path of seq
+// and its log probability
+func (h *HMM) Viterbi(seq [][]float64) ([]int, float64) {
+	if len(seq) == 0 {
+		return nil, 0
+	}
+
+	n := len(h.Start)
+	delta := make([]float64, n)
+	back := make([][]int, len(seq))
+	for i := range delta {
+		delta[i] = math.Log(h.Start[i]) + h.Emission.LogProbability(i, seq[0])
+	}
+
+	for t := 1; t < len(seq); t++ {
+		next := make([]float64, n)
+		back[t] = make([]int, n)
+		for j := 0; j < n; j++ {
+			best, arg := math.Inf(-1), 0
+			for i := 0; i < n; i++ {
+				if v := delta[i] + math.Log(h.Transition[i][j]); v > best {
+					best, arg = v, i
+				}
+			}
+			next[j] = best + h.Emission.LogProbability(j, seq[t])
+			back[t][j] = arg
+		}
+		delta = next
+	}
+
+	path := make([]int, len(seq))
+	best := math.Inf(-1)
+	for i, v := range delta {
+		if v > best {
+			best, path[len(seq)-1] = v, i
+		}
+	}
+	for t := len(seq) - 1; t > 0; t-- {
+		path[t-1] = back[t][path[t]]
+	}
+
+	return path, best
+}
+
+// Fit trains the model on sequences by Baum-Welch until
+// the log-likelihood improves less than tol, and returns
+// the log-likelihood of every iteration
+func (h *HMM) Fit(sequences [][][]float64, iterations int, tol float64) ([]float64, error) {
+	var total int
+	for _, seq := range sequences {
+		total += len(seq)
+	}
+	if total < len(h.Start) {
+		return nil, fmt.Errorf("ml: got %d observations for %d states", total, len(h.Start))
+	}
+
+	switch e := h.Emission.(type) {
+	case *GaussianEmission:
+		if e.Means == nil {
+			e.init(RandOf(h.Rand), len(h.Start), sequences)
+		}
+	case *DiscreteEmission:
+		if e.Probs == nil {
+			e.init(RandOf(h.Rand), len(h.Start), sequences)
+		}
+	}
+
+	n := len(h.Start)
+	var logliks []float64
+	for it := 0; it < iterations; it++ {
+		start := make([]float64, n)
+		xi := make([][]float64, n)
+		for i := range xi {
+			xi[i] = make([]float64, n)
+		}
+		posteriors := make([][][]float64, len(sequences))
+
+		loglik := 0.0
+		for s, seq := range sequences {
+			if len(seq) == 0 {
+				continue
+			}
+			alpha, beta, b, c, ll := h.forwardBackward(seq)
+			loglik += ll
+
+			gamma := make([][]float64, len(seq))
+			for t := range seq {
+				gamma[t] = make([]float64, n)
+				for i := range gamma[t] {
+					gamma[t][i] = alpha[t][i] * beta[t][i]
+				}
+				if t == len(seq)-1 {
+					continue
+				}
+				for i := 0; i < n; i++ {
+					for j := 0; j < n; j++ {
+						xi[i][j] += alpha[t][i] * h.Transition[i][j] * b[t+1][j] * beta[t+1][j] / c[t+1]
+					}
+				}
+			}
+			for i := range start {
+				start[i] += gamma[0][i]
+			}
+			posteriors[s] = gamma
+		}
+
+		logliks = append(logliks, loglik)
+		if it > 0 && loglik-logliks[it-1] < tol {
+			break
+		}
+
+		h.Start = normalized(start)
+		for i := range xi {
+			if row := normalized(xi[i]); row != nil {
+				h.Transition[i] = row
+			}
+		}
+		h.Emission.Maximize(sequences, posteriors)
+	}
+
+	return logliks, nil
+}
+
+// normalized returns x scaled to sum to one,
+// or nil when x sums to zero
+func normalized(x []float64) []float64 {
+	sum := 0.0
+	for _, v := range x {
+		sum += v
+	}
+	if sum == 0 {
+		return nil
+	}
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = v / sum
+	}
+	return out
+}
+
+// GobEncode encodes the model without Rand
+func (h *HMM) GobEncode() ([]byte, error) {
+	return GobEncodeModel(h)
+}
+
+// GobDecode decodes a model of GobEncode
+func (h *HMM) GobDecode(data []byte) error {
+	return GobDecodeModel(h, data)
+}
@@ -0,0 +1,91 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// weatherHMM is the healthy/fever example of the
+// Viterbi algorithm, observing normal, cold or dizzy
+func weatherHMM() *HMM {
+	return &HMM{
+		Start:      []float64{0.6, 0.4},
+		Transition: [][]float64{{0.7, 0.3}, {0.4, 0.6}},
+		Emission: &DiscreteEmission{Probs: [][]float64{
+			{0.5, 0.4, 0.1},
+			{0.1, 0.3, 0.6},
+		}},
+	}
+}
+
+func TestHMMViterbiAndForward(t *testing.T) {
+	h := weatherHMM()
+	seq := [][]float64{{0}, {1}, {2}}
+
+	// healthy, healthy, fever of probability
+	// 0.6·0.5 · 0.7·0.4 · 0.3·0.6
+	path, logp := h.Viterbi(seq)
+	if want := []int{0, 0, 1}; len(path) != 3 || path[0] != want[0] || path[1] != want[1] || path[2] != want[2] {
+		t.Errorf("path %v, want %v", path, want)
+	}
+	if math.Abs(logp-math.Log(0.01512)) > 1e-9 {
+		t.Errorf("path probability %v, want 0.01512", math.Exp(logp))
+	}
+
+	// forward probabilities of the steps are
+	// (0.3, 0.04), (0.0904, 0.0342), (0.007696, 0.028584)
+	if got := h.LogLikelihood(seq); math.Abs(got-math.Log(0.03628)) > 1e-9 {
+		t.Errorf("probability %v, want 0.03628", math.Exp(got))
+	}
+
+	posterior := h.Posterior(seq)
+	if last := posterior[2]; math.Abs(last[1]-0.028584/0.03628) > 1e-9 || math.Abs(last[0]+last[1]-1) > 1e-9 {
+		t.Errorf("last posterior %v, want fever %v", last, 0.028584/0.03628)
+	}
+}
+
+func TestHMMFitGaussian(t *testing.T) {
+	// two sticky states emitting around 0 and 10
+	rnd := rand.New(rand.NewSource(1))
+	var sequences [][][]float64
+	for s := 0; s < 5; s++ {
+		state, seq := 0, make([][]float64, 100)
+		for i := range seq {
+			if rnd.Float64() < 0.1 {
+				state = 1 - state
+			}
+			seq[i] = []float64{10*float64(state) + rnd.NormFloat64()}
+		}
+		sequences = append(sequences, seq)
+	}
+
+	// means start apart, two random observations of
+	// the same state would never separate
+	h := NewHMM(2, &GaussianEmission{
+		Means:     [][]float64{{2}, {8}},
+		Variances: [][]float64{{25}, {25}},
+	})
+	logliks, err := h.Fit(sequences, 100, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(logliks); i++ {
+		if logliks[i] < logliks[i-1]-1e-6 {
+			t.Fatalf("log-likelihood %v after %v", logliks[i], logliks[i-1])
+		}
+	}
+
+	e := h.Emission.(*GaussianEmission)
+	means := []float64{e.Means[0][0], e.Means[1][0]}
+	sort.Float64s(means)
+	if math.Abs(means[0]) > 0.3 || math.Abs(means[1]-10) > 0.3 {
+		t.Errorf("means %v, want about 0 and 10", means)
+	}
+	for i, row := range h.Transition {
+		if row[i] < 0.8 {
+			t.Errorf("transitions %v, want sticky states", h.Transition)
+		}
+	}
+}
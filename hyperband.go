@@ -0,0 +1,259 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BudgetObjective scores params trained with a budget,
+// e.g. epochs of SGD or iterations of Fit, higher is
+// better. Objectives may continue training of a model
+// of the same params from a smaller budget.
+type BudgetObjective func(p Params, budget int) (float64, error)
+
+// BudgetTrial is an evaluation of a BudgetObjective
+type BudgetTrial struct {
+	Params Params
+	Budget int
+	Score  float64
+}
+
+// CVBudgetObjective returns a BudgetObjective of mean
+// metric score of CrossValidate with estimators of
+// newEstimator trained with a budget
+func CVBudgetObjective(newEstimator func(p Params, budget int) Estimator, features [][]float64, output []float64, splitter Splitter, metric Metric) BudgetObjective {
+	return func(p Params, budget int) (float64, error) {
+		scores, err := CrossValidate(func() Estimator { return newEstimator(p, budget) }, features, output, splitter, metric)
+		if err != nil {
+			return 0, err
+		}
+		return mean(scores), nil
+	}
+}
+
+// SGDObjective returns a BudgetObjective training the SGD
+// of newSGD for budget epochs on train and scoring it on
+// validation features and output with metric. Params
+// continue from thetas of their previous budget, so
+// promoting them only costs the extra epochs.
+func SGDObjective(newSGD func(p Params) *SGD, train RowSource, features [][]float64, output []float64, metric Metric) BudgetObjective {
+	type state struct {
+		theta  []float64
+		epochs int
+	}
+	var mu sync.Mutex
+	states := map[string]state{}
+
+	return func(p Params, budget int) (float64, error) {
+		// maps print with sorted keys
+		key := fmt.Sprint(p)
+		s := newSGD(p)
+		l := s.Model.linear()
+
+		mu.Lock()
+		prev, ok := states[key]
+		mu.Unlock()
+
+		s.Epochs = budget
+		if ok && prev.epochs <= budget {
+			s.Theta = prev.theta
+			s.Epochs = budget - prev.epochs
+		}
+		if s.Epochs > 0 {
+			if err := s.Fit(train); err != nil {
+				return 0, err
+			}
+		} else {
+			l.Theta = append([]float64(nil), prev.theta...)
+		}
+
+		mu.Lock()
+		states[key] = state{theta: append([]float64(nil), l.Theta...), epochs: budget}
+		mu.Unlock()
+
+		return metric(output, estimateAll(s.Model, features)), nil
+	}
+}
+
+// Hyperband searches params by successive halving:
+// many random params get a small budget, then the best
+// 1/Eta of them get Eta times the budget, until few get
+// MaxBudget. Brackets trade the number of params against
+// the smallest budget, so params that start slowly are
+// not always dropped early.
+type Hyperband struct {
+	Space     SearchSpace
+	Objective BudgetObjective
+	// MinBudget defaults to 1
+	MinBudget int
+	MaxBudget int
+	// Eta defaults to 3
+	Eta float64
+	// MaxDuration stops the search after the running
+	// trial, zero is no limit
+	MaxDuration time.Duration
+	Rand        *rand.Rand
+	Logger      Logger
+
+	// Trials are evaluated trials in order
+	Trials []BudgetTrial
+}
+
+// NewHyperband returns new pointer of Hyperband
+// of budgets up to maxBudget
+func NewHyperband(space SearchSpace, objective BudgetObjective, maxBudget int) *Hyperband {
+	return &Hyperband{
+		Space:     space,
+		Objective: objective,
+		MinBudget: 1,
+		MaxBudget: maxBudget,
+		Eta:       3,
+	}
+}
+
+// Search runs every bracket and returns the best trial
+// of the largest budget evaluated
+func (h *Hyperband) Search() (BudgetTrial, error) {
+	if err := h.check(); err != nil {
+		return BudgetTrial{}, err
+	}
+	r := RandOf(h.Rand)
+	h.Trials = h.Trials[:0]
+	start := time.Now()
+
+	// brackets s = sMax..0 start sMax+1 params
+	// and more with the smallest budget
+	R := float64(h.MaxBudget)
+	sMax := int(math.Floor(math.Log(R/float64(h.minBudget())) / math.Log(h.eta())))
+	for s := sMax; s >= 0; s-- {
+		n := int(math.Ceil(float64(sMax+1) / float64(s+1) * math.Pow(h.eta(), float64(s))))
+		budget := R * math.Pow(h.eta(), -float64(s))
+
+		configs := make([]Params, n)
+		for i := range configs {
+			configs[i] = h.Space.Sample(r)
+		}
+		if err := h.halve(configs, budget, s, start); err != nil {
+			return BudgetTrial{}, err
+		}
+	}
+
+	return h.best()
+}
+
+// SuccessiveHalving runs a single bracket of n random
+// params starting with MinBudget and returns the best
+// trial of the largest budget evaluated
+func (h *Hyperband) SuccessiveHalving(n int) (BudgetTrial, error) {
+	if err := h.check(); err != nil {
+		return BudgetTrial{}, err
+	}
+	r := RandOf(h.Rand)
+	h.Trials = h.Trials[:0]
+
+	configs := make([]Params, n)
+	for i := range configs {
+		configs[i] = h.Space.Sample(r)
+	}
+	rounds := int(math.Floor(math.Log(float64(h.MaxBudget)/float64(h.minBudget())) / math.Log(h.eta())))
+	if err := h.halve(configs, float64(h.minBudget()), rounds, time.Now()); err != nil {
+		return BudgetTrial{}, err
+	}
+	return h.best()
+}
+
+// halve evaluates configs with budget then keeps the best
+// 1/Eta with Eta times the budget, for rounds+1 rounds
+func (h *Hyperband) halve(configs []Params, budget float64, rounds int, start time.Time) error {
+	log := h.Logger
+	if log == nil {
+		log = packageLogger()
+	}
+
+	for i := 0; i <= rounds && len(configs) > 0; i++ {
+		b := int(math.Round(budget * math.Pow(h.eta(), float64(i))))
+		if b < 1 {
+			b = 1
+		}
+		if b > h.MaxBudget {
+			b = h.MaxBudget
+		}
+
+		trials := make([]BudgetTrial, 0, len(configs))
+		for _, p := range configs {
+			if h.MaxDuration > 0 && time.Since(start) > h.MaxDuration {
+				log.Warn("ml: search ran out of time", "trials", len(h.Trials))
+				return nil
+			}
+			score, err := h.Objective(p, b)
+			if err != nil {
+				return err
+			}
+			t := BudgetTrial{Params: p, Budget: b, Score: score}
+			trials = append(trials, t)
+			h.Trials = append(h.Trials, t)
+		}
+		log.Debug("ml: successive halving round", "configs", len(configs), "budget", b)
+
+		sort.SliceStable(trials, func(i, j int) bool {
+			a, b := trials[i].Score, trials[j].Score
+			return a > b || (!math.IsNaN(a) && math.IsNaN(b))
+		})
+		keep := int(float64(len(configs)) / h.eta())
+		configs = configs[:0]
+		for _, t := range trials[:keep] {
+			configs = append(configs, t.Params)
+		}
+	}
+	return nil
+}
+
+func (h *Hyperband) check() error {
+	if err := h.Space.check(); err != nil {
+		return err
+	}
+	if h.MaxBudget < h.minBudget() {
+		return fmt.Errorf("ml: max budget %d below min budget %d", h.MaxBudget, h.minBudget())
+	}
+	if h.eta() <= 1 {
+		return fmt.Errorf("ml: eta %v should be above 1", h.Eta)
+	}
+	return nil
+}
+
+func (h *Hyperband) eta() float64 {
+	if h.Eta == 0 {
+		return 3
+	}
+	return h.Eta
+}
+
+func (h *Hyperband) minBudget() int {
+	if h.MinBudget < 1 {
+		return 1
+	}
+	return h.MinBudget
+}
+
+// best returns the best trial of the largest budget,
+// scores of smaller budgets are not comparable
+func (h *Hyperband) best() (BudgetTrial, error) {
+	best := -1
+	for i, t := range h.Trials {
+		if math.IsNaN(t.Score) {
+			continue
+		}
+		if best < 0 || t.Budget > h.Trials[best].Budget ||
+			t.Budget == h.Trials[best].Budget && t.Score > h.Trials[best].Score {
+			best = i
+		}
+	}
+	if best < 0 {
+		return BudgetTrial{}, fmt.Errorf("ml: no trial has a score")
+	}
+	return h.Trials[best], nil
+}
@@ -0,0 +1,151 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHyperbandBrackets(t *testing.T) {
+	// brackets of R = 81 and η = 3, table 1 of Li et al.
+	// (2018), start 81, 34, 15, 8 and 5 params with budgets
+	// 1, 3, 9, 27 and 81
+	want := map[int]int{
+		1:  81,
+		3:  27 + 34,
+		9:  9 + 11 + 15,
+		27: 3 + 3 + 5 + 8,
+		81: 1 + 1 + 1 + 2 + 5,
+	}
+	calls := map[int]int{}
+	objective := func(p Params, budget int) (float64, error) {
+		calls[budget]++
+		return p["x"], nil
+	}
+	h := NewHyperband(SearchSpace{{Name: "x", Min: 0, Max: 1}}, objective, 81)
+	h.Rand = rand.New(rand.NewSource(1))
+
+	best, err := h.Search()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for b, n := range want {
+		if calls[b] != n {
+			t.Errorf("%d trials of budget %d, want %d", calls[b], b, n)
+		}
+	}
+	if len(calls) != len(want) || len(h.Trials) != 206 {
+		t.Errorf("%d trials of budgets %v", len(h.Trials), calls)
+	}
+
+	// scores do not depend on budget, so the best param of
+	// every bracket reaches the largest budget
+	top := h.Trials[0]
+	for _, tr := range h.Trials {
+		if tr.Score > top.Score {
+			top = tr
+		}
+	}
+	if best.Budget != 81 || best.Score != top.Score {
+		t.Errorf("best %+v, want %v at budget 81", best, top.Score)
+	}
+}
+
+func TestSuccessiveHalving(t *testing.T) {
+	var order []int
+	objective := func(p Params, budget int) (float64, error) {
+		order = append(order, budget)
+		// the closer to 0.7 the better, more budget
+		// takes off noise of 1/budget
+		return -math.Abs(p["x"]-0.7) - 1/float64(budget), nil
+	}
+	h := NewHyperband(SearchSpace{{Name: "x", Min: 0, Max: 1}}, objective, 27)
+	h.Rand = rand.New(rand.NewSource(1))
+
+	best, err := h.SuccessiveHalving(27)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 27 params with budget 1, then 9 with 3, 3 with 9
+	// and 1 with 27
+	rounds := []struct{ n, budget int }{{27, 1}, {9, 3}, {3, 9}, {1, 27}}
+	i := 0
+	for _, r := range rounds {
+		for k := 0; k < r.n; k++ {
+			if i >= len(order) || order[i] != r.budget {
+				t.Fatalf("budgets %v, want rounds %v", order, rounds)
+			}
+			i++
+		}
+	}
+	if i != len(order) {
+		t.Fatalf("budgets %v, want rounds %v", order, rounds)
+	}
+
+	closest := h.Trials[0]
+	for _, tr := range h.Trials[:27] {
+		if math.Abs(tr.Params["x"]-0.7) < math.Abs(closest.Params["x"]-0.7) {
+			closest = tr
+		}
+	}
+	if best.Budget != 27 || best.Params["x"] != closest.Params["x"] {
+		t.Errorf("best %+v, want x %v at budget 27", best, closest.Params["x"])
+	}
+}
+
+func TestHyperbandCheck(t *testing.T) {
+	objective := func(p Params, budget int) (float64, error) { return 0, nil }
+	space := SearchSpace{{Name: "x", Min: 0, Max: 1}}
+	cases := []*Hyperband{
+		{Space: space, Objective: objective, MinBudget: 10, MaxBudget: 5},
+		{Space: space, Objective: objective, MaxBudget: 5, Eta: 1},
+		{Space: nil, Objective: objective, MaxBudget: 5},
+	}
+	for _, h := range cases {
+		if _, err := h.Search(); err == nil {
+			t.Errorf("no error of %+v", h)
+		}
+	}
+
+	nan := func(p Params, budget int) (float64, error) { return math.NaN(), nil }
+	if _, err := NewHyperband(space, nan, 9).Search(); err == nil {
+		t.Error("no error when no trial has a score")
+	}
+}
+
+func TestSGDObjectiveContinues(t *testing.T) {
+	features := [][]float64{{1, 0}, {1, 1}, {1, 2}, {1, 3}}
+	output := []float64{1, 3, 5, 7}
+
+	var sgds []*SGD
+	objective := SGDObjective(func(p Params) *SGD {
+		s := NewSGD(NewLinearRegression())
+		s.Step = p["step"]
+		s.Rand = rand.New(rand.NewSource(1))
+		sgds = append(sgds, s)
+		return s
+	}, Slices{features, output}, features, output, NegMeanSquaredError)
+
+	p := Params{"step": 0.05}
+	var scores []float64
+	for _, budget := range []int{3, 9, 27, 27} {
+		score, err := objective(p, budget)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scores = append(scores, score)
+	}
+	// promotions only train the extra epochs and
+	// a repeated budget trains none
+	var epochs []int
+	for _, s := range sgds {
+		epochs = append(epochs, s.Epochs)
+	}
+	if want := []int{3, 6, 18, 0}; fmt.Sprint(epochs) != fmt.Sprint(want) {
+		t.Errorf("trained epochs %v, want %v", epochs, want)
+	}
+	if !(scores[0] < scores[1] && scores[1] < scores[2]) || scores[3] != scores[2] {
+		t.Errorf("scores %v should improve with budget", scores)
+	}
+}
@@ -0,0 +1,72 @@
+package ml
+
+import (
+	"math/rand"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// Importance is permutation importance of a column
+type Importance struct {
+	// Name of the column, from the model
+	// or x0, x1, ... when it has none
+	Name string
+	// Mean and Std of Drops
+	Mean float64
+	Std  float64
+	// Drops are decreases of the metric score
+	// in every repeat
+	Drops []float64
+}
+
+// PermutationImportance measures how much the metric
+// score of a fitted model drops when a column of
+// features is shuffled, repeated repeats times for
+// every column. Columns the model relies on get high
+// importance.
+func PermutationImportance(model Estimator, features [][]float64, output []float64, metric Metric, repeats int) []Importance {
+	return PermutationImportanceRand(nil, model, features, output, metric, repeats)
+}
+
+// PermutationImportanceRand is PermutationImportance
+// shuffling with rng, nil uses the global source
+func PermutationImportanceRand(rng *rand.Rand, model Estimator, features [][]float64, output []float64, metric Metric, repeats int) []Importance {
+	rng = RandOf(rng)
+	if len(features) == 0 {
+		return nil
+	}
+	if repeats < 1 {
+		repeats = 1
+	}
+
+	baseline := metric(output, estimateAll(model, features))
+
+	shuffled := make([][]float64, len(features))
+	for i, row := range features {
+		shuffled[i] = append([]float64(nil), row...)
+	}
+
+	importances := make([]Importance, len(features[0]))
+	for j := range importances {
+		col := column(features, j)
+		drops := make([]float64, repeats)
+
+		for r := range drops {
+			rng.Shuffle(len(col), func(a, b int) { col[a], col[b] = col[b], col[a] })
+			for i, row := range shuffled {
+				row[j] = col[i]
+			}
+			drops[r] = baseline - metric(output, estimateAll(model, shuffled))
+		}
+
+		for i, row := range shuffled {
+			row[j] = features[i][j]
+		}
+
+		importances[j].Name = columnName(namesOf(model), j)
+		importances[j].Drops = drops
+		importances[j].Mean, importances[j].Std = stat.MeanStdDev(drops, nil)
+	}
+
+	return importances
+}
@@ -0,0 +1,221 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// CovarianceModel is a fitted model with the estimated
+// covariance matrix of its coefficients
+type CovarianceModel interface {
+	Coefficients() []float64
+	CoefficientCovariance() ([][]float64, error)
+}
+
+// CoefficientCovariance returns σ²(XᵀX)⁻¹ of the training
+// rows, σ² being the residual variance
+func (l *LinearRegression) CoefficientCovariance() ([][]float64, error) {
+	if err := l.checkInference(); err != nil {
+		return nil, err
+	}
+	n, p := len(l.Features), len(l.Theta)
+	if n <= p {
+		return nil, fmt.Errorf("ml: covariance needs more rows than the %d thetas", p)
+	}
+	inv, err := gramInverse(l.Features)
+	if err != nil {
+		return nil, err
+	}
+	rss := 2 * float64(n) * squaredLoss(l.Features, l.Output, l.Theta)
+	inv.ScaleSym(rss/float64(n-p), inv)
+	return symToSlices(inv), nil
+}
+
+// CoefficientCovariance returns (XᵀWX)⁻¹ of the training
+// rows, the inverse Fisher information, W being p(1-p)
+// of the probability of every row
+func (l *LogisticRegression) CoefficientCovariance() ([][]float64, error) {
+	if err := l.checkInference(); err != nil {
+		return nil, err
+	}
+	p := len(l.Theta)
+	info := mat.NewSymDense(p, nil)
+	for _, x := range l.Features {
+		prob := l.Probability(x)
+		info.SymRankOne(info, prob*(1-prob), mat.NewVecDense(p, x))
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(info) {
+		return nil, fmt.Errorf("ml: information matrix is singular, features are collinear or classes separable")
+	}
+	inv := mat.NewSymDense(p, nil)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, fmt.Errorf("ml: information matrix is singular: %v", err)
+	}
+	return symToSlices(inv), nil
+}
+
+// checkInference returns an error unless the model
+// is linear and keeps its training rows
+func (l *Linear) checkInference() error {
+	if !l.IsLinear() {
+		return fmt.Errorf("ml: inference needs the linear hypothesis")
+	}
+	if len(l.Theta) == 0 || len(l.Features) == 0 {
+		return fmt.Errorf("ml: inference needs a model fitted on float64 rows")
+	}
+	return nil
+}
+
+// LikelihoodRatioTest compares nested models fitted on
+// the same rows, restricted having a subset of the
+// parameters of full. The statistic is 2(llf-llr) with
+// degrees of freedom of the extra parameters. A small
+// p-value means the extra parameters improve the fit.
+func LikelihoodRatioTest(restricted, full LikelihoodModel) (*StatTest, error) {
+	for _, m := range []LikelihoodModel{restricted, full} {
+		if err := checkLikelihood(m); err != nil {
+			return nil, fmt.Errorf("ml: %v", err)
+		}
+	}
+	if restricted.NumSamples() != full.NumSamples() {
+		return nil, fmt.Errorf("ml: models fitted on %d and %d rows", restricted.NumSamples(), full.NumSamples())
+	}
+	df := full.NumParams() - restricted.NumParams()
+	if df <= 0 {
+		return nil, fmt.Errorf("ml: full model has %d params, not more than %d of restricted", full.NumParams(), restricted.NumParams())
+	}
+	lr := math.Max(2*(full.LogLikelihood()-restricted.LogLikelihood()), 0)
+	return &StatTest{
+		Statistic: lr,
+		DF:        float64(df),
+		PValue:    distuv.ChiSquared{K: float64(df)}.Survival(lr),
+	}, nil
+}
+
+// WaldTest tests the joint restrictions Rθ = values of
+// coefficients θ of model, one row of R per restriction.
+// A small p-value means the restrictions do not hold.
+// E.g. R {{0, 1, -1}} and values {0} tests whether the
+// second and third coefficients are equal.
+func WaldTest(model CovarianceModel, restrictions [][]float64, values []float64) (*StatTest, error) {
+	theta := model.Coefficients()
+	q := len(restrictions)
+	if q == 0 || len(values) != q {
+		return nil, fmt.Errorf("ml: got %d restrictions and %d values", q, len(values))
+	}
+	for _, r := range restrictions {
+		if len(r) != len(theta) {
+			return nil, fmt.Errorf("ml: restriction of %d coefficients for %d thetas", len(r), len(theta))
+		}
+	}
+	cov, err := model.CoefficientCovariance()
+	if err != nil {
+		return nil, err
+	}
+
+	R := denseOf(restrictions)
+	diff := mat.NewVecDense(q, nil)
+	diff.MulVec(R, mat.NewVecDense(len(theta), theta))
+	diff.SubVec(diff, mat.NewVecDense(q, values))
+
+	var rv, middle mat.Dense
+	rv.Mul(R, denseOf(cov))
+	middle.Mul(&rv, R.T())
+	var solved mat.VecDense
+	if err := solved.SolveVec(&middle, diff); err != nil {
+		return nil, fmt.Errorf("ml: restrictions are linearly dependent: %v", err)
+	}
+	w := mat.Dot(diff, &solved)
+	return &StatTest{
+		Statistic: w,
+		DF:        float64(q),
+		PValue:    distuv.ChiSquared{K: float64(q)}.Survival(w),
+	}, nil
+}
+
+// CoefficientTests returns the Wald test of every
+// coefficient of model being zero
+func CoefficientTests(model CovarianceModel) ([]*StatTest, error) {
+	theta := model.Coefficients()
+	cov, err := model.CoefficientCovariance()
+	if err != nil {
+		return nil, err
+	}
+	tests := make([]*StatTest, len(theta))
+	for j, t := range theta {
+		w := t * t / cov[j][j]
+		tests[j] = &StatTest{
+			Statistic: w,
+			DF:        1,
+			PValue:    distuv.ChiSquared{K: 1}.Survival(w),
+		}
+	}
+	return tests, nil
+}
+
+// maximizeLikelihood returns parameters maximizing ll of n
+// rows from init by BFGS, ll fills grad when it is not nil
+func maximizeLikelihood(ll func(x, grad []float64) float64, init []float64, n int) ([]float64, error) {
+	scale := 1 / float64(n)
+	prob := optimize.Problem{
+		Func: func(x []float64) float64 {
+			return -ll(x, nil) * scale
+		},
+		Grad: func(grad, x []float64) {
+			ll(x, grad)
+			floats.Scale(-scale, grad)
+		},
+	}
+	result, err := optimize.Minimize(prob, init, nil, &optimize.BFGS{})
+	if numopt.Stalled(result, err) {
+		err = numopt.CheckStall(result, err)
+	} else if err == nil {
+		err = result.Status.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ml: maximizing likelihood: %w", err)
+	}
+	return result.X, nil
+}
+
+// observedCovariance returns the inverse observed information
+// of ll at x, by central differences of its gradient
+func observedCovariance(ll func(x, grad []float64) float64, x []float64) ([][]float64, error) {
+	k := len(x)
+	hess := mat.NewDense(k, k, nil)
+	plus, minus := make([]float64, k), make([]float64, k)
+	step := append([]float64(nil), x...)
+	for j := range x {
+		h := 1e-5 * math.Max(1, math.Abs(x[j]))
+		step[j] = x[j] + h
+		ll(step, plus)
+		step[j] = x[j] - h
+		ll(step, minus)
+		step[j] = x[j]
+		for i := range x {
+			hess.Set(i, j, -(plus[i]-minus[i])/(2*h))
+		}
+	}
+	info := mat.NewSymDense(k, nil)
+	for i := range x {
+		for j := i; j < k; j++ {
+			info.SetSym(i, j, (hess.At(i, j)+hess.At(j, i))/2)
+		}
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(info) {
+		return nil, fmt.Errorf("ml: information matrix is not positive definite")
+	}
+	inv := mat.NewSymDense(k, nil)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, fmt.Errorf("ml: information matrix is singular: %v", err)
+	}
+	return symToSlices(inv), nil
+}
@@ -0,0 +1,4 @@
+// Package mmap maps files read-only into memory for the
+// packages of the module, reading them where mapping is
+// not supported.
+package mmap
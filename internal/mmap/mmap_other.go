@@ -0,0 +1,15 @@
+//go:build !unix
+
+package mmap
+
+import "os"
+
+// Map reads path into memory where memory
+// mapping is not supported
+func Map(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
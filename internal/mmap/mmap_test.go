@@ -0,0 +1,31 @@
+package mmap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	dir := t.TempDir()
+	for _, want := range []string{"mapped rows", ""} {
+		path := filepath.Join(dir, "data")
+		if err := os.WriteFile(path, []byte(want), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		data, unmap, err := Map(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("mapped %q, want %q", data, want)
+		}
+		if err = unmap(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, _, err := Map(filepath.Join(dir, "missing")); err == nil {
+		t.Error("no error of a missing file")
+	}
+}
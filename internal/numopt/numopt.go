@@ -0,0 +1,42 @@
+// Package numopt holds optimizer helpers shared by the
+// packages of the module.
+package numopt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Converged tells whether a gradient norm is within
+// 1e-6 of the loss, relative to it above 1
+func Converged(gradientNorm, loss float64) bool {
+	return gradientNorm <= 1e-6*math.Max(1, math.Abs(loss))
+}
+
+// Stalled tells whether err is a line search that
+// could not improve the best location of result
+func Stalled(result *optimize.Result, err error) bool {
+	return result != nil && (errors.Is(err, optimize.ErrLinesearcherFailure) || errors.Is(err, optimize.ErrNoProgress))
+}
+
+// CheckStall returns err unless it is a stalled line
+// search. A stall at a converged gradient returns nil,
+// as the best location is the minimum up to precision,
+// otherwise an error wrapping err with the gradient norm.
+func CheckStall(result *optimize.Result, err error) error {
+	if !Stalled(result, err) {
+		return err
+	}
+	if result.Gradient == nil {
+		return fmt.Errorf("%w at loss %.6g, no gradient to check convergence", err, result.F)
+	}
+	norm := floats.Norm(result.Gradient, 2)
+	if Converged(norm, result.F) {
+		return nil
+	}
+	return fmt.Errorf("%w before converging, loss %.6g with gradient norm %.3g", err, result.F, norm)
+}
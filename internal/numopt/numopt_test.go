@@ -0,0 +1,38 @@
+package numopt
+
+import (
+	"errors"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestCheckStall(t *testing.T) {
+	at := func(f float64, grad ...float64) *optimize.Result {
+		return &optimize.Result{Location: optimize.Location{F: f, Gradient: grad}}
+	}
+	other := errors.New("other")
+	cases := []struct {
+		name   string
+		result *optimize.Result
+		err    error
+		ok     bool
+	}{
+		{"no error", at(1, 1), nil, true},
+		{"converged stall", at(2, 1e-7, 1e-7), optimize.ErrLinesearcherFailure, true},
+		{"relative to loss", at(1e4, 1e-3), optimize.ErrNoProgress, true},
+		{"stall far from minimum", at(2, 0.5), optimize.ErrLinesearcherFailure, false},
+		{"stall without gradient", at(2), optimize.ErrNoProgress, false},
+		{"stall without result", nil, optimize.ErrNoProgress, false},
+		{"other error", at(2, 0), other, false},
+	}
+	for _, c := range cases {
+		err := CheckStall(c.result, c.err)
+		if (err == nil) != c.ok {
+			t.Errorf("%s: got %v", c.name, err)
+		}
+		if err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%s: %v does not wrap %v", c.name, err, c.err)
+		}
+	}
+}
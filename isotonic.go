@@ -0,0 +1,181 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// IsotonicRegression fits the monotone piecewise linear
+// function of one feature closest to output in least
+// squares, by pool adjacent violators
+type IsotonicRegression struct {
+	// Column is the feature the output is monotone in
+	Column int
+	// Decreasing fits a non-increasing function
+	// instead of a non-decreasing one
+	Decreasing bool
+
+	// X and Y are knots of the fitted function,
+	// it is constant outside of X
+	X []float64
+	Y []float64
+}
+
+// NewIsotonicRegression returns new pointer of
+// non-decreasing IsotonicRegression of the first feature
+func NewIsotonicRegression() *IsotonicRegression {
+	return &IsotonicRegression{}
+}
+
+// Fit trains the model on features and output
+func (r *IsotonicRegression) Fit(features [][]float64, output []float64) error {
+	return r.FitWeighted(features, output, nil)
+}
+
+// FitWeighted is Fit with a non-negative weight of every
+// row, a row of weight 2 counts as two equal rows. Nil
+// weights every row 1.
+func (r *IsotonicRegression) FitWeighted(features [][]float64, output, weights []float64) error {
+	if len(features) == 0 || len(features) != len(output) {
+		return fmt.Errorf("ml: got %d rows of features and %d outputs", len(features), len(output))
+	}
+	if weights != nil {
+		if len(weights) != len(output) {
+			return fmt.Errorf("ml: got %d weights of %d rows", len(weights), len(output))
+		}
+		total := 0.0
+		for i, w := range weights {
+			if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				return fmt.Errorf("ml: weight %v of row %d is not a finite non-negative number", w, i)
+			}
+			total += w
+		}
+		if total == 0 {
+			return fmt.Errorf("ml: weights sum to zero")
+		}
+	}
+	for i, x := range features {
+		if r.Column < 0 || r.Column >= len(x) {
+			return fmt.Errorf("ml: row %d has no column %d", i, r.Column)
+		}
+	}
+
+	x := column(features, r.Column)
+	if !r.Decreasing {
+		r.X, r.Y = pav(x, output, weights)
+		return nil
+	}
+
+	// a non-increasing fit of y is the
+	// negated non-decreasing fit of -y
+	negated := make([]float64, len(output))
+	for i, y := range output {
+		negated[i] = -y
+	}
+	r.X, r.Y = pav(x, negated, weights)
+	for i := range r.Y {
+		r.Y[i] = -r.Y[i]
+	}
+
+	return nil
+}
+
+// Estimate returns fitted value of X
+func (r *IsotonicRegression) Estimate(X []float64) float64 {
+	return interpolate(r.X, r.Y, X[r.Column])
+}
+
+// pav sorts x and fits the non-decreasing weighted least
+// squares sequence to y, every pool takes the weighted mean
+// of its rows. Rows of zero weight are left out. It returns
+// the bounds of every pooled block and their fitted value.
+func pav(x, y, weights []float64) ([]float64, []float64) {
+	idx := make([]int, len(x))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return x[idx[a]] < x[idx[b]] })
+
+	type block struct {
+		x      float64
+		sum    float64
+		weight float64
+	}
+
+	// rows with equal x start in the same block
+	var blocks []block
+	for _, i := range idx {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		if w == 0 {
+			continue
+		}
+		if n := len(blocks); n > 0 && blocks[n-1].x == x[i] {
+			blocks[n-1].sum += w * y[i]
+			blocks[n-1].weight += w
+			continue
+		}
+		blocks = append(blocks, block{x: x[i], sum: w * y[i], weight: w})
+	}
+
+	// pooled holds merged blocks with the
+	// index of their first distinct x
+	type pool struct {
+		start  int
+		sum    float64
+		weight float64
+	}
+	var pools []pool
+	for b, bl := range blocks {
+		pools = append(pools, pool{start: b, sum: bl.sum, weight: bl.weight})
+		for n := len(pools); n > 1 && pools[n-2].sum/pools[n-2].weight >= pools[n-1].sum/pools[n-1].weight; n = len(pools) {
+			pools[n-2].sum += pools[n-1].sum
+			pools[n-2].weight += pools[n-1].weight
+			pools = pools[:n-1]
+		}
+	}
+
+	// the first and last x of every pool are enough
+	// to interpolate the step function
+	var xs, ys []float64
+	for p, pl := range pools {
+		last := len(blocks) - 1
+		if p+1 < len(pools) {
+			last = pools[p+1].start - 1
+		}
+
+		v := pl.sum / pl.weight
+		xs = append(xs, blocks[pl.start].x)
+		ys = append(ys, v)
+		if last != pl.start {
+			xs = append(xs, blocks[last].x)
+			ys = append(ys, v)
+		}
+	}
+
+	return xs, ys
+}
+
+// interpolate returns the piecewise linear function through
+// sorted points x, y at v, constant outside of x
+func interpolate(x, y []float64, v float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+
+	i := sort.SearchFloat64s(x, v)
+	switch {
+	case i == 0:
+		return y[0]
+	case i == len(x):
+		return y[len(y)-1]
+	case x[i] == v:
+		return y[i]
+	}
+
+	t := (v - x[i-1]) / (x[i] - x[i-1])
+	return y[i-1] + t*(y[i]-y[i-1])
+}
@@ -0,0 +1,74 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func sameFloats(a, b []float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-12 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestIsotonicRegression(t *testing.T) {
+	features := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+	output := []float64{1, 3, 2, 4, 3, 5}
+
+	// 3, 2 and 4, 3 are pooled into their means
+	r := NewIsotonicRegression()
+	if err := r.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := estimateAll(r, features), []float64{1, 2.5, 2.5, 3.5, 3.5, 5}; !sameFloats(got, want) {
+		t.Errorf("fitted %v, want %v", got, want)
+	}
+
+	r.Decreasing = true
+	if err := r.Fit(features, []float64{5, 3, 4, 2, 3, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := estimateAll(r, features), []float64{5, 3.5, 3.5, 2.5, 2.5, 1}; !sameFloats(got, want) {
+		t.Errorf("decreasing fitted %v, want %v", got, want)
+	}
+}
+
+func TestIsotonicRegressionWeighted(t *testing.T) {
+	features := [][]float64{{1}, {2}, {3}}
+	output := []float64{1, 3, 2}
+
+	// 3 of weight 1 and 2 of weight 3 pool into
+	// (3 + 6) / 4, as if the last row was there 3 times
+	r := NewIsotonicRegression()
+	if err := r.FitWeighted(features, output, []float64{1, 1, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := estimateAll(r, features), []float64{1, 2.25, 2.25}; !sameFloats(got, want) {
+		t.Errorf("weighted fit %v, want %v", got, want)
+	}
+
+	repeated := NewIsotonicRegression()
+	if err := repeated.Fit(append(features, []float64{3}, []float64{3}), append(output, 2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := estimateAll(repeated, features), estimateAll(r, features); !sameFloats(got, want) {
+		t.Errorf("fit of repeated rows %v, want %v of weights", got, want)
+	}
+
+	// a row of zero weight does not count
+	if err := r.FitWeighted(features, output, []float64{1, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := estimateAll(r, features), []float64{1, 1.5, 2}; !sameFloats(got, want) {
+		t.Errorf("fit without row 2 %v, want %v", got, want)
+	}
+
+	for _, weights := range [][]float64{{1, 1}, {1, -1, 1}, {0, 0, 0}, {1, math.NaN(), 1}} {
+		if err := r.FitWeighted(features, output, weights); err == nil {
+			t.Errorf("no error of weights %v", weights)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// KalmanFilter is a linear Gaussian state-space model
+//
+//	x_t = F x_{t-1} + w_t, w_t ~ N(0, Q)
+//	y_t = H x_t + v_t,     v_t ~ N(0, R)
+//
+// with initial state x_0 ~ N(InitialState, InitialCovariance).
+// It filters observations online with Update, or in batch
+// with Filter and Smooth.
+type KalmanFilter struct {
+	Transition        [][]float64
+	Observation       [][]float64
+	ProcessNoise      [][]float64
+	ObservationNoise  [][]float64
+	InitialState      []float64
+	InitialCovariance [][]float64
+
+	// State and Covariance are the current filtered
+	// estimate of Update
+	State      []float64
+	Covariance [][]float64
+}
+
+// KalmanResult holds filtered or smoothed states
+// of every observation
+type KalmanResult struct {
+	States      [][]float64
+	Covariances [][][]float64
+	// LogLikelihood of the observations, set by Filter
+	LogLikelihood float64
+}
+
+// NewKalmanFilter returns new pointer of KalmanFilter
+// starting from a zero state with identity covariance
+func NewKalmanFilter(transition, observation, processNoise, observationNoise [][]float64) *KalmanFilter {
+	n := len(transition)
+	k := &KalmanFilter{
+		Transition:        transition,
+		Observation:       observation,
+		ProcessNoise:      processNoise,
+		ObservationNoise:  observationNoise,
+		InitialState:      make([]float64, n),
+		InitialCovariance: slicesOf(identity(n)),
+	}
+	k.Reset()
+	return k
+}
+
+// Reset sets the online estimate back to the initial state
+func (k *KalmanFilter) Reset() {
+	k.State = append([]float64(nil), k.InitialState...)
+	k.Covariance = slicesOf(denseOf(k.InitialCovariance))
+}
+
+// Update predicts the next state, corrects it with
+// observation y and returns the filtered state. An
+// observation with NaN values only predicts.
+func (k *KalmanFilter) Update(y []float64) []float64 {
+	m := k.matrices()
+	x := mat.NewVecDense(len(k.State), append([]float64(nil), k.State...))
+	p := denseOf(k.Covariance)
+
+	x, p = m.predict(x, p)
+	x, p, _, _ = m.update(x, p, y)
+
+	k.State = x.RawVector().Data
+	k.Covariance = slicesOf(p)
+	return append([]float64(nil), k.State...)
+}
+
+// Filter returns filtered states of every observation
+// and their log-likelihood
+func (k *KalmanFilter) Filter(observations [][]float64) *KalmanResult {
+	f := k.matrices().filter(observations)
+	r := &KalmanResult{LogLikelihood: f.loglik}
+	for t := range observations {
+		r.States = append(r.States, append([]float64(nil), f.x[t+1].RawVector().Data...))
+		r.Covariances = append(r.Covariances, slicesOf(f.p[t+1]))
+	}
+	return r
+}
+
+// Smooth returns Rauch-Tung-Striebel smoothed states of
+// every observation, using all observations
+func (k *KalmanFilter) Smooth(observations [][]float64) *KalmanResult {
+	m := k.matrices()
+	f := m.filter(observations)
+	s := m.smooth(f)
+
+	r := &KalmanResult{LogLikelihood: f.loglik}
+	for t := range observations {
+		r.States = append(r.States, append([]float64(nil), s.x[t+1].RawVector().Data...))
+		r.Covariances = append(r.Covariances, slicesOf(s.p[t+1]))
+	}
+	return r
+}
+
+// EM estimates Transition, ProcessNoise, ObservationNoise
+// and InitialState from observations by expectation
+// maximization, keeping Observation and InitialCovariance.
+// It returns the log-likelihood of every iteration, which
+// never decreases.
+func (k *KalmanFilter) EM(observations [][]float64, iterations int) ([]float64, error) {
+	if len(observations) < 2 {
+		return nil, fmt.Errorf("ml: EM needs at least 2 observations")
+	}
+
+	var logliks []float64
+	for it := 0; it < iterations; it++ {
+		m := k.matrices()
+		f := m.filter(observations)
+		s := m.smooth(f)
+		logliks = append(logliks, f.loglik)
+
+		n := m.f.RawMatrix().Rows
+		s11 := mat.NewDense(n, n, nil)
+		s10 := mat.NewDense(n, n, nil)
+		s00 := mat.NewDense(n, n, nil)
+		var outer mat.Dense
+
+		for t := 1; t < len(s.x); t++ {
+			outer.Outer(1, s.x[t], s.x[t])
+			s11.Add(s11, &outer)
+			s11.Add(s11, s.p[t])
+
+			outer.Outer(1, s.x[t], s.x[t-1])
+			s10.Add(s10, &outer)
+			s10.Add(s10, s.lag[t])
+
+			outer.Outer(1, s.x[t-1], s.x[t-1])
+			s00.Add(s00, &outer)
+			s00.Add(s00, s.p[t-1])
+		}
+
+		var s00inv, f2 mat.Dense
+		if err := s00inv.Inverse(s00); err != nil {
+			return logliks, fmt.Errorf("ml: EM iteration %d: %v", it, err)
+		}
+		f2.Mul(s10, &s00inv)
+
+		var q mat.Dense
+		q.Mul(&f2, s10.T())
+		q.Sub(s11, &q)
+		q.Scale(1/float64(len(s.x)-1), &q)
+
+		o := m.h.RawMatrix().Rows
+		r := mat.NewDense(o, o, nil)
+		observed := 0
+		for t, y := range observations {
+			if hasNaN(y) {
+				continue
+			}
+			observed++
+
+			var hx mat.VecDense
+			hx.MulVec(m.h, s.x[t+1])
+			res := mat.NewVecDense(o, append([]float64(nil), y...))
+			res.SubVec(res, &hx)
+			outer.Outer(1, res, res)
+			r.Add(r, &outer)
+
+			var hp, hph mat.Dense
+			hp.Mul(m.h, s.p[t+1])
+			hph.Mul(&hp, m.h.T())
+			r.Add(r, &hph)
+		}
+		if observed == 0 {
+			return logliks, fmt.Errorf("ml: EM needs observations without NaN")
+		}
+		r.Scale(1/float64(observed), r)
+
+		k.Transition = slicesOf(&f2)
+		k.ProcessNoise = slicesOf(symmetrize(&q))
+		k.ObservationNoise = slicesOf(symmetrize(r))
+		k.InitialState = append([]float64(nil), s.x[0].RawVector().Data...)
+	}
+
+	k.Reset()
+	return logliks, nil
+}
+
+// kalmanMatrices holds model matrices as gonum matrices
+type kalmanMatrices struct {
+	f, h, q, r *mat.Dense
+	x0         *mat.VecDense
+	p0         *mat.Dense
+}
+
+// kalmanPass holds states and covariances of every step,
+// index 0 being the initial state. Predicted values are
+// kept for smoothing.
+type kalmanPass struct {
+	x, xPred []*mat.VecDense
+	p, pPred []*mat.Dense
+	lag      []*mat.Dense
+	loglik   float64
+}
+
+func (k *KalmanFilter) matrices() *kalmanMatrices {
+	return &kalmanMatrices{
+		f:  denseOf(k.Transition),
+		h:  denseOf(k.Observation),
+		q:  denseOf(k.ProcessNoise),
+		r:  denseOf(k.ObservationNoise),
+		x0: mat.NewVecDense(len(k.InitialState), append([]float64(nil), k.InitialState...)),
+		p0: denseOf(k.InitialCovariance),
+	}
+}
+
+func (m *kalmanMatrices) predict(x *mat.VecDense, p *mat.Dense) (*mat.VecDense, *mat.Dense) {
+	var xp mat.VecDense
+	xp.MulVec(m.f, x)
+
+	var fp, pp mat.Dense
+	fp.Mul(m.f, p)
+	pp.Mul(&fp, m.f.T())
+	pp.Add(&pp, m.q)
+
+	return &xp, &pp
+}
+
+// update corrects a predicted state with y, returning the
+// log-likelihood of y and whether y was observed
+func (m *kalmanMatrices) update(x *mat.VecDense, p *mat.Dense, y []float64) (*mat.VecDense, *mat.Dense, float64, bool) {
+	if hasNaN(y) {
+		return x, p, 0, false
+	}
+
+	var hx mat.VecDense
+	hx.MulVec(m.h, x)
+	innovation := mat.NewVecDense(len(y), append([]float64(nil), y...))
+	innovation.SubVec(innovation, &hx)
+
+	var hp, s mat.Dense
+	hp.Mul(m.h, p)
+	s.Mul(&hp, m.h.T())
+	s.Add(&s, m.r)
+
+	var sinv mat.Dense
+	if err := sinv.Inverse(&s); err != nil {
+		return x, p, 0, false
+	}
+
+	// gain K = P H' S^-1
+	var pht, gain mat.Dense
+	pht.Mul(p, m.h.T())
+	gain.Mul(&pht, &sinv)
+
+	var correction mat.VecDense
+	correction.MulVec(&gain, innovation)
+	var xu mat.VecDense
+	xu.AddVec(x, &correction)
+
+	var kh, pu mat.Dense
+	kh.Mul(&gain, m.h)
+	pu.Mul(&kh, p)
+	pu.Sub(p, &pu)
+
+	var sinvInnov mat.VecDense
+	sinvInnov.MulVec(&sinv, innovation)
+	loglik := -0.5 * (float64(len(y))*math.Log(2*math.Pi) + math.Log(mat.Det(&s)) + mat.Dot(innovation, &sinvInnov))
+
+	return &xu, symmetrize(&pu), loglik, true
+}
+
+func (m *kalmanMatrices) filter(observations [][]float64) *kalmanPass {
+	f := &kalmanPass{
+		x:     []*mat.VecDense{m.x0},
+		p:     []*mat.Dense{m.p0},
+		xPred: []*mat.VecDense{m.x0},
+		pPred: []*mat.Dense{m.p0},
+	}
+
+	x, p := m.x0, m.p0
+	for _, y := range observations {
+		xp, pp := m.predict(x, p)
+		var loglik float64
+		x, p, loglik, _ = m.update(xp, pp, y)
+		f.loglik += loglik
+
+		f.xPred = append(f.xPred, xp)
+		f.pPred = append(f.pPred, pp)
+		f.x = append(f.x, x)
+		f.p = append(f.p, p)
+	}
+
+	return f
+}
+
+// smooth runs the Rauch-Tung-Striebel smoother and the
+// lag-one covariances Cov(x_t, x_{t-1}) used by EM
+func (m *kalmanMatrices) smooth(f *kalmanPass) *kalmanPass {
+	n := len(f.x)
+	s := &kalmanPass{
+		x:   make([]*mat.VecDense, n),
+		p:   make([]*mat.Dense, n),
+		lag: make([]*mat.Dense, n),
+	}
+	s.x[n-1] = f.x[n-1]
+	s.p[n-1] = f.p[n-1]
+
+	for t := n - 2; t >= 0; t-- {
+		// J = P_t F' Ppred_{t+1}^-1
+		var ppinv, pft, j mat.Dense
+		if err := ppinv.Inverse(f.pPred[t+1]); err != nil {
+			s.x[t], s.p[t] = f.x[t], f.p[t]
+			s.lag[t+1] = mat.NewDense(f.p[t].RawMatrix().Rows, f.p[t].RawMatrix().Cols, nil)
+			continue
+		}
+		pft.Mul(f.p[t], m.f.T())
+		j.Mul(&pft, &ppinv)
+
+		var dx, corr mat.VecDense
+		dx.SubVec(s.x[t+1], f.xPred[t+1])
+		corr.MulVec(&j, &dx)
+		var xs mat.VecDense
+		xs.AddVec(f.x[t], &corr)
+
+		var dp, jdp, jdpj mat.Dense
+		dp.Sub(s.p[t+1], f.pPred[t+1])
+		jdp.Mul(&j, &dp)
+		jdpj.Mul(&jdp, j.T())
+		var ps mat.Dense
+		ps.Add(f.p[t], &jdpj)
+
+		var lag mat.Dense
+		lag.Mul(s.p[t+1], j.T())
+
+		s.x[t] = &xs
+		s.p[t] = symmetrize(&ps)
+		s.lag[t+1] = &lag
+	}
+
+	return s
+}
+
+func identity(n int) *mat.Dense {
+	d := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		d.Set(i, i, 1)
+	}
+	return d
+}
+
+func symmetrize(d *mat.Dense) *mat.Dense {
+	var t mat.Dense
+	t.Add(d, d.T())
+	t.Scale(0.5, &t)
+	return &t
+}
+
+// slicesOf copies a matrix into rows of slices
+func slicesOf(m mat.Matrix) [][]float64 {
+	r, c := m.Dims()
+	out := make([][]float64, r)
+	for i := range out {
+		out[i] = make([]float64, c)
+		for j := range out[i] {
+			out[i][j] = m.At(i, j)
+		}
+	}
+	return out
+}
+
+func hasNaN(x []float64) bool {
+	for _, v := range x {
+		if math.IsNaN(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKalmanSteadyState(t *testing.T) {
+	// a random walk x_t = x_{t-1} + w observed as y = x + v
+	// reaches the covariance of the scalar Riccati equation
+	// P = q + Pr/(P+r) of the prediction
+	q, r := 0.5, 2.0
+	k := NewKalmanFilter([][]float64{{1}}, [][]float64{{1}}, [][]float64{{q}}, [][]float64{{r}})
+	for i := 0; i < 100; i++ {
+		k.Update([]float64{1})
+	}
+
+	predicted := (q + math.Sqrt(q*q+4*q*r)) / 2
+	filtered := predicted * r / (predicted + r)
+	if got := k.Covariance[0][0]; math.Abs(got-filtered) > 1e-9 {
+		t.Errorf("covariance %v, want %v", got, filtered)
+	}
+
+	// the state then moves by the gain of the residual
+	gain := predicted / (predicted + r)
+	before := k.State[0]
+	if got, want := k.Update([]float64{5})[0], before+gain*(5-before); math.Abs(got-want) > 1e-9 {
+		t.Errorf("state %v, want %v", got, want)
+	}
+
+	// a missing observation only predicts
+	if k.Update([]float64{math.NaN()}); math.Abs(k.Covariance[0][0]-filtered-q) > 1e-9 {
+		t.Errorf("covariance %v after a missing observation, want %v", k.Covariance[0][0], filtered+q)
+	}
+}
+
+func TestKalmanSmoothAndEM(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	observations := make([][]float64, 300)
+	x := 0.0
+	for i := range observations {
+		x = 0.8*x + rnd.NormFloat64()
+		observations[i] = []float64{x + 0.5*rnd.NormFloat64()}
+	}
+
+	k := NewKalmanFilter([][]float64{{0.8}}, [][]float64{{1}}, [][]float64{{1}}, [][]float64{{0.25}})
+	filtered, smoothed := k.Filter(observations), k.Smooth(observations)
+	last := len(observations) - 1
+	if filtered.States[last][0] != smoothed.States[last][0] {
+		t.Errorf("smoothed last state %v, want filtered %v", smoothed.States[last][0], filtered.States[last][0])
+	}
+	for i := range observations {
+		if smoothed.Covariances[i][0][0] > filtered.Covariances[i][0][0]+1e-12 {
+			t.Fatalf("smoothed covariance %v above filtered %v", smoothed.Covariances[i][0][0], filtered.Covariances[i][0][0])
+		}
+	}
+
+	k = NewKalmanFilter([][]float64{{0.5}}, [][]float64{{1}}, [][]float64{{0.5}}, [][]float64{{1}})
+	logliks, err := k.EM(observations, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(logliks); i++ {
+		if logliks[i] < logliks[i-1]-1e-6 {
+			t.Fatalf("log-likelihood %v after %v", logliks[i], logliks[i-1])
+		}
+	}
+	if got := k.Transition[0][0]; math.Abs(got-0.8) > 0.15 {
+		t.Errorf("estimated transition %v, want about 0.8", got)
+	}
+}
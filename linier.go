@@ -1,9 +1,15 @@
 package ml
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
 	"math"
+	"sync"
+	"time"
 
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/optimize"
 )
 
@@ -11,18 +17,63 @@ import (
 // this could be used for either Linear regression
 // and logistic regression
 type Linear struct {
+	// FeatureNames names the feature columns,
+	// it is optional
+	FeatureNames []string
 	Features     [][]float64
 	Theta        []float64
 	Output       []float64
 	LearningRate float64
 	Hypothesis   LinearHypothesis
 	Result       *optimize.Result
+	// Setting is used by Fit, nil uses
+	// gonum default settings
+	Setting *LinearSetting
+	// Metrics, when set, receives iterations
+	// and loss of the optimizer
+	Metrics Metrics
+	// Logger receives training progress and warnings,
+	// nil uses the logger of SetLogger
+	Logger Logger
+	// Checkpoint, when set, receives a Checkpoint of
+	// thetas every CheckpointEvery iterations so a
+	// preempted job can continue with ResumeFrom
+	Checkpoint      io.Writer
+	CheckpointEvery int
+	// WarmStart makes Fit start from current thetas,
+	// e.g. of a loaded model, instead of zeros
+	WarmStart bool
+	// Loss, when set, replaces the squared loss of
+	// LinearRegression or the log loss of
+	// LogisticRegression. It is not saved.
+	Loss Loss
+
+	metricLabels map[string]string
+	resume       *Checkpoint
+	// optionErr is the error of options of the
+	// constructor, returned by Fit
+	optionErr error
+	// features of Fit32, kept as float32
+	features32 [][]float32
+	output32   []float32
+	// convergence of the last training
+	convergence *Convergence
+	// samples and loss of the last fit, which give
+	// the log-likelihood without its training rows
+	samples int
+	loss    float64
+	// mu makes Fit wait for running estimates and
+	// clones, and them for a running Fit
+	mu sync.RWMutex
 }
 
 // LogisticRegression inherits Liner
 type LogisticRegression struct {
 	Linear
 	TrueDegree float64
+	// trueDegreeSet tells a TrueDegree of zero was set,
+	// zero of a struct literal uses 0.5
+	trueDegreeSet bool
 }
 
 // LinearRegression inherits Liner
@@ -37,93 +88,349 @@ type LinearHypothesis func(X, theta []float64) float64
 type LinearSetting struct {
 	MajorIteration int
 	Threshod       float64
+	// MaxDuration limits wall-clock time of training,
+	// zero is no limit. Training that runs out of time
+	// keeps the best thetas so far and Result.Status
+	// is optimize.RuntimeLimit.
+	MaxDuration time.Duration
+	// NewMethod returns a method replacing BFGS, e.g. a
+	// GeneticAlgorithm or SimulatedAnnealing for non-convex
+	// hypotheses. Methods keep state while minimizing, so
+	// every Fit asks for a new one and clones fitted
+	// concurrently do not share it. It is not saved.
+	NewMethod func() optimize.Method
+	// Schedule, when set, scales the Step of SGD and
+	// Adam by epoch. It is not saved.
+	Schedule Schedule
+	// ClipNorm, when positive, clips the norm of
+	// gradients of SGD and Adam steps
+	ClipNorm float64
+	// DivergenceRatio stops SGD with a DivergenceError
+	// when a batch loss exceeds the first one by this
+	// ratio, zero is 1e6
+	DivergenceRatio float64
+	// Constraints, when set, bound thetas
+	Constraints *Constraints
+}
+
+// linearHypothesis is the default hypothesis θ·X,
+// with the SIMD dot product of gonum
+func linearHypothesis(X, theta []float64) float64 {
+	return floats.Dot(X, theta[:len(X)])
 }
 
 func sigmoid(z float64) float64 {
 	return 1 / (1 + math.Exp(-z))
 }
 
-// LinearDefaultSetting returns default
-// setting for Linear regression
-func LinearDefaultSetting() *LinearSetting {
-	return &LinearSetting{
-		MajorIteration: 1e5,
-		Threshod:       1e-12,
+// prepare sets features and output of a fit and
+// resets thetas to zero, unless resuming or WarmStart
+func (l *Linear) prepare(features [][]float64, output []float64) error {
+	if l.optionErr != nil {
+		return l.optionErr
+	}
+	n, err := checkRows(features, output)
+	if err != nil {
+		return err
 	}
+	if err = checkNames(l.FeatureNames, n); err != nil {
+		return err
+	}
+	if bad := nonFinite(features, output); bad > 0 {
+		l.logger().Warn("ml: training data has non-finite values", "count", bad, "rows", len(features))
+	}
+
+	l.Features = features
+	l.Output = output
+	l.features32, l.output32 = nil, nil
+	l.initTheta(n)
+
+	return nil
 }
 
-/***********************
- * LOGISTIC REGRESSION *
- ***********************/
+// prepare32 is prepare of float32 features
+func (l *Linear) prepare32(features [][]float32, output []float32) error {
+	if l.optionErr != nil {
+		return l.optionErr
+	}
+	if !l.IsLinear() {
+		return fmt.Errorf("ml: cannot fit float32 features with a custom hypothesis")
+	}
+	n, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if err = checkNames(l.FeatureNames, n); err != nil {
+		return err
+	}
+	if bad := nonFinite(features, output); bad > 0 {
+		l.logger().Warn("ml: training data has non-finite values", "count", bad, "rows", len(features))
+	}
 
-// NewLogisticRegression return new pointer of
-// LogisticRegression struct with default Linear
-// hypothesis ax+b
-func NewLogisticRegression() *LogisticRegression {
-	lr := &LogisticRegression{}
+	l.Features, l.Output = nil, nil
+	l.features32 = features
+	l.output32 = output
+	l.initTheta(n)
+
+	return nil
+}
 
-	lr.Hypothesis = func(X, theta []float64) float64 {
-		hypothesis := 0.0
-		for key, x := range X {
-			hypothesis += theta[key] * x
+// initTheta sets n thetas to zero, unless resuming
+// or WarmStart
+func (l *Linear) initTheta(n int) {
+	if l.resume != nil {
+		if len(l.Theta) == n {
+			// thetas of ResumeFrom
+			return
 		}
-		return hypothesis
+		l.logger().Warn("ml: checkpoint does not match features, starting from zero", "checkpoint", len(l.Theta), "features", n)
+		l.resume = nil
 	}
-	lr.LearningRate = 1
-	lr.TrueDegree = 0.5
-
-	return lr
+	if l.WarmStart && l.Theta != nil {
+		if len(l.Theta) == n {
+			l.Theta = append([]float64(nil), l.Theta...)
+			return
+		}
+		l.logger().Warn("ml: warm start thetas do not match features, starting from zero", "thetas", len(l.Theta), "features", n)
+	}
+	l.Theta = make([]float64, n)
 }
 
-func (l *LogisticRegression) calculateCost(X []float64, y float64) float64 {
-	h := sigmoid(l.Hypothesis(X, l.Theta))
-	return -y*math.Log(h) - (1-y)*math.Log(1-h)
+func (l *Linear) logger() Logger {
+	if l.Logger != nil {
+		return l.Logger
+	}
+	return packageLogger()
 }
 
-// Minimize start training of hypothesis
-// Minimize start training of hypothesis
-func (l *LogisticRegression) Minimize(setting *LinearSetting) *optimize.Result {
+// minimize runs BFGS on prob starting from
+// current thetas and stores the result
+func (l *Linear) minimize(ctx context.Context, prob optimize.Problem, setting *LinearSetting) (result *optimize.Result, err error) {
+	_, span := StartSpan(ctx, "ml.Minimize", "rows", l.rows(), "features", len(l.Theta))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			span.SetAttributes("iterations", result.MajorIterations, "loss", result.F)
+		}
+		span.End()
+	}()
+
+	l.samples, l.loss = 0, 0
 	var s *optimize.Settings
 
 	if setting != nil {
 		s = &optimize.Settings{
 			GradientThreshold: setting.Threshod,
 			MajorIterations:   setting.MajorIteration,
+			Runtime:           setting.MaxDuration,
 			Converger: &optimize.FunctionConverge{
 				Absolute:   1e-12,
 				Iterations: 1e5,
 			},
 		}
+		if setting.NewMethod != nil {
+			// gradient free methods improve rarely, they
+			// stop after 100 iterations without progress
+			s.Converger = &optimize.FunctionConverge{
+				Absolute:   1e-12,
+				Iterations: 100,
+			}
+		}
 	}
 
-	prob := optimize.Problem{
-		Func: l.Func,
-		Grad: l.Grad,
+	var rec recorders
+	if l.Metrics != nil {
+		rec = append(rec, metricsRecorder{metrics: l.Metrics, labels: l.metricLabels})
+	}
+	offset := 0
+	if l.resume != nil {
+		offset = l.resume.Iteration
+		l.resume = nil
+	}
+	if l.Checkpoint != nil {
+		rec = append(rec, newCheckpointRecorder(l.Checkpoint, l.CheckpointEvery, offset))
+	}
+	conv := newConvergence()
+	l.convergence = conv
+	rec = append(rec, convergenceRecorder{c: conv})
+	if s == nil {
+		s = &optimize.Settings{}
+	}
+	s.Recorder = rec
+	limit := 0
+	if setting != nil {
+		limit = setting.MajorIteration
 	}
 
-	meth := &optimize.BFGS{}
-
-	result, err := optimize.Minimize(prob, l.Theta, s, meth)
+	var meth optimize.Method = &optimize.BFGS{}
+	custom := setting != nil && setting.NewMethod != nil
+	if custom {
+		if meth = setting.NewMethod(); meth == nil {
+			return nil, fmt.Errorf("ml: NewMethod returned nil")
+		}
+	}
+	if setting != nil && setting.Constraints != nil {
+		c := setting.Constraints
+		if custom {
+			return nil, fmt.Errorf("ml: constraints cannot be used with a custom method")
+		}
+		if err = c.check(len(l.Theta)); err != nil {
+			return nil, err
+		}
+		meth = &projectedGradient{project: c.project}
+	}
+	log := l.logger()
+	log.Debug("ml: training started", "rows", l.rows(), "features", len(l.Theta))
+
+	var w watchdog
+	var stalled error
+	result, err = optimize.Minimize(w.watch(prob), l.Theta, s, meth)
+	if d := w.diverged(result, err); d != nil {
+		conv.finish(nil, d, limit)
+		l.Theta = d.Theta
+		log.Error("ml: training diverged", "iteration", d.Iteration, "loss", d.Loss)
+		return nil, d
+	}
+	if numopt.Stalled(result, err) {
+		// the line search cannot improve the best location
+		// any further, which happens close to the minimum
+		// when features are badly scaled, and is only
+		// accepted when the gradient has converged
+		if cerr := numopt.CheckStall(result, err); cerr != nil {
+			err = fmt.Errorf("ml: training stopped: %w", cerr)
+		} else {
+			log.Warn("ml: optimizer stopped early, keeping best thetas", "reason", err, "loss", result.F, "iterations", result.MajorIterations)
+			stalled, err = err, nil
+		}
+	} else if err == nil && result.Status == optimize.RuntimeLimit {
+		log.Warn("ml: training ran out of time, keeping best thetas", "loss", result.F, "iterations", result.MajorIterations, "runtime", result.Runtime)
+	} else if err == nil && custom && (result.Status == optimize.IterationLimit || result.Status == optimize.FunctionEvaluationLimit) {
+		// limits are the usual way to stop
+		// methods that never converge
+	} else if err == nil {
+		err = result.Status.Err()
+	}
+	conv.finish(result, err, limit)
+	if stalled != nil {
+		conv.stall(stalled)
+	}
+	if warning := conv.Warning(); warning != "" && err == nil && stalled == nil && !custom {
+		log.Warn(warning)
+	}
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	if err = result.Status.Err(); err != nil {
-		log.Fatal(err)
+	l.Theta = result.X
+	l.Result = result
+	l.samples, l.loss = l.rows(), result.F
+	log.Info("ml: training finished", "loss", result.F, "iterations", result.MajorIterations, "status", result.Status, "runtime", result.Runtime)
+
+	return result, nil
+}
+
+// rows returns number of training rows
+func (l *Linear) rows() int {
+	if l.features32 != nil {
+		return len(l.features32)
 	}
+	return len(l.Features)
+}
 
-	l.Result = result
-	l.Theta = result.X
+// Coefficients returns thetas of the model
+func (l *Linear) Coefficients() []float64 {
+	return l.Theta
+}
 
-	return result
+// LinearDefaultSetting returns default
+// setting for Linear regression
+func LinearDefaultSetting() *LinearSetting {
+	return &LinearSetting{
+		MajorIteration: 1e5,
+		Threshod:       1e-12,
+	}
+}
+
+/***********************
+ * LOGISTIC REGRESSION *
+ ***********************/
+
+// NewLogisticRegression return new pointer of
+// LogisticRegression struct with default Linear
+// hypothesis ax+b, configured by opts
+func NewLogisticRegression(opts ...Option) *LogisticRegression {
+	lr := &LogisticRegression{}
+
+	lr.Hypothesis = linearHypothesis
+	lr.LearningRate = 1
+	lr.TrueDegree = 0.5
+	lr.trueDegreeSet = true
+	lr.optionErr = lr.Apply(opts...)
+
+	return lr
+}
+
+// calculateCost returns -y*log(h) - (1-y)*log(1-h) of
+// h = sigmoid(z), written as log(1+e^z) - y*z so it stays
+// finite when the sigmoid saturates
+func (l *LogisticRegression) calculateCost(X, theta []float64, y float64) float64 {
+	z := l.Hypothesis(X, theta)
+	return math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z))) - y*z
+}
+
+// Minimize start training of hypothesis, it returns
+// the optimizer result or the error of a failed training
+func (l *LogisticRegression) Minimize(setting *LinearSetting) (*optimize.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.minimize(context.Background(), l.problem(), setting)
+}
+
+// Fit trains the model on features and output
+// starting from zero thetas, or current thetas
+// with WarmStart, using Setting
+func (l *LogisticRegression) Fit(features [][]float64, output []float64) error {
+	return l.FitContext(context.Background(), features, output)
+}
+
+// FitContext is Fit with a context for tracing
+func (l *LogisticRegression) FitContext(ctx context.Context, features [][]float64, output []float64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.prepare(features, output); err != nil {
+		return err
+	}
+	for _, y := range output {
+		if y != 0 && y != 1 {
+			l.logger().Warn("ml: logistic regression output should be 0 or 1", "value", y)
+			break
+		}
+	}
+
+	_, err := l.minimize(ctx, l.problem(), l.Setting)
+	return err
+}
+
+func (l *LogisticRegression) problem() optimize.Problem {
+	return optimize.Problem{
+		Func: l.Func,
+		Grad: l.Grad,
+	}
 }
 
 // Func returns cost of theta
 func (l *LogisticRegression) Func(theta []float64) float64 {
+	if l.Loss != nil {
+		return l.customLoss(sigmoidLink, theta)
+	}
+	if l.features32 != nil {
+		return logisticLoss(l.features32, l.output32, theta)
+	}
 	m := float64(len(l.Features))
 	sum := 0.0
 	for i, X := range l.Features {
-		sum += l.calculateCost(X, l.Output[i])
+		sum += l.calculateCost(X, theta, l.Output[i])
 	}
 
 	return (1 / m) * sum
@@ -131,23 +438,60 @@ func (l *LogisticRegression) Func(theta []float64) float64 {
 
 // Grad updates initil thetas to minimum
 func (l *LogisticRegression) Grad(grad, theta []float64) {
-	m := float64(len(l.Features))
-	for j := range theta {
-		sum := 0.0
-
-		for i, x := range l.Features {
-			sum += (sigmoid(l.Hypothesis(x, theta)) - l.Output[i]) * x[j]
-		}
-		grad[j] = l.LearningRate / m * sum
+	if l.Loss != nil {
+		l.customGrad(sigmoidLink, grad, theta)
+		return
+	}
+	if l.features32 != nil {
+		logisticGrad(grad, l.features32, l.output32, theta, l.LearningRate)
+		return
 	}
+	for j := range grad {
+		grad[j] = 0
+	}
+	// one hypothesis per row, accumulated into every
+	// column, rather than one per row and column
+	for i, x := range l.Features {
+		d := sigmoid(l.Hypothesis(x, theta)) - l.Output[i]
+		floats.AddScaled(grad[:len(x)], d, x)
+	}
+	floats.Scale(l.LearningRate/float64(len(l.Features)), grad)
 }
 
 // Predict start training of hypothesis
 func (l *LogisticRegression) Predict(X []float64) bool {
-	if l.TrueDegree == 0 {
-		return sigmoid(l.Hypothesis(X, l.Theta)) >= 0.5
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sigmoid(l.Hypothesis(X, l.Theta)) >= l.threshold()
+}
+
+// DecisionThreshold returns the probability from which
+// Predict returns true, TrueDegree or 0.5 when it is zero
+// and was never set by NewLogisticRegression, TuneThreshold
+// or Load
+func (l *LogisticRegression) DecisionThreshold() float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.threshold()
+}
+
+func (l *LogisticRegression) threshold() float64 {
+	if l.TrueDegree == 0 && !l.trueDegreeSet {
+		return 0.5
 	}
-	return sigmoid(l.Hypothesis(X, l.Theta)) >= l.TrueDegree
+	return l.TrueDegree
+}
+
+// Probability returns probability of X being true
+func (l *LogisticRegression) Probability(X []float64) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sigmoid(l.Hypothesis(X, l.Theta))
+}
+
+// Estimate returns probability of X being true
+func (l *LogisticRegression) Estimate(X []float64) float64 {
+	return l.Probability(X)
 }
 
 /***********************
@@ -156,69 +500,67 @@ func (l *LogisticRegression) Predict(X []float64) bool {
 
 // NewLinearRegression return new pointer of
 // LogisticRegression struct with default Linear
-// hypothesis ax+b
-func NewLinearRegression() *LinearRegression {
+// hypothesis ax+b, configured by opts
+func NewLinearRegression(opts ...Option) *LinearRegression {
 	lr := &LinearRegression{}
 
-	lr.Hypothesis = func(X, theta []float64) float64 {
-		hypothesis := 0.0
-		for key, x := range X {
-			hypothesis += theta[key] * x
-		}
-		return hypothesis
-	}
+	lr.Hypothesis = linearHypothesis
 	lr.LearningRate = 1
+	lr.optionErr = lr.Apply(opts...)
 
 	return lr
 }
 
-func (l *LinearRegression) calculateCost(x []float64, y float64) float64 {
-	cost := l.Hypothesis(x, l.Theta) - y
-	return math.Pow(cost, 2)
+func (l *LinearRegression) calculateCost(x, theta []float64, y float64) float64 {
+	cost := l.Hypothesis(x, theta) - y
+	return cost * cost
 }
 
-// Minimize start training of hypothesis
-func (l *LinearRegression) Minimize(setting *LinearSetting) *optimize.Result {
-	var s *optimize.Settings
+// Minimize start training of hypothesis, it returns
+// the optimizer result or the error of a failed training
+func (l *LinearRegression) Minimize(setting *LinearSetting) (*optimize.Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.minimize(context.Background(), l.problem(), setting)
+}
 
-	if setting != nil {
-		s = &optimize.Settings{
-			GradientThreshold: setting.Threshod,
-			MajorIterations:   setting.MajorIteration,
-			Converger: &optimize.FunctionConverge{
-				Absolute:   1e-12,
-				Iterations: 1e5,
-			},
-		}
-	}
+// Fit trains the model on features and output
+// starting from zero thetas, or current thetas
+// with WarmStart, using Setting
+func (l *LinearRegression) Fit(features [][]float64, output []float64) error {
+	return l.FitContext(context.Background(), features, output)
+}
 
-	prob := optimize.Problem{
-		Func: l.Func,
-		Grad: l.Grad,
+// FitContext is Fit with a context for tracing
+func (l *LinearRegression) FitContext(ctx context.Context, features [][]float64, output []float64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.prepare(features, output); err != nil {
+		return err
 	}
 
-	meth := &optimize.BFGS{}
-
-	result, err := optimize.Minimize(prob, l.Theta, s, meth)
-	if err != nil {
-		log.Fatal(err)
-	}
+	_, err := l.minimize(ctx, l.problem(), l.Setting)
+	return err
+}
 
-	if err = result.Status.Err(); err != nil {
-		log.Fatal(err)
+func (l *LinearRegression) problem() optimize.Problem {
+	return optimize.Problem{
+		Func: l.Func,
+		Grad: l.Grad,
 	}
-
-	l.Theta = result.X
-	l.Result = result
-
-	return result
 }
 
 // Func return cost
 func (l *LinearRegression) Func(theta []float64) float64 {
+	if l.Loss != nil {
+		return l.customLoss(identityLink, theta)
+	}
+	if l.features32 != nil {
+		return squaredLoss(l.features32, l.output32, theta)
+	}
 	sum := 0.0
 	for i, x := range l.Features {
-		sum += l.calculateCost(x, l.Output[i])
+		sum += l.calculateCost(x, theta, l.Output[i])
 	}
 	m := float64(len(l.Features))
 
@@ -227,18 +569,32 @@ func (l *LinearRegression) Func(theta []float64) float64 {
 
 // Grad updates initil thetas to minimum
 func (l *LinearRegression) Grad(grad, theta []float64) {
-	m := float64(len(l.Features))
-	for j := range theta {
-		sum := 0.0
-
-		for i, x := range l.Features {
-			sum += (l.Hypothesis(x, theta) - l.Output[i]) * x[j]
-		}
-		grad[j] = l.LearningRate / m * sum
+	if l.Loss != nil {
+		l.customGrad(identityLink, grad, theta)
+		return
+	}
+	if l.features32 != nil {
+		squaredGrad(grad, l.features32, l.output32, theta, l.LearningRate)
+		return
+	}
+	for j := range grad {
+		grad[j] = 0
 	}
+	for i, x := range l.Features {
+		d := l.Hypothesis(x, theta) - l.Output[i]
+		floats.AddScaled(grad[:len(x)], d, x)
+	}
+	floats.Scale(l.LearningRate/float64(len(l.Features)), grad)
 }
 
 // Predict start training of hypothesis
 func (l *LinearRegression) Predict(X []float64) float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 	return l.Hypothesis(X, l.Theta)
 }
+
+// Estimate returns predicted value of X
+func (l *LinearRegression) Estimate(X []float64) float64 {
+	return l.Predict(X)
+}
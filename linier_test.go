@@ -0,0 +1,127 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// benchData returns n rows of m features, the first a
+// bias, and a linear output with noise
+func benchData(n, m int) ([][]float64, []float64, []float64) {
+	r := rand.New(rand.NewSource(1))
+	theta := make([]float64, m)
+	for j := range theta {
+		theta[j] = r.NormFloat64()
+	}
+	features := make([][]float64, n)
+	output := make([]float64, n)
+	for i := range features {
+		x := make([]float64, m)
+		x[0] = 1
+		for j := 1; j < m; j++ {
+			x[j] = r.NormFloat64()
+		}
+		features[i] = x
+		for j, v := range x {
+			output[i] += theta[j] * v
+		}
+		output[i] += 0.1 * r.NormFloat64()
+	}
+	return features, output, theta
+}
+
+func TestFuncGradAllocateNothing(t *testing.T) {
+	features, output, theta := benchData(200, 10)
+	logistic := make([]float64, len(output))
+	for i, y := range output {
+		if y > 0 {
+			logistic[i] = 1
+		}
+	}
+	linear := NewLinearRegression()
+	linear.Features, linear.Output = features, output
+	classifier := NewLogisticRegression()
+	classifier.Features, classifier.Output = features, logistic
+
+	grad := make([]float64, len(theta))
+	for name, f := range map[string]func(){
+		"LinearRegression.Func":   func() { linear.Func(theta) },
+		"LinearRegression.Grad":   func() { linear.Grad(grad, theta) },
+		"LogisticRegression.Func": func() { classifier.Func(theta) },
+		"LogisticRegression.Grad": func() { classifier.Grad(grad, theta) },
+	} {
+		if allocs := testing.AllocsPerRun(10, f); allocs != 0 {
+			t.Errorf("%s allocates %v times", name, allocs)
+		}
+	}
+}
+
+func TestMinimizeReturnsError(t *testing.T) {
+	features, output := lineData(10, 1)
+	output[3] = math.NaN()
+
+	linear := NewLinearRegression()
+	linear.Features, linear.Output, linear.Theta = features, output, []float64{0, 0}
+	if result, err := linear.Minimize(nil); err == nil || result != nil {
+		t.Errorf("got %v, %v of a NaN output, want an error", result, err)
+	}
+
+	classifier := NewLogisticRegression()
+	classifier.Features, classifier.Output, classifier.Theta = features, output, []float64{0, 0}
+	if _, err := classifier.Minimize(nil); err == nil {
+		t.Error("no error of a NaN output")
+	}
+}
+
+func BenchmarkLinearRegressionFunc(b *testing.B) {
+	features, output, theta := benchData(2000, 50)
+	l := NewLinearRegression()
+	l.Features, l.Output = features, output
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Func(theta)
+	}
+}
+
+func BenchmarkLinearRegressionGrad(b *testing.B) {
+	features, output, theta := benchData(2000, 50)
+	l := NewLinearRegression()
+	l.Features, l.Output = features, output
+	grad := make([]float64, len(theta))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Grad(grad, theta)
+	}
+}
+
+func BenchmarkLogisticRegressionGrad(b *testing.B) {
+	features, output, theta := benchData(2000, 50)
+	for i, y := range output {
+		output[i] = 0
+		if y > 0 {
+			output[i] = 1
+		}
+	}
+	l := NewLogisticRegression()
+	l.Features, l.Output = features, output
+	grad := make([]float64, len(theta))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Grad(grad, theta)
+	}
+}
+
+func BenchmarkLinearRegressionFit(b *testing.B) {
+	features, output, _ := benchData(2000, 50)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := NewLinearRegression().Fit(features, output); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ml
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Logger receives training progress, convergence warnings
+// and data quality notices as a message with key value
+// pairs. *slog.Logger is a Logger, its handler level sets
+// the verbosity.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+var defaultLogger = struct {
+	sync.RWMutex
+	Logger
+}{Logger: nopLogger{}}
+
+// SetLogger sets the logger of models without their own
+// Logger, nil discards logs. It discards logs by default.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	defaultLogger.Lock()
+	defaultLogger.Logger = l
+	defaultLogger.Unlock()
+}
+
+func packageLogger() Logger {
+	defaultLogger.RLock()
+	defer defaultLogger.RUnlock()
+	return defaultLogger.Logger
+}
+
+// NewLogger returns a Logger writing text records at
+// level and above to w, e.g. NewLogger(os.Stderr,
+// slog.LevelInfo)
+func NewLogger(w io.Writer, level slog.Level) Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// SlogLogger returns a Logger writing to handler
+func SlogLogger(handler slog.Handler) Logger {
+	return slog.New(handler)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
@@ -0,0 +1,205 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Loss is a training loss of a prediction against its
+// output. Predictions are hypotheses of LinearRegression
+// and probabilities of LogisticRegression.
+type Loss interface {
+	// Value returns the loss of prediction
+	Value(prediction, output float64) float64
+	// Derivative returns the derivative of Value
+	// with respect to prediction
+	Derivative(prediction, output float64) float64
+}
+
+// SquaredLoss is (p-y)²/2, the default loss
+// of LinearRegression
+type SquaredLoss struct{}
+
+// Value returns the loss of prediction
+func (SquaredLoss) Value(p, y float64) float64 {
+	return (p - y) * (p - y) / 2
+}
+
+// Derivative returns the derivative of Value
+func (SquaredLoss) Derivative(p, y float64) float64 {
+	return p - y
+}
+
+// AbsoluteLoss is |p-y|, fitting the median
+type AbsoluteLoss struct{}
+
+// Value returns the loss of prediction
+func (AbsoluteLoss) Value(p, y float64) float64 {
+	return math.Abs(p - y)
+}
+
+// Derivative returns the derivative of Value
+func (AbsoluteLoss) Derivative(p, y float64) float64 {
+	switch {
+	case p > y:
+		return 1
+	case p < y:
+		return -1
+	}
+	return 0
+}
+
+// HuberLoss is squared within Delta of the output
+// and absolute beyond it, so outliers weigh less
+type HuberLoss struct {
+	Delta float64
+}
+
+// Value returns the loss of prediction
+func (h HuberLoss) Value(p, y float64) float64 {
+	d := math.Abs(p - y)
+	if d <= h.Delta {
+		return d * d / 2
+	}
+	return h.Delta * (d - h.Delta/2)
+}
+
+// Derivative returns the derivative of Value
+func (h HuberLoss) Derivative(p, y float64) float64 {
+	return math.Max(-h.Delta, math.Min(p-y, h.Delta))
+}
+
+// QuantileLoss is the pinball loss of Quantile in
+// (0, 1), e.g. 0.9 fits the 90th percentile
+type QuantileLoss struct {
+	Quantile float64
+}
+
+// Value returns the loss of prediction
+func (q QuantileLoss) Value(p, y float64) float64 {
+	if y >= p {
+		return q.Quantile * (y - p)
+	}
+	return (1 - q.Quantile) * (p - y)
+}
+
+// Derivative returns the derivative of Value
+func (q QuantileLoss) Derivative(p, y float64) float64 {
+	if y > p {
+		return -q.Quantile
+	}
+	if y < p {
+		return 1 - q.Quantile
+	}
+	return 0
+}
+
+// AsymmetricLoss is a squared loss weighted by Under
+// when prediction is below output and by Over when it
+// is above, e.g. Under 3 and Over 1 penalize running
+// out of stock 3 times more than overstocking
+type AsymmetricLoss struct {
+	Under float64
+	Over  float64
+}
+
+// Value returns the loss of prediction
+func (a AsymmetricLoss) Value(p, y float64) float64 {
+	return a.weight(p, y) * (p - y) * (p - y) / 2
+}
+
+// Derivative returns the derivative of Value
+func (a AsymmetricLoss) Derivative(p, y float64) float64 {
+	return a.weight(p, y) * (p - y)
+}
+
+func (a AsymmetricLoss) weight(p, y float64) float64 {
+	if p < y {
+		return a.Under
+	}
+	return a.Over
+}
+
+// LogLoss is the cross entropy of probability p,
+// the default loss of LogisticRegression
+type LogLoss struct{}
+
+// Value returns the loss of prediction
+func (LogLoss) Value(p, y float64) float64 {
+	p = math.Min(math.Max(p, 1e-15), 1-1e-15)
+	return -y*math.Log(p) - (1-y)*math.Log(1-p)
+}
+
+// Derivative returns the derivative of Value
+func (LogLoss) Derivative(p, y float64) float64 {
+	p = math.Min(math.Max(p, 1e-15), 1-1e-15)
+	return (p - y) / (p * (1 - p))
+}
+
+// WithLoss trains the model with loss instead
+// of its default loss. It is not saved.
+func WithLoss(loss Loss) Option {
+	return func(o *options) error {
+		if loss == nil {
+			return fmt.Errorf("ml: nil loss")
+		}
+		o.linear.Loss = loss
+		return nil
+	}
+}
+
+// link maps a hypothesis z to a prediction p,
+// returning p and dp/dz
+type link func(z float64) (float64, float64)
+
+func identityLink(z float64) (float64, float64) {
+	return z, 1
+}
+
+func sigmoidLink(z float64) (float64, float64) {
+	p := sigmoid(z)
+	return p, p * (1 - p)
+}
+
+// lossOf returns mean loss of predictions of
+// hypotheses h of features
+func lossOf[T Float](loss Loss, link link, features [][]T, output []T, h func(x []T) float64) float64 {
+	sum := 0.0
+	for i, x := range features {
+		p, _ := link(h(x))
+		sum += loss.Value(p, float64(output[i]))
+	}
+	return sum / float64(len(features))
+}
+
+// lossGrad sets grad to the gradient of lossOf,
+// scaled by rate like the default losses
+func lossGrad[T Float](grad []float64, loss Loss, link link, features [][]T, output []T, h func(x []T) float64, rate float64) {
+	for j := range grad {
+		grad[j] = 0
+	}
+	for i, x := range features {
+		p, dp := link(h(x))
+		addScaled(grad, loss.Derivative(p, float64(output[i]))*dp, x)
+	}
+	floats.Scale(rate/float64(len(features)), grad)
+}
+
+// customLoss returns Func of the Loss of the model
+func (l *Linear) customLoss(link link, theta []float64) float64 {
+	if l.features32 != nil {
+		return lossOf(l.Loss, link, l.features32, l.output32, func(x []float32) float64 { return dotOf(x, theta) })
+	}
+	return lossOf(l.Loss, link, l.Features, l.Output, func(x []float64) float64 { return l.Hypothesis(x, theta) })
+}
+
+// customGrad returns Grad of the Loss of the model
+func (l *Linear) customGrad(link link, grad, theta []float64) {
+	if l.features32 != nil {
+		lossGrad(grad, l.Loss, link, l.features32, l.output32, func(x []float32) float64 { return dotOf(x, theta) }, l.LearningRate)
+		return
+	}
+	lossGrad(grad, l.Loss, link, l.Features, l.Output, func(x []float64) float64 { return l.Hypothesis(x, theta) }, l.LearningRate)
+}
@@ -0,0 +1,121 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLossValues(t *testing.T) {
+	cases := []struct {
+		name        string
+		loss        Loss
+		p, y        float64
+		value, grad float64
+	}{
+		{"squared", SquaredLoss{}, 3, 1, 2, 2},
+		{"absolute", AbsoluteLoss{}, 1, 3, 2, -1},
+		{"huber within delta", HuberLoss{Delta: 1}, 1.5, 1, 0.125, 0.5},
+		{"huber beyond delta", HuberLoss{Delta: 1}, 4, 1, 2.5, 1},
+		{"quantile under", QuantileLoss{Quantile: 0.9}, 1, 3, 1.8, -0.9},
+		{"quantile over", QuantileLoss{Quantile: 0.9}, 3, 1, 0.2, 0.1},
+		{"asymmetric under", AsymmetricLoss{Under: 3, Over: 1}, 1, 3, 6, -6},
+		{"asymmetric over", AsymmetricLoss{Under: 3, Over: 1}, 3, 1, 2, 2},
+		{"log of true", LogLoss{}, 0.8, 1, -math.Log(0.8), -1 / 0.8},
+		{"log of false", LogLoss{}, 0.8, 0, -math.Log(0.2), 1 / 0.2},
+	}
+	for _, c := range cases {
+		if got := c.loss.Value(c.p, c.y); math.Abs(got-c.value) > 1e-12 {
+			t.Errorf("%s: Value(%v, %v) = %v, want %v", c.name, c.p, c.y, got, c.value)
+		}
+		if got := c.loss.Derivative(c.p, c.y); math.Abs(got-c.grad) > 1e-12 {
+			t.Errorf("%s: Derivative(%v, %v) = %v, want %v", c.name, c.p, c.y, got, c.grad)
+		}
+	}
+	if got := (LogLoss{}).Value(0, 1); math.IsInf(got, 0) {
+		t.Error("log loss of a certain wrong prediction is infinite")
+	}
+}
+
+func TestLossDerivativeNumeric(t *testing.T) {
+	losses := map[string]Loss{
+		"squared":    SquaredLoss{},
+		"absolute":   AbsoluteLoss{},
+		"huber":      HuberLoss{Delta: 0.5},
+		"quantile":   QuantileLoss{Quantile: 0.25},
+		"asymmetric": AsymmetricLoss{Under: 3, Over: 0.5},
+		"log":        LogLoss{},
+	}
+	// points away from kinks of absolute,
+	// huber and quantile losses
+	points := [][2]float64{{0.2, 1}, {0.7, 0}, {0.45, 0.4}, {0.9, 1}}
+	const h = 1e-6
+	for name, loss := range losses {
+		for _, pt := range points {
+			p, y := pt[0], pt[1]
+			want := (loss.Value(p+h, y) - loss.Value(p-h, y)) / (2 * h)
+			if got := loss.Derivative(p, y); math.Abs(got-want) > 1e-5*math.Max(1, math.Abs(want)) {
+				t.Errorf("%s: Derivative(%v, %v) = %v, numeric %v", name, p, y, got, want)
+			}
+		}
+	}
+}
+
+func TestLossGradNumeric(t *testing.T) {
+	features := [][]float64{{1, 0.5}, {1, -1}, {1, 2}, {1, 0.1}, {1, -0.3}}
+	output := []float64{1, 0, 1, 0, 1}
+	theta := []float64{0.3, -0.2}
+
+	check := func(name string, f func([]float64) float64, grad func(grad, theta []float64), rate float64) {
+		got := make([]float64, len(theta))
+		grad(got, theta)
+		for j := range theta {
+			x := append([]float64(nil), theta...)
+			x[j] += 1e-6
+			up := f(x)
+			x[j] -= 2e-6
+			want := rate * (up - f(x)) / 2e-6
+			if math.Abs(got[j]-want) > 1e-6 {
+				t.Errorf("%s: gradient %d is %v, numeric %v", name, j, got[j], want)
+			}
+		}
+	}
+
+	linear := NewLinearRegression(WithLoss(AsymmetricLoss{Under: 3, Over: 1}))
+	linear.Features, linear.Output = features, output
+	check("linear asymmetric", linear.Func, linear.Grad, linear.LearningRate)
+
+	logistic := NewLogisticRegression(WithLoss(HuberLoss{Delta: 0.2}))
+	logistic.Features, logistic.Output = features, output
+	check("logistic huber", logistic.Func, logistic.Grad, logistic.LearningRate)
+}
+
+func TestLossFitsStatistic(t *testing.T) {
+	// an intercept only model fits the statistic of
+	// output minimizing its loss
+	var (
+		features [][]float64
+		output   []float64
+	)
+	for i := 0; i < 100; i++ {
+		features = append(features, []float64{1})
+		output = append(output, float64(i%2))
+	}
+	cases := []struct {
+		name string
+		loss Loss
+		want float64
+	}{
+		{"squared fits the mean", SquaredLoss{}, 0.5},
+		// 3(1-p) = p
+		{"asymmetric of under 3 and over 1", AsymmetricLoss{Under: 3, Over: 1}, 0.75},
+	}
+	for _, c := range cases {
+		model := NewLinearRegression(WithLoss(c.loss))
+		if err := model.Fit(features, output); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := model.Estimate([]float64{1}); math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("%s: fitted %v, want %v", c.name, got, c.want)
+		}
+	}
+}
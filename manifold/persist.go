@@ -0,0 +1,17 @@
+package manifold
+
+import "github.com/maxrafiandy/ml"
+
+func init() {
+	ml.RegisterModel("manifold.UMAP", &UMAP{})
+}
+
+// GobEncode encodes the model without Rand
+func (u *UMAP) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(u)
+}
+
+// GobDecode decodes a model of GobEncode
+func (u *UMAP) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(u, data)
+}
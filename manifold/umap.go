@@ -0,0 +1,398 @@
+// Package manifold embeds rows of features into few
+// dimensions for visualization and downstream models.
+package manifold
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// UMAP is Uniform Manifold Approximation and Projection
+// (McInnes, Healy and Melville, 2018): a fuzzy graph of
+// nearest Neighbors of rows is laid out in Components
+// dimensions by SGD, pulling neighbors together and
+// pushing random pairs apart. Unlike t-SNE it embeds new
+// rows by Transform against the fitted layout. It is an
+// ml.Transformer. Neighbors are found exactly, which is
+// quadratic in rows.
+type UMAP struct {
+	// Components of the embedding, defaults to 2
+	Components int
+	// Neighbors defaults to 15, more keeps
+	// more global structure
+	Neighbors int
+	// MinDist is the least distance of embedded
+	// points, defaults to 0.1, and Spread their
+	// scale, defaults to 1
+	MinDist float64
+	Spread  float64
+	// Epochs of SGD, defaults to 500 below
+	// 10000 rows and 200 otherwise
+	Epochs int
+	// LearningRate defaults to 1
+	LearningRate float64
+	// NegativeSamples per edge, defaults to 5
+	NegativeSamples int
+	// Rand draws the initial layout and negative
+	// samples, nil uses the global source
+	Rand *rand.Rand
+
+	// A and B of the curve 1/(1+a·d^2b) of
+	// similarity of embedded points, fitted
+	// of MinDist and Spread
+	A, B float64
+	// Features are the training rows
+	// and Embedding their layout
+	Features  [][]float64
+	Embedding [][]float64
+}
+
+// NewUMAP returns new pointer of UMAP of components
+func NewUMAP(components int) *UMAP {
+	return &UMAP{
+		Components:      components,
+		Neighbors:       15,
+		MinDist:         0.1,
+		Spread:          1,
+		LearningRate:    1,
+		NegativeSamples: 5,
+	}
+}
+
+func (u *UMAP) defaults(n int) {
+	if u.Components <= 0 {
+		u.Components = 2
+	}
+	if u.Neighbors <= 0 {
+		u.Neighbors = 15
+	}
+	if u.Spread <= 0 {
+		u.Spread = 1
+	}
+	if u.MinDist < 0 {
+		u.MinDist = 0.1
+	}
+	if u.Epochs <= 0 {
+		u.Epochs = 500
+		if n > 10000 {
+			u.Epochs = 200
+		}
+	}
+	if u.LearningRate <= 0 {
+		u.LearningRate = 1
+	}
+	if u.NegativeSamples <= 0 {
+		u.NegativeSamples = 5
+	}
+}
+
+// edge of the fuzzy graph
+type edge struct {
+	i, j   int
+	weight float64
+}
+
+// Fit embeds rows of features, output is ignored
+func (u *UMAP) Fit(features [][]float64, output []float64) error {
+	n := len(features)
+	if n < 3 {
+		return fmt.Errorf("manifold: UMAP needs at least 3 rows, got %d", n)
+	}
+	for i, row := range features {
+		if len(row) != len(features[0]) {
+			return fmt.Errorf("manifold: row %d has %d columns, expected %d", i, len(row), len(features[0]))
+		}
+	}
+	u.defaults(n)
+	u.A, u.B = fitCurve(u.MinDist, u.Spread)
+	u.Features = features
+	k := min(u.Neighbors, n-1)
+	rng := ml.RandOf(u.Rand)
+
+	// directed memberships of neighbors, then
+	// their fuzzy union w+w'-ww'
+	weights := make([]map[int]float64, n)
+	for i, x := range features {
+		idx, dist := neighbors(x, features, k, i)
+		weights[i] = map[int]float64{}
+		for m, w := range memberships(dist) {
+			weights[i][idx[m]] = w
+		}
+	}
+	var edges []edge
+	for i := range weights {
+		for j, w := range weights[i] {
+			v := weights[j][i]
+			if v > 0 && j < i {
+				// added from j
+				continue
+			}
+			edges = append(edges, edge{i, j, w + v - w*v})
+		}
+	}
+	sort.Slice(edges, func(a, b int) bool {
+		if edges[a].i != edges[b].i {
+			return edges[a].i < edges[b].i
+		}
+		return edges[a].j < edges[b].j
+	})
+
+	u.Embedding = u.initialize(edges, n, rng)
+	u.optimize(u.Embedding, u.Embedding, edges, u.Epochs, true, rng)
+	return nil
+}
+
+// initialize returns a spectral layout of the graph of
+// edges for up to 2000 rows, else a random one, scaled
+// to coordinates within ±10
+func (u *UMAP) initialize(edges []edge, n int, rng *rand.Rand) [][]float64 {
+	y := make([][]float64, n)
+	for i := range y {
+		y[i] = make([]float64, u.Components)
+	}
+	spectral := false
+	if n <= 2000 && u.Components+1 < n {
+		degree := make([]float64, n)
+		for _, e := range edges {
+			degree[e.i] += e.weight
+			degree[e.j] += e.weight
+		}
+		sym := mat.NewSymDense(n, nil)
+		for _, e := range edges {
+			sym.SetSym(e.i, e.j, e.weight/math.Sqrt(degree[e.i]*degree[e.j]))
+		}
+		var eig mat.EigenSym
+		if eig.Factorize(sym, true) {
+			var vectors mat.Dense
+			eig.VectorsTo(&vectors)
+			// skip the leading trivial eigenvector
+			for i := range y {
+				for c := range y[i] {
+					y[i][c] = vectors.At(i, n-2-c)
+				}
+			}
+			spectral = true
+		}
+	}
+	if !spectral {
+		for i := range y {
+			for c := range y[i] {
+				y[i][c] = rng.Float64()*20 - 10
+			}
+		}
+		return y
+	}
+	scale := 0.0
+	for i := range y {
+		scale = math.Max(scale, floats.Norm(y[i], math.Inf(1)))
+	}
+	for i := range y {
+		floats.Scale(10/scale, y[i])
+		for c := range y[i] {
+			y[i][c] += rng.NormFloat64() * 1e-4
+		}
+	}
+	return y
+}
+
+// optimize lays out head rows by SGD on edges from head to
+// tail rows. When move is set, tail rows move as well.
+func (u *UMAP) optimize(head, tail [][]float64, edges []edge, epochs int, move bool, rng *rand.Rand) {
+	if len(edges) == 0 {
+		return
+	}
+	a, b := u.A, u.B
+	maxWeight := 0.0
+	for _, e := range edges {
+		maxWeight = math.Max(maxWeight, e.weight)
+	}
+	// edges are sampled every epochs[e] epochs
+	// by their weight, negatives every negative[e]
+	every := make([]float64, len(edges))
+	next := make([]float64, len(edges))
+	everyNeg := make([]float64, len(edges))
+	nextNeg := make([]float64, len(edges))
+	for m, e := range edges {
+		every[m] = maxWeight / e.weight
+		next[m] = every[m]
+		everyNeg[m] = every[m] / float64(u.NegativeSamples)
+		nextNeg[m] = everyNeg[m]
+	}
+	clip := func(v float64) float64 {
+		return math.Max(-4, math.Min(4, v))
+	}
+	dims := u.Components
+	for epoch := 1; epoch <= epochs; epoch++ {
+		alpha := u.LearningRate * (1 - float64(epoch-1)/float64(epochs))
+		for m, e := range edges {
+			if next[m] > float64(epoch) {
+				continue
+			}
+			yi, yj := head[e.i], tail[e.j]
+			d2 := sqDistance(yi, yj)
+			coef := 0.0
+			if d2 > 0 {
+				coef = -2 * a * b * math.Pow(d2, b-1) / (1 + a*math.Pow(d2, b))
+			}
+			for c := 0; c < dims; c++ {
+				g := clip(coef * (yi[c] - yj[c]))
+				yi[c] += g * alpha
+				if move {
+					yj[c] -= g * alpha
+				}
+			}
+			next[m] += every[m]
+
+			negatives := int((float64(epoch) - nextNeg[m]) / everyNeg[m])
+			for s := 0; s < negatives; s++ {
+				yk := tail[rng.Intn(len(tail))]
+				d2 := sqDistance(yi, yk)
+				if d2 == 0 {
+					continue
+				}
+				coef := 2 * b / ((0.001 + d2) * (1 + a*math.Pow(d2, b)))
+				for c := 0; c < dims; c++ {
+					yi[c] += clip(coef*(yi[c]-yk[c])) * alpha
+				}
+			}
+			nextNeg[m] += float64(negatives) * everyNeg[m]
+		}
+	}
+}
+
+// Transform embeds rows of features into the fitted
+// layout, starting at the weighted mean of their
+// neighbors' embedding and optimizing only the new rows
+func (u *UMAP) Transform(features [][]float64) [][]float64 {
+	n := len(u.Features)
+	k := min(u.Neighbors, n)
+	rng := ml.RandOf(u.Rand)
+	y := make([][]float64, len(features))
+	var edges []edge
+	for i, x := range features {
+		idx, dist := neighbors(x, u.Features, k, -1)
+		w := memberships(dist)
+		y[i] = make([]float64, u.Components)
+		total := floats.Sum(w)
+		for m, j := range idx {
+			floats.AddScaled(y[i], w[m]/total, u.Embedding[j])
+			edges = append(edges, edge{i, j, w[m]})
+		}
+	}
+	epochs := u.Epochs / 3
+	if epochs < 1 {
+		epochs = 1
+	}
+	u.optimize(y, u.Embedding, edges, epochs, false, rng)
+	return y
+}
+
+// neighbors returns indices and distances of the k rows of
+// features nearest x, nearest first, skipping row self
+func neighbors(x []float64, features [][]float64, k, self int) ([]int, []float64) {
+	idx := make([]int, 0, len(features))
+	dist := make([]float64, len(features))
+	for j, y := range features {
+		if j != self {
+			idx = append(idx, j)
+			dist[j] = floats.Distance(x, y, 2)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return dist[idx[a]] < dist[idx[b]] })
+	idx = idx[:min(k, len(idx))]
+	out := make([]float64, len(idx))
+	for m, j := range idx {
+		out[m] = dist[j]
+	}
+	return idx, out
+}
+
+// memberships returns exp(-(d-ρ)/σ) of ascending distances
+// dist, ρ being the nearest and σ found so they sum to
+// log2 of their number
+func memberships(dist []float64) []float64 {
+	w := make([]float64, len(dist))
+	if len(dist) == 0 {
+		return w
+	}
+	rho := dist[0]
+	target := math.Log2(float64(len(dist)))
+	sum := func(sigma float64) float64 {
+		s := 0.0
+		for _, d := range dist {
+			s += math.Exp(-math.Max(0, d-rho) / sigma)
+		}
+		return s
+	}
+	lo, hi, sigma := 0.0, math.Inf(1), 1.0
+	for it := 0; it < 64; it++ {
+		s := sum(sigma)
+		if math.Abs(s-target) < 1e-5 {
+			break
+		}
+		if s > target {
+			hi = sigma
+			sigma = (lo + hi) / 2
+		} else {
+			lo = sigma
+			if math.IsInf(hi, 1) {
+				sigma *= 2
+			} else {
+				sigma = (lo + hi) / 2
+			}
+		}
+	}
+	// keep sigma from vanishing of near duplicates
+	mean := floats.Sum(dist) / float64(len(dist))
+	sigma = math.Max(sigma, 1e-3*mean)
+	for m, d := range dist {
+		w[m] = math.Exp(-math.Max(0, d-rho) / sigma)
+	}
+	return w
+}
+
+// fitCurve returns a and b of 1/(1+a·d^2b) closest by least
+// squares to 1 below minDist and exp(-(d-minDist)/spread)
+// above it, for d up to 3 spread
+func fitCurve(minDist, spread float64) (float64, float64) {
+	var xs, ys []float64
+	for m := 1; m <= 300; m++ {
+		d := 3 * spread * float64(m) / 300
+		xs = append(xs, d)
+		if d < minDist {
+			ys = append(ys, 1)
+		} else {
+			ys = append(ys, math.Exp(-(d-minDist)/spread))
+		}
+	}
+	loss := func(p []float64) float64 {
+		a, b := math.Exp(p[0]), math.Exp(p[1])
+		s := 0.0
+		for m, d := range xs {
+			r := 1/(1+a*math.Pow(d, 2*b)) - ys[m]
+			s += r * r
+		}
+		return s
+	}
+	result, err := optimize.Minimize(optimize.Problem{Func: loss}, []float64{math.Log(1.6), math.Log(0.9)}, nil, &optimize.NelderMead{})
+	if err != nil && result == nil {
+		return 1.577, 0.895
+	}
+	return math.Exp(result.X[0]), math.Exp(result.X[1])
+}
+
+func sqDistance(a, b []float64) float64 {
+	s := 0.0
+	for c := range a {
+		d := a[c] - b[c]
+		s += d * d
+	}
+	return s
+}
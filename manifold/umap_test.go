@@ -0,0 +1,66 @@
+package manifold
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestCurveAndMemberships(t *testing.T) {
+	// the curve of the reference implementation
+	// of min_dist 0.1 and spread 1
+	if a, b := fitCurve(0.1, 1); math.Abs(a-1.577) > 0.02 || math.Abs(b-0.895) > 0.02 {
+		t.Errorf("curve a %v b %v, want 1.577 and 0.895", a, b)
+	}
+
+	// the nearest is 1 and they sum to log2 of 8
+	w := memberships([]float64{1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5})
+	if w[0] != 1 || math.Abs(floats.Sum(w)-3) > 1e-4 {
+		t.Errorf("memberships %v of sum %v, want 1 first of sum 3", w, floats.Sum(w))
+	}
+}
+
+func TestUMAPSeparatesClusters(t *testing.T) {
+	// two blobs in 5 dimensions
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, 60)
+	for i := range features {
+		features[i] = make([]float64, 5)
+		for j := range features[i] {
+			features[i][j] = r.NormFloat64()
+			if i >= 30 {
+				features[i][j] += 10
+			}
+		}
+	}
+
+	u := NewUMAP(2)
+	u.Neighbors = 10
+	u.Epochs = 200
+	if err := u.Fit(features, nil); err != nil {
+		t.Fatal(err)
+	}
+	centers := [2][]float64{make([]float64, 2), make([]float64, 2)}
+	for i, y := range u.Embedding {
+		floats.AddScaled(centers[i/30], 1.0/30, y)
+	}
+	within := 0.0
+	for i, y := range u.Embedding {
+		within = math.Max(within, floats.Distance(y, centers[i/30], 2))
+	}
+	if between := floats.Distance(centers[0], centers[1], 2); between < 2*within {
+		t.Errorf("blobs %v apart, want at least twice their radius %v", between, within)
+	}
+
+	// a new row of the second blob is laid out near it
+	y := u.Transform([][]float64{{10, 10, 10, 10, 10}})[0]
+	if floats.Distance(y, centers[1], 2) > floats.Distance(y, centers[0], 2) {
+		t.Errorf("new row at %v, nearer the first blob %v than %v", y, centers[0], centers[1])
+	}
+
+	if err := u.Fit(features[:2], nil); err == nil {
+		t.Error("no error of 2 rows")
+	}
+}
@@ -0,0 +1,164 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+// MarginalModel is a fitted model of outcome probabilities,
+// such as LogisticRegression, ProbitRegression and
+// SoftmaxRegression, with covariance of its coefficients
+type MarginalModel interface {
+	CovarianceModel
+	// marginal returns derivatives of probability of every
+	// outcome by every column of X, of coefficients
+	marginal(coefficients, X []float64) [][]float64
+}
+
+// MarginalEffects are changes of probability of every
+// outcome by a unit change of every column, outcomes
+// being true for binary models and classes for
+// SoftmaxRegression. Effects of constant columns, such
+// as the bias column, are zero.
+type MarginalEffects struct {
+	// Effects[k][j] is the effect of column j on outcome k
+	Effects [][]float64
+	// StdErrors are standard errors of Effects
+	// by the delta method
+	StdErrors [][]float64
+}
+
+// AverageMarginalEffects returns marginal effects
+// averaged over rows of features
+func AverageMarginalEffects(model MarginalModel, features [][]float64) (*MarginalEffects, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("ml: marginal effects need rows")
+	}
+	return marginalEffects(model, features, features)
+}
+
+// MarginalEffectsAtMeans returns marginal effects at
+// the column means of features
+func MarginalEffectsAtMeans(model MarginalModel, features [][]float64) (*MarginalEffects, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("ml: marginal effects need rows")
+	}
+	means := make([]float64, len(features[0]))
+	for j := range means {
+		means[j] = stat.Mean(column(features, j), nil)
+	}
+	return marginalEffects(model, features, [][]float64{means})
+}
+
+// marginalEffects returns effects averaged over rows, with
+// the delta method covariance JΣJᵀ of the jacobian J of
+// the effects by the coefficients
+func marginalEffects(model MarginalModel, features, rows [][]float64) (*MarginalEffects, error) {
+	cov, err := model.CoefficientCovariance()
+	if err != nil {
+		return nil, err
+	}
+	coefficients := model.Coefficients()
+	p := len(features[0])
+	for _, x := range rows {
+		if len(x) != p {
+			return nil, fmt.Errorf("ml: row has %d columns, expected %d", len(x), p)
+		}
+	}
+	constant := make([]bool, p)
+	for j := range constant {
+		constant[j] = stat.Variance(column(features, j), nil) == 0
+	}
+
+	// average returns flattened effects of coefficients c
+	average := func(c []float64) []float64 {
+		var sum []float64
+		for _, x := range rows {
+			var flat []float64
+			for _, e := range model.marginal(c, x) {
+				flat = append(flat, e...)
+			}
+			if sum == nil {
+				sum = flat
+			} else {
+				floats.Add(sum, flat)
+			}
+		}
+		floats.Scale(1/float64(len(rows)), sum)
+		return sum
+	}
+	effects := average(coefficients)
+
+	jacobian := make([][]float64, len(coefficients))
+	c := append([]float64(nil), coefficients...)
+	for m := range c {
+		h := 1e-6 * math.Max(1, math.Abs(c[m]))
+		c[m] = coefficients[m] + h
+		plus := average(c)
+		c[m] = coefficients[m] - h
+		minus := average(c)
+		c[m] = coefficients[m]
+		floats.Sub(plus, minus)
+		floats.Scale(1/(2*h), plus)
+		jacobian[m] = plus
+	}
+	se := make([]float64, len(effects))
+	for e := range se {
+		v := 0.0
+		for a := range jacobian {
+			for b := range jacobian {
+				v += jacobian[a][e] * cov[a][b] * jacobian[b][e]
+			}
+		}
+		se[e] = math.Sqrt(math.Max(v, 0))
+	}
+
+	out := &MarginalEffects{}
+	for k := 0; k < len(effects)/p; k++ {
+		e, s := effects[k*p:(k+1)*p], se[k*p:(k+1)*p]
+		for j, ok := range constant {
+			if ok {
+				e[j], s[j] = 0, 0
+			}
+		}
+		out.Effects = append(out.Effects, e)
+		out.StdErrors = append(out.StdErrors, s)
+	}
+	return out, nil
+}
+
+// marginal returns p(1-p)θ of probability p of X
+func (l *LogisticRegression) marginal(coefficients, X []float64) [][]float64 {
+	prob := sigmoid(floats.Dot(X, coefficients))
+	effect := append([]float64(nil), coefficients...)
+	floats.Scale(prob*(1-prob), effect)
+	return [][]float64{effect}
+}
+
+// marginal returns φ(θ·X)θ
+func (r *ProbitRegression) marginal(coefficients, X []float64) [][]float64 {
+	z := floats.Dot(X, coefficients)
+	effect := append([]float64(nil), coefficients...)
+	floats.Scale(math.Exp(-z*z/2)/math.Sqrt(2*math.Pi), effect)
+	return [][]float64{effect}
+}
+
+// marginal returns pₖ(θₖ - Σ pₗθₗ) of every class k
+func (s *SoftmaxRegression) marginal(coefficients, X []float64) [][]float64 {
+	theta := softmaxThetas(coefficients, s.Classes)
+	prob := softmaxProbabilities(theta, X)
+	mean := make([]float64, len(X))
+	for k, t := range theta {
+		floats.AddScaled(mean, prob[k], t)
+	}
+	effects := make([][]float64, len(theta))
+	for k, t := range theta {
+		effects[k] = make([]float64, len(X))
+		floats.SubTo(effects[k], t, mean)
+		floats.Scale(prob[k], effects[k])
+	}
+	return effects
+}
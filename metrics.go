@@ -0,0 +1,52 @@
+package ml
+
+import "math"
+
+// Metric scores estimates against output,
+// a higher score is better
+type Metric func(output, estimates []float64) float64
+
+// Accuracy returns fraction of estimates rounding to
+// output, so probabilities of at least 0.5 are true
+func Accuracy(output, estimates []float64) float64 {
+	correct := 0.0
+	for i, y := range output {
+		if math.Round(estimates[i]) == y {
+			correct++
+		}
+	}
+	return correct / float64(len(output))
+}
+
+// MeanSquaredError returns mean squared difference
+// of estimates and output, lower is better
+func MeanSquaredError(output, estimates []float64) float64 {
+	sum := 0.0
+	for i, y := range output {
+		d := estimates[i] - y
+		sum += d * d
+	}
+	return sum / float64(len(output))
+}
+
+// NegMeanSquaredError returns negated MeanSquaredError
+// so it can be used as a Metric
+func NegMeanSquaredError(output, estimates []float64) float64 {
+	return -MeanSquaredError(output, estimates)
+}
+
+// R2 returns coefficient of determination of estimates
+func R2(output, estimates []float64) float64 {
+	mean := 0.0
+	for _, y := range output {
+		mean += y
+	}
+	mean /= float64(len(output))
+
+	res, tot := 0.0, 0.0
+	for i, y := range output {
+		res += (y - estimates[i]) * (y - estimates[i])
+		tot += (y - mean) * (y - mean)
+	}
+	return 1 - res/tot
+}
@@ -0,0 +1,156 @@
+package ml
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Names of metrics reported to Metrics
+const (
+	MetricTrainingIterations = "ml_training_iterations_total"
+	MetricTrainingLoss       = "ml_training_loss"
+	MetricTrainingDuration   = "ml_training_duration_seconds"
+	MetricPredictions        = "ml_predictions_total"
+	MetricPredictionLatency  = "ml_prediction_duration_seconds"
+	MetricPredictionValue    = "ml_prediction_value"
+)
+
+// Metrics receives instrumentation of training and
+// prediction, e.g. to export it to Prometheus. Labels
+// may be nil. Implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	// Add increases counter name by value
+	Add(name string, value float64, labels map[string]string)
+	// Set sets gauge name to value
+	Set(name string, value float64, labels map[string]string)
+	// Observe records value in histogram name
+	Observe(name string, value float64, labels map[string]string)
+}
+
+// instrumentable models report training metrics,
+// restore sets back their previous metrics
+type instrumentable interface {
+	instrument(metrics Metrics, labels map[string]string) (restore func())
+}
+
+// instrument sets metrics receiving iterations and loss
+// of Minimize and Fit with labels
+func (l *Linear) instrument(metrics Metrics, labels map[string]string) func() {
+	old, oldLabels := l.Metrics, l.metricLabels
+	l.Metrics = metrics
+	l.metricLabels = labels
+	return func() {
+		l.Metrics, l.metricLabels = old, oldLabels
+	}
+}
+
+// metricsRecorder reports optimizer iterations
+type metricsRecorder struct {
+	metrics Metrics
+	labels  map[string]string
+}
+
+func (r metricsRecorder) Init() error {
+	return nil
+}
+
+func (r metricsRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if op&optimize.MajorIteration != 0 {
+		r.metrics.Add(MetricTrainingIterations, 1, r.labels)
+		r.metrics.Set(MetricTrainingLoss, loc.F, r.labels)
+	}
+	return nil
+}
+
+// Instrumented reports training duration, prediction
+// counts, latency and values of Estimator to Metrics,
+// labelled with model Name. Models with an optimizer
+// also report iterations and loss. Instrumented is an
+// Estimator. Save leaves out Metrics, set it again
+// after Load, until then nothing is reported.
+type Instrumented struct {
+	Estimator Estimator
+	Metrics   Metrics
+	Name      string
+}
+
+// NewInstrumented returns new pointer of Instrumented
+func NewInstrumented(estimator Estimator, metrics Metrics, name string) *Instrumented {
+	return &Instrumented{
+		Estimator: estimator,
+		Metrics:   metrics,
+		Name:      name,
+	}
+}
+
+func (i *Instrumented) labels() map[string]string {
+	return map[string]string{"model": i.Name}
+}
+
+// Fit fits Estimator and reports its duration. Metrics
+// of the Estimator are only replaced during Fit.
+func (i *Instrumented) Fit(features [][]float64, output []float64) error {
+	if i.Metrics == nil {
+		return i.Estimator.Fit(features, output)
+	}
+	labels := i.labels()
+	if m, ok := i.Estimator.(instrumentable); ok {
+		defer m.instrument(i.Metrics, labels)()
+	}
+
+	start := time.Now()
+	err := i.Estimator.Fit(features, output)
+	i.Metrics.Observe(MetricTrainingDuration, time.Since(start).Seconds(), labels)
+
+	return err
+}
+
+// Estimate returns estimate of Estimator and
+// reports its latency and value
+func (i *Instrumented) Estimate(X []float64) float64 {
+	if i.Metrics == nil {
+		return i.Estimator.Estimate(X)
+	}
+	labels := i.labels()
+
+	start := time.Now()
+	v := i.Estimator.Estimate(X)
+	i.Metrics.Observe(MetricPredictionLatency, time.Since(start).Seconds(), labels)
+	i.Metrics.Add(MetricPredictions, 1, labels)
+	i.Metrics.Observe(MetricPredictionValue, v, labels)
+
+	return v
+}
+
+// instrument passes metrics to the estimator of the pipeline
+func (p *Pipeline) instrument(metrics Metrics, labels map[string]string) func() {
+	if m, ok := p.Estimator.(instrumentable); ok {
+		return m.instrument(metrics, labels)
+	}
+	return func() {}
+}
+
+type instrumentedState struct {
+	Estimator Estimator
+	Name      string
+}
+
+// GobEncode saves Estimator and Name
+func (i *Instrumented) GobEncode() ([]byte, error) {
+	return encodeGob(&instrumentedState{i.Estimator, i.Name})
+}
+
+// GobDecode restores a saved Instrumented
+// without Metrics
+func (i *Instrumented) GobDecode(data []byte) error {
+	var s instrumentedState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	i.Estimator, i.Name, i.Metrics = s.Estimator, s.Name, nil
+	return nil
+}
@@ -0,0 +1,64 @@
+package ml
+
+import (
+	"sync"
+	"testing"
+)
+
+type countingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *countingMetrics) record(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	c.counts[name]++
+}
+
+func (c *countingMetrics) Add(name string, value float64, labels map[string]string) {
+	c.record(name)
+}
+
+func (c *countingMetrics) Set(name string, value float64, labels map[string]string) {
+	c.record(name)
+}
+
+func (c *countingMetrics) Observe(name string, value float64, labels map[string]string) {
+	c.record(name)
+}
+
+func TestInstrumentedRestoresMetrics(t *testing.T) {
+	own := &countingMetrics{}
+	model := NewLinearRegression(WithMetrics(own))
+
+	features := make([][]float64, 20)
+	output := make([]float64, len(features))
+	for i := range features {
+		x := float64(i) / 10
+		features[i] = []float64{1, x}
+		output[i] = 1 + 2*x
+	}
+
+	wrapped := &countingMetrics{}
+	if err := NewInstrumented(model, wrapped, "linear").Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.counts[MetricTrainingIterations] == 0 || wrapped.counts[MetricTrainingDuration] != 1 {
+		t.Errorf("instrumented metrics got %v", wrapped.counts)
+	}
+	if model.Metrics != own || model.metricLabels != nil {
+		t.Errorf("metrics of the model were not restored")
+	}
+
+	before := wrapped.counts[MetricTrainingIterations]
+	if err := model.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.counts[MetricTrainingIterations] != before || own.counts[MetricTrainingIterations] == 0 {
+		t.Errorf("direct Fit reported to the instrumented metrics")
+	}
+}
@@ -0,0 +1,304 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// MixedModel is a linear mixed-effects model for grouped
+// rows, such as students within schools,
+//
+//	y = θ·x + b·z + ε,  b ~ N(0, G),  ε ~ N(0, σ²)
+//
+// where z is 1 followed by RandomSlopes columns of x, so
+// every group has its own intercept and slopes b. Variances
+// maximize the restricted likelihood (REML), profiling out
+// thetas and σ², and the random effects are their best
+// linear unbiased predictions.
+type MixedModel struct {
+	// Groups are groups of every training row
+	Groups []int
+	// RandomSlopes are columns whose slopes vary
+	// by group, none is a random intercept model
+	RandomSlopes []int
+
+	// Theta are fixed effects, StdErrors their
+	// standard errors
+	Theta     []float64
+	StdErrors []float64
+	// Sigma is the residual standard deviation
+	Sigma float64
+	// Covariance is G, of the random intercept
+	// then slopes of RandomSlopes
+	Covariance [][]float64
+	// RandomEffects are b of every group
+	RandomEffects map[int][]float64
+	// REML is the restricted log-likelihood
+	REML float64
+}
+
+// NewMixedModel returns new pointer of MixedModel of
+// rows in groups, with random slopes of columns
+func NewMixedModel(groups []int, randomSlopes ...int) *MixedModel {
+	return &MixedModel{Groups: groups, RandomSlopes: randomSlopes}
+}
+
+// mixedGroup holds cross products of a group
+type mixedGroup struct {
+	label int
+	ztz   *mat.SymDense
+	ztx   *mat.Dense
+	zty   *mat.VecDense
+	rows  []int
+}
+
+// Fit trains the model on features and output of Groups
+func (m *MixedModel) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	n := len(features)
+	if len(m.Groups) != n {
+		return fmt.Errorf("ml: got %d groups for %d rows", len(m.Groups), n)
+	}
+	if n <= p {
+		return fmt.Errorf("ml: mixed model needs more rows than the %d thetas", p)
+	}
+	for _, j := range m.RandomSlopes {
+		if j < 0 || j >= p {
+			return fmt.Errorf("ml: random slope of column %d of %d columns", j, p)
+		}
+	}
+	q := 1 + len(m.RandomSlopes)
+
+	// cross products of every group and all rows
+	var groups []*mixedGroup
+	index := map[int]*mixedGroup{}
+	for i, label := range m.Groups {
+		g, ok := index[label]
+		if !ok {
+			g = &mixedGroup{label: label}
+			index[label] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, i)
+	}
+	for _, g := range groups {
+		Z := mat.NewDense(len(g.rows), q, nil)
+		for r, i := range g.rows {
+			Z.Set(r, 0, 1)
+			for c, j := range m.RandomSlopes {
+				Z.Set(r, c+1, features[i][j])
+			}
+		}
+		X, y := subset(features, output, g.rows)
+		g.ztz = mat.NewSymDense(q, nil)
+		g.ztz.SymOuterK(1, Z.T())
+		g.ztx = mat.NewDense(q, p, nil)
+		g.ztx.Mul(Z.T(), denseOf(X))
+		g.zty = mat.NewVecDense(q, nil)
+		g.zty.MulVec(Z.T(), mat.NewVecDense(len(y), y))
+	}
+	X := denseOf(features)
+	Y := mat.NewVecDense(n, output)
+	xtx := mat.NewSymDense(p, nil)
+	xtx.SymOuterK(1, X.T())
+	xty := mat.NewVecDense(p, nil)
+	xty.MulVec(X.T(), Y)
+	yty := mat.Dot(Y, Y)
+
+	// relative covariance factor Λ, G = σ²ΛΛᵀ, of its
+	// lower triangle
+	factor := func(params []float64) *mat.TriDense {
+		L := mat.NewTriDense(q, mat.Lower, nil)
+		k := 0
+		for i := 0; i < q; i++ {
+			for j := 0; j <= i; j++ {
+				L.SetTri(i, j, params[k])
+				k++
+			}
+		}
+		return L
+	}
+
+	// reml returns minus twice the profiled restricted
+	// log-likelihood without constants, with thetas,
+	// their scaled information A and the residual
+	// sum of squares
+	type fit struct {
+		objective float64
+		theta     *mat.VecDense
+		info      *mat.Cholesky
+		rss       float64
+	}
+	reml := func(params []float64) fit {
+		bad := fit{objective: math.Inf(1)}
+		L := factor(params)
+		A := mat.NewSymDense(p, nil)
+		A.CopySym(xtx)
+		b := mat.NewVecDense(p, nil)
+		b.CopyVec(xty)
+		yy := yty
+		logdet := 0.0
+		var W, S, corr mat.Dense
+		var u, v mat.VecDense
+		for _, g := range groups {
+			// M = I + ΛᵀZᵀZΛ, W = ΛᵀZᵀX, u = ΛᵀZᵀy
+			M := g.inner(L)
+			var chol mat.Cholesky
+			if !chol.Factorize(M) {
+				return bad
+			}
+			logdet += chol.LogDet()
+			W.Reset()
+			W.Mul(L.T(), g.ztx)
+			u.Reset()
+			u.MulVec(L.T(), g.zty)
+
+			// subtract WᵀM⁻¹W, WᵀM⁻¹u and uᵀM⁻¹u
+			S.Reset()
+			if err := chol.SolveTo(&S, &W); err != nil {
+				return bad
+			}
+			corr.Reset()
+			corr.Mul(W.T(), &S)
+			for i := 0; i < p; i++ {
+				for j := i; j < p; j++ {
+					A.SetSym(i, j, A.At(i, j)-(corr.At(i, j)+corr.At(j, i))/2)
+				}
+			}
+			v.Reset()
+			if err := chol.SolveVecTo(&v, &u); err != nil {
+				return bad
+			}
+			var wv mat.VecDense
+			wv.MulVec(W.T(), &v)
+			b.SubVec(b, &wv)
+			yy -= mat.Dot(&u, &v)
+		}
+		var info mat.Cholesky
+		if !info.Factorize(A) {
+			return bad
+		}
+		var theta mat.VecDense
+		if err := info.SolveVecTo(&theta, b); err != nil {
+			return bad
+		}
+		rss := yy - mat.Dot(&theta, b)
+		if !(rss > 0) {
+			return bad
+		}
+		return fit{
+			objective: float64(n-p)*math.Log(rss) + logdet + info.LogDet(),
+			theta:     &theta,
+			info:      &info,
+			rss:       rss,
+		}
+	}
+
+	init := make([]float64, q*(q+1)/2)
+	for i, k := 0, 0; i < q; i++ {
+		init[k+i] = 1
+		k += i + 1
+	}
+	prob := optimize.Problem{
+		Func: func(params []float64) float64 {
+			return reml(params).objective
+		},
+	}
+	result, err := optimize.Minimize(prob, init, nil, &optimize.NelderMead{})
+	if err != nil && (result == nil || math.IsInf(result.F, 1)) {
+		return fmt.Errorf("ml: mixed model: %v", err)
+	}
+	best := reml(result.X)
+	if math.IsInf(best.objective, 1) {
+		return fmt.Errorf("ml: mixed model: restricted likelihood is not finite")
+	}
+
+	df := float64(n - p)
+	sigma2 := best.rss / df
+	m.Theta = best.theta.RawVector().Data
+	m.Sigma = math.Sqrt(sigma2)
+	m.REML = -0.5 * (best.objective - df*math.Log(df) + df*(1+math.Log(2*math.Pi)))
+
+	inv := mat.NewSymDense(p, nil)
+	if err := best.info.InverseTo(inv); err != nil {
+		return fmt.Errorf("ml: mixed model: %v", err)
+	}
+	m.StdErrors = make([]float64, p)
+	for j := range m.StdErrors {
+		m.StdErrors[j] = math.Sqrt(sigma2 * inv.At(j, j))
+	}
+
+	L := factor(result.X)
+	G := mat.NewSymDense(q, nil)
+	G.SymOuterK(sigma2, L)
+	m.Covariance = symToSlices(G)
+
+	// b = ΛM⁻¹ΛᵀZᵀ(y - Xθ) of every group
+	m.RandomEffects = make(map[int][]float64, len(groups))
+	for _, g := range groups {
+		var r, u, v, b mat.VecDense
+		r.MulVec(g.ztx, best.theta)
+		r.SubVec(g.zty, &r)
+		u.MulVec(L.T(), &r)
+		M := g.inner(L)
+		var chol mat.Cholesky
+		if !chol.Factorize(M) {
+			return fmt.Errorf("ml: mixed model: group %d is singular", g.label)
+		}
+		if err := chol.SolveVecTo(&v, &u); err != nil {
+			return fmt.Errorf("ml: mixed model: %v", err)
+		}
+		b.MulVec(L, &v)
+		m.RandomEffects[g.label] = b.RawVector().Data
+	}
+	return nil
+}
+
+// inner returns I + ΛᵀZᵀZΛ of the group
+func (g *mixedGroup) inner(L *mat.TriDense) *mat.SymDense {
+	q := g.ztz.Symmetric()
+	var ZL, LZL mat.Dense
+	ZL.Mul(g.ztz, L)
+	LZL.Mul(L.T(), &ZL)
+	M := mat.NewSymDense(q, nil)
+	for i := 0; i < q; i++ {
+		for j := i; j < q; j++ {
+			M.SetSym(i, j, (LZL.At(i, j)+LZL.At(j, i))/2)
+		}
+		M.SetSym(i, i, M.At(i, i)+1)
+	}
+	return M
+}
+
+// Estimate returns predicted value of X from fixed
+// effects, as for a group not seen in training
+func (m *MixedModel) Estimate(X []float64) float64 {
+	return floats.Dot(X, m.Theta)
+}
+
+// EstimateGroup returns predicted value of X of
+// group, with its random effects
+func (m *MixedModel) EstimateGroup(X []float64, group int) float64 {
+	y := m.Estimate(X)
+	b, ok := m.RandomEffects[group]
+	if !ok {
+		return y
+	}
+	y += b[0]
+	for c, j := range m.RandomSlopes {
+		y += b[c+1] * X[j]
+	}
+	return y
+}
+
+// Coefficients returns fixed effects
+func (m *MixedModel) Coefficients() []float64 {
+	return m.Theta
+}
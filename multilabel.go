@@ -0,0 +1,277 @@
+package ml
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MultiLabelBinarizer maps sets of labels of every sample
+// to rows of indicators, 1 for every label of the sample
+type MultiLabelBinarizer struct {
+	// Classes are the labels, sorted, of the indicators
+	Classes []string
+}
+
+// NewMultiLabelBinarizer returns new pointer of MultiLabelBinarizer
+func NewMultiLabelBinarizer() *MultiLabelBinarizer {
+	return &MultiLabelBinarizer{}
+}
+
+// Fit collects classes of labels
+func (b *MultiLabelBinarizer) Fit(labels [][]string) {
+	seen := map[string]bool{}
+	b.Classes = nil
+	for _, set := range labels {
+		for _, l := range set {
+			if !seen[l] {
+				seen[l] = true
+				b.Classes = append(b.Classes, l)
+			}
+		}
+	}
+	sort.Strings(b.Classes)
+}
+
+// Transform returns indicators of labels,
+// labels not in Classes are ignored
+func (b *MultiLabelBinarizer) Transform(labels [][]string) [][]float64 {
+	index := make(map[string]int, len(b.Classes))
+	for k, c := range b.Classes {
+		index[c] = k
+	}
+	out := make([][]float64, len(labels))
+	for i, set := range labels {
+		out[i] = make([]float64, len(b.Classes))
+		for _, l := range set {
+			if k, ok := index[l]; ok {
+				out[i][k] = 1
+			}
+		}
+	}
+	return out
+}
+
+// FitTransform fits classes of labels and
+// returns their indicators
+func (b *MultiLabelBinarizer) FitTransform(labels [][]string) [][]float64 {
+	b.Fit(labels)
+	return b.Transform(labels)
+}
+
+// InverseTransform returns labels of indicators of at least 0.5
+func (b *MultiLabelBinarizer) InverseTransform(indicators [][]float64) [][]string {
+	out := make([][]string, len(indicators))
+	for i, row := range indicators {
+		for k, v := range row {
+			if v >= 0.5 && k < len(b.Classes) {
+				out[i] = append(out[i], b.Classes[k])
+			}
+		}
+	}
+	return out
+}
+
+// checkLabels returns the number of labels, or an error
+// unless labels are indicator rows of every feature row
+func checkLabels(features, labels [][]float64) (int, error) {
+	if len(features) == 0 {
+		return 0, fmt.Errorf("ml: cannot fit empty features")
+	}
+	if len(features) != len(labels) {
+		return 0, fmt.Errorf("ml: got %d rows of features and %d rows of labels", len(features), len(labels))
+	}
+	k := len(labels[0])
+	for i, row := range labels {
+		if len(row) != k {
+			return 0, fmt.Errorf("ml: row %d has %d labels, expected %d", i, len(row), k)
+		}
+	}
+	return k, nil
+}
+
+// BinaryRelevance is a multi-label classifier of one
+// binary classifier per label, each ignoring the others
+type BinaryRelevance struct {
+	NewEstimator func() Estimator
+	// Threshold on probabilities of labels,
+	// defaults to 0.5
+	Threshold float64
+
+	Estimators []Estimator
+}
+
+// NewBinaryRelevance returns new pointer of BinaryRelevance
+func NewBinaryRelevance(newEstimator func() Estimator) *BinaryRelevance {
+	return &BinaryRelevance{NewEstimator: newEstimator, Threshold: 0.5}
+}
+
+// Fit trains a classifier on features for every
+// column of labels, indicator rows of samples
+func (b *BinaryRelevance) Fit(features, labels [][]float64) error {
+	k, err := checkLabels(features, labels)
+	if err != nil {
+		return err
+	}
+	b.Estimators = make([]Estimator, k)
+	for l := range b.Estimators {
+		b.Estimators[l] = b.NewEstimator()
+		if err := b.Estimators[l].Fit(features, column(labels, l)); err != nil {
+			return fmt.Errorf("ml: label %d: %v", l, err)
+		}
+	}
+	return nil
+}
+
+// Probabilities returns probability of every label of X
+func (b *BinaryRelevance) Probabilities(X []float64) []float64 {
+	prob := make([]float64, len(b.Estimators))
+	for l, e := range b.Estimators {
+		prob[l] = e.Estimate(X)
+	}
+	return prob
+}
+
+// Predict returns indicators of labels of X
+func (b *BinaryRelevance) Predict(X []float64) []float64 {
+	return indicators(b.Probabilities(X), b.Threshold)
+}
+
+// ClassifierChain is a multi-label classifier of one binary
+// classifier per label, each also fed the labels before it
+// along Order, so it learns correlations between labels
+type ClassifierChain struct {
+	NewEstimator func() Estimator
+	// Order of labels along the chain,
+	// nil is the order of columns
+	Order []int
+	// Threshold on probabilities of labels,
+	// defaults to 0.5
+	Threshold float64
+
+	// Estimators are classifiers along Order
+	Estimators []Estimator
+}
+
+// NewClassifierChain returns new pointer of ClassifierChain
+func NewClassifierChain(newEstimator func() Estimator) *ClassifierChain {
+	return &ClassifierChain{NewEstimator: newEstimator, Threshold: 0.5}
+}
+
+// Fit trains a classifier for every column of labels, on
+// features followed by true labels before it in the chain
+func (c *ClassifierChain) Fit(features, labels [][]float64) error {
+	k, err := checkLabels(features, labels)
+	if err != nil {
+		return err
+	}
+	order := c.Order
+	if order == nil {
+		order = make([]int, k)
+		for l := range order {
+			order[l] = l
+		}
+	}
+	if len(order) != k {
+		return fmt.Errorf("ml: order of %d labels for %d labels", len(order), k)
+	}
+	seen := make([]bool, k)
+	for _, l := range order {
+		if l < 0 || l >= k || seen[l] {
+			return fmt.Errorf("ml: order %v is not a permutation of %d labels", order, k)
+		}
+		seen[l] = true
+	}
+	c.Order = order
+
+	X := make([][]float64, len(features))
+	for i, row := range features {
+		X[i] = append(make([]float64, 0, len(row)+k), row...)
+	}
+	c.Estimators = make([]Estimator, k)
+	for step, l := range order {
+		c.Estimators[step] = c.NewEstimator()
+		y := column(labels, l)
+		if err := c.Estimators[step].Fit(X, y); err != nil {
+			return fmt.Errorf("ml: label %d: %v", l, err)
+		}
+		for i := range X {
+			X[i] = append(X[i], y[i])
+		}
+	}
+	return nil
+}
+
+// Probabilities returns probability of every label of X,
+// each given predicted labels before it in the chain
+func (c *ClassifierChain) Probabilities(X []float64) []float64 {
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	prob := make([]float64, len(c.Estimators))
+	x := append(make([]float64, 0, len(X)+len(c.Estimators)), X...)
+	for step, e := range c.Estimators {
+		p := e.Estimate(x)
+		prob[c.Order[step]] = p
+		if p >= threshold {
+			x = append(x, 1)
+		} else {
+			x = append(x, 0)
+		}
+	}
+	return prob
+}
+
+// Predict returns indicators of labels of X
+func (c *ClassifierChain) Predict(X []float64) []float64 {
+	return indicators(c.Probabilities(X), c.Threshold)
+}
+
+// indicators returns 1 of probabilities of at least
+// threshold, defaulting to 0.5, else 0
+func indicators(prob []float64, threshold float64) []float64 {
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	out := make([]float64, len(prob))
+	for l, p := range prob {
+		if p >= threshold {
+			out[l] = 1
+		}
+	}
+	return out
+}
+
+// HammingLoss returns the fraction of labels predicted
+// wrong over every sample and label, lower is better
+func HammingLoss(labels, predictions [][]float64) float64 {
+	wrong, total := 0.0, 0.0
+	for i, row := range labels {
+		for l, v := range row {
+			if predictions[i][l] != v {
+				wrong++
+			}
+			total++
+		}
+	}
+	return wrong / total
+}
+
+// SubsetAccuracy returns the fraction of samples
+// with every label predicted right
+func SubsetAccuracy(labels, predictions [][]float64) float64 {
+	right := 0.0
+	for i, row := range labels {
+		exact := true
+		for l, v := range row {
+			if predictions[i][l] != v {
+				exact = false
+				break
+			}
+		}
+		if exact {
+			right++
+		}
+	}
+	return right / float64(len(labels))
+}
@@ -0,0 +1,127 @@
+package ml
+
+import "fmt"
+
+// FeatureNamer is a model knowing the names of its
+// feature columns, in the order it expects them
+type FeatureNamer interface {
+	Names() []string
+}
+
+// Coefficient is a named coefficient of a linear model
+type Coefficient struct {
+	Name  string
+	Value float64
+}
+
+// Names returns FeatureNames
+func (l *Linear) Names() []string {
+	return l.FeatureNames
+}
+
+// Names returns FeatureNames
+func (p *Pipeline) Names() []string {
+	return p.FeatureNames
+}
+
+// NamedCoefficients returns thetas with the names of
+// their columns, x0, x1, ... when unnamed
+func (l *Linear) NamedCoefficients() []Coefficient {
+	c := make([]Coefficient, len(l.Theta))
+	for j, v := range l.Theta {
+		c[j] = Coefficient{Name: columnName(l.FeatureNames, j), Value: v}
+	}
+	return c
+}
+
+// columnName returns names[j], or xj when unnamed
+func columnName(names []string, j int) string {
+	if j < len(names) && names[j] != "" {
+		return names[j]
+	}
+	return fmt.Sprintf("x%d", j)
+}
+
+// namesOf returns names of columns of model,
+// nil when it has none
+func namesOf(model Estimator) []string {
+	if n, ok := model.(FeatureNamer); ok {
+		return n.Names()
+	}
+	return nil
+}
+
+// checkNames returns an error unless names, when
+// set, name every one of columns
+func checkNames(names []string, columns int) error {
+	if names != nil && len(names) != columns {
+		return fmt.Errorf("ml: got %d feature names for %d columns", len(names), columns)
+	}
+	return nil
+}
+
+// CheckNames returns an error unless names match the
+// names of columns of model in count and order. Models
+// without names accept any names.
+func CheckNames(model Estimator, names []string) error {
+	want := namesOf(model)
+	if want == nil {
+		return nil
+	}
+	if len(names) != len(want) {
+		return fmt.Errorf("ml: got %d features, model expects %d", len(names), len(want))
+	}
+	for j := range want {
+		if names[j] != want[j] {
+			return fmt.Errorf("ml: feature %d is %q, model expects %q", j, names[j], want[j])
+		}
+	}
+	return nil
+}
+
+// Reorder returns rows of X, whose columns are named
+// names, with columns in the order model expects. Extra
+// columns are dropped and missing ones are an error.
+func Reorder(model Estimator, names []string, X [][]float64) ([][]float64, error) {
+	want := namesOf(model)
+	if want == nil {
+		return nil, fmt.Errorf("ml: %T has no feature names", model)
+	}
+
+	index := make(map[string]int, len(names))
+	for j, name := range names {
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("ml: feature %q appears twice", name)
+		}
+		index[name] = j
+	}
+	cols := make([]int, len(want))
+	for k, name := range want {
+		j, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("ml: missing feature %q", name)
+		}
+		cols[k] = j
+	}
+
+	out := make([][]float64, len(X))
+	for i, x := range X {
+		if len(x) != len(names) {
+			return nil, fmt.Errorf("ml: row %d has %d columns, expected %d", i, len(x), len(names))
+		}
+		out[i] = make([]float64, len(cols))
+		for k, j := range cols {
+			out[i][k] = x[j]
+		}
+	}
+	return out, nil
+}
+
+// WithFeatureNames names the feature columns, Fit
+// checks there is a name for every column
+func WithFeatureNames(names ...string) Option {
+	return func(o *options) error {
+		o.linear.FeatureNames = append([]string(nil), names...)
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mathext"
+)
+
+// NegativeBinomialRegression is a NB2 regression of counts,
+// with mean μ = exp(θ·x) and variance μ + αμ², for counts
+// overdispersed relative to Poisson. Thetas and the
+// dispersion Alpha maximize the likelihood together.
+type NegativeBinomialRegression struct {
+	Theta []float64
+	// Alpha is the dispersion, zero is Poisson
+	Alpha float64
+	// StdErrors of thetas and AlphaStdErr come from
+	// the observed information of the likelihood
+	StdErrors   []float64
+	AlphaStdErr float64
+
+	loglik     float64
+	samples    int
+	covariance [][]float64
+}
+
+// NewNegativeBinomialRegression returns new pointer
+// of NegativeBinomialRegression
+func NewNegativeBinomialRegression() *NegativeBinomialRegression {
+	return &NegativeBinomialRegression{}
+}
+
+// Fit trains the model on features and counts of output
+func (r *NegativeBinomialRegression) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	for i, y := range output {
+		if y < 0 || y != math.Floor(y) {
+			return fmt.Errorf("ml: output %d is %v, not a count", i, y)
+		}
+	}
+
+	// start from least squares of log counts
+	logs := make([]float64, len(output))
+	for i, y := range output {
+		logs[i] = math.Log(y + 0.5)
+	}
+	init, err := leastSquares(features, logs)
+	if err != nil {
+		init = make([]float64, p)
+	}
+	init = append(init, 0)
+
+	// parameters are thetas and log Alpha
+	ll := func(x, grad []float64) float64 {
+		return negativeBinomialLikelihood(features, output, x, grad)
+	}
+	x, err := maximizeLikelihood(ll, init, len(output))
+	if err != nil {
+		return fmt.Errorf("ml: negative binomial: %v", err)
+	}
+	r.Theta = append([]float64(nil), x[:p]...)
+	r.Alpha = math.Exp(x[p])
+	r.loglik = ll(x, nil)
+	r.samples = len(output)
+
+	cov, err := observedCovariance(ll, x)
+	if err != nil {
+		r.covariance = nil
+		r.StdErrors, r.AlphaStdErr = nil, math.NaN()
+		return nil
+	}
+	r.covariance = make([][]float64, p)
+	r.StdErrors = make([]float64, p)
+	for j := 0; j < p; j++ {
+		r.covariance[j] = cov[j][:p]
+		r.StdErrors[j] = math.Sqrt(cov[j][j])
+	}
+	// delta method from log Alpha
+	r.AlphaStdErr = r.Alpha * math.Sqrt(cov[p][p])
+	return nil
+}
+
+// negativeBinomialLikelihood returns log-likelihood of
+// thetas and log Alpha of x, filling grad when not nil
+func negativeBinomialLikelihood(features [][]float64, output, x, grad []float64) float64 {
+	p := len(x) - 1
+	inv := math.Exp(-x[p])
+	for j := range grad {
+		grad[j] = 0
+	}
+	lgInv, _ := math.Lgamma(inv)
+	digInv := mathext.Digamma(inv)
+	ll := 0.0
+	for i, row := range features {
+		y := output[i]
+		mu := math.Exp(floats.Dot(row, x[:p]))
+		a, _ := math.Lgamma(y + inv)
+		b, _ := math.Lgamma(y + 1)
+		ll += a - lgInv - b + inv*math.Log(inv/(inv+mu)) + y*math.Log(mu/(inv+mu))
+		if grad == nil {
+			continue
+		}
+		floats.AddScaled(grad[:p], inv*(y-mu)/(inv+mu), row)
+		// by 1/Alpha, then by log Alpha
+		d := mathext.Digamma(y+inv) - digInv + math.Log(inv/(inv+mu)) + 1 - (inv+y)/(inv+mu)
+		grad[p] -= inv * d
+	}
+	return ll
+}
+
+// Estimate returns expected count of X
+func (r *NegativeBinomialRegression) Estimate(X []float64) float64 {
+	return math.Exp(floats.Dot(X, r.Theta))
+}
+
+// Coefficients returns thetas
+func (r *NegativeBinomialRegression) Coefficients() []float64 {
+	return r.Theta
+}
+
+// CoefficientCovariance returns covariance of thetas,
+// the inverse observed information of the training rows
+func (r *NegativeBinomialRegression) CoefficientCovariance() ([][]float64, error) {
+	if r.covariance == nil {
+		return nil, fmt.Errorf("ml: covariance needs a model fitted with a regular information matrix")
+	}
+	return r.covariance, nil
+}
+
+// LogLikelihood returns negative binomial
+// log-likelihood of the training data
+func (r *NegativeBinomialRegression) LogLikelihood() float64 {
+	return r.loglik
+}
+
+// NumParams returns the number of thetas plus Alpha
+func (r *NegativeBinomialRegression) NumParams() int {
+	return len(r.Theta) + 1
+}
+
+// NumSamples returns the number of training rows
+func (r *NegativeBinomialRegression) NumSamples() int {
+	return r.samples
+}
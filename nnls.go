@@ -0,0 +1,140 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// NNLS returns thetas minimizing |y - Xθ|² subject to
+// θ >= 0, by the active set method of Lawson and Hanson,
+// e.g. for spectral unmixing where thetas are abundances
+func NNLS(features [][]float64, output []float64) ([]float64, error) {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return nil, err
+	}
+	n := len(features)
+	A := denseOf(features)
+	b := mat.NewVecDense(n, output)
+
+	theta := make([]float64, p)
+	passive := make([]bool, p)
+	w := make([]float64, p)
+	residual := mat.NewVecDense(n, nil)
+	gradient := func() {
+		residual.MulVec(A, mat.NewVecDense(p, theta))
+		residual.SubVec(b, residual)
+		g := mat.NewVecDense(p, w)
+		g.MulVec(A.T(), residual)
+	}
+
+	// tolerance of scipy and MATLAB
+	eps := math.Nextafter(1, 2) - 1
+	tol := 10 * eps * mat.Norm(A, 1) * float64(max(n, p))
+	gradient()
+	for iter := 0; iter < 3*p; iter++ {
+		j, best := -1, tol
+		for k, v := range w {
+			if !passive[k] && v > best {
+				j, best = k, v
+			}
+		}
+		if j < 0 {
+			return theta, nil
+		}
+		passive[j] = true
+
+		for {
+			s, err := passiveSolve(A, b, passive)
+			if err != nil {
+				return nil, err
+			}
+			feasible := true
+			alpha := math.Inf(1)
+			for k := range s {
+				if passive[k] && s[k] <= tol {
+					feasible = false
+					alpha = math.Min(alpha, theta[k]/(theta[k]-s[k]))
+				}
+			}
+			if feasible {
+				copy(theta, s)
+				break
+			}
+			// step back to the boundary and free
+			// thetas that reached zero
+			for k := range theta {
+				theta[k] += alpha * (s[k] - theta[k])
+				if passive[k] && math.Abs(theta[k]) <= tol {
+					passive[k] = false
+					theta[k] = 0
+				}
+			}
+		}
+		gradient()
+	}
+	return theta, fmt.Errorf("ml: NNLS did not converge after %d iterations", 3*p)
+}
+
+// passiveSolve returns least squares thetas of
+// passive columns of A, other thetas are zero
+func passiveSolve(A *mat.Dense, b *mat.VecDense, passive []bool) ([]float64, error) {
+	var cols []int
+	for k, ok := range passive {
+		if ok {
+			cols = append(cols, k)
+		}
+	}
+	n, _ := A.Dims()
+	sub := mat.NewDense(n, len(cols), nil)
+	for c, k := range cols {
+		for i := 0; i < n; i++ {
+			sub.Set(i, c, A.At(i, k))
+		}
+	}
+	var s mat.VecDense
+	if err := s.SolveVec(sub, b); err != nil {
+		if _, ok := err.(mat.Condition); !ok {
+			return nil, fmt.Errorf("ml: NNLS: %v", err)
+		}
+	}
+	theta := make([]float64, len(passive))
+	for c, k := range cols {
+		theta[k] = s.AtVec(c)
+	}
+	return theta, nil
+}
+
+// NNLSRegression is a linear regression of
+// non-negative thetas fitted by NNLS
+type NNLSRegression struct {
+	Theta []float64
+}
+
+// NewNNLSRegression returns new pointer of NNLSRegression
+func NewNNLSRegression() *NNLSRegression {
+	return &NNLSRegression{}
+}
+
+// Fit trains the model on features and output
+func (r *NNLSRegression) Fit(features [][]float64, output []float64) error {
+	theta, err := NNLS(features, output)
+	if err != nil {
+		return err
+	}
+	r.Theta = theta
+	return nil
+}
+
+// Estimate returns predicted value of X
+func (r *NNLSRegression) Estimate(X []float64) float64 {
+	return floats.Dot(X, r.Theta)
+}
+
+// Coefficients returns thetas
+func (r *NNLSRegression) Coefficients() []float64 {
+	return r.Theta
+}
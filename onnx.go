@@ -0,0 +1,379 @@
+package ml
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ONNX versions written by ExportONNX: IR version 7
+// with the default and ai.onnx.ml operator sets
+const (
+	onnxIRVersion   = 7
+	onnxOpset       = 13
+	onnxMLOpset     = 1
+	onnxMLDomain    = "ai.onnx.ml"
+	onnxFloat       = 1
+	onnxInt64       = 7
+	onnxAttrFloats  = 6
+	onnxAttrInts    = 7
+	onnxAttrString  = 3
+	onnxAttrInt     = 2
+	onnxInputName   = "X"
+	onnxBatchParam  = "N"
+	onnxProducer    = "github.com/maxrafiandy/ml"
+	onnxModelDomain = "ml"
+)
+
+// onnxExporter is a model with an ONNX graph. Its input
+// is the float tensor of the same rows Estimate takes,
+// bias column included. Values are stored as float32.
+type onnxExporter interface {
+	// onnxFeatures returns number of input columns
+	onnxFeatures() (int, error)
+	// onnxNodes adds nodes reading input to g and
+	// returns the name of their output
+	onnxNodes(g *onnxGraph, input string) (string, error)
+}
+
+// onnxGraph collects nodes and outputs of the model
+type onnxGraph struct {
+	nodes   []onnxNode
+	outputs []onnxValue
+	names   int
+}
+
+type onnxNode struct {
+	op      string
+	inputs  []string
+	outputs []string
+	attrs   []onnxAttr
+}
+
+type onnxAttr struct {
+	name   string
+	kind   int
+	i      int64
+	s      string
+	floats []float64
+	ints   []int64
+}
+
+// onnxValue is a graph input or output tensor of
+// shape [N] or [N, columns]
+type onnxValue struct {
+	name    string
+	elem    int
+	columns int
+}
+
+// name returns a new unique tensor name
+func (g *onnxGraph) name(prefix string) string {
+	g.names++
+	return fmt.Sprintf("%s_%d", prefix, g.names)
+}
+
+// ExportONNX writes the model as an ONNX LinearRegressor
+// with output "variable" of shape [N, 1]
+func (l *LinearRegression) ExportONNX(w io.Writer) error {
+	return exportONNX(w, l)
+}
+
+// ExportONNX writes the model as an ONNX LinearClassifier
+// with outputs "label" and "probabilities" of classes 0
+// and 1. The label thresholds probabilities at 0.5,
+// ignoring TrueDegree.
+func (l *LogisticRegression) ExportONNX(w io.Writer) error {
+	return exportONNX(w, l)
+}
+
+// ExportONNX writes the scaler as an ONNX Scaler
+// with output "variable"
+func (s *StandardScaler) ExportONNX(w io.Writer) error {
+	return exportONNX(w, s)
+}
+
+// ExportONNX writes the steps and estimator as one ONNX
+// graph, every step and the estimator must support ONNX
+func (p *Pipeline) ExportONNX(w io.Writer) error {
+	return exportONNX(w, p)
+}
+
+func (l *Linear) onnxFeatures() (int, error) {
+	if len(l.Theta) == 0 {
+		return 0, fmt.Errorf("ml: cannot export an unfitted model")
+	}
+	if !l.IsLinear() {
+		return 0, fmt.Errorf("ml: cannot export a custom hypothesis")
+	}
+	return len(l.Theta), nil
+}
+
+func (l *LinearRegression) onnxNodes(g *onnxGraph, input string) (string, error) {
+	g.nodes = append(g.nodes, onnxNode{
+		op:      "LinearRegressor",
+		inputs:  []string{input},
+		outputs: []string{"variable"},
+		attrs: []onnxAttr{
+			{name: "coefficients", kind: onnxAttrFloats, floats: l.Theta},
+			{name: "intercepts", kind: onnxAttrFloats, floats: []float64{0}},
+			{name: "targets", kind: onnxAttrInt, i: 1},
+		},
+	})
+	g.outputs = append(g.outputs, onnxValue{name: "variable", elem: onnxFloat, columns: 1})
+	return "variable", nil
+}
+
+func (l *LogisticRegression) onnxNodes(g *onnxGraph, input string) (string, error) {
+	// scores -z and z of classes 0 and 1 turn into
+	// 1-p and p under the logistic transform
+	coefficients := make([]float64, 2*len(l.Theta))
+	for j, t := range l.Theta {
+		coefficients[j] = -t
+		coefficients[len(l.Theta)+j] = t
+	}
+
+	g.nodes = append(g.nodes, onnxNode{
+		op:      "LinearClassifier",
+		inputs:  []string{input},
+		outputs: []string{"label", "probabilities"},
+		attrs: []onnxAttr{
+			{name: "coefficients", kind: onnxAttrFloats, floats: coefficients},
+			{name: "intercepts", kind: onnxAttrFloats, floats: []float64{0, 0}},
+			{name: "classlabels_ints", kind: onnxAttrInts, ints: []int64{0, 1}},
+			{name: "post_transform", kind: onnxAttrString, s: "LOGISTIC"},
+		},
+	})
+	g.outputs = append(g.outputs,
+		onnxValue{name: "label", elem: onnxInt64},
+		onnxValue{name: "probabilities", elem: onnxFloat, columns: 2},
+	)
+	return "probabilities", nil
+}
+
+func (s *StandardScaler) onnxFeatures() (int, error) {
+	if len(s.Mean) == 0 {
+		return 0, fmt.Errorf("ml: cannot export an unfitted scaler")
+	}
+	return len(s.Mean), nil
+}
+
+func (s *StandardScaler) onnxNodes(g *onnxGraph, input string) (string, error) {
+	output := g.name("scaled")
+	scale := make([]float64, len(s.Scale))
+	for j, v := range s.Scale {
+		scale[j] = 1 / v
+	}
+
+	g.nodes = append(g.nodes, onnxNode{
+		op:      "Scaler",
+		inputs:  []string{input},
+		outputs: []string{output},
+		attrs: []onnxAttr{
+			{name: "offset", kind: onnxAttrFloats, floats: s.Mean},
+			{name: "scale", kind: onnxAttrFloats, floats: scale},
+		},
+	})
+	return output, nil
+}
+
+func (p *Pipeline) onnxFeatures() (int, error) {
+	if len(p.Steps) == 0 {
+		return onnxOf(p.Estimator).onnxFeatures()
+	}
+	return onnxOf(p.Steps[0]).onnxFeatures()
+}
+
+func (p *Pipeline) onnxNodes(g *onnxGraph, input string) (string, error) {
+	for _, step := range p.Steps {
+		e := onnxOf(step)
+		if _, err := e.onnxFeatures(); err != nil {
+			return "", err
+		}
+		var err error
+		if input, err = e.onnxNodes(g, input); err != nil {
+			return "", err
+		}
+	}
+
+	e := onnxOf(p.Estimator)
+	if _, err := e.onnxFeatures(); err != nil {
+		return "", err
+	}
+	return e.onnxNodes(g, input)
+}
+
+// onnxOf returns model as an onnxExporter, or one
+// failing with an error when it has no ONNX graph
+func onnxOf(model interface{}) onnxExporter {
+	if e, ok := model.(onnxExporter); ok {
+		return e
+	}
+	return onnxUnsupported{model}
+}
+
+type onnxUnsupported struct {
+	model interface{}
+}
+
+func (u onnxUnsupported) onnxFeatures() (int, error) {
+	return 0, fmt.Errorf("ml: %T cannot be exported to ONNX", u.model)
+}
+
+func (u onnxUnsupported) onnxNodes(g *onnxGraph, input string) (string, error) {
+	return "", fmt.Errorf("ml: %T cannot be exported to ONNX", u.model)
+}
+
+func exportONNX(w io.Writer, model onnxExporter) error {
+	features, err := model.onnxFeatures()
+	if err != nil {
+		return err
+	}
+
+	g := &onnxGraph{}
+	if _, err = model.onnxNodes(g, onnxInputName); err != nil {
+		return err
+	}
+	if len(g.outputs) == 0 {
+		// transformers alone expose their last tensor
+		g.nodes[len(g.nodes)-1].outputs[0] = "variable"
+		g.outputs = append(g.outputs, onnxValue{name: "variable", elem: onnxFloat, columns: features})
+	}
+
+	var graph protoWriter
+	for i, n := range g.nodes {
+		var node protoWriter
+		for _, in := range n.inputs {
+			node.string(1, in)
+		}
+		for _, out := range n.outputs {
+			node.string(2, out)
+		}
+		node.string(3, fmt.Sprintf("%s_%d", n.op, i))
+		node.string(4, n.op)
+		for _, a := range n.attrs {
+			node.message(5, a.proto())
+		}
+		node.string(7, onnxMLDomain)
+		graph.message(1, &node)
+	}
+	graph.string(2, "ml")
+	graph.message(11, onnxValue{name: onnxInputName, elem: onnxFloat, columns: features}.proto())
+	for _, out := range g.outputs {
+		graph.message(12, out.proto())
+	}
+
+	var m protoWriter
+	m.varint(1, onnxIRVersion)
+	m.string(2, onnxProducer)
+	m.string(4, onnxModelDomain)
+	m.message(7, &graph)
+	for _, opset := range []struct {
+		domain  string
+		version uint64
+	}{{"", onnxOpset}, {onnxMLDomain, onnxMLOpset}} {
+		var o protoWriter
+		o.string(1, opset.domain)
+		o.varint(2, opset.version)
+		m.message(8, &o)
+	}
+
+	_, err = w.Write(m.buf)
+	return err
+}
+
+func (a onnxAttr) proto() *protoWriter {
+	var p protoWriter
+	p.string(1, a.name)
+	switch a.kind {
+	case onnxAttrInt:
+		p.varint(3, uint64(a.i))
+	case onnxAttrString:
+		p.string(4, a.s)
+	case onnxAttrFloats:
+		p.floats(7, a.floats)
+	case onnxAttrInts:
+		p.ints(8, a.ints)
+	}
+	p.varint(20, uint64(a.kind))
+	return &p
+}
+
+func (v onnxValue) proto() *protoWriter {
+	var shape protoWriter
+	var batch protoWriter
+	batch.string(2, onnxBatchParam)
+	shape.message(1, &batch)
+	if v.columns > 0 {
+		var cols protoWriter
+		cols.varint(1, uint64(v.columns))
+		shape.message(1, &cols)
+	}
+
+	var tensor protoWriter
+	tensor.varint(1, uint64(v.elem))
+	tensor.message(2, &shape)
+
+	var typ protoWriter
+	typ.message(1, &tensor)
+
+	var p protoWriter
+	p.string(1, v.name)
+	p.message(2, &typ)
+	return &p
+}
+
+// protoWriter appends fields in protocol buffers wire
+// format, enough of it to write ONNX models without
+// generated code
+type protoWriter struct {
+	buf []byte
+}
+
+func (p *protoWriter) key(field, wire int) {
+	p.buf = appendUvarint(p.buf, uint64(field<<3|wire))
+}
+
+func (p *protoWriter) varint(field int, v uint64) {
+	p.key(field, 0)
+	p.buf = appendUvarint(p.buf, v)
+}
+
+func (p *protoWriter) bytes(field int, b []byte) {
+	p.key(field, 2)
+	p.buf = appendUvarint(p.buf, uint64(len(b)))
+	p.buf = append(p.buf, b...)
+}
+
+func (p *protoWriter) string(field int, s string) {
+	p.bytes(field, []byte(s))
+}
+
+func (p *protoWriter) message(field int, m *protoWriter) {
+	p.bytes(field, m.buf)
+}
+
+// floats writes packed float32 values
+func (p *protoWriter) floats(field int, v []float64) {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(float32(f)))
+	}
+	p.bytes(field, b)
+}
+
+// ints writes packed varint values
+func (p *protoWriter) ints(field int, v []int64) {
+	var b []byte
+	for _, i := range v {
+		b = appendUvarint(b, uint64(i))
+	}
+	p.bytes(field, b)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(b, tmp[:n]...)
+}
@@ -0,0 +1,173 @@
+package ml
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Option configures a linear model on construction or
+// with Apply. Invalid options are returned by Apply, or
+// by Fit of a model constructed with them.
+type Option func(*options) error
+
+type options struct {
+	linear     *Linear
+	trueDegree *float64
+}
+
+func apply(o *options, opts []Option) error {
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setting returns Setting, set to the default
+// setting when nil
+func (o *options) setting() *LinearSetting {
+	if o.linear.Setting == nil {
+		o.linear.Setting = LinearDefaultSetting()
+	}
+	return o.linear.Setting
+}
+
+// Apply applies opts to the model
+func (l *LinearRegression) Apply(opts ...Option) error {
+	return apply(&options{linear: &l.Linear}, opts)
+}
+
+// Apply applies opts to the model
+func (l *LogisticRegression) Apply(opts ...Option) error {
+	return apply(&options{linear: &l.Linear, trueDegree: &l.TrueDegree}, opts)
+}
+
+// WithLearningRate scales gradients, it must be positive
+func WithLearningRate(rate float64) Option {
+	return func(o *options) error {
+		if rate <= 0 {
+			return fmt.Errorf("ml: learning rate must be positive, got %v", rate)
+		}
+		o.linear.LearningRate = rate
+		return nil
+	}
+}
+
+// WithMaxIterations limits major iterations of the optimizer
+func WithMaxIterations(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("ml: max iterations must be positive, got %d", n)
+		}
+		o.setting().MajorIteration = n
+		return nil
+	}
+}
+
+// WithGradientThreshold stops the optimizer when the
+// gradient norm falls below threshold
+func WithGradientThreshold(threshold float64) Option {
+	return func(o *options) error {
+		if threshold < 0 {
+			return fmt.Errorf("ml: gradient threshold must not be negative, got %v", threshold)
+		}
+		o.setting().Threshod = threshold
+		return nil
+	}
+}
+
+// WithMaxDuration limits wall-clock time of training
+func WithMaxDuration(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("ml: max duration must be positive, got %v", d)
+		}
+		o.setting().MaxDuration = d
+		return nil
+	}
+}
+
+// WithMethod replaces BFGS by a method of newMethod,
+// e.g. a GeneticAlgorithm when gradients are unreliable.
+// newMethod is called on every Fit and must return a
+// new method every time.
+func WithMethod(newMethod func() optimize.Method) Option {
+	return func(o *options) error {
+		if newMethod == nil {
+			return fmt.Errorf("ml: nil method")
+		}
+		o.setting().NewMethod = newMethod
+		return nil
+	}
+}
+
+// WithHypothesis replaces the default hypothesis θ·X
+func WithHypothesis(h LinearHypothesis) Option {
+	return func(o *options) error {
+		if h == nil {
+			return fmt.Errorf("ml: hypothesis must not be nil")
+		}
+		o.linear.Hypothesis = h
+		return nil
+	}
+}
+
+// WithWarmStart starts Fit from a copy of theta
+func WithWarmStart(theta []float64) Option {
+	return func(o *options) error {
+		if len(theta) == 0 {
+			return fmt.Errorf("ml: warm start needs thetas")
+		}
+		o.linear.Theta = append([]float64(nil), theta...)
+		o.linear.WarmStart = true
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the model
+func WithLogger(logger Logger) Option {
+	return func(o *options) error {
+		o.linear.Logger = logger
+		return nil
+	}
+}
+
+// WithMetrics reports training to metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) error {
+		o.linear.Metrics = metrics
+		return nil
+	}
+}
+
+// WithCheckpoint writes a Checkpoint to w every
+// every iterations
+func WithCheckpoint(w io.Writer, every int) Option {
+	return func(o *options) error {
+		if w == nil || every <= 0 {
+			return fmt.Errorf("ml: checkpoint needs a writer and a positive interval")
+		}
+		o.linear.Checkpoint = w
+		o.linear.CheckpointEvery = every
+		return nil
+	}
+}
+
+// WithDecisionThreshold sets the probability from which
+// Predict of a logistic regression returns true
+func WithDecisionThreshold(p float64) Option {
+	return func(o *options) error {
+		if o.trueDegree == nil {
+			return fmt.Errorf("ml: decision threshold needs a logistic regression")
+		}
+		if p <= 0 || p >= 1 {
+			return fmt.Errorf("ml: decision threshold must be in (0, 1), got %v", p)
+		}
+		*o.trueDegree = p
+		return nil
+	}
+}
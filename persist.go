@@ -0,0 +1,382 @@
+package ml
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// ModelFormatVersion is the version of the binary model
+// format written by Save. Load reads every version up to
+// this one, so models saved today load in later versions
+// of the package.
+//
+// The format is the magic "GOMLMODL", the version as a big
+// endian uint16, the registered type name prefixed by its
+// uint16 length, the gob payload prefixed by its uint64
+// length, then the CRC-32 (IEEE) of everything before it.
+// Gob matches struct fields by name, so fields added to a
+// model later are zero when loading older files and fields
+// removed are skipped. Registered names never change.
+const ModelFormatVersion = 1
+
+const modelMagic = "GOMLMODL"
+
+var models = struct {
+	sync.RWMutex
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}{
+	names: map[reflect.Type]string{},
+	types: map[string]reflect.Type{},
+}
+
+// RegisterModel makes model, a pointer to a struct, known
+// to Save and Load under name. Models held in interface
+// fields, such as pipeline steps, must be registered too.
+// Func fields are not saved, so loaded models need them,
+// e.g. NewEstimator, set again before refitting. Gob
+// cannot encode *rand.Rand, models with a Rand field
+// implement GobEncode and GobDecode with GobEncodeModel
+// and GobDecodeModel, leaving it out.
+// Subpackages register their models when imported.
+func RegisterModel(name string, model interface{}) {
+	t := reflect.TypeOf(model)
+	if t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("ml: RegisterModel of non-pointer %v", t))
+	}
+
+	models.Lock()
+	defer models.Unlock()
+	if other, ok := models.types[name]; ok && other != t {
+		panic(fmt.Sprintf("ml: model name %q registered for %v and %v", name, other, t))
+	}
+
+	gob.RegisterName(name, model)
+	models.names[t] = name
+	models.types[name] = t
+}
+
+var randType = reflect.TypeOf((*rand.Rand)(nil))
+
+// savedFields returns a struct type of the exported
+// fields of t but *rand.Rand ones, and their indices in t
+func savedFields(t reflect.Type) (reflect.Type, []int) {
+	var (
+		fields []reflect.StructField
+		index  []int
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type == randType {
+			continue
+		}
+		// gob names embedded fields by their type
+		fields = append(fields, reflect.StructField{Name: f.Name, Type: f.Type})
+		index = append(index, i)
+	}
+	return reflect.StructOf(fields), index
+}
+
+// GobEncodeModel encodes the exported fields of model, a
+// pointer to struct, leaving out *rand.Rand fields
+func GobEncodeModel(model interface{}) ([]byte, error) {
+	v := reflect.ValueOf(model).Elem()
+	t, index := savedFields(v.Type())
+	saved := reflect.New(t).Elem()
+	for i, j := range index {
+		saved.Field(i).Set(v.Field(j))
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).EncodeValue(saved)
+	return buf.Bytes(), err
+}
+
+// GobDecodeModel decodes data of GobEncodeModel into
+// model, a pointer to struct
+func GobDecodeModel(model interface{}, data []byte) error {
+	v := reflect.ValueOf(model).Elem()
+	t, index := savedFields(v.Type())
+	saved := reflect.New(t)
+	if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(saved); err != nil {
+		return err
+	}
+	for i, j := range index {
+		v.Field(j).Set(saved.Elem().Field(i))
+	}
+	return nil
+}
+
+func init() {
+	RegisterModel("ml.LinearRegression", &LinearRegression{})
+	RegisterModel("ml.LogisticRegression", &LogisticRegression{})
+	RegisterModel("ml.Pipeline", &Pipeline{})
+	RegisterModel("ml.IsotonicRegression", &IsotonicRegression{})
+	RegisterModel("ml.StandardScaler", &StandardScaler{})
+	RegisterModel("ml.BiasTransformer", &BiasTransformer{})
+	RegisterModel("ml.PlattScaling", &PlattScaling{})
+	RegisterModel("ml.CalibratedClassifier", &CalibratedClassifier{})
+	RegisterModel("ml.RFE", &RFE{})
+	RegisterModel("ml.SequentialSelector", &SequentialSelector{})
+	RegisterModel("ml.Stepwise", &Stepwise{})
+	RegisterModel("ml.ElasticNet", &ElasticNet{})
+	RegisterModel("ml.ElasticNetCV", &ElasticNetCV{})
+	RegisterModel("ml.GroupLasso", &GroupLasso{})
+	RegisterModel("ml.NNLSRegression", &NNLSRegression{})
+	RegisterModel("ml.TotalLeastSquares", &TotalLeastSquares{})
+	RegisterModel("ml.RANSAC", &RANSAC{})
+	RegisterModel("ml.TheilSen", &TheilSen{})
+	RegisterModel("ml.SegmentedRegression", &SegmentedRegression{})
+	RegisterModel("ml.NegativeBinomialRegression", &NegativeBinomialRegression{})
+	RegisterModel("ml.Tobit", &Tobit{})
+	RegisterModel("ml.MixedModel", &MixedModel{})
+	RegisterModel("ml.ConditionalLogistic", &ConditionalLogistic{})
+	RegisterModel("ml.SoftmaxRegression", &SoftmaxRegression{})
+	RegisterModel("ml.ProbitRegression", &ProbitRegression{})
+	RegisterModel("ml.MultiLabelBinarizer", &MultiLabelBinarizer{})
+	RegisterModel("ml.BinaryRelevance", &BinaryRelevance{})
+	RegisterModel("ml.ClassifierChain", &ClassifierChain{})
+	RegisterModel("ml.HierarchicalClassifier", &HierarchicalClassifier{})
+	RegisterModel("ml.SelfTraining", &SelfTraining{})
+	RegisterModel("ml.LabelPropagation", &LabelPropagation{})
+	RegisterModel("ml.Quantized", &Quantized{})
+	RegisterModel("ml.HMM", &HMM{})
+	RegisterModel("ml.DiscreteEmission", &DiscreteEmission{})
+	RegisterModel("ml.GaussianEmission", &GaussianEmission{})
+	RegisterModel("ml.KalmanFilter", &KalmanFilter{})
+	RegisterModel("ml.AutoML", &AutoML{})
+	RegisterModel("ml.SGD", &SGD{})
+	RegisterModel("ml.Instrumented", &Instrumented{})
+	RegisterModel("ml.PELT", &PELT{})
+	RegisterModel("ml.BinarySegmentation", &BinarySegmentation{})
+	RegisterModel("ml.CUSUM", &CUSUM{})
+	RegisterModel("ml.NormalMeanCost", &NormalMeanCost{})
+	RegisterModel("ml.NormalMeanVarCost", &NormalMeanVarCost{})
+
+	gob.RegisterName("ml.KFold", KFold{})
+	gob.RegisterName("ml.ExpandingWindow", ExpandingWindow{})
+	gob.RegisterName("ml.SlidingWindow", SlidingWindow{})
+}
+
+// Save writes model in the versioned binary format
+func Save(w io.Writer, model interface{}) error {
+	models.RLock()
+	name, ok := models.names[reflect.TypeOf(model)]
+	models.RUnlock()
+	if !ok {
+		return fmt.Errorf("ml: model type %T is not registered", model)
+	}
+
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(model); err != nil {
+		return fmt.Errorf("ml: encoding %s: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(modelMagic)
+	binary.Write(&buf, binary.BigEndian, uint16(ModelFormatVersion))
+	binary.Write(&buf, binary.BigEndian, uint16(len(name)))
+	buf.WriteString(name)
+	binary.Write(&buf, binary.BigEndian, uint64(payload.Len()))
+	buf.Write(payload.Bytes())
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Load reads a model written by Save, returning a
+// pointer of its registered type
+func Load(r io.Reader) (interface{}, error) {
+	h := crc32.NewIEEE()
+	tr := io.TeeReader(r, h)
+
+	magic := make([]byte, len(modelMagic))
+	if _, err := io.ReadFull(tr, magic); err != nil {
+		return nil, fmt.Errorf("ml: reading model header: %v", err)
+	}
+	if string(magic) != modelMagic {
+		return nil, fmt.Errorf("ml: not a model file")
+	}
+
+	var version, nameLen uint16
+	if err := binary.Read(tr, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("ml: reading model header: %v", err)
+	}
+	if version == 0 || version > ModelFormatVersion {
+		return nil, fmt.Errorf("ml: model format version %d is newer than supported version %d", version, ModelFormatVersion)
+	}
+	if err := binary.Read(tr, binary.BigEndian, &nameLen); err != nil {
+		return nil, fmt.Errorf("ml: reading model header: %v", err)
+	}
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(tr, name); err != nil {
+		return nil, fmt.Errorf("ml: reading model header: %v", err)
+	}
+
+	var size uint64
+	if err := binary.Read(tr, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("ml: reading model header: %v", err)
+	}
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, tr, int64(size)); err != nil {
+		return nil, fmt.Errorf("ml: reading model payload: %v", err)
+	}
+
+	sum := h.Sum32()
+	var stored uint32
+	if err := binary.Read(r, binary.BigEndian, &stored); err != nil {
+		return nil, fmt.Errorf("ml: reading model checksum: %v", err)
+	}
+	if stored != sum {
+		return nil, fmt.Errorf("ml: model checksum mismatch, file is corrupted")
+	}
+
+	models.RLock()
+	t, ok := models.types[string(name)]
+	models.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("ml: unknown model type %q", name)
+	}
+
+	model := reflect.New(t.Elem())
+	if err := gob.NewDecoder(&payload).DecodeValue(model); err != nil {
+		return nil, fmt.Errorf("ml: decoding %s: %v", name, err)
+	}
+
+	return model.Interface(), nil
+}
+
+// SaveFile writes model to path
+func SaveFile(path string, model interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err = Save(w, model); err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// LoadFile reads a model from path
+func LoadFile(path string) (interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(bufio.NewReader(f))
+}
+
+// linearState is the saved state of linear models, the
+// hypothesis is restored to the default one
+type linearState struct {
+	FeatureNames []string
+	Theta        []float64
+	LearningRate float64
+	Setting      *LinearSetting
+	TrueDegree   float64
+	// TrueDegreeSet is false in files saved before
+	// a zero TrueDegree could be set
+	TrueDegreeSet bool
+}
+
+// IsLinear tells whether Hypothesis is the default θ·X,
+// models with a custom hypothesis cannot be exported
+func (l *Linear) IsLinear() bool {
+	return l.Hypothesis == nil || reflect.ValueOf(l.Hypothesis).Pointer() == reflect.ValueOf(linearHypothesis).Pointer()
+}
+
+func (l *Linear) state() (*linearState, error) {
+	if !l.IsLinear() {
+		return nil, fmt.Errorf("ml: cannot save a custom hypothesis")
+	}
+	setting := l.Setting
+	if setting != nil && (setting.NewMethod != nil || setting.Schedule != nil) {
+		// methods and schedules are not saved
+		s := *setting
+		s.NewMethod, s.Schedule = nil, nil
+		setting = &s
+	}
+	return &linearState{
+		FeatureNames: l.FeatureNames,
+		Theta:        l.Theta,
+		LearningRate: l.LearningRate,
+		Setting:      setting,
+	}, nil
+}
+
+func (l *Linear) restore(s *linearState) {
+	l.FeatureNames = s.FeatureNames
+	l.Theta = s.Theta
+	l.LearningRate = s.LearningRate
+	l.Setting = s.Setting
+	l.Hypothesis = linearHypothesis
+}
+
+// GobEncode saves thetas and settings, training
+// data and optimizer result are not saved
+func (l *LinearRegression) GobEncode() ([]byte, error) {
+	s, err := l.state()
+	if err != nil {
+		return nil, err
+	}
+	return encodeGob(s)
+}
+
+// GobDecode restores a saved LinearRegression
+func (l *LinearRegression) GobDecode(data []byte) error {
+	var s linearState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	l.restore(&s)
+	return nil
+}
+
+// GobEncode saves thetas and settings, training
+// data and optimizer result are not saved
+func (l *LogisticRegression) GobEncode() ([]byte, error) {
+	s, err := l.state()
+	if err != nil {
+		return nil, err
+	}
+	s.TrueDegree = l.TrueDegree
+	s.TrueDegreeSet = l.trueDegreeSet
+	return encodeGob(s)
+}
+
+// GobDecode restores a saved LogisticRegression
+func (l *LogisticRegression) GobDecode(data []byte) error {
+	var s linearState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	l.restore(&s)
+	l.TrueDegree = s.TrueDegree
+	l.trueDegreeSet = s.TrueDegreeSet
+	return nil
+}
+
+func encodeGob(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
@@ -0,0 +1,187 @@
+package ml
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveLeavesOutRand(t *testing.T) {
+	features := make([][]float64, 30)
+	output := make([]float64, len(features))
+	for i := range features {
+		x := float64(i)
+		features[i] = []float64{1, x}
+		output[i] = 3 - x
+	}
+	output[7] = 100
+
+	model := NewTheilSen()
+	model.Rand = rand.New(rand.NewSource(1))
+	if err := model.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	v, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := v.(*TheilSen)
+	if loaded.Rand != nil || loaded.MaxSubpopulation != model.MaxSubpopulation || !reflect.DeepEqual(loaded.Theta, model.Theta) {
+		t.Errorf("loaded %+v, saved %+v", loaded, model)
+	}
+}
+
+func roundTrip(t *testing.T, model interface{}) interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	v, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSaveHMM(t *testing.T) {
+	seqs := [][][]float64{{{0}, {0}, {1}, {1}, {0}, {1}, {1}, {1}, {0}, {0}}}
+	hmm := NewDiscreteHMM(2, 2)
+	hmm.Rand = rand.New(rand.NewSource(1))
+	if _, err := hmm.Fit(seqs, 10, 1e-6); err != nil {
+		t.Fatal(err)
+	}
+	loaded := roundTrip(t, hmm).(*HMM)
+	if loaded.Rand != nil || loaded.LogLikelihood(seqs[0]) != hmm.LogLikelihood(seqs[0]) {
+		t.Errorf("loaded %+v, saved %+v", loaded, hmm)
+	}
+}
+
+func TestSaveKalman(t *testing.T) {
+	kalman := NewKalmanFilter([][]float64{{1}}, [][]float64{{1}}, [][]float64{{0.1}}, [][]float64{{1}})
+	kalman.Update([]float64{2})
+	if loaded := roundTrip(t, kalman).(*KalmanFilter); !reflect.DeepEqual(loaded, kalman) {
+		t.Errorf("loaded %+v, saved %+v", loaded, kalman)
+	}
+}
+
+type failingEstimator struct{}
+
+func (failingEstimator) Fit(features [][]float64, output []float64) error {
+	return errors.New("always fails")
+}
+
+func (failingEstimator) Estimate(X []float64) float64 {
+	return 0
+}
+
+func TestSaveAutoML(t *testing.T) {
+	features, output := lineData(40, 2)
+	a := &AutoML{
+		Task: RegressionTask,
+		Candidates: []AutoCandidate{
+			{Name: "linear", New: func(time.Duration) Estimator { return NewLinearRegression() }},
+			{Name: "failing", New: func(time.Duration) Estimator { return failingEstimator{} }},
+		},
+		Rand: rand.New(rand.NewSource(1)),
+	}
+	if err := a.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := roundTrip(t, a).(*AutoML)
+	if loaded.BestName != "linear" || loaded.Best.Estimate(features[3]) != a.Best.Estimate(features[3]) {
+		t.Errorf("loaded best %s %+v, saved %s %+v", loaded.BestName, loaded.Best, a.BestName, a.Best)
+	}
+	if len(loaded.Leaderboard) != 2 || loaded.Leaderboard[1].Err == nil || loaded.Leaderboard[1].Err.Error() != a.Leaderboard[1].Err.Error() {
+		t.Errorf("loaded leaderboard %+v, saved %+v", loaded.Leaderboard, a.Leaderboard)
+	}
+}
+
+func TestSaveSGD(t *testing.T) {
+	features, output := lineData(64, 2)
+	s := NewAdam(NewLinearRegression())
+	s.Rand = rand.New(rand.NewSource(1))
+	s.Epochs = 2
+	s.Privacy = &Privacy{Clip: 1, NoiseMultiplier: 1, Delta: 1e-5}
+	if err := s.Fit(Slices{features, output}); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := roundTrip(t, s).(*SGD)
+	if loaded.Rand != nil || !reflect.DeepEqual(loaded.Model.Coefficients(), s.Model.Coefficients()) {
+		t.Errorf("loaded thetas %v, saved %v", loaded.Model.Coefficients(), s.Model.Coefficients())
+	}
+	if !reflect.DeepEqual(loaded.Adam, s.Adam) || !reflect.DeepEqual(loaded.Accountant, s.Accountant) {
+		t.Errorf("loaded Adam %+v and %+v, saved %+v and %+v", loaded.Adam, loaded.Accountant, s.Adam, s.Accountant)
+	}
+	if loaded.Accountant.Epsilon(1e-5) != s.Accountant.Epsilon(1e-5) || !reflect.DeepEqual(loaded.Losses, s.Losses) {
+		t.Errorf("loaded privacy spent %v, saved %v", loaded.Accountant.Epsilon(1e-5), s.Accountant.Epsilon(1e-5))
+	}
+}
+
+func TestSaveInstrumented(t *testing.T) {
+	features, output := lineData(20, 2)
+	i := NewInstrumented(NewLinearRegression(), &countingMetrics{}, "linear")
+	if err := i.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+
+	// Metrics is left out and nothing is reported
+	// until it is set again
+	loaded := roundTrip(t, i).(*Instrumented)
+	if loaded.Metrics != nil || loaded.Name != "linear" || loaded.Estimate(features[2]) != i.Estimate(features[2]) {
+		t.Errorf("loaded %+v, saved %+v", loaded, i)
+	}
+	if err := loaded.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveChangePoints(t *testing.T) {
+	series := make([][]float64, 40)
+	for i := range series {
+		series[i] = []float64{float64(i / 20 * 5)}
+	}
+
+	for _, cost := range []SegmentCost{&NormalMeanCost{}, &NormalMeanVarCost{}} {
+		cost.Fit(series)
+		loaded := roundTrip(t, cost).(SegmentCost)
+		if loaded.Cost(5, 30) != cost.Cost(5, 30) {
+			t.Errorf("loaded %T cost %v, saved %v", cost, loaded.Cost(5, 30), cost.Cost(5, 30))
+		}
+	}
+
+	pelt := NewPELT(10)
+	want, err := pelt.Detect(series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := roundTrip(t, pelt).(*PELT).Detect(series); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded PELT detected %v, %v, want %v", got, err, want)
+	}
+	segmentation := NewBinarySegmentation(10)
+	want, err = segmentation.Detect(series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := roundTrip(t, segmentation).(*BinarySegmentation).Detect(series); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded binary segmentation detected %v, %v, want %v", got, err, want)
+	}
+
+	// a CUSUM saved mid-stream continues it
+	c := NewCUSUM(10)
+	c.Detect(series[:15])
+	loaded := roundTrip(t, c).(*CUSUM)
+	if got, want := loaded.Detect(series[15:]), c.Detect(series[15:]); !reflect.DeepEqual(got, want) || len(want) == 0 {
+		t.Errorf("loaded CUSUM detected %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package ml
+
+import "context"
+
+// Pipeline chains transformer steps in front
+// of an estimator. Pipeline is an Estimator.
+type Pipeline struct {
+	Steps     []Transformer
+	Estimator Estimator
+	// FeatureNames names the input columns,
+	// it is optional
+	FeatureNames []string
+}
+
+// NewPipeline returns new pointer of Pipeline
+// applying steps in order before estimator
+func NewPipeline(estimator Estimator, steps ...Transformer) *Pipeline {
+	return &Pipeline{
+		Steps:     steps,
+		Estimator: estimator,
+	}
+}
+
+// Fit fits every step on the output of the previous
+// step, then fits the estimator on the last output
+func (p *Pipeline) Fit(features [][]float64, output []float64) error {
+	return p.FitContext(context.Background(), features, output)
+}
+
+// FitContext is Fit with a context for tracing
+func (p *Pipeline) FitContext(ctx context.Context, features [][]float64, output []float64) error {
+	if len(features) > 0 {
+		if err := checkNames(p.FeatureNames, len(features[0])); err != nil {
+			return err
+		}
+	}
+	for _, step := range p.Steps {
+		if err := step.Fit(features, output); err != nil {
+			return err
+		}
+		features = step.Transform(features)
+	}
+
+	return fitContext(ctx, p.Estimator, features, output)
+}
+
+// Transform applies every step to features
+func (p *Pipeline) Transform(features [][]float64) [][]float64 {
+	for _, step := range p.Steps {
+		features = step.Transform(features)
+	}
+	return features
+}
+
+// Estimate transforms X and returns the estimate
+func (p *Pipeline) Estimate(X []float64) float64 {
+	return p.Estimator.Estimate(p.Transform([][]float64{X})[0])
+}
+
+// PredictBatch transforms X and returns the estimate
+// of every row, in a batch when Estimator is a
+// BatchEstimator
+func (p *Pipeline) PredictBatch(X [][]float64) ([]float64, error) {
+	return predictAll(p.Estimator, p.Transform(X))
+}
@@ -0,0 +1,330 @@
+package ml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PMML 4.4 RegressionModel documents are supported. The
+// package has no tree models, so TreeModel documents are
+// rejected by ImportPMML.
+const (
+	pmmlVersion   = "4.4"
+	pmmlNamespace = "http://www.dmg.org/PMML-4_4"
+	pmmlTarget    = "y"
+)
+
+type pmmlDocument struct {
+	XMLName         xml.Name             `xml:"PMML"`
+	Namespace       string               `xml:"xmlns,attr,omitempty"`
+	Version         string               `xml:"version,attr"`
+	Header          pmmlHeader           `xml:"Header"`
+	DataDictionary  pmmlDataDictionary   `xml:"DataDictionary"`
+	RegressionModel *pmmlRegressionModel `xml:"RegressionModel"`
+	TreeModel       *struct{}            `xml:"TreeModel"`
+}
+
+type pmmlHeader struct {
+	Application pmmlApplication `xml:"Application"`
+}
+
+type pmmlApplication struct {
+	Name string `xml:"name,attr"`
+}
+
+type pmmlDataDictionary struct {
+	NumberOfFields int             `xml:"numberOfFields,attr"`
+	Fields         []pmmlDataField `xml:"DataField"`
+}
+
+type pmmlDataField struct {
+	Name     string      `xml:"name,attr"`
+	OpType   string      `xml:"optype,attr"`
+	DataType string      `xml:"dataType,attr"`
+	Values   []pmmlValue `xml:"Value"`
+}
+
+type pmmlValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type pmmlRegressionModel struct {
+	FunctionName  string            `xml:"functionName,attr"`
+	Normalization string            `xml:"normalizationMethod,attr,omitempty"`
+	MiningSchema  pmmlMiningSchema  `xml:"MiningSchema"`
+	Tables        []pmmlRegressionT `xml:"RegressionTable"`
+}
+
+type pmmlMiningSchema struct {
+	Fields []pmmlMiningField `xml:"MiningField"`
+}
+
+type pmmlMiningField struct {
+	Name      string `xml:"name,attr"`
+	UsageType string `xml:"usageType,attr,omitempty"`
+}
+
+// pmmlRegressionT is a RegressionTable
+type pmmlRegressionT struct {
+	Intercept             float64                 `xml:"intercept,attr"`
+	TargetCategory        string                  `xml:"targetCategory,attr,omitempty"`
+	NumericPredictors     []pmmlNumericPredictor  `xml:"NumericPredictor"`
+	CategoricalPredictors []pmmlCategoricalPredic `xml:"CategoricalPredictor"`
+}
+
+type pmmlNumericPredictor struct {
+	Name        string  `xml:"name,attr"`
+	Exponent    int     `xml:"exponent,attr,omitempty"`
+	Coefficient float64 `xml:"coefficient,attr"`
+}
+
+// pmmlCategoricalPredic is a CategoricalPredictor,
+// only read to reject it
+type pmmlCategoricalPredic struct {
+	Name string `xml:"name,attr"`
+}
+
+// ExportPMML writes the model as a PMML RegressionModel.
+// Every theta is a coefficient of field x1, x2, ...,
+// or of its FeatureNames, and the intercept is zero.
+func (l *LinearRegression) ExportPMML(w io.Writer) error {
+	return exportPMML(w, l, false, l.FeatureNames)
+}
+
+// ExportPMML writes the model as a PMML RegressionModel
+// with logit normalization. Every theta is a coefficient
+// of field x1, x2, ..., or of its FeatureNames, and the
+// intercept is zero.
+func (l *LogisticRegression) ExportPMML(w io.Writer) error {
+	return exportPMML(w, l, false, l.FeatureNames)
+}
+
+// ExportPMML writes a pipeline of a BiasTransformer and
+// a linear model as a PMML RegressionModel, with the
+// first theta as intercept. Fields are named by
+// FeatureNames of the pipeline when set.
+func (p *Pipeline) ExportPMML(w io.Writer) error {
+	if len(p.Steps) != 1 {
+		return fmt.Errorf("ml: PMML export needs a pipeline of a BiasTransformer and a linear model")
+	}
+	if _, ok := p.Steps[0].(*BiasTransformer); !ok {
+		return fmt.Errorf("ml: PMML export needs a pipeline of a BiasTransformer and a linear model")
+	}
+	return exportPMML(w, p.Estimator, true, p.FeatureNames)
+}
+
+func exportPMML(w io.Writer, model Estimator, intercept bool, names []string) error {
+	var (
+		linear   *Linear
+		function = "regression"
+	)
+	switch m := model.(type) {
+	case *LinearRegression:
+		linear = &m.Linear
+	case *LogisticRegression:
+		linear = &m.Linear
+		function = "classification"
+	default:
+		return fmt.Errorf("ml: %T cannot be exported to PMML", model)
+	}
+	if len(linear.Theta) == 0 {
+		return fmt.Errorf("ml: cannot export an unfitted model")
+	}
+	if !linear.IsLinear() {
+		return fmt.Errorf("ml: cannot export a custom hypothesis")
+	}
+
+	theta := linear.Theta
+	table := pmmlRegressionT{}
+	if intercept {
+		table.Intercept = theta[0]
+		theta = theta[1:]
+	}
+
+	doc := &pmmlDocument{
+		Namespace: pmmlNamespace,
+		Version:   pmmlVersion,
+		Header:    pmmlHeader{Application: pmmlApplication{Name: "github.com/maxrafiandy/ml"}},
+		RegressionModel: &pmmlRegressionModel{
+			FunctionName: function,
+		},
+	}
+	rm := doc.RegressionModel
+
+	if names != nil && len(names) != len(theta) {
+		return fmt.Errorf("ml: got %d feature names for %d fields", len(names), len(theta))
+	}
+	for j, t := range theta {
+		name := "x" + strconv.Itoa(j+1)
+		if names != nil {
+			name = names[j]
+		}
+		doc.DataDictionary.Fields = append(doc.DataDictionary.Fields, pmmlDataField{Name: name, OpType: "continuous", DataType: "double"})
+		rm.MiningSchema.Fields = append(rm.MiningSchema.Fields, pmmlMiningField{Name: name})
+		table.NumericPredictors = append(table.NumericPredictors, pmmlNumericPredictor{Name: name, Coefficient: t})
+	}
+
+	target := pmmlDataField{Name: pmmlTarget, OpType: "continuous", DataType: "double"}
+	if function == "classification" {
+		target = pmmlDataField{
+			Name:     pmmlTarget,
+			OpType:   "categorical",
+			DataType: "integer",
+			Values:   []pmmlValue{{"0"}, {"1"}},
+		}
+		rm.Normalization = "logit"
+		table.TargetCategory = "1"
+		rm.Tables = []pmmlRegressionT{table, {TargetCategory: "0"}}
+	} else {
+		rm.Tables = []pmmlRegressionT{table}
+	}
+	doc.DataDictionary.Fields = append(doc.DataDictionary.Fields, target)
+	doc.DataDictionary.NumberOfFields = len(doc.DataDictionary.Fields)
+	rm.MiningSchema.Fields = append(rm.MiningSchema.Fields, pmmlMiningField{Name: pmmlTarget, UsageType: "target"})
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ImportPMML reads a PMML RegressionModel, returning a
+// pipeline of a BiasTransformer and a LinearRegression,
+// or a LogisticRegression for binary classification with
+// logit or softmax normalization. The pipeline estimates
+// rows of the active fields in MiningSchema order.
+func ImportPMML(r io.Reader) (*Pipeline, error) {
+	var doc pmmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ml: reading PMML: %v", err)
+	}
+	if doc.TreeModel != nil {
+		return nil, fmt.Errorf("ml: PMML TreeModel is not supported")
+	}
+	rm := doc.RegressionModel
+	if rm == nil {
+		return nil, fmt.Errorf("ml: PMML has no RegressionModel")
+	}
+
+	var active []string
+	for _, f := range rm.MiningSchema.Fields {
+		switch f.UsageType {
+		case "", "active":
+			active = append(active, f.Name)
+		case "target", "predicted", "supplementary":
+		default:
+			return nil, fmt.Errorf("ml: PMML field %q has unsupported usage %q", f.Name, f.UsageType)
+		}
+	}
+
+	theta := func(t pmmlRegressionT) ([]float64, error) {
+		if len(t.CategoricalPredictors) > 0 {
+			return nil, fmt.Errorf("ml: PMML CategoricalPredictor %q is not supported", t.CategoricalPredictors[0].Name)
+		}
+		out := make([]float64, len(active)+1)
+		out[0] = t.Intercept
+		for _, p := range t.NumericPredictors {
+			if p.Exponent > 1 {
+				return nil, fmt.Errorf("ml: PMML predictor %q has exponent %d", p.Name, p.Exponent)
+			}
+			j := indexOf(active, p.Name)
+			if j < 0 {
+				return nil, fmt.Errorf("ml: PMML predictor %q is not an active field", p.Name)
+			}
+			out[j+1] += p.Coefficient
+		}
+		return out, nil
+	}
+
+	switch rm.FunctionName {
+	case "regression":
+		if len(rm.Tables) != 1 {
+			return nil, fmt.Errorf("ml: PMML regression has %d tables, expected 1", len(rm.Tables))
+		}
+		if rm.Normalization != "" && rm.Normalization != "none" {
+			return nil, fmt.Errorf("ml: PMML regression normalization %q is not supported", rm.Normalization)
+		}
+		t, err := theta(rm.Tables[0])
+		if err != nil {
+			return nil, err
+		}
+		model := NewLinearRegression()
+		model.Theta = t
+		p := NewPipeline(model, NewBiasTransformer())
+		p.FeatureNames = active
+		return p, nil
+
+	case "classification":
+		if len(rm.Tables) != 2 {
+			return nil, fmt.Errorf("ml: PMML classification has %d tables, only binary is supported", len(rm.Tables))
+		}
+		if rm.Normalization != "logit" && rm.Normalization != "softmax" {
+			return nil, fmt.Errorf("ml: PMML classification normalization %q is not supported", rm.Normalization)
+		}
+
+		pos := -1
+		for i, table := range rm.Tables {
+			switch strings.ToLower(table.TargetCategory) {
+			case "1", "true":
+				pos = i
+			}
+		}
+		if pos < 0 {
+			return nil, fmt.Errorf("ml: PMML classification has no table for target category \"1\" or \"true\"")
+		}
+
+		// logit is the probability of the first table's
+		// category, softmax of two tables is the logistic
+		// of their difference
+		var t []float64
+		if rm.Normalization == "logit" {
+			first, err := theta(rm.Tables[0])
+			if err != nil {
+				return nil, err
+			}
+			t = first
+			if pos != 0 {
+				for j := range t {
+					t[j] = -t[j]
+				}
+			}
+		} else {
+			var err error
+			if t, err = theta(rm.Tables[pos]); err != nil {
+				return nil, err
+			}
+			other, err := theta(rm.Tables[1-pos])
+			if err != nil {
+				return nil, err
+			}
+			for j := range t {
+				t[j] -= other[j]
+			}
+		}
+		model := NewLogisticRegression()
+		model.Theta = t
+		p := NewPipeline(model, NewBiasTransformer())
+		p.FeatureNames = active
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("ml: PMML function %q is not supported", rm.FunctionName)
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,53 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+const pmmlReversedTables = `<?xml version="1.0"?>
+<PMML version="4.4" xmlns="http://www.dmg.org/PMML-4_4">
+  <DataDictionary numberOfFields="2">
+    <DataField name="x" optype="continuous" dataType="double"/>
+    <DataField name="y" optype="categorical" dataType="integer"/>
+  </DataDictionary>
+  <RegressionModel functionName="classification" normalizationMethod="%s">
+    <MiningSchema>
+      <MiningField name="x"/>
+      <MiningField name="y" usageType="target"/>
+    </MiningSchema>
+    <RegressionTable intercept="%s" targetCategory="0">%s</RegressionTable>
+    <RegressionTable intercept="%s" targetCategory="1">%s</RegressionTable>
+  </RegressionModel>
+</PMML>`
+
+func TestImportPMMLTargetCategory(t *testing.T) {
+	coef := func(c string) string {
+		if c == "" {
+			return ""
+		}
+		return `<NumericPredictor name="x" coefficient="` + c + `"/>`
+	}
+	build := func(norm, i0, c0, i1, c1 string) string {
+		return fmt.Sprintf(pmmlReversedTables, norm, i0, coef(c0), i1, coef(c1))
+	}
+
+	// P(y=1|x) is the logistic of 1+2x in every document
+	docs := map[string]string{
+		"logit first negative": build("logit", "-1", "-2", "0", ""),
+		"softmax reversed":     build("softmax", "0.5", "-1", "1.5", "1"),
+	}
+	for name, doc := range docs {
+		p, err := ImportPMML(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got := p.Estimate([]float64{0.5})
+		want := 1 / (1 + math.Exp(-2))
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: P(y=1) = %v, want %v", name, got, want)
+		}
+	}
+}
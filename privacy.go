@@ -0,0 +1,184 @@
+package ml
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Privacy makes SGD differentially private (DP-SGD): the
+// gradient of every row is clipped to L2 norm Clip and
+// gaussian noise of standard deviation NoiseMultiplier
+// times Clip is added to the sum of every batch. Noise is
+// drawn from SGD.Rand, which must not be seeded with a
+// known seed in production. Losses of SGD are not private.
+type Privacy struct {
+	Clip            float64
+	NoiseMultiplier float64
+	// Delta is δ of the (ε, δ) guarantee, usually
+	// less than 1 over the number of rows
+	Delta float64
+}
+
+// privateGrad sets grad to the clipped and noised mean
+// gradient of rows of l.Features at theta
+func (s *SGD) privateGrad(grad, theta []float64, r *rand.Rand) {
+	l := s.Model.linear()
+	features, output := l.Features, l.Output
+	defer func() { l.Features, l.Output = features, output }()
+
+	p := s.Privacy
+	row := make([]float64, len(grad))
+	for j := range grad {
+		grad[j] = 0
+	}
+	for i := range features {
+		l.Features, l.Output = features[i:i+1], output[i:i+1]
+		s.Model.Grad(row, theta)
+		if norm := floats.Norm(row, 2); norm > p.Clip {
+			floats.Scale(p.Clip/norm, row)
+		}
+		floats.Add(grad, row)
+	}
+	for j := range grad {
+		grad[j] = (grad[j] + r.NormFloat64()*p.NoiseMultiplier*p.Clip) / float64(len(features))
+	}
+}
+
+// rdpOrders are the Rényi orders tracked by Accountant
+var rdpOrders = func() []float64 {
+	var orders []float64
+	for a := 2; a <= 256; a++ {
+		orders = append(orders, float64(a))
+	}
+	return orders
+}()
+
+// Accountant adds up privacy spent by DP-SGD steps with
+// Rényi differential privacy of the sampled gaussian
+// mechanism, as TensorFlow Privacy and Opacus do. It
+// assumes rows join a batch independently with
+// probability q, which shuffled batches approximate.
+type Accountant struct {
+	rdp []float64
+}
+
+// Step adds steps of DP-SGD with sampling rate q
+// and noise multiplier sigma
+func (a *Accountant) Step(q, sigma float64, steps int) {
+	if a.rdp == nil {
+		a.rdp = make([]float64, len(rdpOrders))
+	}
+	for i, alpha := range rdpOrders {
+		a.rdp[i] += float64(steps) * sampledGaussianRDP(q, sigma, int(alpha))
+	}
+}
+
+// Epsilon returns ε of the (ε, δ) guarantee
+// of all steps so far
+func (a *Accountant) Epsilon(delta float64) float64 {
+	if a.rdp == nil {
+		return 0
+	}
+	eps := math.Inf(1)
+	for i, alpha := range rdpOrders {
+		eps = math.Min(eps, a.rdp[i]+math.Log(1/delta)/(alpha-1))
+	}
+	return eps
+}
+
+// GobEncode saves privacy spent so far
+func (a *Accountant) GobEncode() ([]byte, error) {
+	return encodeGob(a.rdp)
+}
+
+// GobDecode restores a saved Accountant
+func (a *Accountant) GobDecode(data []byte) error {
+	a.rdp = nil
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(&a.rdp)
+}
+
+// sampledGaussianRDP returns RDP of order alpha of one
+// step of the sampled gaussian mechanism (Mironov et
+// al. 2019), summed in log space
+func sampledGaussianRDP(q, sigma float64, alpha int) float64 {
+	if q == 0 {
+		return 0
+	}
+	if sigma == 0 {
+		return math.Inf(1)
+	}
+	if q >= 1 {
+		return float64(alpha) / (2 * sigma * sigma)
+	}
+
+	terms := make([]float64, alpha+1)
+	for k := range terms {
+		lgA, _ := math.Lgamma(float64(alpha + 1))
+		lgK, _ := math.Lgamma(float64(k + 1))
+		lgAK, _ := math.Lgamma(float64(alpha - k + 1))
+		terms[k] = lgA - lgK - lgAK +
+			float64(k)*math.Log(q) + float64(alpha-k)*math.Log1p(-q) +
+			float64(k*k-k)/(2*sigma*sigma)
+	}
+	return floats.LogSumExp(terms) / float64(alpha-1)
+}
+
+// GaussianMechanism returns values with gaussian noise
+// making them (ε, δ) differentially private, for values
+// whose L2 norm changes at most sensitivity when a row
+// changes. Use it for output perturbation of thetas of a
+// model whose sensitivity is known, e.g. 2L/(nλ) for an
+// L-Lipschitz loss with L2 regularization λ over n rows.
+// The noise is the least of the analytic gaussian
+// mechanism (Balle and Wang, 2018), valid for every ε.
+func GaussianMechanism(values []float64, sensitivity, epsilon, delta float64, r *rand.Rand) ([]float64, error) {
+	if epsilon <= 0 || delta <= 0 || delta >= 1 {
+		return nil, fmt.Errorf("ml: invalid privacy parameters epsilon %v and delta %v", epsilon, delta)
+	}
+	if sensitivity <= 0 {
+		return nil, fmt.Errorf("ml: sensitivity must be positive, got %v", sensitivity)
+	}
+
+	r = RandOf(r)
+	sigma := gaussianSigma(sensitivity, epsilon, delta)
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = v + r.NormFloat64()*sigma
+	}
+	return out, nil
+}
+
+// gaussianDelta returns δ of gaussian noise sigma for
+// sensitivity and ε, Φ(Δ/2σ - εσ/Δ) - e^ε Φ(-Δ/2σ - εσ/Δ)
+func gaussianDelta(sensitivity, epsilon, sigma float64) float64 {
+	a, b := sensitivity/(2*sigma), epsilon*sigma/sensitivity
+	return 0.5*math.Erfc((b-a)/math.Sqrt2) - math.Exp(epsilon+math.Log(0.5*math.Erfc((a+b)/math.Sqrt2)))
+}
+
+// gaussianSigma returns the least sigma whose
+// gaussianDelta is at most delta, by bisection as
+// gaussianDelta falls with sigma
+func gaussianSigma(sensitivity, epsilon, delta float64) float64 {
+	hi := sensitivity
+	for gaussianDelta(sensitivity, epsilon, hi) > delta {
+		hi *= 2
+	}
+	lo := hi / 2
+	for gaussianDelta(sensitivity, epsilon, lo) <= delta && lo > 1e-300 {
+		lo /= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if gaussianDelta(sensitivity, epsilon, mid) > delta {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
@@ -0,0 +1,68 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestGaussianSigma(t *testing.T) {
+	// analytic gaussian mechanism of sensitivity 1,
+	// smaller than the classic √(2 ln(1.25/δ))/ε
+	cases := []struct{ epsilon, delta, sigma float64 }{
+		{1, 1e-5, 3.730632},
+		{0.5, 1e-5, 7.031827},
+		{0.1, 1e-5, 30.749566},
+		{5, 1e-6, 0.980049},
+	}
+	for _, c := range cases {
+		got := gaussianSigma(1, c.epsilon, c.delta)
+		if math.Abs(got-c.sigma) > 1e-5 {
+			t.Errorf("ε %v δ %v: sigma %v, want %v", c.epsilon, c.delta, got, c.sigma)
+		}
+		if scaled := gaussianSigma(3, c.epsilon, c.delta); math.Abs(scaled-3*got) > 1e-9*scaled {
+			t.Errorf("ε %v δ %v: sigma of sensitivity 3 is %v, want %v", c.epsilon, c.delta, scaled, 3*got)
+		}
+	}
+}
+
+func TestGaussianMechanism(t *testing.T) {
+	values := make([]float64, 20000)
+	noised, err := GaussianMechanism(values, 1, 2, 1e-5, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gaussianSigma(1, 2, 1e-5)
+	if sd := stat.StdDev(noised, nil); math.Abs(sd-want) > 0.03*want {
+		t.Errorf("noise has deviation %v, want %v", sd, want)
+	}
+
+	if _, err := GaussianMechanism(values, 1, 0, 1e-5, nil); err == nil {
+		t.Error("expected an error for zero epsilon")
+	}
+	if _, err := GaussianMechanism(values, 0, 1, 1e-5, nil); err == nil {
+		t.Error("expected an error for zero sensitivity")
+	}
+}
+
+func TestAccountantOfShortBatch(t *testing.T) {
+	// 70 rows in batches of 32 leave a last batch
+	// of 6 rows every epoch
+	features, output := lineData(70, 1)
+	s := NewSGD(NewLinearRegression())
+	s.Rand = rand.New(rand.NewSource(1))
+	s.Epochs = 2
+	s.Privacy = &Privacy{Clip: 1, NoiseMultiplier: 1.1, Delta: 1e-5}
+	if err := s.Fit(Slices{features, output}); err != nil {
+		t.Fatal(err)
+	}
+
+	var want Accountant
+	want.Step(32.0/70, 1.1, 4)
+	want.Step(6.0/70, 1.1, 2)
+	if got := s.Epsilon(); math.Abs(got-want.Epsilon(1e-5)) > 1e-12 {
+		t.Errorf("ε %v, want %v", got, want.Epsilon(1e-5))
+	}
+}
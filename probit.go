@@ -0,0 +1,123 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ProbitRegression is a binary regression of probability
+// Φ(θ·x) of output being 1, Φ being the standard normal
+// distribution
+type ProbitRegression struct {
+	Theta []float64
+	// StdErrors come from the observed information
+	StdErrors []float64
+
+	loglik     float64
+	samples    int
+	covariance [][]float64
+}
+
+// NewProbitRegression returns new pointer of ProbitRegression
+func NewProbitRegression() *ProbitRegression {
+	return &ProbitRegression{}
+}
+
+// Fit trains the model on features and output of 0 and 1
+func (r *ProbitRegression) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	for i, y := range output {
+		if y != 0 && y != 1 {
+			return fmt.Errorf("ml: output %d is %v, not 0 or 1", i, y)
+		}
+	}
+
+	ll := func(x, grad []float64) float64 {
+		return probitLikelihood(features, output, x, grad)
+	}
+	theta, err := maximizeLikelihood(ll, make([]float64, p), len(output))
+	if err != nil {
+		return fmt.Errorf("ml: probit: %v", err)
+	}
+	r.Theta = theta
+	r.loglik = ll(theta, nil)
+	r.samples = len(output)
+
+	r.covariance, err = observedCovariance(ll, theta)
+	if err != nil {
+		r.StdErrors = nil
+		return nil
+	}
+	r.StdErrors = make([]float64, p)
+	for j := range r.StdErrors {
+		r.StdErrors[j] = math.Sqrt(r.covariance[j][j])
+	}
+	return nil
+}
+
+// probitLikelihood returns log-likelihood of theta,
+// filling grad when not nil
+func probitLikelihood(features [][]float64, output, theta, grad []float64) float64 {
+	for j := range grad {
+		grad[j] = 0
+	}
+	ll := 0.0
+	for i, row := range features {
+		// Φ(z) is the probability of the observed output
+		z := floats.Dot(row, theta)
+		sign := 1.0
+		if output[i] == 0 {
+			z, sign = -z, -1
+		}
+		ll += logNormalCDF(z)
+		if grad != nil {
+			floats.AddScaled(grad, sign*millsRatio(z), row)
+		}
+	}
+	return ll
+}
+
+// Probability returns probability of X being true
+func (r *ProbitRegression) Probability(X []float64) float64 {
+	return 0.5 * math.Erfc(-floats.Dot(X, r.Theta)/math.Sqrt2)
+}
+
+// Estimate returns probability of X being true
+func (r *ProbitRegression) Estimate(X []float64) float64 {
+	return r.Probability(X)
+}
+
+// Coefficients returns thetas
+func (r *ProbitRegression) Coefficients() []float64 {
+	return r.Theta
+}
+
+// CoefficientCovariance returns covariance of thetas,
+// the inverse observed information of the training rows
+func (r *ProbitRegression) CoefficientCovariance() ([][]float64, error) {
+	if r.covariance == nil {
+		return nil, fmt.Errorf("ml: covariance needs a model fitted with a regular information matrix")
+	}
+	return r.covariance, nil
+}
+
+// LogLikelihood returns Bernoulli log-likelihood
+// of the training data
+func (r *ProbitRegression) LogLikelihood() float64 {
+	return r.loglik
+}
+
+// NumParams returns the number of thetas
+func (r *ProbitRegression) NumParams() int {
+	return len(r.Theta)
+}
+
+// NumSamples returns the number of training rows
+func (r *ProbitRegression) NumSamples() int {
+	return r.samples
+}
@@ -0,0 +1,195 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// ProfileQuantiles are the quantiles computed
+// for every column of a profile
+var ProfileQuantiles = []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+
+// Profile is a data profiling report of features.
+// Missing values are represented by NaN.
+type Profile struct {
+	Columns []ColumnProfile
+	// Pearson and Spearman are pairwise correlation
+	// matrices computed over rows where both columns
+	// are present
+	Pearson  [][]float64
+	Spearman [][]float64
+}
+
+// ColumnProfile holds descriptive statistics of a column
+type ColumnProfile struct {
+	Name        string
+	Count       int
+	Missing     int
+	MissingRate float64
+	Mean        float64
+	Std         float64
+	Min         float64
+	Max         float64
+	// Quantiles are aligned with ProfileQuantiles
+	Quantiles   []float64
+	Cardinality int
+}
+
+// NewProfile computes descriptive statistics of every
+// column of features and their pairwise correlations.
+// names may be nil, columns are then named x0, x1, ...
+func NewProfile(features [][]float64, names []string) (*Profile, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("ml: cannot profile empty features")
+	}
+
+	n := len(features[0])
+	for i, row := range features {
+		if len(row) != n {
+			return nil, fmt.Errorf("ml: row %d has %d columns, expected %d", i, len(row), n)
+		}
+	}
+	if names != nil && len(names) != n {
+		return nil, fmt.Errorf("ml: got %d names for %d columns", len(names), n)
+	}
+
+	p := &Profile{
+		Columns:  make([]ColumnProfile, n),
+		Pearson:  make([][]float64, n),
+		Spearman: make([][]float64, n),
+	}
+
+	for j := 0; j < n; j++ {
+		name := fmt.Sprintf("x%d", j)
+		if names != nil {
+			name = names[j]
+		}
+		p.Columns[j] = profileColumn(name, column(features, j))
+		p.Pearson[j] = make([]float64, n)
+		p.Spearman[j] = make([]float64, n)
+	}
+
+	for j := 0; j < n; j++ {
+		p.Pearson[j][j] = 1
+		p.Spearman[j][j] = 1
+		for k := j + 1; k < n; k++ {
+			x, y := completePairs(column(features, j), column(features, k))
+			p.Pearson[j][k] = Pearson(x, y)
+			p.Spearman[j][k] = Spearman(x, y)
+			p.Pearson[k][j] = p.Pearson[j][k]
+			p.Spearman[k][j] = p.Spearman[j][k]
+		}
+	}
+
+	return p, nil
+}
+
+func profileColumn(name string, x []float64) ColumnProfile {
+	c := ColumnProfile{
+		Name:      name,
+		Min:       math.NaN(),
+		Max:       math.NaN(),
+		Mean:      math.NaN(),
+		Std:       math.NaN(),
+		Quantiles: make([]float64, len(ProfileQuantiles)),
+	}
+
+	present := make([]float64, 0, len(x))
+	for _, v := range x {
+		if math.IsNaN(v) {
+			c.Missing++
+			continue
+		}
+		present = append(present, v)
+	}
+	c.Count = len(present)
+	c.MissingRate = float64(c.Missing) / float64(len(x))
+
+	for i := range c.Quantiles {
+		c.Quantiles[i] = math.NaN()
+	}
+	if len(present) == 0 {
+		return c
+	}
+
+	sort.Float64s(present)
+	c.Min = present[0]
+	c.Max = present[len(present)-1]
+	c.Mean, c.Std = stat.MeanStdDev(present, nil)
+	for i, q := range ProfileQuantiles {
+		c.Quantiles[i] = stat.Quantile(q, stat.LinInterp, present, nil)
+	}
+
+	c.Cardinality = 1
+	for i := 1; i < len(present); i++ {
+		if present[i] != present[i-1] {
+			c.Cardinality++
+		}
+	}
+
+	return c
+}
+
+// Pearson returns Pearson correlation coefficient of x and y
+func Pearson(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+	return stat.Correlation(x, y, nil)
+}
+
+// Spearman returns Spearman rank correlation of x and y.
+// Ties are given their average rank.
+func Spearman(x, y []float64) float64 {
+	return Pearson(rank(x), rank(y))
+}
+
+// rank returns 1-based ranks of x, averaging ties
+func rank(x []float64) []float64 {
+	idx := make([]int, len(x))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return x[idx[a]] < x[idx[b]] })
+
+	ranks := make([]float64, len(x))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && x[idx[j+1]] == x[idx[i]] {
+			j++
+		}
+		r := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			ranks[idx[k]] = r
+		}
+		i = j + 1
+	}
+
+	return ranks
+}
+
+// column returns a copy of column j of features
+func column(features [][]float64, j int) []float64 {
+	c := make([]float64, len(features))
+	for i, row := range features {
+		c[i] = row[j]
+	}
+	return c
+}
+
+// completePairs drops pairs where either value is missing
+func completePairs(x, y []float64) ([]float64, []float64) {
+	a := make([]float64, 0, len(x))
+	b := make([]float64, 0, len(y))
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsNaN(y[i]) {
+			continue
+		}
+		a = append(a, x[i])
+		b = append(b, y[i])
+	}
+	return a, b
+}
@@ -0,0 +1,229 @@
+// Package prommetrics collects ml.Metrics in memory and
+// serves them in the Prometheus text exposition format,
+// without depending on the Prometheus client library.
+package prommetrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// DefaultBuckets are histogram buckets of latencies
+// in seconds, as in the Prometheus client
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// ProbabilityBuckets suit prediction values of classifiers
+var ProbabilityBuckets = []float64{.1, .2, .3, .4, .5, .6, .7, .8, .9, 1}
+
+// Collector implements ml.Metrics and http.Handler,
+// serving collected metrics to Prometheus scrapes
+type Collector struct {
+	// Buckets of histograms by metric name,
+	// DefaultBuckets otherwise
+	Buckets map[string][]float64
+	// Help texts by metric name
+	Help map[string]string
+
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+type family struct {
+	kind   string
+	series map[string]*series
+}
+
+type series struct {
+	labels  string
+	value   float64
+	buckets []float64
+	counts  []uint64
+	count   uint64
+}
+
+// New returns new pointer of Collector with help texts
+// of ml metrics. Prediction values use ProbabilityBuckets,
+// set Buckets[ml.MetricPredictionValue] for regressions.
+func New() *Collector {
+	return &Collector{
+		Buckets: map[string][]float64{
+			ml.MetricPredictionValue: ProbabilityBuckets,
+		},
+		Help: map[string]string{
+			ml.MetricTrainingIterations: "Optimizer iterations of training.",
+			ml.MetricTrainingLoss:       "Loss at the latest training iteration.",
+			ml.MetricTrainingDuration:   "Duration of Fit in seconds.",
+			ml.MetricPredictions:        "Number of estimates.",
+			ml.MetricPredictionLatency:  "Duration of Estimate in seconds.",
+			ml.MetricPredictionValue:    "Distribution of estimates.",
+		},
+		families: map[string]*family{},
+	}
+}
+
+// get returns series of name with labels, or nil when
+// name was first used as another kind of metric
+func (c *Collector) get(name, kind string, labels map[string]string) *series {
+	if c.families == nil {
+		c.families = map[string]*family{}
+	}
+	f, ok := c.families[name]
+	if !ok {
+		f = &family{kind: kind, series: map[string]*series{}}
+		c.families[name] = f
+	}
+	if f.kind != kind {
+		return nil
+	}
+
+	key := formatLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		if kind == "histogram" {
+			s.buckets = DefaultBuckets
+			if b, ok := c.Buckets[name]; ok {
+				s.buckets = b
+			}
+			s.counts = make([]uint64, len(s.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Add increases counter name by value
+func (c *Collector) Add(name string, value float64, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.get(name, "counter", labels); s != nil {
+		s.value += value
+	}
+}
+
+// Set sets gauge name to value
+func (c *Collector) Set(name string, value float64, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.get(name, "gauge", labels); s != nil {
+		s.value = value
+	}
+}
+
+// Observe records value in histogram name
+func (c *Collector) Observe(name string, value float64, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.get(name, "histogram", labels)
+	if s == nil {
+		return
+	}
+	s.value += value
+	s.count++
+	for i, b := range s.buckets {
+		if value <= b {
+			s.counts[i]++
+		}
+	}
+}
+
+// WriteTo writes metrics in the text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.families))
+	for name := range c.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := c.families[name]
+		if help, ok := c.Help[name]; ok {
+			fmt.Fprintf(&b, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != "histogram" {
+				fmt.Fprintf(&b, "%s%s %s\n", name, braces(s.labels), formatFloat(s.value))
+				continue
+			}
+			for i, bound := range s.buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braces(join(s.labels, `le="`+formatFloat(bound)+`"`)), s.counts[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, braces(join(s.labels, `le="+Inf"`)), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, braces(s.labels), formatFloat(s.value))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, braces(s.labels), s.count)
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves metrics to a Prometheus scrape
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// formatLabels returns labels sorted by name as
+// name="value" pairs separated by commas
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escape.Replace(labels[name]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func join(labels, pair string) string {
+	if labels == "" {
+		return pair
+	}
+	return labels + "," + pair
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
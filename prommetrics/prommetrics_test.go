@@ -0,0 +1,51 @@
+package prommetrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExposition(t *testing.T) {
+	c := New()
+	c.Buckets["latency"] = []float64{0.1, 1}
+	c.Help["latency"] = "Latency.\nIn seconds."
+
+	c.Add("requests", 1, map[string]string{"model": "a"})
+	c.Add("requests", 2, map[string]string{"model": "a"})
+	c.Add("requests", 1, map[string]string{"model": `b"c`})
+	c.Set("loss", 0.5, nil)
+	c.Set("loss", 0.25, nil)
+	for _, v := range []float64{0.05, 0.5, 0.5, 3} {
+		c.Observe("latency", v, nil)
+	}
+	// a name of another kind is ignored
+	c.Observe("requests", 1, nil)
+
+	want := `# HELP latency Latency.\nIn seconds.
+# TYPE latency histogram
+latency_bucket{le="0.1"} 1
+latency_bucket{le="1"} 3
+latency_bucket{le="+Inf"} 4
+latency_sum 4.05
+latency_count 4
+# TYPE loss gauge
+loss 0.25
+# TYPE requests counter
+requests{model="a"} 3
+requests{model="b\"c"} 1
+`
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("wrote\n%s\nwant\n%s", b.String(), want)
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Body.String() != want || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("served %q of %s", rec.Body.String(), rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,173 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quantized is a fitted linear model with integer thetas
+// and inputs, for targets without fast floating point.
+// Every input column j is quantized with its own scale,
+// x ≈ q*InputScale[j], which is folded into the thetas so
+// θ·X is a single integer dot product times Scale.
+type Quantized struct {
+	// Bits of thetas and inputs, 8 or 16
+	Bits int
+	// Theta are the quantized thetas
+	Theta []int16
+	// Scale converts the integer dot product back
+	Scale float64
+	// InputScale quantizes every input column
+	InputScale []float64
+	// Logistic applies the sigmoid to the dot product
+	Logistic bool
+}
+
+// Quantize returns model quantized to bits, 8 or 16, with
+// input scales taken from the largest absolute value of
+// every column of calibration. Inputs beyond the
+// calibration range saturate.
+func Quantize(model LinearModel, calibration [][]float64, bits int) (*Quantized, error) {
+	if bits != 8 && bits != 16 {
+		return nil, fmt.Errorf("ml: cannot quantize to %d bits, use 8 or 16", bits)
+	}
+
+	q := &Quantized{Bits: bits}
+	switch m := model.(type) {
+	case *LinearRegression:
+		if !m.IsLinear() {
+			return nil, fmt.Errorf("ml: cannot quantize a custom hypothesis")
+		}
+	case *LogisticRegression:
+		if !m.IsLinear() {
+			return nil, fmt.Errorf("ml: cannot quantize a custom hypothesis")
+		}
+		q.Logistic = true
+	default:
+		return nil, fmt.Errorf("ml: cannot quantize %T", model)
+	}
+
+	theta := model.Coefficients()
+	if len(calibration) == 0 {
+		return nil, fmt.Errorf("ml: cannot quantize without calibration rows")
+	}
+
+	limit := q.limit()
+	q.InputScale = make([]float64, len(theta))
+	folded := make([]float64, len(theta))
+	largest := 0.0
+	for j := range theta {
+		high := 0.0
+		for i, x := range calibration {
+			if len(x) != len(theta) {
+				return nil, fmt.Errorf("ml: calibration row %d has %d columns, expected %d", i, len(x), len(theta))
+			}
+			high = math.Max(high, math.Abs(x[j]))
+		}
+		if high == 0 {
+			high = 1
+		}
+		q.InputScale[j] = high / limit
+		folded[j] = theta[j] * q.InputScale[j]
+		largest = math.Max(largest, math.Abs(folded[j]))
+	}
+
+	q.Scale = largest / limit
+	if q.Scale == 0 {
+		q.Scale = 1
+	}
+	q.Theta = make([]int16, len(theta))
+	for j, v := range folded {
+		q.Theta[j] = int16(math.Round(v / q.Scale))
+	}
+
+	return q, nil
+}
+
+// limit returns the largest quantized magnitude
+func (q *Quantized) limit() float64 {
+	return float64(int(1)<<(q.Bits-1) - 1)
+}
+
+// QuantizeInput returns X quantized with InputScale,
+// saturating values beyond the calibration range
+func (q *Quantized) QuantizeInput(X []float64) []int16 {
+	limit := q.limit()
+	out := make([]int16, len(X))
+	for j, v := range X {
+		out[j] = int16(math.Max(-limit, math.Min(limit, math.Round(v/q.InputScale[j]))))
+	}
+	return out
+}
+
+// EstimateInt returns estimate of quantized X using
+// only integer arithmetic up to the final scaling
+func (q *Quantized) EstimateInt(X []int16) float64 {
+	var dot int64
+	for j, v := range X {
+		dot += int64(q.Theta[j]) * int64(v)
+	}
+
+	z := float64(dot) * q.Scale
+	if q.Logistic {
+		return sigmoid(z)
+	}
+	return z
+}
+
+// Estimate quantizes X and returns its estimate
+func (q *Quantized) Estimate(X []float64) float64 {
+	return q.EstimateInt(q.QuantizeInput(X))
+}
+
+// Fit returns an error, quantize a fitted model instead
+func (q *Quantized) Fit(features [][]float64, output []float64) error {
+	return fmt.Errorf("ml: cannot fit a quantized model, quantize a fitted one")
+}
+
+// QuantizationReport compares estimates of a model and
+// its quantized version
+type QuantizationReport struct {
+	// MaxError and MeanError are the largest and mean
+	// absolute differences of estimates
+	MaxError  float64
+	MeanError float64
+	// Score and QuantizedScore are the metric
+	// scores of both models
+	Score          float64
+	QuantizedScore float64
+	// Agreement is the fraction of rows where a
+	// classifier keeps its label at threshold 0.5
+	Agreement float64
+}
+
+// Drop returns how much the metric score dropped
+func (r *QuantizationReport) Drop() float64 {
+	return r.Score - r.QuantizedScore
+}
+
+// Report compares estimates of q with those of model
+// on features and output, scored by metric
+func (q *Quantized) Report(model Estimator, features [][]float64, output []float64, metric Metric) *QuantizationReport {
+	estimates := estimateAll(model, features)
+	quantized := estimateAll(q, features)
+
+	r := &QuantizationReport{
+		Score:          metric(output, estimates),
+		QuantizedScore: metric(output, quantized),
+	}
+	agree := 0
+	for i, e := range estimates {
+		d := math.Abs(e - quantized[i])
+		r.MaxError = math.Max(r.MaxError, d)
+		r.MeanError += d / float64(len(estimates))
+		if (e >= 0.5) == (quantized[i] >= 0.5) {
+			agree++
+		}
+	}
+	if q.Logistic {
+		r.Agreement = float64(agree) / float64(len(estimates))
+	}
+
+	return r
+}
@@ -0,0 +1,23 @@
+package ml
+
+import "math/rand"
+
+// globalSource is the global source of math/rand,
+// safe for concurrent use
+type globalSource struct{}
+
+func (globalSource) Int63() int64   { return rand.Int63() }
+func (globalSource) Uint64() uint64 { return rand.Uint64() }
+func (globalSource) Seed(int64)     {}
+
+// RandOf returns r, or a Rand of the global source
+// of math/rand when r is nil. Stochastic parts of this
+// module take a *rand.Rand field named Rand, nil using
+// the global source, so runs are reproducible with a
+// seeded one.
+func RandOf(r *rand.Rand) *rand.Rand {
+	if r != nil {
+		return r
+	}
+	return rand.New(globalSource{})
+}
@@ -0,0 +1,169 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RANSAC fits an estimator robust to gross outliers, such
+// as sensor glitches. Every trial fits a new estimator on
+// MinSamples random rows and counts inliers, rows of absolute
+// residual within Threshold. The estimator is then refitted
+// on inliers of the trial with the most of them.
+type RANSAC struct {
+	NewEstimator func() Estimator
+	// MinSamples are rows of every trial,
+	// defaults to the number of columns
+	MinSamples int
+	// Threshold is the largest absolute residual of
+	// inliers, defaults to the median absolute deviation
+	// of the output
+	Threshold float64
+	// MaxTrials defaults to 100
+	MaxTrials int
+	// Probability of drawing one subset free of outliers
+	// stops trials early, defaults to 0.99
+	Probability float64
+	// Rand draws the subsets, nil uses the global
+	// source. It is not saved.
+	Rand *rand.Rand
+
+	// Inliers tells which rows are inliers
+	Inliers []bool
+	// Trials are trials of the last Fit
+	Trials int
+	// Estimator is fitted on inliers
+	Estimator Estimator
+}
+
+// NewRANSAC returns new pointer of RANSAC
+func NewRANSAC(newEstimator func() Estimator) *RANSAC {
+	return &RANSAC{
+		NewEstimator: newEstimator,
+		MaxTrials:    100,
+		Probability:  0.99,
+	}
+}
+
+// Fit finds inliers of features and output and
+// fits the estimator on them
+func (r *RANSAC) Fit(features [][]float64, output []float64) error {
+	if r.NewEstimator == nil {
+		return fmt.Errorf("ml: RANSAC needs NewEstimator")
+	}
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	n := len(features)
+	samples := r.MinSamples
+	if samples <= 0 {
+		samples = p
+	}
+	if samples > n {
+		return fmt.Errorf("ml: RANSAC needs %d rows, got %d", samples, n)
+	}
+	threshold := r.Threshold
+	if threshold <= 0 {
+		threshold = medianAbsoluteDeviation(output)
+	}
+	trials := r.MaxTrials
+	if trials <= 0 {
+		trials = 100
+	}
+	probability := r.Probability
+	if probability <= 0 || probability >= 1 {
+		probability = 0.99
+	}
+	rng := RandOf(r.Rand)
+
+	var (
+		best     []bool
+		count    int
+		score    = math.Inf(1)
+		inliers  = make([]bool, n)
+		residual = make([]float64, n)
+	)
+	r.Trials = 0
+	for r.Trials < trials {
+		r.Trials++
+		X, y := subset(features, output, rng.Perm(n)[:samples])
+		estimator := r.NewEstimator()
+		if err := estimator.Fit(X, y); err != nil {
+			// degenerate subsets, e.g. repeated rows
+			continue
+		}
+		for i, v := range estimateAll(estimator, features) {
+			residual[i] = math.Abs(output[i] - v)
+		}
+		c, s := 0, 0.0
+		for i, e := range residual {
+			inliers[i] = e <= threshold
+			if inliers[i] {
+				c++
+				s += e
+			}
+		}
+		// more inliers, then smaller residuals
+		if c < count || c == count && s >= score {
+			continue
+		}
+		best, inliers = inliers, make([]bool, n)
+		count, score = c, s
+		if count == n {
+			break
+		}
+		// trials needed to draw an outlier free subset
+		w := math.Pow(float64(count)/float64(n), float64(samples))
+		if w > 0 && float64(r.Trials) >= math.Log(1-probability)/math.Log(1-w) {
+			break
+		}
+	}
+	if count < samples {
+		return fmt.Errorf("ml: RANSAC found no consensus of %d inliers in %d trials", samples, r.Trials)
+	}
+
+	var idx []int
+	for i, ok := range best {
+		if ok {
+			idx = append(idx, i)
+		}
+	}
+	X, y := subset(features, output, idx)
+	estimator := r.NewEstimator()
+	if err := estimator.Fit(X, y); err != nil {
+		return err
+	}
+	r.Inliers = best
+	r.Estimator = estimator
+	return nil
+}
+
+// medianAbsoluteDeviation returns median of |x - median(x)|
+func medianAbsoluteDeviation(x []float64) float64 {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	median := quantile(0.5, sorted)
+	for i, v := range x {
+		sorted[i] = math.Abs(v - median)
+	}
+	sort.Float64s(sorted)
+	return quantile(0.5, sorted)
+}
+
+// Estimate returns predicted value of X
+func (r *RANSAC) Estimate(X []float64) float64 {
+	return r.Estimator.Estimate(X)
+}
+
+// GobEncode encodes the model without Rand
+func (r *RANSAC) GobEncode() ([]byte, error) {
+	return GobEncodeModel(r)
+}
+
+// GobDecode decodes a model of GobEncode
+func (r *RANSAC) GobDecode(data []byte) error {
+	return GobDecodeModel(r, data)
+}
@@ -0,0 +1,327 @@
+// Package registry stores versions of fitted models with
+// their metadata, and promotes them through stages such
+// as staging and production.
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Stage of a model version
+type Stage string
+
+const (
+	// None is the stage of new versions
+	None Stage = "none"
+	// Staging versions are being validated
+	Staging Stage = "staging"
+	// Production is the serving version,
+	// at most one per model
+	Production Stage = "production"
+	// Archived versions were replaced in production
+	Archived Stage = "archived"
+)
+
+// Version is the metadata of a registered model version
+type Version struct {
+	Model   string `json:"model"`
+	Version int    `json:"version"`
+	Stage   Stage  `json:"stage"`
+	// Type is the Go type of the model
+	Type        string             `json:"type"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"`
+	Params      map[string]string  `json:"params,omitempty"`
+	DatasetHash string             `json:"dataset_hash,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// Metadata describes a model being registered
+type Metadata struct {
+	Metrics     map[string]float64
+	Params      map[string]string
+	DatasetHash string
+}
+
+// ErrNotFound is wrapped by errors of missing
+// models, versions and keys
+var ErrNotFound = ml.ErrNotFound
+
+// Registry stores model artifacts in the ml model format
+// and their metadata as JSON in an ml.Storage, under
+// <model>/<version>/model.bin and meta.json. A Registry is
+// safe for concurrent use, but only one Registry should
+// write a Storage at a time.
+type Registry struct {
+	Storage ml.Storage
+	// Now returns timestamps, defaults to time.Now
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+// New returns new pointer of Registry storing in storage
+func New(storage ml.Storage) *Registry {
+	return &Registry{Storage: storage, Now: time.Now}
+}
+
+func (r *Registry) now() time.Time {
+	if r.Now == nil {
+		return time.Now().UTC()
+	}
+	return r.Now().UTC()
+}
+
+func versionKey(name string, version int, file string) string {
+	return path.Join(name, fmt.Sprintf("%06d", version), file)
+}
+
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\") || name == "." || name == ".." {
+		return fmt.Errorf("registry: invalid model name %q", name)
+	}
+	return nil
+}
+
+// Register saves model as the next version of name
+func (r *Registry) Register(name string, model interface{}, meta Metadata) (*Version, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	var artifact bytes.Buffer
+	if err := ml.Save(&artifact, model); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, err := r.versions(name)
+	if err != nil {
+		return nil, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].Version + 1
+	}
+
+	now := r.now()
+	v := &Version{
+		Model:       name,
+		Version:     next,
+		Stage:       None,
+		Type:        fmt.Sprintf("%T", model),
+		Metrics:     meta.Metrics,
+		Params:      meta.Params,
+		DatasetHash: meta.DatasetHash,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	// the artifact goes first so metadata
+	// never points to a missing model
+	if err = r.write(versionKey(name, next, "model.bin"), artifact.Bytes()); err != nil {
+		return nil, err
+	}
+	if err = r.put(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *Registry) put(v *Version) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return r.write(versionKey(v.Model, v.Version, "meta.json"), data)
+}
+
+func (r *Registry) write(key string, data []byte) error {
+	w, err := r.Storage.Create(key)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (r *Registry) read(key string) ([]byte, error) {
+	rc, err := r.Storage.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
+
+// Versions returns every version of name, oldest first
+func (r *Registry) Versions(name string) ([]*Version, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	return r.versions(name)
+}
+
+func (r *Registry) versions(name string) ([]*Version, error) {
+	keys, err := r.Storage.List(name + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*Version
+	for _, key := range keys {
+		if path.Base(key) != "meta.json" {
+			continue
+		}
+		data, err := r.read(key)
+		if err != nil {
+			return nil, err
+		}
+		var v Version
+		if err = json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("registry: reading %s: %v", key, err)
+		}
+		versions = append(versions, &v)
+	}
+	return versions, nil
+}
+
+// Get returns metadata of a version of name
+func (r *Registry) Get(name string, version int) (*Version, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	data, err := r.read(versionKey(name, version, "meta.json"))
+	if err != nil {
+		return nil, err
+	}
+	var v Version
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("registry: reading %s version %d: %v", name, version, err)
+	}
+	return &v, nil
+}
+
+// Load returns the model of a version of name
+func (r *Registry) Load(name string, version int) (interface{}, *Version, error) {
+	v, err := r.Get(name, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := ml.LoadFrom(r.Storage, versionKey(name, version, "model.bin"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return model, v, nil
+}
+
+// Latest returns the newest version of name in stage,
+// an empty stage matches every stage
+func (r *Registry) Latest(name string, stage Stage) (*Version, error) {
+	versions, err := r.Versions(name)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if stage == "" || versions[i].Stage == stage {
+			return versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in stage %q", ErrNotFound, name, stage)
+}
+
+// LoadLatest returns the model of the newest version of
+// name in stage, e.g. the current production model
+func (r *Registry) LoadLatest(name string, stage Stage) (interface{}, *Version, error) {
+	v, err := r.Latest(name, stage)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.Load(name, v.Version)
+}
+
+// SetStage moves a version of name to stage. Promoting a
+// version to Production archives the previous one.
+func (r *Registry) SetStage(name string, version int, stage Stage) (*Version, error) {
+	switch stage {
+	case None, Staging, Production, Archived:
+	default:
+		return nil, fmt.Errorf("registry: unknown stage %q", stage)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.Get(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	if stage == Production {
+		versions, err := r.versions(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range versions {
+			if other.Version != version && other.Stage == Production {
+				other.Stage, other.UpdatedAt = Archived, now
+				if err = r.put(other); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	v.Stage, v.UpdatedAt = stage, now
+	return v, r.put(v)
+}
+
+// DatasetHash returns a SHA-256 of features and output,
+// identifying the data a model was trained on
+func DatasetHash(features [][]float64, output []float64) string {
+	h := sha256.New()
+	var buf [8]byte
+	write := func(v float64) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+
+	for _, row := range features {
+		write(float64(len(row)))
+		for _, v := range row {
+			write(v)
+		}
+	}
+	write(float64(len(output)))
+	for _, v := range output {
+		write(v)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IsNotFound tells whether err is caused by a missing
+// model, version or key
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func TestRegistryStages(t *testing.T) {
+	r := New(ml.NewFileStorage(t.TempDir()))
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.Now = func() time.Time { return now }
+
+	features := [][]float64{{1, 1}, {1, 2}, {1, 3}}
+	output := []float64{3, 5, 7}
+	var models []*ml.LinearRegression
+	for i := 1; i <= 2; i++ {
+		m := ml.NewLinearRegression()
+		if err := m.Fit(features, output); err != nil {
+			t.Fatal(err)
+		}
+		v, err := r.Register("price", m, Metadata{
+			Metrics:     map[string]float64{"rmse": float64(i)},
+			DatasetHash: DatasetHash(features, output),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Version != i || v.Stage != None || v.Type != "*ml.LinearRegression" {
+			t.Errorf("registered %+v, want version %d", v, i)
+		}
+		models = append(models, m)
+		now = now.Add(time.Hour)
+	}
+
+	model, v, err := r.Load("price", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := model.(*ml.LinearRegression).Estimate([]float64{1, 4}), models[0].Estimate([]float64{1, 4}); got != want || v.Metrics["rmse"] != 1 {
+		t.Errorf("loaded estimate %v of metrics %v, want %v of rmse 1", got, v.Metrics, want)
+	}
+
+	// promoting version 2 archives version 1
+	if _, err = r.SetStage("price", 1, Production); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.SetStage("price", 2, Production); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := r.Versions("price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Stage != Archived || versions[1].Stage != Production {
+		t.Fatalf("versions %+v, want archived 1 and production 2", versions)
+	}
+	if !versions[0].UpdatedAt.After(versions[0].CreatedAt) {
+		t.Errorf("archived version updated at %v, created at %v", versions[0].UpdatedAt, versions[0].CreatedAt)
+	}
+	if _, v, err = r.LoadLatest("price", Production); err != nil || v.Version != 2 {
+		t.Errorf("latest production %+v, %v, want version 2", v, err)
+	}
+
+	if _, err = r.Latest("price", Staging); !IsNotFound(err) {
+		t.Errorf("latest staging error %v, want not found", err)
+	}
+	if _, err = r.Get("price", 3); !IsNotFound(err) {
+		t.Errorf("version 3 error %v, want not found", err)
+	}
+	if _, err = r.SetStage("price", 1, "live"); err == nil {
+		t.Error("no error of an unknown stage")
+	}
+	if _, err = r.Register("../price", models[0], Metadata{}); err == nil {
+		t.Error("no error of an invalid name")
+	}
+}
+
+func TestDatasetHash(t *testing.T) {
+	features := [][]float64{{1, 2}, {3, 4}}
+	output := []float64{5, 6}
+	h := DatasetHash(features, output)
+	if len(h) != 64 || h != DatasetHash([][]float64{{1, 2}, {3, 4}}, []float64{5, 6}) {
+		t.Errorf("hash %q of equal data differs", h)
+	}
+	// rows of other lengths of the same values
+	// are other data
+	for _, other := range []string{
+		DatasetHash([][]float64{{1, 2}, {3, 4}}, []float64{5, 7}),
+		DatasetHash([][]float64{{1, 2, 3}, {4}}, []float64{5, 6}),
+	} {
+		if other == h {
+			t.Errorf("hash %q of other data", h)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// WriteHTML writes the report as a self-contained HTML
+// page, curves are inline SVG and nothing is loaded
+func (r *Report) WriteHTML(w io.Writer) error {
+	return page.Execute(w, r)
+}
+
+// chartSize is width and height of charts in pixels
+const chartSize = 240
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"points":  points,
+	"metrics": sortedMetrics,
+	"size":    func() int { return chartSize },
+	"bar": func(v, max float64) float64 {
+		if max <= 0 {
+			return 0
+		}
+		return math.Max(v, 0) / max * 200
+	},
+	"maxMean": func(imp []Importance) float64 {
+		m := 0.0
+		for _, v := range imp {
+			m = math.Max(m, v.Mean)
+		}
+		return m
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Report of {{.Model}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.charts { display: flex; flex-wrap: wrap; gap: 2em; }
+svg { background: #fafafa; border: 1px solid #ccc; }
+polyline { fill: none; stroke: #1f77b4; stroke-width: 2; }
+line.diagonal { stroke: #999; stroke-dasharray: 4; }
+</style>
+</head>
+<body>
+<h1>{{.Model}}</h1>
+<p>{{.Rows}} rows{{if .Classifier}}, threshold {{printf "%.4g" .Threshold}}{{end}}</p>
+
+<h2>Metrics</h2>
+<table>
+{{range metrics .Metrics}}<tr><td>{{.Name}}</td><td>{{printf "%.6g" .Value}}</td></tr>
+{{end}}</table>
+
+{{with .Confusion}}
+<h2>Confusion matrix</h2>
+<table>
+<tr><th></th><th>predicted true</th><th>predicted false</th></tr>
+<tr><td>true</td><td>{{.TruePositive}}</td><td>{{.FalseNegative}}</td></tr>
+<tr><td>false</td><td>{{.FalsePositive}}</td><td>{{.TrueNegative}}</td></tr>
+</table>
+{{end}}
+
+{{if .Classifier}}
+<div class="charts">
+{{with .ROC}}<div><h2>ROC curve</h2>
+<svg width="{{size}}" height="{{size}}"><line class="diagonal" x1="0" y1="{{size}}" x2="{{size}}" y2="0"/><polyline points="{{points .X .Y}}"/></svg>
+<p>area {{printf "%.4f" .Area}}</p></div>{{end}}
+{{with .PR}}<div><h2>Precision-recall curve</h2>
+<svg width="{{size}}" height="{{size}}"><polyline points="{{points .X .Y}}"/></svg>
+<p>average precision {{printf "%.4f" .Area}}</p></div>{{end}}
+{{with .Calibration}}<div><h2>Calibration curve</h2>
+<svg width="{{size}}" height="{{size}}"><line class="diagonal" x1="0" y1="{{size}}" x2="{{size}}" y2="0"/><polyline points="{{points .Predicted .Fraction}}"/></svg>
+<p>predicted against observed fraction</p></div>{{end}}
+</div>
+{{end}}
+
+{{with .Coefficients}}
+<h2>Coefficients</h2>
+<table>
+{{range .}}<tr><td>{{.Name}}</td><td>{{printf "%.6g" .Value}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Importances}}{{$max := maxMean .}}
+<h2>Permutation importance</h2>
+<table>
+<tr><th>feature</th><th>mean</th><th>std</th><th></th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{printf "%.4g" .Mean}}</td><td>{{printf "%.4g" .Std}}</td><td><svg width="200" height="10"><rect width="{{bar .Mean $max}}" height="10" fill="#1f77b4"/></svg></td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// points returns SVG points of x and y in [0, 1],
+// with y up
+func points(x, y []float64) string {
+	var b strings.Builder
+	for i := range x {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", x[i]*chartSize, (1-y[i])*chartSize)
+	}
+	return b.String()
+}
+
+type namedMetric struct {
+	Name  string
+	Value float64
+}
+
+// sortedMetrics returns metrics sorted by name
+func sortedMetrics(metrics map[string]float64) []namedMetric {
+	m := make([]namedMetric, 0, len(metrics))
+	for name, v := range metrics {
+		m = append(m, namedMetric{name, v})
+	}
+	sort.Slice(m, func(i, j int) bool { return m[i].Name < m[j].Name })
+	return m
+}
@@ -0,0 +1,278 @@
+// Package report evaluates a fitted estimator on held-out
+// rows and bundles metrics, confusion matrix, ROC and
+// precision-recall curves, calibration curve and feature
+// importances into a JSON document or a self-contained
+// HTML page.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Options of New, the zero value is usable
+type Options struct {
+	// Classifier evaluates probabilities of binary
+	// output, it is set for logistic regressions
+	Classifier bool
+	// Threshold of true predictions, nil uses the
+	// decision threshold of logistic regressions or 0.5
+	Threshold *float64
+	// Bins of the calibration curve, defaults to 10
+	Bins int
+	// Repeats of permutation importance, defaults
+	// to 5, negative skips importances
+	Repeats int
+	// Rand shuffles columns of permutation importance,
+	// nil uses the global source
+	Rand *rand.Rand
+}
+
+// Report of a fitted model
+type Report struct {
+	Model      string             `json:"model"`
+	Classifier bool               `json:"classifier"`
+	Rows       int                `json:"rows"`
+	Metrics    map[string]float64 `json:"metrics"`
+
+	Threshold   float64      `json:"threshold,omitempty"`
+	Confusion   *Confusion   `json:"confusion,omitempty"`
+	ROC         *Curve       `json:"roc,omitempty"`
+	PR          *Curve       `json:"pr,omitempty"`
+	Calibration *Calibration `json:"calibration,omitempty"`
+
+	Coefficients []Coefficient `json:"coefficients,omitempty"`
+	Importances  []Importance  `json:"importances,omitempty"`
+}
+
+// Confusion is ml.Confusion at Threshold
+type Confusion struct {
+	TruePositive  float64 `json:"true_positive"`
+	FalsePositive float64 `json:"false_positive"`
+	TrueNegative  float64 `json:"true_negative"`
+	FalseNegative float64 `json:"false_negative"`
+}
+
+// Calibration is ml.Reliability of probabilities
+type Calibration struct {
+	Predicted []float64 `json:"predicted"`
+	Fraction  []float64 `json:"fraction"`
+	Count     []int     `json:"count"`
+}
+
+// Coefficient is a named coefficient of a linear model
+type Coefficient struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Importance is permutation importance of a column
+type Importance struct {
+	Name string  `json:"name"`
+	Mean float64 `json:"mean"`
+	Std  float64 `json:"std"`
+}
+
+// Curve is a ROC curve, of false positive rate X and
+// true positive rate Y, or a precision-recall curve,
+// of recall X and precision Y. Area is the area under
+// the ROC curve or the average precision.
+type Curve struct {
+	X          []float64 `json:"x"`
+	Y          []float64 `json:"y"`
+	Thresholds []float64 `json:"thresholds"`
+	Area       float64   `json:"area"`
+}
+
+// namedCoefficients is a model with named coefficients
+type namedCoefficients interface {
+	NamedCoefficients() []ml.Coefficient
+}
+
+// New evaluates model on features and output, opts
+// may be nil
+func New(model ml.Estimator, features [][]float64, output []float64, opts *Options) (*Report, error) {
+	if len(features) == 0 || len(features) != len(output) {
+		return nil, fmt.Errorf("report: got %d rows of features and %d outputs", len(features), len(output))
+	}
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	threshold := 0.5
+	if l, ok := model.(*ml.LogisticRegression); ok {
+		o.Classifier = true
+		threshold = l.DecisionThreshold()
+	}
+	if o.Threshold == nil {
+		o.Threshold = &threshold
+	}
+	if o.Bins == 0 {
+		o.Bins = 10
+	}
+	if o.Repeats == 0 {
+		o.Repeats = 5
+	}
+
+	estimates, err := estimate(model, features)
+	if err != nil {
+		return nil, fmt.Errorf("report: %v", err)
+	}
+	for _, v := range estimates {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("report: model returned %v", v)
+		}
+	}
+
+	r := &Report{
+		Model:      fmt.Sprintf("%T", model),
+		Classifier: o.Classifier,
+		Rows:       len(features),
+		Metrics:    map[string]float64{},
+	}
+
+	metric := ml.R2
+	if o.Classifier {
+		for _, y := range output {
+			if y != 0 && y != 1 {
+				return nil, fmt.Errorf("report: classifier output should be 0 or 1, got %v", y)
+			}
+		}
+		r.classification(output, estimates, o)
+		metric = ml.Accuracy
+	} else {
+		mse := ml.MeanSquaredError(output, estimates)
+		r.Metrics["mse"] = mse
+		r.Metrics["rmse"] = math.Sqrt(mse)
+		r.Metrics["mae"] = meanAbsoluteError(output, estimates)
+		r.Metrics["r2"] = ml.R2(output, estimates)
+	}
+
+	if c, ok := model.(namedCoefficients); ok {
+		for _, v := range c.NamedCoefficients() {
+			r.Coefficients = append(r.Coefficients, Coefficient{Name: v.Name, Value: v.Value})
+		}
+	}
+	if o.Repeats > 0 {
+		for _, v := range ml.PermutationImportanceRand(o.Rand, model, features, output, metric, o.Repeats) {
+			r.Importances = append(r.Importances, Importance{Name: v.Name, Mean: v.Mean, Std: v.Std})
+		}
+	}
+
+	// e.g. r2 of constant output, JSON has no NaN
+	for name, v := range r.Metrics {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			delete(r.Metrics, name)
+		}
+	}
+
+	return r, nil
+}
+
+// classification fills metrics and curves of probabilities
+func (r *Report) classification(output, probabilities []float64, o Options) {
+	c := ml.NewConfusion(output, probabilities, *o.Threshold)
+	r.Threshold = *o.Threshold
+	r.Confusion = (*Confusion)(&c)
+	r.ROC, r.PR = curves(output, probabilities)
+	r.Calibration = (*Calibration)(ml.ReliabilityCurve(output, probabilities, o.Bins))
+
+	r.Metrics["accuracy"] = (c.TruePositive + c.TrueNegative) / float64(len(output))
+	r.Metrics["precision"] = ratio(c.TruePositive, c.TruePositive+c.FalsePositive)
+	r.Metrics["recall"] = ratio(c.TruePositive, c.TruePositive+c.FalseNegative)
+	r.Metrics["f1"] = ml.F1(c)
+	r.Metrics["brier"] = ml.BrierScore(output, probabilities)
+	r.Metrics["roc_auc"] = r.ROC.Area
+	r.Metrics["average_precision"] = r.PR.Area
+}
+
+// curves returns ROC and precision-recall curves with a
+// point per distinct score, from the highest score down.
+// The first point has threshold highest score plus one
+// so no row is true.
+func curves(output, scores []float64) (*Curve, *Curve) {
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+
+	positives, negatives := 0.0, 0.0
+	for _, y := range output {
+		if y == 1 {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+
+	top := scores[idx[0]] + 1
+	roc := &Curve{X: []float64{0}, Y: []float64{0}, Thresholds: []float64{top}}
+	pr := &Curve{X: []float64{0}, Y: []float64{1}, Thresholds: []float64{top}}
+	tp, fp := 0.0, 0.0
+	for k, i := range idx {
+		if output[i] == 1 {
+			tp++
+		} else {
+			fp++
+		}
+		// ties share a point
+		if k+1 < len(idx) && scores[idx[k+1]] == scores[i] {
+			continue
+		}
+
+		fpr, tpr := ratio(fp, negatives), ratio(tp, positives)
+		roc.Area += (fpr - roc.X[len(roc.X)-1]) * (tpr + roc.Y[len(roc.Y)-1]) / 2
+		roc.X = append(roc.X, fpr)
+		roc.Y = append(roc.Y, tpr)
+		roc.Thresholds = append(roc.Thresholds, scores[i])
+
+		precision := tp / (tp + fp)
+		pr.Area += (tpr - pr.X[len(pr.X)-1]) * precision
+		pr.X = append(pr.X, tpr)
+		pr.Y = append(pr.Y, precision)
+		pr.Thresholds = append(pr.Thresholds, scores[i])
+	}
+
+	return roc, pr
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// estimate returns estimates of every row of features
+func estimate(model ml.Estimator, features [][]float64) ([]float64, error) {
+	if b, ok := model.(ml.BatchEstimator); ok {
+		return b.PredictBatch(features)
+	}
+	estimates := make([]float64, len(features))
+	for i, x := range features {
+		estimates[i] = model.Estimate(x)
+	}
+	return estimates, nil
+}
+
+func meanAbsoluteError(output, estimates []float64) float64 {
+	sum := 0.0
+	for i, y := range output {
+		sum += math.Abs(estimates[i] - y)
+	}
+	return sum / float64(len(output))
+}
+
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+// score estimates the first feature
+type score struct{}
+
+func (score) Fit(features [][]float64, output []float64) error { return nil }
+
+func (score) Estimate(X []float64) float64 { return X[0] }
+
+func TestClassificationReport(t *testing.T) {
+	// ranked scores of true, false, true, false
+	features := [][]float64{{0.9}, {0.8}, {0.7}, {0.2}}
+	output := []float64{1, 0, 1, 0}
+	r, err := New(score{}, features, output, &Options{Classifier: true, Repeats: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 3 rows are true at 0.5, one of them wrongly
+	if c := *r.Confusion; c.TruePositive != 2 || c.FalsePositive != 1 || c.TrueNegative != 1 || c.FalseNegative != 0 {
+		t.Errorf("confusion %+v at threshold %v", c, r.Threshold)
+	}
+	for name, want := range map[string]float64{
+		"accuracy":          0.75,
+		"precision":         2.0 / 3,
+		"recall":            1,
+		"f1":                0.8,
+		"brier":             (0.01 + 0.64 + 0.09 + 0.04) / 4,
+		"roc_auc":           0.75,
+		"average_precision": 0.5 + 0.5*2/3,
+	} {
+		if got := r.Metrics[name]; math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s %v, want %v", name, got, want)
+		}
+	}
+	if want := []float64{0, 0, 0.5, 0.5, 1}; !equal(r.ROC.X, want) || !equal(r.ROC.Y, []float64{0, 0.5, 0.5, 1, 1}) {
+		t.Errorf("ROC %v %v", r.ROC.X, r.ROC.Y)
+	}
+	if r.Importances != nil {
+		t.Errorf("importances %v of negative repeats", r.Importances)
+	}
+
+	var b bytes.Buffer
+	if err = r.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err = json.Unmarshal(b.Bytes(), &decoded); err != nil || decoded.Metrics["roc_auc"] != 0.75 {
+		t.Errorf("decoded %+v, %v", decoded, err)
+	}
+	b.Reset()
+	if err = r.WriteHTML(&b); err != nil || !strings.Contains(b.String(), "<svg") {
+		t.Errorf("HTML of %d bytes, %v", b.Len(), err)
+	}
+}
+
+func TestRegressionReport(t *testing.T) {
+	features := [][]float64{{1}, {2}, {3}, {4}}
+	output := []float64{1, 3, 3, 5}
+	r, err := New(score{}, features, output, &Options{Repeats: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// residuals 0, -1, 0, -1
+	for name, want := range map[string]float64{"mse": 0.5, "rmse": math.Sqrt(0.5), "mae": 0.5, "r2": 0.75} {
+		if got := r.Metrics[name]; math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s %v, want %v", name, got, want)
+		}
+	}
+	if len(r.Importances) != 1 || r.Confusion != nil {
+		t.Errorf("importances %v and confusion %v of a regression", r.Importances, r.Confusion)
+	}
+
+	if _, err = New(score{}, features, output[:2], nil); err == nil {
+		t.Error("no error of missing outputs")
+	}
+	if _, err = New(score{}, features, output, &Options{Classifier: true}); err == nil {
+		t.Error("no error of a classifier of output 3")
+	}
+}
+
+func equal(a, b []float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-12 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
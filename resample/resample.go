@@ -0,0 +1,296 @@
+// Package resample balances skewed classification data by
+// random over-sampling, random under-sampling and SMOTE,
+// and draws stratified samples. Results are new Features
+// and Output slices; original rows are reused, not copied.
+package resample
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Sampler draws random rows from Rand, so resampling is
+// reproducible with a seeded Rand. A nil Rand uses the
+// global source of math/rand. Package functions use
+// a Sampler with nil Rand.
+type Sampler struct {
+	Rand *rand.Rand
+}
+
+// NewSampler returns a Sampler seeded with seed
+func NewSampler(seed int64) Sampler {
+	return Sampler{Rand: rand.New(rand.NewSource(seed))}
+}
+
+func (s Sampler) rand() *rand.Rand {
+	return ml.RandOf(s.Rand)
+}
+
+// RandomOverSample duplicates random rows of every class
+// until it has at least ratio times the rows of the
+// largest class. A ratio of 1 fully balances classes.
+func RandomOverSample(features [][]float64, output []float64, ratio float64) ([][]float64, []float64, error) {
+	return Sampler{}.RandomOverSample(features, output, ratio)
+}
+
+// RandomOverSample is RandomOverSample drawing from s
+func (s Sampler) RandomOverSample(features [][]float64, output []float64, ratio float64) ([][]float64, []float64, error) {
+	rng := s.rand()
+	classes, err := groupClasses(features, output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := int(math.Ceil(ratio * float64(largest(classes))))
+	X := append([][]float64(nil), features...)
+	y := append([]float64(nil), output...)
+
+	for _, c := range classes {
+		for n := len(c.rows); n < target; n++ {
+			i := c.rows[rng.Intn(len(c.rows))]
+			X = append(X, features[i])
+			y = append(y, c.label)
+		}
+	}
+
+	return X, y, nil
+}
+
+// RandomUnderSample drops random rows of every class until
+// it has at most the rows of the smallest class divided by
+// ratio. A ratio of 1 fully balances classes.
+func RandomUnderSample(features [][]float64, output []float64, ratio float64) ([][]float64, []float64, error) {
+	return Sampler{}.RandomUnderSample(features, output, ratio)
+}
+
+// RandomUnderSample is RandomUnderSample drawing from s
+func (s Sampler) RandomUnderSample(features [][]float64, output []float64, ratio float64) ([][]float64, []float64, error) {
+	rng := s.rand()
+	classes, err := groupClasses(features, output)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ratio <= 0 {
+		return nil, nil, fmt.Errorf("resample: ratio must be positive, got %v", ratio)
+	}
+
+	target := int(math.Floor(float64(smallest(classes)) / ratio))
+	var X [][]float64
+	var y []float64
+
+	for _, c := range classes {
+		rows := c.rows
+		if len(rows) > target {
+			rows = sample(rng, rows, target)
+		}
+		for _, i := range rows {
+			X = append(X, features[i])
+			y = append(y, c.label)
+		}
+	}
+
+	return X, y, nil
+}
+
+// SMOTE over-samples every class up to ratio times the rows
+// of the largest class with synthetic rows interpolated
+// between a row and one of its k nearest neighbours of the
+// same class
+func SMOTE(features [][]float64, output []float64, k int, ratio float64) ([][]float64, []float64, error) {
+	return Sampler{}.SMOTE(features, output, k, ratio)
+}
+
+// SMOTE is SMOTE drawing from s
+func (s Sampler) SMOTE(features [][]float64, output []float64, k int, ratio float64) ([][]float64, []float64, error) {
+	rng := s.rand()
+	classes, err := groupClasses(features, output)
+	if err != nil {
+		return nil, nil, err
+	}
+	if k < 1 {
+		return nil, nil, fmt.Errorf("resample: SMOTE needs at least 1 neighbour, got %d", k)
+	}
+
+	target := int(math.Ceil(ratio * float64(largest(classes))))
+	X := append([][]float64(nil), features...)
+	y := append([]float64(nil), output...)
+
+	for _, c := range classes {
+		if len(c.rows) >= target {
+			continue
+		}
+		if len(c.rows) < 2 {
+			return nil, nil, fmt.Errorf("resample: SMOTE needs at least 2 rows of class %v", c.label)
+		}
+
+		neighbours := nearest(features, c.rows, k)
+		for n := len(c.rows); n < target; n++ {
+			r := rng.Intn(len(c.rows))
+			a := features[c.rows[r]]
+			b := features[neighbours[r][rng.Intn(len(neighbours[r]))]]
+
+			u := rng.Float64()
+			synthetic := make([]float64, len(a))
+			for j := range a {
+				synthetic[j] = a[j] + u*(b[j]-a[j])
+			}
+
+			X = append(X, synthetic)
+			y = append(y, c.label)
+		}
+	}
+
+	return X, y, nil
+}
+
+// StratifiedSample draws fraction of the rows without
+// replacement, keeping the proportion of every class
+func StratifiedSample(features [][]float64, output []float64, fraction float64) ([][]float64, []float64, error) {
+	return Sampler{}.StratifiedSample(features, output, fraction)
+}
+
+// StratifiedSample is StratifiedSample drawing from s
+func (s Sampler) StratifiedSample(features [][]float64, output []float64, fraction float64) ([][]float64, []float64, error) {
+	rng := s.rand()
+	classes, err := groupClasses(features, output)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fraction <= 0 || fraction > 1 {
+		return nil, nil, fmt.Errorf("resample: fraction must be in (0, 1], got %v", fraction)
+	}
+
+	var X [][]float64
+	var y []float64
+	for _, c := range classes {
+		for _, i := range sample(rng, c.rows, int(math.Round(fraction*float64(len(c.rows))))) {
+			X = append(X, features[i])
+			y = append(y, c.label)
+		}
+	}
+
+	return X, y, nil
+}
+
+// StratifiedBootstrap draws rows with replacement within
+// every class, keeping the number of rows of every class
+func StratifiedBootstrap(features [][]float64, output []float64) ([][]float64, []float64, error) {
+	return Sampler{}.StratifiedBootstrap(features, output)
+}
+
+// StratifiedBootstrap is StratifiedBootstrap drawing from s
+func (s Sampler) StratifiedBootstrap(features [][]float64, output []float64) ([][]float64, []float64, error) {
+	rng := s.rand()
+	classes, err := groupClasses(features, output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var X [][]float64
+	var y []float64
+	for _, c := range classes {
+		for range c.rows {
+			X = append(X, features[c.rows[rng.Intn(len(c.rows))]])
+			y = append(y, c.label)
+		}
+	}
+
+	return X, y, nil
+}
+
+// class holds row indices of a class
+type class struct {
+	label float64
+	rows  []int
+}
+
+// groupClasses returns rows of every class ordered by label
+func groupClasses(features [][]float64, output []float64) ([]class, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("resample: cannot resample empty features")
+	}
+	if len(features) != len(output) {
+		return nil, fmt.Errorf("resample: got %d rows of features and %d outputs", len(features), len(output))
+	}
+
+	index := make(map[float64]int)
+	var classes []class
+	for i, y := range output {
+		c, ok := index[y]
+		if !ok {
+			c = len(classes)
+			index[y] = c
+			classes = append(classes, class{label: y})
+		}
+		classes[c].rows = append(classes[c].rows, i)
+	}
+
+	sort.Slice(classes, func(a, b int) bool { return classes[a].label < classes[b].label })
+	return classes, nil
+}
+
+func largest(classes []class) int {
+	n := 0
+	for _, c := range classes {
+		if len(c.rows) > n {
+			n = len(c.rows)
+		}
+	}
+	return n
+}
+
+func smallest(classes []class) int {
+	n := math.MaxInt32
+	for _, c := range classes {
+		if len(c.rows) < n {
+			n = len(c.rows)
+		}
+	}
+	return n
+}
+
+// sample returns n of rows drawn without replacement
+func sample(rng *rand.Rand, rows []int, n int) []int {
+	out := make([]int, n)
+	for k, p := range rng.Perm(len(rows))[:n] {
+		out[k] = rows[p]
+	}
+	sort.Ints(out)
+	return out
+}
+
+// nearest returns for every row of rows the indices
+// of its k nearest other rows by euclidean distance
+func nearest(features [][]float64, rows []int, k int) [][]int {
+	if k > len(rows)-1 {
+		k = len(rows) - 1
+	}
+
+	out := make([][]int, len(rows))
+	others := make([]int, 0, len(rows)-1)
+	dist := make([]float64, len(features))
+
+	for r, i := range rows {
+		others = others[:0]
+		for _, o := range rows {
+			if o == i {
+				continue
+			}
+			d := 0.0
+			for j, v := range features[i] {
+				d += (v - features[o][j]) * (v - features[o][j])
+			}
+			dist[o] = d
+			others = append(others, o)
+		}
+
+		sort.Slice(others, func(a, b int) bool { return dist[others[a]] < dist[others[b]] })
+		out[r] = append([]int(nil), others[:k]...)
+	}
+
+	return out
+}
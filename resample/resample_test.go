@@ -0,0 +1,103 @@
+package resample
+
+import (
+	"testing"
+)
+
+// skewed returns 8 rows of class 0 at 0..7
+// and 2 rows of class 1 at 10 and 12
+func skewed() ([][]float64, []float64) {
+	var features [][]float64
+	var output []float64
+	for i := 0; i < 8; i++ {
+		features = append(features, []float64{float64(i)})
+		output = append(output, 0)
+	}
+	features = append(features, []float64{10}, []float64{12})
+	output = append(output, 1, 1)
+	return features, output
+}
+
+// counts returns rows of every class of output and checks
+// that rows of class 1 pass in
+func counts(t *testing.T, name string, X [][]float64, y []float64, in func(v float64) bool) map[float64]int {
+	t.Helper()
+	n := map[float64]int{}
+	for i, label := range y {
+		n[label]++
+		if label == 1 && !in(X[i][0]) {
+			t.Errorf("%s: row %v of class 1", name, X[i])
+		}
+	}
+	return n
+}
+
+func original(v float64) bool { return v == 10 || v == 12 }
+
+func TestBalancing(t *testing.T) {
+	features, output := skewed()
+
+	X, y, err := RandomOverSample(features, output, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := counts(t, "over-sample", X, y, original); n[0] != 8 || n[1] != 8 {
+		t.Errorf("over-sampled %v rows of classes, want 8 of both", n)
+	}
+
+	X, y, err = RandomUnderSample(features, output, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := counts(t, "under-sample", X, y, original); n[0] != 2 || n[1] != 2 {
+		t.Errorf("under-sampled %v rows of classes, want 2 of both", n)
+	}
+
+	// synthetic rows lie between the 2 rows of class 1
+	X, y, err = SMOTE(features, output, 1, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	between := func(v float64) bool { return v >= 10 && v <= 12 }
+	if n := counts(t, "SMOTE", X, y, between); n[0] != 8 || n[1] != 4 {
+		t.Errorf("SMOTE gave %v rows of classes, want 8 and 4", n)
+	}
+
+	if _, _, err = SMOTE(features[:9], output[:9], 1, 1); err == nil {
+		t.Error("no error of SMOTE of a single row")
+	}
+	if _, _, err = RandomUnderSample(features, output, 0); err == nil {
+		t.Error("no error of ratio 0")
+	}
+	if _, _, err = RandomOverSample(features, output[:3], 1); err == nil {
+		t.Error("no error of missing outputs")
+	}
+}
+
+func TestStratified(t *testing.T) {
+	features, output := skewed()
+
+	X, y, err := StratifiedSample(features, output, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := counts(t, "stratified sample", X, y, original)
+	if n[0] != 4 || n[1] != 1 {
+		t.Errorf("sampled %v rows of classes, want 4 and 1", n)
+	}
+	seen := map[float64]bool{}
+	for _, x := range X {
+		if seen[x[0]] {
+			t.Errorf("row %v drawn twice without replacement", x)
+		}
+		seen[x[0]] = true
+	}
+
+	X, y, err = StratifiedBootstrap(features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := counts(t, "stratified bootstrap", X, y, original); n[0] != 8 || n[1] != 2 {
+		t.Errorf("bootstrap of %v rows of classes, want 8 and 2", n)
+	}
+}
@@ -0,0 +1,107 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ResidualAnalysis holds diagnostics of a linear
+// regression on its rows
+type ResidualAnalysis struct {
+	// Residuals are outputs minus estimates
+	Residuals []float64
+	// Standardized are residuals divided by their
+	// standard error σ√(1-h)
+	Standardized []float64
+	// Leverage are hat values h, the diagonal of
+	// X(XᵀX)⁻¹Xᵀ, above 2p/n is commonly high
+	Leverage []float64
+	// CooksDistance measures the influence of every
+	// row on the fit, above 4/n is commonly influential
+	CooksDistance []float64
+	// DurbinWatson is near 2 without autocorrelation
+	// of residuals in row order, below 2 for positive
+	// and above 2 for negative autocorrelation
+	DurbinWatson float64
+	// Sigma is the residual standard error
+	Sigma float64
+	// DF are residual degrees of freedom n-p
+	DF int
+}
+
+// AnalyzeResiduals returns residual diagnostics of model
+// on features and output, usually its training rows.
+// Features include the bias column, if any.
+func AnalyzeResiduals(model *LinearRegression, features [][]float64, output []float64) (*ResidualAnalysis, error) {
+	if !model.IsLinear() {
+		return nil, fmt.Errorf("ml: residual analysis needs the linear hypothesis")
+	}
+	n, err := checkRows(features, output)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(model.Theta) {
+		return nil, fmt.Errorf("ml: got %d features for %d thetas", n, len(model.Theta))
+	}
+	rows, p := len(features), len(model.Theta)
+	if rows <= p {
+		return nil, fmt.Errorf("ml: residual analysis needs more rows than the %d thetas", p)
+	}
+
+	a := &ResidualAnalysis{
+		Residuals:     make([]float64, rows),
+		Standardized:  make([]float64, rows),
+		Leverage:      make([]float64, rows),
+		CooksDistance: make([]float64, rows),
+		DF:            rows - p,
+	}
+	rss := 0.0
+	for i, x := range features {
+		a.Residuals[i] = output[i] - model.Predict(x)
+		rss += a.Residuals[i] * a.Residuals[i]
+	}
+	a.Sigma = math.Sqrt(rss / float64(a.DF))
+
+	inv, err := gramInverse(features)
+	if err != nil {
+		return nil, err
+	}
+	var hx mat.VecDense
+	for i, row := range features {
+		x := mat.NewVecDense(p, row)
+		hx.MulVec(inv, x)
+		h := mat.Dot(x, &hx)
+		a.Leverage[i] = h
+		a.Standardized[i] = a.Residuals[i] / (a.Sigma * math.Sqrt(1-h))
+		a.CooksDistance[i] = a.Standardized[i] * a.Standardized[i] / float64(p) * h / (1 - h)
+	}
+
+	num := 0.0
+	for i := 1; i < rows; i++ {
+		d := a.Residuals[i] - a.Residuals[i-1]
+		num += d * d
+	}
+	a.DurbinWatson = num / rss
+
+	return a, nil
+}
+
+// gramInverse returns (XᵀX)⁻¹ of features
+func gramInverse(features [][]float64) (*mat.SymDense, error) {
+	X := denseOf(features)
+	_, p := X.Dims()
+	gram := mat.NewSymDense(p, nil)
+	gram.SymOuterK(1, X.T())
+
+	var chol mat.Cholesky
+	if !chol.Factorize(gram) {
+		return nil, fmt.Errorf("ml: features are collinear, XᵀX is singular")
+	}
+	inv := mat.NewSymDense(p, nil)
+	if err := chol.InverseTo(inv); err != nil {
+		return nil, fmt.Errorf("ml: features are collinear: %v", err)
+	}
+	return inv, nil
+}
@@ -0,0 +1,92 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeResiduals(t *testing.T) {
+	// y = 2.2 + 0.6x is the least squares fit, leverage is
+	// 1/n + (x-3)²/10 and Cook's distance r²/(pσ²)·h/(1-h)²
+	features := [][]float64{{1, 1}, {1, 2}, {1, 3}, {1, 4}, {1, 5}}
+	output := []float64{2, 4, 5, 4, 5}
+	model := NewLinearRegression()
+	model.Theta = []float64{2.2, 0.6}
+
+	a, err := AnalyzeResiduals(model, features, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ResidualAnalysis{
+		Residuals:     []float64{-0.8, 0.6, 1, -0.6, -0.2},
+		Standardized:  []float64{-0.8 / math.Sqrt(0.32), 0.6 / math.Sqrt(0.56), 1 / math.Sqrt(0.64), -0.6 / math.Sqrt(0.56), -0.2 / math.Sqrt(0.32)},
+		Leverage:      []float64{0.6, 0.3, 0.2, 0.3, 0.6},
+		CooksDistance: []float64{1.5, 0.225 * 0.3 / 0.49, 0.625 * 0.2 / 0.64, 0.225 * 0.3 / 0.49, 0.09375},
+		// 4.84 / 2.4
+		DurbinWatson: 2.0166666666666666,
+		Sigma:        math.Sqrt(0.8),
+		DF:           3,
+	}
+	near := func(name string, got, want []float64) {
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("%s %v, want %v", name, got, want)
+				return
+			}
+		}
+	}
+	near("residuals", a.Residuals, want.Residuals)
+	near("standardized", a.Standardized, want.Standardized)
+	near("leverage", a.Leverage, want.Leverage)
+	near("Cook's distance", a.CooksDistance, want.CooksDistance)
+	near("scalars", []float64{a.DurbinWatson, a.Sigma, float64(a.DF)}, []float64{want.DurbinWatson, want.Sigma, float64(want.DF)})
+}
+
+func TestAnalyzeResidualsDurbinWatson(t *testing.T) {
+	// alternating residuals are negatively
+	// autocorrelated, a trend positively
+	features := make([][]float64, 20)
+	alternating, trend := make([]float64, 20), make([]float64, 20)
+	for i := range features {
+		features[i] = []float64{1}
+		alternating[i] = float64(i%2*2 - 1)
+		trend[i] = float64(i) - 9.5
+	}
+	model := NewLinearRegression()
+	model.Theta = []float64{0}
+
+	a, err := AnalyzeResiduals(model, features, alternating)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 19 differences of ±2 over 20 squares of 1
+	if math.Abs(a.DurbinWatson-3.8) > 1e-12 {
+		t.Errorf("Durbin-Watson %v of alternating residuals, want 3.8", a.DurbinWatson)
+	}
+	if a, err = AnalyzeResiduals(model, features, trend); err != nil {
+		t.Fatal(err)
+	}
+	// 19 differences of 1 over Σ(i-9.5)² = 665
+	if math.Abs(a.DurbinWatson-19.0/665) > 1e-12 {
+		t.Errorf("Durbin-Watson %v of trending residuals, want %v", a.DurbinWatson, 19.0/665)
+	}
+}
+
+func TestAnalyzeResidualsErrors(t *testing.T) {
+	model := NewLinearRegression()
+	model.Theta = []float64{0, 1}
+	collinear := [][]float64{{1, 2}, {1, 2}, {1, 2}}
+	if _, err := AnalyzeResiduals(model, collinear, []float64{1, 2, 3}); err == nil {
+		t.Error("no error of collinear features")
+	}
+	if _, err := AnalyzeResiduals(model, [][]float64{{1, 1}, {1, 2}}, []float64{1, 2}); err == nil {
+		t.Error("no error of as many rows as thetas")
+	}
+	if _, err := AnalyzeResiduals(model, [][]float64{{1}, {2}, {3}}, []float64{1, 2, 3}); err == nil {
+		t.Error("no error of fewer features than thetas")
+	}
+	model.Hypothesis = func(x, theta []float64) float64 { return theta[0] * math.Exp(theta[1]*x[1]) }
+	if _, err := AnalyzeResiduals(model, [][]float64{{1, 1}, {1, 2}, {1, 3}}, []float64{1, 2, 3}); err == nil {
+		t.Error("no error of a custom hypothesis")
+	}
+}
@@ -0,0 +1,237 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RFE is recursive feature elimination. It repeatedly
+// fits an estimator and drops the columns with the
+// smallest absolute coefficients. RFE is an Estimator
+// and a Transformer.
+type RFE struct {
+	// NewEstimator returns the LinearModel to fit
+	NewEstimator func() Estimator
+	// NFeatures is the number of columns to select,
+	// including Keep columns. Zero selects half of
+	// the columns. With CV it is the minimum tried.
+	NFeatures int
+	// Step is the number of columns dropped
+	// every iteration, defaults to 1
+	Step int
+	// CV, when set, chooses the number of columns
+	// with the best mean cross-validated Metric
+	CV     Splitter
+	Metric Metric
+	// Keep are columns never eliminated, such
+	// as the bias column
+	Keep []int
+
+	// Selected are the chosen columns, ascending
+	Selected []int
+	// Ranking is 1 for selected columns and
+	// larger for columns eliminated earlier
+	Ranking []int
+	// Scores maps number of columns to mean
+	// cross-validated score when CV is set
+	Scores map[int]float64
+	// Estimator is fitted on selected columns
+	Estimator Estimator
+}
+
+// NewRFE returns new pointer of RFE selecting
+// nFeatures columns of features
+func NewRFE(newEstimator func() Estimator, nFeatures int) *RFE {
+	return &RFE{
+		NewEstimator: newEstimator,
+		NFeatures:    nFeatures,
+		Step:         1,
+	}
+}
+
+// Fit eliminates columns of features and fits the
+// estimator on the selected ones
+func (r *RFE) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+
+	n := len(features[0])
+	target := r.NFeatures
+	if target <= 0 {
+		target = n / 2
+		if r.CV != nil {
+			target = 1
+		}
+	}
+	if target < len(r.Keep) {
+		target = len(r.Keep)
+	}
+	if target < 1 || target > n {
+		return fmt.Errorf("ml: cannot select %d of %d columns", target, n)
+	}
+
+	if r.CV != nil {
+		if r.Metric == nil {
+			return fmt.Errorf("ml: RFE with CV requires a Metric")
+		}
+
+		best, err := r.crossValidate(features, output, target)
+		if err != nil {
+			return err
+		}
+		target = best
+	}
+
+	ranking := make([]int, n)
+	estimator, selected, err := r.eliminate(features, output, target, func(dropped []int, round int) {
+		for _, j := range dropped {
+			ranking[j] = round
+		}
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	rounds := 0
+	for _, rank := range ranking {
+		if rank > rounds {
+			rounds = rank
+		}
+	}
+	for j := range ranking {
+		if ranking[j] == 0 {
+			ranking[j] = 1
+		} else {
+			ranking[j] = rounds - ranking[j] + 2
+		}
+	}
+
+	r.Selected = selected
+	r.Ranking = ranking
+	r.Estimator = estimator
+
+	return nil
+}
+
+// crossValidate scores the elimination path on every
+// fold and returns the number of columns scoring best
+func (r *RFE) crossValidate(features [][]float64, output []float64, target int) (int, error) {
+	sums := make(map[int]float64)
+	folds, err := r.CV.Split(len(features))
+	if err != nil {
+		return 0, err
+	}
+
+	for f, fold := range folds {
+		trainX, trainY := subset(features, output, fold.Train)
+		testX, testY := subset(features, output, fold.Test)
+
+		_, _, err := r.eliminate(trainX, trainY, target, nil, func(estimator Estimator, cols []int) {
+			sums[len(cols)] += r.Metric(testY, estimateAll(estimator, selectColumns(testX, cols)))
+		})
+		if err != nil {
+			return 0, fmt.Errorf("ml: fold %d: %v", f, err)
+		}
+	}
+
+	r.Scores = make(map[int]float64, len(sums))
+	best, bestScore := 0, math.Inf(-1)
+	for count, sum := range sums {
+		r.Scores[count] = sum / float64(len(folds))
+		if r.Scores[count] > bestScore || r.Scores[count] == bestScore && count < best {
+			best, bestScore = count, r.Scores[count]
+		}
+	}
+
+	return best, nil
+}
+
+// eliminate fits and drops columns until target columns
+// remain. onDrop receives dropped columns of every round
+// and onFit every fitted estimator with its columns.
+func (r *RFE) eliminate(
+	features [][]float64,
+	output []float64,
+	target int,
+	onDrop func(dropped []int, round int),
+	onFit func(estimator Estimator, cols []int),
+) (Estimator, []int, error) {
+	step := r.Step
+	if step < 1 {
+		step = 1
+	}
+
+	keep := make(map[int]bool, len(r.Keep))
+	for _, j := range r.Keep {
+		keep[j] = true
+	}
+
+	cols := make([]int, len(features[0]))
+	for j := range cols {
+		cols[j] = j
+	}
+
+	for round := 1; ; round++ {
+		estimator := r.NewEstimator()
+		if err := estimator.Fit(selectColumns(features, cols), output); err != nil {
+			return nil, nil, err
+		}
+		if onFit != nil {
+			onFit(estimator, cols)
+		}
+		if len(cols) <= target {
+			return estimator, cols, nil
+		}
+
+		model, ok := estimator.(LinearModel)
+		if !ok {
+			return nil, nil, fmt.Errorf("ml: RFE requires a LinearModel, got %T", estimator)
+		}
+		coef := model.Coefficients()
+
+		var candidates []int
+		for k, j := range cols {
+			if !keep[j] {
+				candidates = append(candidates, k)
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return math.Abs(coef[candidates[a]]) < math.Abs(coef[candidates[b]])
+		})
+
+		drop := step
+		if len(cols)-drop < target {
+			drop = len(cols) - target
+		}
+
+		dropped := make(map[int]bool, drop)
+		var droppedCols []int
+		for _, k := range candidates[:drop] {
+			dropped[k] = true
+			droppedCols = append(droppedCols, cols[k])
+		}
+		if onDrop != nil {
+			onDrop(droppedCols, round)
+		}
+
+		remaining := cols[:0:0]
+		for k, j := range cols {
+			if !dropped[k] {
+				remaining = append(remaining, j)
+			}
+		}
+		cols = remaining
+	}
+}
+
+// Transform returns features keeping only selected columns
+func (r *RFE) Transform(features [][]float64) [][]float64 {
+	return selectColumns(features, r.Selected)
+}
+
+// Estimate returns estimate of X using selected columns
+func (r *RFE) Estimate(X []float64) float64 {
+	return r.Estimator.Estimate(selectColumns([][]float64{X}, r.Selected)[0])
+}
@@ -0,0 +1,69 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// StandardScaler centers every column to zero mean and
+// scales it to unit variance. StandardScaler is a
+// Transformer.
+type StandardScaler struct {
+	// Keep are columns left untouched,
+	// such as the bias column
+	Keep []int
+
+	// Mean and Scale, the population standard deviation,
+	// are computed by Fit. Constant columns keep scale 1.
+	Mean  []float64
+	Scale []float64
+}
+
+// NewStandardScaler returns new pointer of StandardScaler
+// leaving keep columns untouched
+func NewStandardScaler(keep ...int) *StandardScaler {
+	return &StandardScaler{Keep: keep}
+}
+
+// Fit computes mean and scale of every column,
+// output is ignored
+func (s *StandardScaler) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+
+	n := len(features[0])
+	s.Mean = make([]float64, n)
+	s.Scale = make([]float64, n)
+	for j := 0; j < n; j++ {
+		s.Scale[j] = 1
+		if contains(s.Keep, j) {
+			continue
+		}
+
+		col := column(features, j)
+		m := mean(col)
+		variance := 0.0
+		for _, v := range col {
+			variance += (v - m) * (v - m)
+		}
+		s.Mean[j] = m
+		if sd := math.Sqrt(variance / float64(len(col))); sd > 0 {
+			s.Scale[j] = sd
+		}
+	}
+
+	return nil
+}
+
+// Transform returns standardized features
+func (s *StandardScaler) Transform(features [][]float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, row := range features {
+		out[i] = make([]float64, len(row))
+		for j, v := range row {
+			out[i][j] = (v - s.Mean[j]) / s.Scale[j]
+		}
+	}
+	return out
+}
@@ -0,0 +1,195 @@
+package ml
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+)
+
+// Schedule scales the Step of SGD during training. Epoch
+// counts epochs done so far, including a fraction of the
+// running epoch, and continues across ResumeFrom.
+type Schedule interface {
+	Factor(epoch float64) float64
+}
+
+// StepDecay multiplies the step by Gamma every Every epochs
+type StepDecay struct {
+	Every float64
+	Gamma float64
+}
+
+// Factor returns the scale of the step at epoch
+func (s StepDecay) Factor(epoch float64) float64 {
+	if s.Every <= 0 {
+		return 1
+	}
+	return math.Pow(s.Gamma, math.Floor(epoch/s.Every))
+}
+
+// ExponentialDecay multiplies the step by Gamma every
+// epoch, smoothly within epochs
+type ExponentialDecay struct {
+	Gamma float64
+}
+
+// Factor returns the scale of the step at epoch
+func (s ExponentialDecay) Factor(epoch float64) float64 {
+	return math.Pow(s.Gamma, epoch)
+}
+
+// CosineDecay lowers the step along half a cosine from
+// 1 to Min over Epochs, then keeps Min
+type CosineDecay struct {
+	Epochs float64
+	Min    float64
+}
+
+// Factor returns the scale of the step at epoch
+func (s CosineDecay) Factor(epoch float64) float64 {
+	if s.Epochs <= 0 || epoch >= s.Epochs {
+		return s.Min
+	}
+	return s.Min + (1-s.Min)*(1+math.Cos(math.Pi*epoch/s.Epochs))/2
+}
+
+// InverseTimeDecay is 1/(1+Decay·epoch)
+type InverseTimeDecay struct {
+	Decay float64
+}
+
+// Factor returns the scale of the step at epoch
+func (s InverseTimeDecay) Factor(epoch float64) float64 {
+	return 1 / (1 + s.Decay*epoch)
+}
+
+// Warmup raises the step linearly from zero over Epochs,
+// then follows Then counting epochs after the warmup,
+// nil Then keeps the step
+type Warmup struct {
+	Epochs float64
+	Then   Schedule
+}
+
+// Factor returns the scale of the step at epoch
+func (s Warmup) Factor(epoch float64) float64 {
+	if epoch < s.Epochs {
+		return epoch / s.Epochs
+	}
+	if s.Then == nil {
+		return 1
+	}
+	return s.Then.Factor(epoch - s.Epochs)
+}
+
+// WithSchedule sets the Schedule of the step of SGD
+func WithSchedule(schedule Schedule) Option {
+	return func(o *options) error {
+		if schedule == nil {
+			return fmt.Errorf("ml: nil schedule")
+		}
+		o.setting().Schedule = schedule
+		return nil
+	}
+}
+
+// Adam adapts the step of every theta by running means
+// of gradients and squared gradients. Zero fields take
+// the defaults of NewAdam. The moments are saved in
+// checkpoints and continue across ResumeFrom, and
+// across fits with WarmStart.
+type Adam struct {
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+
+	m, v []float64
+	t    int
+}
+
+// NewAdam returns new pointer of SGD training model
+// with Adam for 10 epochs with step size 0.001
+func NewAdam(model SGDModel) *SGD {
+	s := NewSGD(model)
+	s.Step = 0.001
+	s.Adam = &Adam{Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8}
+	return s
+}
+
+// reset clears moments of a previous fit
+func (a *Adam) reset(n int) {
+	a.m, a.v, a.t = make([]float64, n), make([]float64, n), 0
+}
+
+// start readies moments of n thetas, continuing those
+// of checkpoint c or, with warm, of the previous fit
+func (a *Adam) start(n int, c *Checkpoint, warm bool) {
+	switch {
+	case c != nil && c.Adam != nil && len(c.Adam.M) == n && len(c.Adam.V) == n:
+		a.m = append([]float64(nil), c.Adam.M...)
+		a.v = append([]float64(nil), c.Adam.V...)
+		a.t = c.Adam.T
+	case warm && len(a.m) == n:
+	default:
+		a.reset(n)
+	}
+}
+
+// state returns a copy of the moments
+func (a *Adam) state() *AdamState {
+	return &AdamState{
+		M: append([]float64(nil), a.m...),
+		V: append([]float64(nil), a.v...),
+		T: a.t,
+	}
+}
+
+type adamState struct {
+	Beta1, Beta2, Epsilon float64
+	Moments               *AdamState
+}
+
+// GobEncode saves the settings with the moments, so
+// a loaded SGD continues them with WarmStart
+func (a *Adam) GobEncode() ([]byte, error) {
+	return encodeGob(&adamState{a.Beta1, a.Beta2, a.Epsilon, a.state()})
+}
+
+// GobDecode restores a saved Adam
+func (a *Adam) GobDecode(data []byte) error {
+	var s adamState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	a.Beta1, a.Beta2, a.Epsilon = s.Beta1, s.Beta2, s.Epsilon
+	a.m, a.v, a.t = nil, nil, 0
+	if s.Moments != nil {
+		a.m, a.v, a.t = s.Moments.M, s.Moments.V, s.Moments.T
+	}
+	return nil
+}
+
+// update moves theta by step along the
+// bias-corrected moments of grad
+func (a *Adam) update(theta, grad []float64, step float64) {
+	beta1, beta2, eps := a.Beta1, a.Beta2, a.Epsilon
+	if beta1 == 0 {
+		beta1 = 0.9
+	}
+	if beta2 == 0 {
+		beta2 = 0.999
+	}
+	if eps == 0 {
+		eps = 1e-8
+	}
+
+	a.t++
+	c1 := 1 - math.Pow(beta1, float64(a.t))
+	c2 := 1 - math.Pow(beta2, float64(a.t))
+	for j, g := range grad {
+		a.m[j] = beta1*a.m[j] + (1-beta1)*g
+		a.v[j] = beta2*a.v[j] + (1-beta2)*g*g
+		theta[j] -= step * (a.m[j] / c1) / (math.Sqrt(a.v[j]/c2) + eps)
+	}
+}
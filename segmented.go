@@ -0,0 +1,211 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// SegmentedRegression is a linear regression whose slope
+// along Column changes at breakpoints, e.g. dose-response
+// or pricing curves, continuous at every breakpoint:
+//
+//	y = θ·x + Σ Changes[k]·max(0, x[Column] - Knots[k])
+//
+// Knots minimize squared errors, searched over values of
+// Column one knot at a time, then refined between
+// neighbouring values.
+type SegmentedRegression struct {
+	// Column is the feature of the breakpoints
+	Column int
+	// Breakpoints are the number of knots, defaults to 1
+	Breakpoints int
+	// MinSegment are the fewest rows of every
+	// segment, defaults to 3
+	MinSegment int
+	// MaxIterations are sweeps over the knots,
+	// defaults to 20
+	MaxIterations int
+
+	// Knots are the breakpoints, ascending
+	Knots []float64
+	// Theta are thetas of features, Changes are
+	// changes of slope at every knot
+	Theta   []float64
+	Changes []float64
+}
+
+// NewSegmentedRegression returns new pointer of
+// SegmentedRegression of breakpoints along column
+func NewSegmentedRegression(column, breakpoints int) *SegmentedRegression {
+	return &SegmentedRegression{Column: column, Breakpoints: breakpoints, MinSegment: 3, MaxIterations: 20}
+}
+
+// Fit trains the model on features and output
+func (s *SegmentedRegression) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if s.Column < 0 || s.Column >= p {
+		return fmt.Errorf("ml: column %d of %d columns", s.Column, p)
+	}
+	breaks := s.Breakpoints
+	if breaks <= 0 {
+		breaks = 1
+	}
+	minSegment := s.MinSegment
+	if minSegment <= 0 {
+		minSegment = 3
+	}
+	sweeps := s.MaxIterations
+	if sweeps <= 0 {
+		sweeps = 20
+	}
+
+	x := column(features, s.Column)
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	var values []float64
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			values = append(values, v)
+		}
+	}
+
+	// valid tells whether every segment has enough rows
+	valid := func(knots []float64) bool {
+		prev := 0
+		for _, k := range knots {
+			at := sort.Search(len(sorted), func(i int) bool { return sorted[i] > k })
+			if at-prev < minSegment {
+				return false
+			}
+			prev = at
+		}
+		return len(sorted)-prev >= minSegment
+	}
+	sse := func(knots []float64) float64 {
+		X := s.hinges(features, knots)
+		theta, err := leastSquares(X, output)
+		if err != nil {
+			return math.Inf(1)
+		}
+		sum := 0.0
+		for i, row := range X {
+			e := output[i] - floats.Dot(row, theta)
+			sum += e * e
+		}
+		return sum
+	}
+
+	knots := make([]float64, breaks)
+	for k := range knots {
+		knots[k] = quantile(float64(k+1)/float64(breaks+1), sorted)
+	}
+	if !valid(knots) {
+		return fmt.Errorf("ml: %d rows cannot make %d segments of %d rows", len(x), breaks+1, minSegment)
+	}
+	best := sse(knots)
+
+	trial := make([]float64, breaks)
+	for sweep := 0; sweep < sweeps; sweep++ {
+		moved := false
+		for k := range knots {
+			copy(trial, knots)
+			for _, v := range values {
+				if k > 0 && v <= knots[k-1] || k < breaks-1 && v >= knots[k+1] {
+					continue
+				}
+				trial[k] = v
+				if !valid(trial) {
+					continue
+				}
+				if e := sse(trial); e < best {
+					best = e
+					knots[k] = v
+					moved = true
+				}
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	// knots may lie between values, golden section
+	// search between the neighbouring values
+	for k := range knots {
+		at := sort.SearchFloat64s(values, knots[k])
+		lo, hi := values[max(at-1, 0)], values[min(at+1, len(values)-1)]
+		copy(trial, knots)
+		f := func(v float64) float64 {
+			trial[k] = v
+			if k > 0 && v <= knots[k-1] || k < breaks-1 && v >= knots[k+1] || !valid(trial) {
+				return math.Inf(1)
+			}
+			return sse(trial)
+		}
+		ratio := (math.Sqrt(5) - 1) / 2
+		a, b := hi-ratio*(hi-lo), lo+ratio*(hi-lo)
+		fa, fb := f(a), f(b)
+		for it := 0; it < 60 && hi-lo > 1e-10*math.Max(1, math.Abs(hi)); it++ {
+			if fa < fb {
+				hi, b, fb = b, a, fa
+				a = hi - ratio*(hi-lo)
+				fa = f(a)
+			} else {
+				lo, a, fa = a, b, fb
+				b = lo + ratio*(hi-lo)
+				fb = f(b)
+			}
+		}
+		if v := (lo + hi) / 2; f(v) < best {
+			best = f(v)
+			knots[k] = v
+		}
+	}
+
+	theta, err := leastSquares(s.hinges(features, knots), output)
+	if err != nil {
+		return err
+	}
+	s.Knots = knots
+	s.Theta = theta[:p]
+	s.Changes = theta[p:]
+	return nil
+}
+
+// hinges returns features with max(0, x - knot)
+// of Column for every knot
+func (s *SegmentedRegression) hinges(features [][]float64, knots []float64) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, row := range features {
+		out[i] = append(append(make([]float64, 0, len(row)+len(knots)), row...), make([]float64, len(knots))...)
+		for k, v := range knots {
+			out[i][len(row)+k] = math.Max(0, row[s.Column]-v)
+		}
+	}
+	return out
+}
+
+// Slopes returns slopes along Column of every
+// segment, from left to right
+func (s *SegmentedRegression) Slopes() []float64 {
+	slopes := []float64{s.Theta[s.Column]}
+	for k, d := range s.Changes {
+		slopes = append(slopes, slopes[k]+d)
+	}
+	return slopes
+}
+
+// Estimate returns predicted value of X
+func (s *SegmentedRegression) Estimate(X []float64) float64 {
+	y := floats.Dot(X, s.Theta)
+	for k, v := range s.Knots {
+		y += s.Changes[k] * math.Max(0, X[s.Column]-v)
+	}
+	return y
+}
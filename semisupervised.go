@@ -0,0 +1,326 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// SelfTraining fits a probabilistic classifier on labelled
+// rows, output of unlabelled rows being NaN, then labels
+// the unlabelled rows it is confident of and refits, until
+// no row is confident enough. Binary classifiers estimate
+// probabilities of true, classifiers with Probabilities of
+// every class, such as SoftmaxRegression, label the most
+// probable class.
+type SelfTraining struct {
+	NewEstimator func() Estimator
+	// Threshold is the probability of the label
+	// needed to take it, defaults to 0.75
+	Threshold float64
+	// MaxIterations defaults to 10
+	MaxIterations int
+
+	// Labelled tells which training rows were labelled,
+	// given or pseudo
+	Labelled []bool
+	// Iterations are refits after the first fit
+	Iterations int
+	Estimator  Estimator
+}
+
+// NewSelfTraining returns new pointer of SelfTraining
+func NewSelfTraining(newEstimator func() Estimator) *SelfTraining {
+	return &SelfTraining{NewEstimator: newEstimator, Threshold: 0.75, MaxIterations: 10}
+}
+
+// Fit trains the classifier on features and output,
+// NaN output being unlabelled
+func (s *SelfTraining) Fit(features [][]float64, output []float64) error {
+	if _, err := checkRows(features, output); err != nil {
+		return err
+	}
+	threshold := s.Threshold
+	if threshold <= 0 {
+		threshold = 0.75
+	}
+	iterations := s.MaxIterations
+	if iterations <= 0 {
+		iterations = 10
+	}
+
+	labels := append([]float64(nil), output...)
+	s.Labelled = make([]bool, len(output))
+	for i, y := range output {
+		s.Labelled[i] = !math.IsNaN(y)
+	}
+
+	s.Iterations = 0
+	for {
+		var idx []int
+		for i, ok := range s.Labelled {
+			if ok {
+				idx = append(idx, i)
+			}
+		}
+		if len(idx) == 0 {
+			return fmt.Errorf("ml: self training needs labelled rows")
+		}
+		X, y := subset(features, labels, idx)
+		estimator := s.NewEstimator()
+		if err := estimator.Fit(X, y); err != nil {
+			return err
+		}
+		s.Estimator = estimator
+		if s.Iterations == iterations || len(idx) == len(labels) {
+			return nil
+		}
+
+		added := 0
+		for i, ok := range s.Labelled {
+			if ok {
+				continue
+			}
+			label, prob := s.label(features[i])
+			if prob >= threshold {
+				labels[i] = label
+				s.Labelled[i] = true
+				added++
+			}
+		}
+		if added == 0 {
+			return nil
+		}
+		s.Iterations++
+	}
+}
+
+// label returns the most probable label of X
+// with its probability
+func (s *SelfTraining) label(X []float64) (float64, float64) {
+	if mc, ok := s.Estimator.(multiclass); ok {
+		prob := mc.Probabilities(X)
+		k := floats.MaxIdx(prob)
+		return float64(k), prob[k]
+	}
+	p := s.Estimator.Estimate(X)
+	if p >= 0.5 {
+		return 1, p
+	}
+	return 0, 1 - p
+}
+
+// Estimate returns estimate of X of the classifier
+func (s *SelfTraining) Estimate(X []float64) float64 {
+	return s.Estimator.Estimate(X)
+}
+
+// LabelPropagation labels unlabelled rows, output being
+// NaN, by propagating classes 0 to Classes-1 of labelled
+// rows along a graph of the rows, weighted by an RBF kernel
+// or joining nearest Neighbors, while labelled rows keep
+// their classes (Zhu and Ghahramani, 2002)
+type LabelPropagation struct {
+	Classes int
+	// Gamma of the RBF kernel exp(-γ|x-x'|²),
+	// defaults to 1/median of squared distances
+	Gamma float64
+	// Neighbors, when set, weights the graph 1 between
+	// a row and its nearest neighbors instead
+	Neighbors int
+	// MaxIterations defaults to 1000
+	MaxIterations int
+	// Tolerance defaults to 1e-6
+	Tolerance float64
+
+	// Features of training rows, and Distributions
+	// of classes of every training row
+	Features      [][]float64
+	Distributions [][]float64
+	// Labels are most probable classes of training rows
+	Labels []float64
+}
+
+// NewLabelPropagation returns new pointer of
+// LabelPropagation of classes
+func NewLabelPropagation(classes int) *LabelPropagation {
+	return &LabelPropagation{Classes: classes, MaxIterations: 1000, Tolerance: 1e-6}
+}
+
+// Fit labels rows of features with NaN output
+func (l *LabelPropagation) Fit(features [][]float64, output []float64) error {
+	if _, err := checkRows(features, output); err != nil {
+		return err
+	}
+	if l.Classes < 2 {
+		return fmt.Errorf("ml: label propagation needs at least 2 classes, got %d", l.Classes)
+	}
+	labelled := 0
+	for i, y := range output {
+		if math.IsNaN(y) {
+			continue
+		}
+		if y < 0 || y >= float64(l.Classes) || y != math.Floor(y) {
+			return fmt.Errorf("ml: output %d is %v, not a class of %d", i, y, l.Classes)
+		}
+		labelled++
+	}
+	if labelled == 0 {
+		return fmt.Errorf("ml: label propagation needs labelled rows")
+	}
+	iterations := l.MaxIterations
+	if iterations <= 0 {
+		iterations = 1000
+	}
+	tolerance := l.Tolerance
+	if tolerance <= 0 {
+		tolerance = 1e-6
+	}
+
+	n := len(features)
+	l.Features = features
+	if l.Neighbors <= 0 && l.Gamma <= 0 {
+		l.Gamma = medianGamma(features)
+	}
+	weights := make([][]float64, n)
+	for i := range weights {
+		weights[i] = l.weights(features[i], true)
+		weights[i][i] = 0
+	}
+	if l.Neighbors > 0 {
+		// symmetric graph of neighbors
+		for i := range weights {
+			for j := range weights[i] {
+				if weights[i][j] > 0 {
+					weights[j][i] = weights[i][j]
+				}
+			}
+		}
+	}
+
+	dist := make([][]float64, n)
+	for i, y := range output {
+		dist[i] = make([]float64, l.Classes)
+		if math.IsNaN(y) {
+			for k := range dist[i] {
+				dist[i][k] = 1 / float64(l.Classes)
+			}
+		} else {
+			dist[i][int(y)] = 1
+		}
+	}
+	next := make([][]float64, n)
+	for i := range next {
+		next[i] = make([]float64, l.Classes)
+	}
+	for it := 0; it < iterations; it++ {
+		moved := 0.0
+		for i, y := range output {
+			if !math.IsNaN(y) {
+				copy(next[i], dist[i])
+				continue
+			}
+			for k := range next[i] {
+				next[i][k] = 0
+			}
+			total := floats.Sum(weights[i])
+			if total == 0 {
+				copy(next[i], dist[i])
+				continue
+			}
+			for j, w := range weights[i] {
+				if w > 0 {
+					floats.AddScaled(next[i], w/total, dist[j])
+				}
+			}
+			moved = math.Max(moved, floats.Distance(next[i], dist[i], math.Inf(1)))
+		}
+		dist, next = next, dist
+		if moved <= tolerance {
+			break
+		}
+	}
+
+	l.Distributions = dist
+	l.Labels = make([]float64, n)
+	for i, d := range dist {
+		l.Labels[i] = float64(floats.MaxIdx(d))
+	}
+	return nil
+}
+
+// weights returns weights of edges from X to training
+// rows, of one more neighbor when X is a training row
+func (l *LabelPropagation) weights(X []float64, training bool) []float64 {
+	w := make([]float64, len(l.Features))
+	for j, x := range l.Features {
+		w[j] = floats.Distance(X, x, 2)
+	}
+	if l.Neighbors > 0 {
+		idx := make([]int, len(w))
+		for j := range idx {
+			idx[j] = j
+		}
+		sort.SliceStable(idx, func(a, b int) bool { return w[idx[a]] < w[idx[b]] })
+		for j := range w {
+			w[j] = 0
+		}
+		k := l.Neighbors
+		if training {
+			k++
+		}
+		for _, j := range idx[:min(k, len(idx))] {
+			w[j] = 1
+		}
+		return w
+	}
+	for j, d := range w {
+		w[j] = math.Exp(-l.Gamma * d * d)
+	}
+	return w
+}
+
+// medianGamma returns 1/median of squared distances
+// between rows, of at most 1000 rows
+func medianGamma(features [][]float64) float64 {
+	rows := features[:min(len(features), 1000)]
+	var d []float64
+	for i := range rows {
+		for j := i + 1; j < len(rows); j++ {
+			v := floats.Distance(rows[i], rows[j], 2)
+			d = append(d, v*v)
+		}
+	}
+	sort.Float64s(d)
+	if m := quantile(0.5, d); m > 0 {
+		return 1 / m
+	}
+	return 1
+}
+
+// Probabilities returns probability of every class of X,
+// weighted by its edges to training rows
+func (l *LabelPropagation) Probabilities(X []float64) []float64 {
+	prob := make([]float64, l.Classes)
+	w := l.weights(X, false)
+	total := floats.Sum(w)
+	if total == 0 {
+		for k := range prob {
+			prob[k] = 1 / float64(l.Classes)
+		}
+		return prob
+	}
+	for j, v := range w {
+		if v > 0 {
+			floats.AddScaled(prob, v/total, l.Distributions[j])
+		}
+	}
+	return prob
+}
+
+// Estimate returns the most probable class of X
+func (l *LabelPropagation) Estimate(X []float64) float64 {
+	return float64(floats.MaxIdx(l.Probabilities(X)))
+}
@@ -0,0 +1,220 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SequentialSelector greedily adds (forward) or removes
+// (backward) one column at a time, choosing the column
+// with the best mean cross-validated Metric. It is an
+// Estimator and a Transformer.
+type SequentialSelector struct {
+	NewEstimator func() Estimator
+	// NFeatures is the number of columns to select,
+	// including Keep columns. Zero selects half.
+	NFeatures int
+	Backward  bool
+	CV        Splitter
+	Metric    Metric
+	// Keep are columns always selected
+	Keep []int
+	// Workers evaluating candidates concurrently,
+	// defaults to GOMAXPROCS
+	Workers int
+
+	// Selected are the chosen columns, ascending
+	Selected []int
+	// Path holds the columns and score of every step
+	Path []SelectionStep
+	// Estimator is fitted on selected columns
+	Estimator Estimator
+}
+
+// SelectionStep is a step of sequential selection
+type SelectionStep struct {
+	Columns []int
+	Score   float64
+}
+
+// NewSequentialSelector returns new pointer of
+// SequentialSelector doing forward selection of
+// nFeatures columns with 5-fold cross-validation
+func NewSequentialSelector(newEstimator func() Estimator, nFeatures int, metric Metric) *SequentialSelector {
+	return &SequentialSelector{
+		NewEstimator: newEstimator,
+		NFeatures:    nFeatures,
+		CV:           KFold{K: 5},
+		Metric:       metric,
+	}
+}
+
+// Fit selects columns of features and fits the
+// estimator on them
+func (s *SequentialSelector) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+
+	n := len(features[0])
+	target := s.NFeatures
+	if target <= 0 {
+		target = n / 2
+	}
+	if target < len(s.Keep) || target > n || target < 1 {
+		return fmt.Errorf("ml: cannot select %d of %d columns keeping %d", target, n, len(s.Keep))
+	}
+	if s.NewEstimator == nil || s.CV == nil || s.Metric == nil {
+		return fmt.Errorf("ml: sequential selection needs NewEstimator, CV and Metric")
+	}
+
+	keep := make(map[int]bool, len(s.Keep))
+	for _, j := range s.Keep {
+		if j < 0 || j >= n || keep[j] {
+			return fmt.Errorf("ml: keep column %d is out of %d columns or repeated", j, n)
+		}
+		keep[j] = true
+	}
+
+	// every candidate is scored on the same folds
+	split, err := s.CV.Split(len(features))
+	if err != nil {
+		return err
+	}
+	folds := fixedFolds(split)
+
+	var current []int
+	if s.Backward {
+		for j := 0; j < n; j++ {
+			current = append(current, j)
+		}
+	} else {
+		current = append(current, s.Keep...)
+		sort.Ints(current)
+	}
+
+	s.Path = nil
+	for len(current) != target {
+		var candidates [][]int
+		for j := 0; j < n; j++ {
+			if keep[j] || contains(current, j) != s.Backward {
+				continue
+			}
+			candidates = append(candidates, toggle(current, j))
+		}
+
+		scores, err := s.scoreAll(features, output, folds, candidates)
+		if err != nil {
+			return err
+		}
+
+		best := 0
+		for c := range scores {
+			if scores[c] > scores[best] {
+				best = c
+			}
+		}
+
+		current = candidates[best]
+		s.Path = append(s.Path, SelectionStep{
+			Columns: current,
+			Score:   scores[best],
+		})
+	}
+
+	s.Selected = current
+	s.Estimator = s.NewEstimator()
+
+	return s.Estimator.Fit(selectColumns(features, current), output)
+}
+
+// scoreAll returns mean cross-validated score of every
+// candidate column set, evaluated concurrently
+func (s *SequentialSelector) scoreAll(features [][]float64, output []float64, folds Splitter, candidates [][]int) ([]float64, error) {
+	workers := s.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	scores := make([]float64, len(candidates))
+	errs := make([]error, len(candidates))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				cv, err := CrossValidate(s.NewEstimator, selectColumns(features, candidates[c]), output, folds, s.Metric)
+				if err != nil {
+					errs[c] = err
+					continue
+				}
+				scores[c] = mean(cv)
+				if math.IsNaN(scores[c]) {
+					scores[c] = math.Inf(-1)
+				}
+			}
+		}()
+	}
+
+	for c := range candidates {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scores, nil
+}
+
+// Transform returns features keeping only selected columns
+func (s *SequentialSelector) Transform(features [][]float64) [][]float64 {
+	return selectColumns(features, s.Selected)
+}
+
+// Estimate returns estimate of X using selected columns
+func (s *SequentialSelector) Estimate(X []float64) float64 {
+	return s.Estimator.Estimate(selectColumns([][]float64{X}, s.Selected)[0])
+}
+
+// fixedFolds is a Splitter always returning the same folds
+type fixedFolds []Fold
+
+// Split returns the folds, n is ignored
+func (f fixedFolds) Split(n int) ([]Fold, error) {
+	return f, nil
+}
+
+func contains(cols []int, j int) bool {
+	for _, c := range cols {
+		if c == j {
+			return true
+		}
+	}
+	return false
+}
+
+// toggle returns sorted cols with j added or removed
+func toggle(cols []int, j int) []int {
+	out := make([]int, 0, len(cols)+1)
+	for _, c := range cols {
+		if c != j {
+			out = append(out, c)
+		}
+	}
+	if len(out) == len(cols) {
+		out = append(out, j)
+	}
+	sort.Ints(out)
+	return out
+}
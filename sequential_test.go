@@ -0,0 +1,58 @@
+package ml
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// relevantData returns rows of a bias column and 3 columns
+// of which output depends on columns 1 and 3 only
+func relevantData(n int) ([][]float64, []float64) {
+	r := rand.New(rand.NewSource(1))
+	features := make([][]float64, n)
+	output := make([]float64, n)
+	for i := range features {
+		features[i] = []float64{1, r.Float64(), r.Float64(), r.Float64()}
+		output[i] = 1 + 2*features[i][1] - 3*features[i][3]
+	}
+	return features, output
+}
+
+func TestSequentialSelector(t *testing.T) {
+	features, output := relevantData(60)
+	newEstimator := func() Estimator { return NewLinearRegression() }
+
+	for _, backward := range []bool{false, true} {
+		s := NewSequentialSelector(newEstimator, 3, R2)
+		s.Keep = []int{0}
+		s.Backward = backward
+		if err := s.Fit(features, output); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(s.Selected, []int{0, 1, 3}) {
+			t.Errorf("backward %v: selected %v, want [0 1 3]", backward, s.Selected)
+		}
+	}
+}
+
+func TestSequentialSelectorErrors(t *testing.T) {
+	features, output := relevantData(20)
+	newEstimator := func() Estimator { return NewLinearRegression() }
+
+	cases := map[string]func(s *SequentialSelector){
+		"nil CV":               func(s *SequentialSelector) { s.CV = nil },
+		"keep out of range":    func(s *SequentialSelector) { s.Keep = []int{4} },
+		"negative keep":        func(s *SequentialSelector) { s.Keep = []int{-1} },
+		"repeated keep":        func(s *SequentialSelector) { s.Keep = []int{0, 0} },
+		"more keep than wants": func(s *SequentialSelector) { s.Keep = []int{0, 1, 2} },
+		"too many folds":       func(s *SequentialSelector) { s.CV = KFold{K: 21} },
+	}
+	for name, change := range cases {
+		s := NewSequentialSelector(newEstimator, 2, R2)
+		change(s)
+		if err := s.Fit(features, output); err == nil {
+			t.Errorf("no error of %s", name)
+		}
+	}
+}
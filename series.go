@@ -0,0 +1,188 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Lag returns x shifted k steps forward, the first
+// k values are NaN
+func Lag(x []float64, k int) []float64 {
+	out := make([]float64, len(x))
+	for t := range out {
+		if t < k {
+			out[t] = math.NaN()
+		} else {
+			out[t] = x[t-k]
+		}
+	}
+	return out
+}
+
+// RollingMean returns mean of the w values before every
+// step, excluding the step itself, NaN without enough
+// history or when the window has a NaN
+func RollingMean(x []float64, w int) []float64 {
+	out := make([]float64, len(x))
+	for t := range out {
+		if t < w || w < 1 {
+			out[t] = math.NaN()
+			continue
+		}
+
+		// summed per window, so a NaN only
+		// spoils the windows holding it
+		m := 0.0
+		for _, v := range x[t-w : t] {
+			m += v
+		}
+		out[t] = m / float64(w)
+	}
+	return out
+}
+
+// RollingStd returns sample standard deviation of the w
+// values before every step, NaN without enough history
+func RollingStd(x []float64, w int) []float64 {
+	out := make([]float64, len(x))
+	for t := range out {
+		if t < w || w < 2 {
+			out[t] = math.NaN()
+			continue
+		}
+
+		m := 0.0
+		for _, v := range x[t-w : t] {
+			m += v
+		}
+		m /= float64(w)
+
+		ss := 0.0
+		for _, v := range x[t-w : t] {
+			ss += (v - m) * (v - m)
+		}
+		out[t] = math.Sqrt(ss / float64(w-1))
+	}
+	return out
+}
+
+// Difference returns x differenced order times,
+// the first order values are NaN
+func Difference(x []float64, order int) []float64 {
+	out := append([]float64(nil), x...)
+	for d := 0; d < order; d++ {
+		for t := len(out) - 1; t >= 0; t-- {
+			if t <= d {
+				out[t] = math.NaN()
+			} else {
+				out[t] -= out[t-1]
+			}
+		}
+	}
+	return out
+}
+
+// SeriesFeatures builds a supervised learning matrix from
+// a series with one row per time step and one column per
+// variable. Features of a step only use earlier steps.
+type SeriesFeatures struct {
+	// Lags adds the value k steps before of every column
+	Lags []int
+	// Windows adds rolling mean and standard
+	// deviation over w steps of every column
+	Windows []int
+	// Difference differences every column this many
+	// times first, so the output is differenced too
+	Difference int
+	// Bias prepends a column of ones
+	Bias bool
+}
+
+// Build returns features and output where output is column
+// target of the series. Steps without enough history are
+// dropped, it is an error when no step is left.
+func (s *SeriesFeatures) Build(series [][]float64, target int) ([][]float64, []float64, error) {
+	if len(series) == 0 {
+		return nil, nil, fmt.Errorf("ml: cannot build features of empty series")
+	}
+	if target < 0 || target >= len(series[0]) {
+		return nil, nil, fmt.Errorf("ml: target column %d out of range", target)
+	}
+	// a lag of 0 is the output itself
+	for _, k := range s.Lags {
+		if k < 1 {
+			return nil, nil, fmt.Errorf("ml: lags must be at least 1, got %d", k)
+		}
+	}
+	for _, w := range s.Windows {
+		if w < 2 {
+			return nil, nil, fmt.Errorf("ml: windows must be at least 2 steps, got %d", w)
+		}
+	}
+	if s.Difference < 0 {
+		return nil, nil, fmt.Errorf("ml: difference order must not be negative, got %d", s.Difference)
+	}
+
+	var cols [][]float64
+	for j := range series[0] {
+		cols = append(cols, Difference(column(series, j), s.Difference))
+	}
+
+	var generated [][]float64
+	for _, k := range s.Lags {
+		for _, c := range cols {
+			generated = append(generated, Lag(c, k))
+		}
+	}
+	for _, w := range s.Windows {
+		for _, c := range cols {
+			generated = append(generated, RollingMean(c, w), RollingStd(c, w))
+		}
+	}
+
+	var features [][]float64
+	var output []float64
+	for t := range series {
+		row := make([]float64, 0, len(generated)+1)
+		if s.Bias {
+			row = append(row, 1)
+		}
+
+		complete := !math.IsNaN(cols[target][t])
+		for _, g := range generated {
+			complete = complete && !math.IsNaN(g[t])
+			row = append(row, g[t])
+		}
+		if !complete {
+			continue
+		}
+
+		features = append(features, row)
+		output = append(output, cols[target][t])
+	}
+	if len(features) == 0 {
+		return nil, nil, fmt.Errorf("ml: no step of %d has enough history for the lags, windows and differencing", len(series))
+	}
+
+	return features, output, nil
+}
+
+// Names returns names of the built feature columns
+// given names of the series columns
+func (s *SeriesFeatures) Names(columns []string) []string {
+	var names []string
+	if s.Bias {
+		names = append(names, "bias")
+	}
+	for _, k := range s.Lags {
+		for _, c := range columns {
+			names = append(names, fmt.Sprintf("%s lag %d", c, k))
+		}
+	}
+	for _, w := range s.Windows {
+		for _, c := range columns {
+			names = append(names, fmt.Sprintf("%s mean %d", c, w), fmt.Sprintf("%s std %d", c, w))
+		}
+	}
+	return names
+}
@@ -0,0 +1,65 @@
+package ml
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRollingMeanSkipsEarlyNaN(t *testing.T) {
+	got := RollingMean([]float64{math.NaN(), 1, 2, 3, 4, 5}, 2)
+	want := []float64{math.NaN(), math.NaN(), math.NaN(), 1.5, 2.5, 3.5}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || !math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Fatalf("RollingMean = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSeriesFeaturesDifferenced(t *testing.T) {
+	series := make([][]float64, 30)
+	for i := range series {
+		series[i] = []float64{float64(i * i)}
+	}
+	plain := SeriesFeatures{Lags: []int{1}, Windows: []int{3}}
+	features, _, err := plain.Build(series, 0)
+	if err != nil || len(features) != 27 {
+		t.Fatalf("undifferenced: %d rows, %v", len(features), err)
+	}
+
+	diffed := SeriesFeatures{Lags: []int{1}, Windows: []int{3}, Difference: 1}
+	features, output, err := diffed.Build(series, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first difference is NaN, so one more step is lost
+	if len(features) != 26 {
+		t.Fatalf("differenced: got %d rows, want 26", len(features))
+	}
+	// differences of i² are 2i-1, so the first
+	// row at step 4 has output 7, lag 5 and mean 3
+	if output[0] != 7 || features[0][0] != 5 || features[0][1] != 3 {
+		t.Errorf("first row %v output %v", features[0], output[0])
+	}
+}
+
+func TestSeriesFeaturesTooShort(t *testing.T) {
+	s := SeriesFeatures{Lags: []int{5}}
+	if _, _, err := s.Build([][]float64{{1}, {2}, {3}}, 0); err == nil {
+		t.Error("expected an error when no row is left")
+	}
+}
+
+func TestSeriesFeaturesInvalid(t *testing.T) {
+	series := [][]float64{{1}, {2}, {3}, {4}, {5}}
+	cases := map[string]SeriesFeatures{
+		"lag of 0 leaking the output": {Lags: []int{1, 0}},
+		"negative lag":                {Lags: []int{-1}},
+		"window of 1":                 {Windows: []int{1}},
+		"negative difference":         {Lags: []int{1}, Difference: -1},
+	}
+	for name, s := range cases {
+		if _, _, err := s.Build(series, 0); err == nil {
+			t.Errorf("no error of %s", name)
+		}
+	}
+}
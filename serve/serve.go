@@ -0,0 +1,246 @@
+// Package serve exposes fitted estimators over HTTP with
+// JSON requests and responses. Service implements the
+// Prediction service of prediction.proto for gRPC servers.
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// DefaultMaxBodyBytes limits the size of request bodies
+const DefaultMaxBodyBytes = 10 << 20
+
+// Handler serves a fitted estimator:
+//
+//	POST /predict  {"features": [..]} or {"instances": [[..], ..]}
+//	GET  /healthz  {"status": "ok"}
+//
+// Requests may name their columns with "names", they are
+// then reordered to the feature names of the model.
+// Predictions are returned as {"prediction": v} or
+// {"predictions": [..]}. Classifiers return probabilities
+// and labels instead. Invalid input gets status 400 with
+// {"error": ".."}.
+type Handler struct {
+	Model ml.Estimator
+	// Features is the expected length of a feature
+	// vector, zero accepts any length
+	Features int
+	// Classifier responds with probability of true and a
+	// label of probability at least Threshold, or 0.5
+	// when Threshold is nil
+	Classifier bool
+	Threshold  *float64
+	// MaxBodyBytes defaults to DefaultMaxBodyBytes
+	MaxBodyBytes int64
+
+	once sync.Once
+	mux  *http.ServeMux
+}
+
+// PredictRequest is the body of /predict, with
+// either Features or Instances set. Names, when set,
+// names their columns.
+type PredictRequest struct {
+	Names     []string    `json:"names,omitempty"`
+	Features  []float64   `json:"features,omitempty"`
+	Instances [][]float64 `json:"instances,omitempty"`
+}
+
+// PredictResponse is the response of /predict
+type PredictResponse struct {
+	Prediction    *float64  `json:"prediction,omitempty"`
+	Predictions   []float64 `json:"predictions,omitempty"`
+	Probability   *float64  `json:"probability,omitempty"`
+	Probabilities []float64 `json:"probabilities,omitempty"`
+	Label         *bool     `json:"label,omitempty"`
+	Labels        []bool    `json:"labels,omitempty"`
+}
+
+// ErrorResponse is the body of failed requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns new pointer of Handler serving
+// model. Logistic regressions are served as classifiers
+// with their DecisionThreshold as threshold.
+func NewHandler(model ml.Estimator, features int) *Handler {
+	h := &Handler{
+		Model:    model,
+		Features: features,
+	}
+	if l, ok := model.(*ml.LogisticRegression); ok {
+		h.Classifier = true
+		threshold := l.DecisionThreshold()
+		h.Threshold = &threshold
+	}
+	return h
+}
+
+// ServeHTTP routes requests to /predict and /healthz
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		h.mux = http.NewServeMux()
+		h.mux.HandleFunc("/predict", h.predict)
+		h.mux.HandleFunc("/healthz", h.health)
+	})
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) predict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	limit := h.MaxBodyBytes
+	if limit == 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	var req PredictRequest
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON: %v", err))
+		return
+	}
+
+	single := req.Features != nil
+	rows := req.Instances
+	switch {
+	case single && rows != nil:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("set either features or instances, not both"))
+		return
+	case single:
+		rows = [][]float64{req.Features}
+	case len(rows) == 0:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no features or instances"))
+		return
+	}
+	if req.Names != nil {
+		var err error
+		if rows, err = ml.Reorder(h.Model, req.Names, rows); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	for i, x := range rows {
+		if err := h.validate(x); err != nil {
+			if !single {
+				err = fmt.Errorf("instance %d: %v", i, err)
+			}
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	estimates, err := h.estimate(r.Context(), rows)
+	if errors.Is(err, ErrInvalidArgument) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var resp PredictResponse
+	switch {
+	case h.Classifier:
+		threshold := h.threshold()
+		labels := make([]bool, len(estimates))
+		for i, p := range estimates {
+			labels[i] = p >= threshold
+		}
+		if single {
+			resp.Probability, resp.Label = &estimates[0], &labels[0]
+		} else {
+			resp.Probabilities, resp.Labels = estimates, labels
+		}
+	case single:
+		resp.Prediction = &estimates[0]
+	default:
+		resp.Predictions = estimates
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// threshold returns Threshold, or 0.5 when unset
+func (h *Handler) threshold() float64 {
+	if h.Threshold == nil {
+		return 0.5
+	}
+	return *h.Threshold
+}
+
+func (h *Handler) validate(x []float64) error {
+	if len(x) == 0 {
+		return fmt.Errorf("empty feature vector")
+	}
+	if h.Features > 0 && len(x) != h.Features {
+		return fmt.Errorf("got %d features, expected %d", len(x), h.Features)
+	}
+	return nil
+}
+
+// estimate returns estimates of rows in an ml.Predict
+// span, turning panics of the model into errors. Rows
+// a BatchEstimator rejects are ErrInvalidArgument.
+func (h *Handler) estimate(ctx context.Context, rows [][]float64) (estimates []float64, err error) {
+	_, span := ml.StartSpan(ctx, "ml.Predict", "rows", len(rows), "model", fmt.Sprintf("%T", h.Model))
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("model failed: %v", r)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if b, ok := h.Model.(ml.BatchEstimator); ok {
+		if estimates, err = b.PredictBatch(rows); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+		}
+	} else {
+		estimates = make([]float64, len(rows))
+		for i, x := range rows {
+			estimates[i] = h.Model.Estimate(x)
+		}
+	}
+	for _, v := range estimates {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("model returned %v", v)
+		}
+	}
+	return estimates, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
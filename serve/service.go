@@ -0,0 +1,197 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var (
+	// ErrInvalidArgument wraps errors of invalid requests,
+	// a gRPC server maps it to status InvalidArgument
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNotFound wraps errors of unknown models,
+	// a gRPC server maps it to status NotFound
+	ErrNotFound = errors.New("model not found")
+)
+
+// Instance is a feature vector
+type Instance struct {
+	Features []float64
+}
+
+// PredictionRequest asks estimates of Instances from
+// Model, which may be empty when a single model is served
+type PredictionRequest struct {
+	Model     string
+	Instances []Instance
+}
+
+// PredictionResponse holds estimates of a request,
+// Labels are set for classifiers only
+type PredictionResponse struct {
+	Model     string
+	Estimates []float64
+	Labels    []bool
+}
+
+// MetadataRequest asks metadata of Model
+type MetadataRequest struct {
+	Model string
+}
+
+// ModelMetadata describes a served model
+type ModelMetadata struct {
+	Name       string
+	Type       string
+	Features   int32
+	Classifier bool
+	Threshold  float64
+}
+
+// ListModelsRequest asks metadata of every model
+type ListModelsRequest struct{}
+
+// ListModelsResponse holds metadata of every model
+type ListModelsResponse struct {
+	Models []*ModelMetadata
+}
+
+// PredictionStream is the server side of PredictStream,
+// the stream of stubs generated from prediction.proto
+// implements it once its messages are converted
+type PredictionStream interface {
+	Context() context.Context
+	Recv() (*PredictionRequest, error)
+	Send(*PredictionResponse) error
+}
+
+// Service implements the Prediction service of
+// prediction.proto without depending on a transport.
+// Serve it over gRPC by registering it with stubs
+// generated by protoc-gen-go-grpc, converting messages.
+// Models are configured like Handler and looked up by
+// name. It is safe for concurrent use once built.
+type Service struct {
+	Models map[string]*Handler
+}
+
+// NewService returns new pointer of Service
+// serving handler models by name
+func NewService(models map[string]*Handler) *Service {
+	return &Service{Models: models}
+}
+
+// model returns the handler of name, or the only
+// handler when name is empty
+func (s *Service) model(name string) (string, *Handler, error) {
+	if name == "" && len(s.Models) == 1 {
+		for n, h := range s.Models {
+			return n, h, nil
+		}
+	}
+	h, ok := s.Models[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return name, h, nil
+}
+
+// Predict estimates every instance of req
+func (s *Service) Predict(ctx context.Context, req *PredictionRequest) (*PredictionResponse, error) {
+	name, h, err := s.model(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Instances) == 0 {
+		return nil, fmt.Errorf("%w: no instances", ErrInvalidArgument)
+	}
+
+	rows := make([][]float64, len(req.Instances))
+	for i, in := range req.Instances {
+		if err := h.validate(in.Features); err != nil {
+			return nil, fmt.Errorf("%w: instance %d: %v", ErrInvalidArgument, i, err)
+		}
+		rows[i] = in.Features
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	estimates, err := h.estimate(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PredictionResponse{Model: name, Estimates: estimates}
+	if h.Classifier {
+		threshold := h.threshold()
+		resp.Labels = make([]bool, len(estimates))
+		for i, p := range estimates {
+			resp.Labels[i] = p >= threshold
+		}
+	}
+	return resp, nil
+}
+
+// PredictStream answers every request of stream in order
+// until the client closes it. Failed requests end the
+// stream with their error.
+func (s *Service) PredictStream(stream PredictionStream) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.Predict(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// GetMetadata describes the model of req
+func (s *Service) GetMetadata(ctx context.Context, req *MetadataRequest) (*ModelMetadata, error) {
+	name, h, err := s.model(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	return metadata(name, h), nil
+}
+
+// ListModels describes every model sorted by name
+func (s *Service) ListModels(ctx context.Context, req *ListModelsRequest) (*ListModelsResponse, error) {
+	names := make([]string, 0, len(s.Models))
+	for name := range s.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &ListModelsResponse{}
+	for _, name := range names {
+		resp.Models = append(resp.Models, metadata(name, s.Models[name]))
+	}
+	return resp, nil
+}
+
+func metadata(name string, h *Handler) *ModelMetadata {
+	m := &ModelMetadata{
+		Name:       name,
+		Type:       fmt.Sprintf("%T", h.Model),
+		Features:   int32(h.Features),
+		Classifier: h.Classifier,
+	}
+	if h.Classifier {
+		m.Threshold = h.threshold()
+	}
+	return m
+}
@@ -0,0 +1,135 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func testService() *Service {
+	linear := ml.NewLinearRegression()
+	linear.Theta = []float64{1, 2}
+	logistic := ml.NewLogisticRegression()
+	logistic.Theta = []float64{0, 1}
+
+	return NewService(map[string]*Handler{
+		"linear":   NewHandler(linear, 2),
+		"logistic": NewHandler(logistic, 2),
+	})
+}
+
+func TestServicePredict(t *testing.T) {
+	req := &PredictionRequest{Model: "logistic", Instances: []Instance{{[]float64{1, 2}}, {[]float64{1, -2}}}}
+	resp, err := testService().Predict(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 1 / (1 + math.Exp(-2))
+	if resp.Model != "logistic" || len(resp.Estimates) != 2 || math.Abs(resp.Estimates[0]-want) > 1e-12 {
+		t.Errorf("got %+v", resp)
+	}
+	if !reflect.DeepEqual(resp.Labels, []bool{true, false}) {
+		t.Errorf("labels %v", resp.Labels)
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	s := testService()
+	cases := []struct {
+		req  *PredictionRequest
+		want error
+	}{
+		{&PredictionRequest{Model: "missing", Instances: []Instance{{[]float64{1, 2}}}}, ErrNotFound},
+		{&PredictionRequest{Model: "linear", Instances: []Instance{{[]float64{1}}}}, ErrInvalidArgument},
+		{&PredictionRequest{Model: "linear"}, ErrInvalidArgument},
+	}
+	for _, c := range cases {
+		if _, err := s.Predict(context.Background(), c.req); !errors.Is(err, c.want) {
+			t.Errorf("%+v: error %v, want %v", c.req, err, c.want)
+		}
+	}
+}
+
+func TestServiceMetadata(t *testing.T) {
+	list, err := testService().ListModels(context.Background(), &ListModelsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*ModelMetadata{
+		{Name: "linear", Type: "*ml.LinearRegression", Features: 2},
+		{Name: "logistic", Type: "*ml.LogisticRegression", Features: 2, Classifier: true, Threshold: 0.5},
+	}
+	if !reflect.DeepEqual(list.Models, want) {
+		t.Errorf("got %+v", list.Models)
+	}
+}
+
+// sliceStream is a PredictionStream of requests
+type sliceStream struct {
+	requests  []*PredictionRequest
+	responses []*PredictionResponse
+}
+
+func (s *sliceStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *sliceStream) Recv() (*PredictionRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *sliceStream) Send(resp *PredictionResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestServicePredictStream(t *testing.T) {
+	stream := &sliceStream{}
+	for _, x := range []float64{1, 2, 3} {
+		stream.requests = append(stream.requests, &PredictionRequest{Model: "linear", Instances: []Instance{{[]float64{1, x}}}})
+	}
+	if err := testService().PredictStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.responses) != 3 {
+		t.Fatalf("%d responses, want 3", len(stream.responses))
+	}
+	for i, resp := range stream.responses {
+		if want := 1 + 2*float64(i+1); len(resp.Estimates) != 1 || resp.Estimates[0] != want {
+			t.Errorf("response %d: %v, want %v", i, resp.Estimates, want)
+		}
+	}
+}
+
+func TestPredictWrongLength(t *testing.T) {
+	linear := ml.NewLinearRegression()
+	linear.Theta = []float64{1, 2}
+	h := NewHandler(linear, 0)
+
+	// a handler of any length leaves the check to
+	// the model, which rejects the row
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"features": [1, 2, 3]}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+
+	s := NewService(map[string]*Handler{"linear": h})
+	req := &PredictionRequest{Instances: []Instance{{[]float64{1, 2, 3}}}}
+	if _, err := s.Predict(context.Background(), req); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("error %v, want %v", err, ErrInvalidArgument)
+	}
+}
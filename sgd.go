@@ -0,0 +1,383 @@
+package ml
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// RowSource is a dataset read in chunks of rows,
+// such as datasets.Mapped
+type RowSource interface {
+	// Len returns number of rows
+	Len() int
+	// Width returns number of features
+	Width() int
+	// Rows returns features and output of rows start
+	// to end, excluding end, in slices the caller
+	// may reorder
+	Rows(start, end int) ([][]float64, []float64)
+}
+
+// Slices is a RowSource of features and output in memory
+type Slices struct {
+	Features [][]float64
+	Output   []float64
+}
+
+// Len returns number of rows
+func (s Slices) Len() int {
+	return len(s.Features)
+}
+
+// Width returns number of features
+func (s Slices) Width() int {
+	if len(s.Features) == 0 {
+		return 0
+	}
+	return len(s.Features[0])
+}
+
+// Rows returns copies of slices of rows start to end,
+// sharing the rows
+func (s Slices) Rows(start, end int) ([][]float64, []float64) {
+	return append([][]float64(nil), s.Features[start:end]...), append([]float64(nil), s.Output[start:end]...)
+}
+
+// SGDModel is a model trained by SGD,
+// LinearRegression or LogisticRegression
+type SGDModel interface {
+	LinearModel
+	Func(theta []float64) float64
+	Grad(grad, theta []float64)
+	linear() *Linear
+}
+
+func (l *Linear) linear() *Linear {
+	return l
+}
+
+// SGD trains a linear model by mini-batch stochastic
+// gradient descent, reading one chunk of rows at a time
+// so the data can be larger than RAM. Every epoch visits
+// chunks in random order and shuffles rows within them.
+//
+// It honours Checkpoint, CheckpointEvery (in epochs),
+// ResumeFrom, WarmStart, Setting.MaxDuration,
+// Setting.Schedule, Setting.ClipNorm, Setting.Constraints,
+// Logger and Metrics of the model. Training stops with a DivergenceError
+// when the loss becomes NaN, infinite or explodes.
+type SGD struct {
+	Model SGDModel
+	// Epochs is number of passes over the data
+	Epochs int
+	// ChunkSize is rows read at once, defaults to 10000
+	ChunkSize int
+	// BatchSize is rows of every step, defaults to 32
+	BatchSize int
+	// Step is the step size of every update
+	Step float64
+	// Rand shuffles chunks and rows,
+	// nil uses the global source
+	Rand *rand.Rand
+	// Theta, when set, are the starting thetas
+	// instead of those chosen by the model
+	Theta []float64
+	// Privacy, when set, trains with DP-SGD
+	Privacy *Privacy
+	// Adam, when set, adapts steps of every theta
+	Adam *Adam
+
+	// Losses is the running mean loss of every epoch
+	Losses []float64
+	// Accountant holds privacy spent with Privacy
+	Accountant Accountant
+}
+
+// NewSGD returns new pointer of SGD training model
+// for 10 epochs with step size 0.01
+func NewSGD(model SGDModel) *SGD {
+	return &SGD{
+		Model:     model,
+		Epochs:    10,
+		ChunkSize: 10000,
+		BatchSize: 32,
+		Step:      0.01,
+	}
+}
+
+// Fit trains the model on source
+func (s *SGD) Fit(source RowSource) error {
+	return s.FitContext(context.Background(), source)
+}
+
+// FitContext is Fit with a context, training stops
+// with the context error when ctx is done
+func (s *SGD) FitContext(ctx context.Context, source RowSource) (err error) {
+	l := s.Model.linear()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.optionErr != nil {
+		return l.optionErr
+	}
+	// the mean loss of batches is no likelihood
+	l.samples = 0
+	rows, width := source.Len(), source.Width()
+	if rows == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+	if err := checkNames(l.FeatureNames, width); err != nil {
+		return err
+	}
+
+	ctx, span := StartSpan(ctx, "ml.SGD", "rows", rows, "features", width, "epochs", s.Epochs)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if p := s.Privacy; p != nil && (p.Clip <= 0 || p.NoiseMultiplier <= 0 || p.Delta <= 0) {
+		return fmt.Errorf("ml: Privacy needs positive Clip, NoiseMultiplier and Delta")
+	}
+
+	chunkSize, batchSize := s.ChunkSize, s.BatchSize
+	if chunkSize <= 0 {
+		chunkSize = 10000
+	}
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+	r := RandOf(s.Rand)
+
+	offset := 0
+	if l.resume != nil {
+		offset = l.resume.Iteration
+	}
+	// resume is the checkpoint thetas continue from
+	var resume *Checkpoint
+	if s.Theta != nil {
+		if len(s.Theta) != width {
+			return fmt.Errorf("ml: got %d thetas for %d features", len(s.Theta), width)
+		}
+		l.Theta = append([]float64(nil), s.Theta...)
+	} else {
+		l.initTheta(width)
+		resume = l.resume
+	}
+	l.resume = nil
+	l.features32, l.output32 = nil, nil
+	defer func() { l.Features, l.Output = nil, nil }()
+
+	var enc *gob.Encoder
+	if l.Checkpoint != nil {
+		enc = gob.NewEncoder(l.Checkpoint)
+	}
+	var (
+		deadline    time.Time
+		schedule    Schedule
+		clip        float64
+		constraints *Constraints
+	)
+	if l.Setting != nil {
+		if l.Setting.MaxDuration > 0 {
+			deadline = time.Now().Add(l.Setting.MaxDuration)
+		}
+		schedule, clip = l.Setting.Schedule, l.Setting.ClipNorm
+		if constraints = l.Setting.Constraints; constraints != nil {
+			if err = constraints.check(width); err != nil {
+				return err
+			}
+			constraints.project(l.Theta)
+		}
+	}
+	ratio := divergenceRatio(l.Setting)
+	if s.Adam != nil {
+		s.Adam.start(width, resume, l.WarmStart && resume == nil && s.Theta == nil)
+	}
+
+	log := l.logger()
+	log.Debug("ml: SGD started", "rows", rows, "features", width, "epochs", s.Epochs)
+
+	conv := newConvergence()
+	l.convergence = conv
+	began, reason := time.Now(), "epochs done"
+	defer func() {
+		conv.Runtime = time.Since(began)
+		if err != nil {
+			reason = err.Error()
+		}
+		conv.StopReason = reason
+		conv.Losses = append([]float64(nil), s.Losses...)
+		if n := len(s.Losses); n > 0 {
+			conv.Loss = s.Losses[n-1]
+			conv.Converged = err == nil && n > 1 && math.Abs(s.Losses[n-2]-s.Losses[n-1]) <= 1e-3*math.Abs(s.Losses[n-2])
+		}
+		conv.Exhausted = err == nil && !conv.Converged && conv.Iterations == s.Epochs
+		if warning := conv.Warning(); warning != "" && err == nil {
+			log.Warn(warning)
+		}
+	}()
+
+	theta := l.Theta
+	grad := make([]float64, width)
+	// last are thetas of the last finite batch loss
+	last := append([]float64(nil), theta...)
+	first := math.NaN()
+	chunks := (rows + chunkSize - 1) / chunkSize
+	s.Losses = s.Losses[:0]
+
+	for epoch := 1; epoch <= s.Epochs; epoch++ {
+		total, done := 0.0, 0
+		for _, c := range r.Perm(chunks) {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				log.Warn("ml: SGD ran out of time, keeping thetas", "epoch", offset+epoch)
+				reason = optimize.RuntimeLimit.String()
+				return nil
+			}
+
+			features, output := source.Rows(c*chunkSize, min(rows, (c+1)*chunkSize))
+			r.Shuffle(len(features), func(i, j int) {
+				features[i], features[j] = features[j], features[i]
+				output[i], output[j] = output[j], output[i]
+			})
+
+			for start := 0; start < len(features); start += batchSize {
+				end := min(len(features), start+batchSize)
+				l.Features, l.Output = features[start:end], output[start:end]
+
+				f := s.Model.Func(theta)
+				if math.IsNaN(first) {
+					first = math.Max(f, 1)
+				}
+				if math.IsNaN(f) || math.IsInf(f, 0) || f > first*ratio {
+					copy(theta, last)
+					err = &DivergenceError{Iteration: offset + epoch, Loss: f, Theta: append([]float64(nil), last...)}
+					log.Error("ml: SGD diverged", "epoch", offset+epoch, "loss", f)
+					return err
+				}
+				copy(last, theta)
+				total += f * float64(end-start)
+				if s.Privacy != nil {
+					s.privateGrad(grad, theta, r)
+					s.Accountant.Step(float64(end-start)/float64(rows), s.Privacy.NoiseMultiplier, 1)
+				} else {
+					s.Model.Grad(grad, theta)
+				}
+				clipGradient(grad, clip)
+				conv.Evaluations++
+				conv.GradientNorm = floats.Norm(grad, 2)
+
+				step := s.Step
+				if schedule != nil {
+					step *= schedule.Factor(float64(offset+epoch-1) + float64(done)/float64(rows))
+				}
+				if s.Adam != nil {
+					s.Adam.update(theta, grad, step)
+				} else {
+					floats.AddScaled(theta, -step, grad)
+				}
+				if constraints != nil {
+					constraints.project(theta)
+				}
+				done += end - start
+			}
+		}
+
+		loss := total / float64(rows)
+		s.Losses = append(s.Losses, loss)
+		conv.Iterations++
+		if s.Privacy != nil {
+			log.Info("ml: SGD epoch finished", "epoch", offset+epoch, "loss", loss, "epsilon", s.Epsilon())
+		} else {
+			log.Info("ml: SGD epoch finished", "epoch", offset+epoch, "loss", loss)
+		}
+		if l.Metrics != nil {
+			l.Metrics.Add(MetricTrainingIterations, 1, l.metricLabels)
+			l.Metrics.Set(MetricTrainingLoss, loss, l.metricLabels)
+		}
+
+		every := l.CheckpointEvery
+		if every <= 0 {
+			every = 1
+		}
+		if enc != nil && epoch%every == 0 {
+			c := Checkpoint{Iteration: offset + epoch, Theta: append([]float64(nil), theta...), Loss: loss}
+			if s.Adam != nil {
+				c.Adam = s.Adam.state()
+			}
+			if err = enc.Encode(&c); err != nil {
+				return fmt.Errorf("ml: checkpoint: %v", err)
+			}
+		}
+	}
+
+	if len(s.Losses) > 0 {
+		span.SetAttributes("loss", s.Losses[len(s.Losses)-1])
+	}
+	return nil
+}
+
+// Epsilon returns ε spent so far with Privacy
+// at its Delta
+func (s *SGD) Epsilon() float64 {
+	if s.Privacy == nil {
+		return math.Inf(1)
+	}
+	return s.Accountant.Epsilon(s.Privacy.Delta)
+}
+
+// Gradient returns the mean gradient and loss of model
+// at theta over all rows of source, read in chunks of
+// chunkSize rows. It holds the model for writing, so it
+// waits for a running Fit and leaves its rows in place.
+func Gradient(model SGDModel, source RowSource, theta []float64, chunkSize int) ([]float64, float64) {
+	l := model.linear()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if chunkSize <= 0 {
+		chunkSize = 10000
+	}
+	features, output := l.Features, l.Output
+	features32, output32 := l.features32, l.output32
+	l.features32, l.output32 = nil, nil
+	defer func() {
+		l.Features, l.Output = features, output
+		l.features32, l.output32 = features32, output32
+	}()
+
+	rows := source.Len()
+	grad := make([]float64, len(theta))
+	chunk := make([]float64, len(theta))
+	loss := 0.0
+	for start := 0; start < rows; start += chunkSize {
+		end := min(rows, start+chunkSize)
+		l.Features, l.Output = source.Rows(start, end)
+
+		w := float64(end-start) / float64(rows)
+		loss += w * model.Func(theta)
+		model.Grad(chunk, theta)
+		floats.AddScaled(grad, w, chunk)
+	}
+	return grad, loss
+}
+
+// GobEncode encodes the model without Rand
+func (s *SGD) GobEncode() ([]byte, error) {
+	return GobEncodeModel(s)
+}
+
+// GobDecode decodes a model of GobEncode
+func (s *SGD) GobDecode(data []byte) error {
+	return GobDecodeModel(s, data)
+}
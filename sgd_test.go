@@ -0,0 +1,42 @@
+package ml
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestGradientConcurrent runs under go test -race
+func TestGradientConcurrent(t *testing.T) {
+	features, output := lineData(50, 2)
+	model := NewLinearRegression()
+	if err := model.Fit(features, output); err != nil {
+		t.Fatal(err)
+	}
+	theta := append([]float64(nil), model.Theta...)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				switch g {
+				case 0:
+					Gradient(model, Slices{features, output}, theta, 16)
+				case 1:
+					if err := model.Fit(features, output); err != nil {
+						t.Error(err)
+					}
+				default:
+					model.Estimate(features[i])
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// the rows of Fit are left in place
+	if len(model.Features) != len(features) {
+		t.Errorf("model holds %d rows after Gradient, want %d", len(model.Features), len(features))
+	}
+}
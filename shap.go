@@ -0,0 +1,232 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// KernelSHAP approximates Shapley values of any fitted
+// estimator. Features missing from a coalition are
+// replaced by rows of Background and the model output
+// is averaged over them. The model must be safe for
+// concurrent Estimate calls.
+type KernelSHAP struct {
+	Model Estimator
+	// Background rows stand in for missing features,
+	// a few dozen representative rows is usually enough
+	Background [][]float64
+	// Samples is the number of coalitions evaluated,
+	// zero uses 2048. All coalitions are enumerated
+	// when there are fewer than Samples of them.
+	Samples int
+	// Workers evaluating coalitions concurrently,
+	// defaults to GOMAXPROCS
+	Workers int
+	// Rand samples coalitions, nil uses the global
+	// source. A set Rand makes Explain unsafe for
+	// concurrent use.
+	Rand *rand.Rand
+}
+
+// NewKernelSHAP returns new pointer of KernelSHAP
+// evaluating up to 2048 coalitions
+func NewKernelSHAP(model Estimator, background [][]float64) *KernelSHAP {
+	return &KernelSHAP{
+		Model:      model,
+		Background: background,
+		Samples:    2048,
+	}
+}
+
+// BaseValue returns the mean estimate over the
+// background, the value explained away by Explain
+func (k *KernelSHAP) BaseValue() float64 {
+	return mean(estimateAll(k.Model, k.Background))
+}
+
+// Explain returns Shapley value of every column of X.
+// They sum up to Estimate(X) minus BaseValue.
+func (k *KernelSHAP) Explain(X []float64) ([]float64, error) {
+	if len(k.Background) == 0 {
+		return nil, fmt.Errorf("ml: KernelSHAP requires background rows")
+	}
+	if k.Samples < 0 {
+		return nil, fmt.Errorf("ml: KernelSHAP samples must not be negative, got %d", k.Samples)
+	}
+
+	m := len(X)
+	for i, b := range k.Background {
+		if len(b) != m {
+			return nil, fmt.Errorf("ml: background row %d has %d columns, X has %d", i, len(b), m)
+		}
+	}
+	base := k.BaseValue()
+	full := k.Model.Estimate(X)
+	if m == 1 {
+		return []float64{full - base}, nil
+	}
+
+	coalitions, weights := k.coalitions(m)
+	values := k.evaluate(X, coalitions)
+
+	// eliminate the last column through the constraint
+	// that values sum up to full-base, then solve the
+	// weighted least squares problem for the others
+	a := mat.NewDense(len(coalitions), m-1, nil)
+	b := mat.NewVecDense(len(coalitions), nil)
+	for c, coalition := range coalitions {
+		w := math.Sqrt(weights[c])
+		last := 0.0
+		if coalition[m-1] {
+			last = 1
+		}
+
+		for j := 0; j < m-1; j++ {
+			v := -last
+			if coalition[j] {
+				v++
+			}
+			a.Set(c, j, w*v)
+		}
+		b.SetVec(c, w*(values[c]-base-last*(full-base)))
+	}
+
+	var phi mat.VecDense
+	if err := phi.SolveVec(a, b); err != nil {
+		return nil, fmt.Errorf("ml: cannot solve KernelSHAP weights: %v", err)
+	}
+
+	shap := make([]float64, m)
+	shap[m-1] = full - base
+	for j := 0; j < m-1; j++ {
+		shap[j] = phi.AtVec(j)
+		shap[m-1] -= shap[j]
+	}
+
+	return shap, nil
+}
+
+// coalitions returns coalitions of m columns with their
+// Shapley kernel weights, enumerating all of them when
+// possible and sampling from the kernel otherwise
+func (k *KernelSHAP) coalitions(m int) ([][]bool, []float64) {
+	var coalitions [][]bool
+	var weights []float64
+
+	samples := k.Samples
+	if samples == 0 {
+		samples = 2048
+	}
+
+	if m < 31 && (1<<uint(m))-2 <= samples {
+		for mask := 1; mask < (1<<uint(m))-1; mask++ {
+			coalition := make([]bool, m)
+			size := 0
+			for j := range coalition {
+				if mask&(1<<uint(j)) != 0 {
+					coalition[j] = true
+					size++
+				}
+			}
+			coalitions = append(coalitions, coalition)
+			weights = append(weights, shapleyKernel(m, size))
+		}
+		return coalitions, weights
+	}
+
+	// sizes are drawn proportionally to their total kernel
+	// weight, so every sampled coalition weighs the same
+	sizeWeights := make([]float64, m)
+	total := 0.0
+	for s := 1; s < m; s++ {
+		sizeWeights[s] = float64(m-1) / float64(s*(m-s))
+		total += sizeWeights[s]
+	}
+
+	r := RandOf(k.Rand)
+	for len(coalitions) < samples {
+		u := r.Float64() * total
+		size := 1
+		for ; size < m-1 && u > sizeWeights[size]; size++ {
+			u -= sizeWeights[size]
+		}
+
+		coalition := make([]bool, m)
+		for _, j := range r.Perm(m)[:size] {
+			coalition[j] = true
+		}
+
+		// pair every coalition with its complement
+		complement := make([]bool, m)
+		for j := range coalition {
+			complement[j] = !coalition[j]
+		}
+
+		coalitions = append(coalitions, coalition, complement)
+		weights = append(weights, 1, 1)
+	}
+
+	return coalitions, weights
+}
+
+// evaluate returns mean estimate over the background of
+// X with columns outside every coalition replaced
+func (k *KernelSHAP) evaluate(X []float64, coalitions [][]bool) []float64 {
+	workers := k.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	values := make([]float64, len(coalitions))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			z := make([]float64, len(X))
+			for c := range jobs {
+				sum := 0.0
+				for _, b := range k.Background {
+					for j, in := range coalitions[c] {
+						if in {
+							z[j] = X[j]
+						} else {
+							z[j] = b[j]
+						}
+					}
+					sum += k.Model.Estimate(z)
+				}
+				values[c] = sum / float64(len(k.Background))
+			}
+		}()
+	}
+
+	for c := range coalitions {
+		jobs <- c
+	}
+	close(jobs)
+	wg.Wait()
+
+	return values
+}
+
+// shapleyKernel returns weight of a coalition of
+// size s out of m columns
+func shapleyKernel(m, s int) float64 {
+	return float64(m-1) / (binomial(m, s) * float64(s*(m-s)))
+}
+
+func binomial(n, k int) float64 {
+	b := 1.0
+	for i := 1; i <= k; i++ {
+		b *= float64(n-k+i) / float64(i)
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+package ml
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// funcModel estimates with a fixed func
+type funcModel func(X []float64) float64
+
+func (f funcModel) Fit(features [][]float64, output []float64) error { return nil }
+func (f funcModel) Estimate(X []float64) float64                     { return f(X) }
+
+func TestKernelSHAPExact(t *testing.T) {
+	// x0·x1 is shared evenly by both columns and x2
+	// is its own, against a background of zeros
+	model := funcModel(func(X []float64) float64 { return X[0]*X[1] + X[2] })
+	k := NewKernelSHAP(model, [][]float64{{0, 0, 0}})
+	k.Samples = 0
+
+	shap, err := k.Explain([]float64{2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{3, 3, 4}
+	for j := range want {
+		if math.Abs(shap[j]-want[j]) > 1e-9 {
+			t.Fatalf("shapley values %v, want %v", shap, want)
+		}
+	}
+}
+
+func TestKernelSHAPAdditive(t *testing.T) {
+	// 12 columns have more coalitions than samples
+	model := funcModel(func(X []float64) float64 {
+		f := 0.0
+		for j, x := range X {
+			f += float64(j) * x * x
+		}
+		return math.Sin(f)
+	})
+	r := rand.New(rand.NewSource(1))
+	background := make([][]float64, 5)
+	for i := range background {
+		background[i] = make([]float64, 12)
+		for j := range background[i] {
+			background[i][j] = r.Float64()
+		}
+	}
+	X := make([]float64, 12)
+	for j := range X {
+		X[j] = r.Float64()
+	}
+
+	k := NewKernelSHAP(model, background)
+	k.Samples = 100
+	k.Rand = r
+	shap, err := k.Explain(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := k.BaseValue()
+	for _, v := range shap {
+		sum += v
+	}
+	if want := model.Estimate(X); math.Abs(sum-want) > 1e-9 {
+		t.Errorf("base value and contributions add up to %v, want estimate %v", sum, want)
+	}
+
+	k.Samples = -1
+	if _, err = k.Explain(X); err == nil {
+		t.Error("no error of negative samples")
+	}
+	k.Samples = 100
+	k.Background = [][]float64{{1, 2}}
+	if _, err = k.Explain(X); err == nil {
+		t.Error("no error of background rows of other width")
+	}
+}
@@ -0,0 +1,151 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SklearnModel is the JSON schema read by ImportSklearn,
+// written from Python with e.g.
+//
+//	json.dump({
+//	    "model": type(clf).__name__,
+//	    "coef": clf.coef_.tolist(),
+//	    "intercept": clf.intercept_.tolist(),
+//	    "classes": clf.classes_.tolist(),
+//	    "scaler": {"mean": sc.mean_.tolist(), "scale": sc.scale_.tolist()},
+//	}, f)
+//
+// Coef and Intercept may be scalars, vectors or single
+// row matrices. Classes and Scaler are optional.
+type SklearnModel struct {
+	Model     string          `json:"model"`
+	Coef      json.RawMessage `json:"coef"`
+	Intercept json.RawMessage `json:"intercept"`
+	Classes   []interface{}   `json:"classes,omitempty"`
+	Scaler    *SklearnScaler  `json:"scaler,omitempty"`
+}
+
+// SklearnScaler holds StandardScaler parameters, a nil
+// Mean or Scale stands for with_mean or with_std false
+type SklearnScaler struct {
+	Mean  []float64 `json:"mean"`
+	Scale []float64 `json:"scale"`
+}
+
+// sklearnRegressors and sklearnClassifiers are the linear
+// models of scikit-learn ImportSklearn accepts
+var (
+	sklearnRegressors = map[string]bool{
+		"LinearRegression": true,
+		"Ridge":            true,
+		"RidgeCV":          true,
+		"Lasso":            true,
+		"LassoCV":          true,
+		"ElasticNet":       true,
+		"ElasticNetCV":     true,
+		"SGDRegressor":     true,
+		"HuberRegressor":   true,
+	}
+	sklearnClassifiers = map[string]bool{
+		"LogisticRegression":   true,
+		"LogisticRegressionCV": true,
+		"SGDClassifier":        true,
+	}
+)
+
+// ImportSklearn reads a scikit-learn linear model in the
+// SklearnModel schema, returning a pipeline of an optional
+// StandardScaler, a BiasTransformer carrying the intercept
+// and a LinearRegression or LogisticRegression. The
+// pipeline takes rows of the features the model was
+// trained on. Classifiers must be binary, they estimate
+// probability of the second class.
+func ImportSklearn(r io.Reader) (*Pipeline, error) {
+	var m SklearnModel
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("ml: reading scikit-learn model: %v", err)
+	}
+
+	coef, err := sklearnVector(m.Coef)
+	if err != nil {
+		return nil, fmt.Errorf("ml: scikit-learn coef: %v", err)
+	}
+	if len(coef) == 0 {
+		return nil, fmt.Errorf("ml: scikit-learn model has no coef")
+	}
+	intercept := []float64{0}
+	if len(m.Intercept) > 0 {
+		if intercept, err = sklearnVector(m.Intercept); err != nil {
+			return nil, fmt.Errorf("ml: scikit-learn intercept: %v", err)
+		}
+	}
+	if len(intercept) != 1 {
+		return nil, fmt.Errorf("ml: scikit-learn model has %d intercepts, only single output models are supported", len(intercept))
+	}
+	theta := append(intercept, coef...)
+
+	var model Estimator
+	switch {
+	case sklearnRegressors[m.Model]:
+		l := NewLinearRegression()
+		l.Theta = theta
+		model = l
+	case sklearnClassifiers[m.Model]:
+		if m.Classes != nil && len(m.Classes) != 2 {
+			return nil, fmt.Errorf("ml: scikit-learn classifier has %d classes, only binary is supported", len(m.Classes))
+		}
+		l := NewLogisticRegression()
+		l.Theta = theta
+		model = l
+	default:
+		return nil, fmt.Errorf("ml: scikit-learn model %q is not supported", m.Model)
+	}
+
+	steps := []Transformer{NewBiasTransformer()}
+	if s := m.Scaler; s != nil {
+		scaler := &StandardScaler{
+			Mean:  s.Mean,
+			Scale: s.Scale,
+		}
+		if scaler.Mean == nil {
+			scaler.Mean = make([]float64, len(coef))
+		}
+		if scaler.Scale == nil {
+			scaler.Scale = make([]float64, len(coef))
+			for j := range scaler.Scale {
+				scaler.Scale[j] = 1
+			}
+		}
+		if len(scaler.Mean) != len(coef) || len(scaler.Scale) != len(coef) {
+			return nil, fmt.Errorf("ml: scikit-learn scaler has %d means and %d scales for %d coefficients", len(scaler.Mean), len(scaler.Scale), len(coef))
+		}
+		steps = append([]Transformer{scaler}, steps...)
+	}
+
+	return NewPipeline(model, steps...), nil
+}
+
+// sklearnVector reads a scalar, a vector or a
+// single row matrix
+func sklearnVector(raw json.RawMessage) ([]float64, error) {
+	var scalar float64
+	if err := json.Unmarshal(raw, &scalar); err == nil {
+		return []float64{scalar}, nil
+	}
+
+	var vector []float64
+	if err := json.Unmarshal(raw, &vector); err == nil {
+		return vector, nil
+	}
+
+	var matrix [][]float64
+	if err := json.Unmarshal(raw, &matrix); err != nil {
+		return nil, err
+	}
+	if len(matrix) != 1 {
+		return nil, fmt.Errorf("got %d rows, only single output models are supported", len(matrix))
+	}
+	return matrix[0], nil
+}
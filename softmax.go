@@ -0,0 +1,168 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// SoftmaxRegression is a multinomial logit regression of
+// outputs 0 to Classes-1, probability of class k being
+// proportional to exp(θₖ·x). Thetas of class 0 are zero,
+// the reference of the other classes.
+type SoftmaxRegression struct {
+	Classes int
+	// Theta holds thetas of every class
+	Theta [][]float64
+	// StdErrors of thetas of every class, zero for
+	// class 0, come from the observed information
+	StdErrors [][]float64
+
+	loglik     float64
+	samples    int
+	covariance [][]float64
+}
+
+// NewSoftmaxRegression returns new pointer of
+// SoftmaxRegression of classes
+func NewSoftmaxRegression(classes int) *SoftmaxRegression {
+	return &SoftmaxRegression{Classes: classes}
+}
+
+// Fit trains the model on features and classes of output
+func (s *SoftmaxRegression) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if s.Classes < 2 {
+		return fmt.Errorf("ml: softmax needs at least 2 classes, got %d", s.Classes)
+	}
+	for i, y := range output {
+		if y < 0 || y >= float64(s.Classes) || y != math.Floor(y) {
+			return fmt.Errorf("ml: output %d is %v, not a class of %d", i, y, s.Classes)
+		}
+	}
+
+	ll := func(x, grad []float64) float64 {
+		return softmaxLikelihood(features, output, s.Classes, x, grad)
+	}
+	x, err := maximizeLikelihood(ll, make([]float64, (s.Classes-1)*p), len(output))
+	if err != nil {
+		return fmt.Errorf("ml: softmax: %v", err)
+	}
+	s.Theta = softmaxThetas(x, s.Classes)
+	s.loglik = ll(x, nil)
+	s.samples = len(output)
+
+	s.covariance, err = observedCovariance(ll, x)
+	if err != nil {
+		s.StdErrors = nil
+		return nil
+	}
+	se := make([]float64, len(x))
+	for j := range se {
+		se[j] = math.Sqrt(s.covariance[j][j])
+	}
+	s.StdErrors = softmaxThetas(se, s.Classes)
+	return nil
+}
+
+// softmaxThetas returns thetas of every class from
+// thetas of classes 1 to classes-1 in x
+func softmaxThetas(x []float64, classes int) [][]float64 {
+	p := len(x) / (classes - 1)
+	theta := [][]float64{make([]float64, p)}
+	for k := 1; k < classes; k++ {
+		theta = append(theta, append([]float64(nil), x[(k-1)*p:k*p]...))
+	}
+	return theta
+}
+
+// softmaxProbabilities returns probabilities of every
+// class of X
+func softmaxProbabilities(theta [][]float64, X []float64) []float64 {
+	prob := make([]float64, len(theta))
+	for k, t := range theta {
+		prob[k] = floats.Dot(X, t)
+	}
+	norm := floats.LogSumExp(prob)
+	for k := range prob {
+		prob[k] = math.Exp(prob[k] - norm)
+	}
+	return prob
+}
+
+// softmaxLikelihood returns log-likelihood of thetas of
+// classes 1 to classes-1 in x, filling grad when not nil
+func softmaxLikelihood(features [][]float64, output []float64, classes int, x, grad []float64) float64 {
+	theta := softmaxThetas(x, classes)
+	p := len(theta[0])
+	for j := range grad {
+		grad[j] = 0
+	}
+	ll := 0.0
+	for i, row := range features {
+		prob := softmaxProbabilities(theta, row)
+		y := int(output[i])
+		ll += math.Log(prob[y])
+		if grad == nil {
+			continue
+		}
+		for k := 1; k < classes; k++ {
+			d := -prob[k]
+			if k == y {
+				d++
+			}
+			floats.AddScaled(grad[(k-1)*p:k*p], d, row)
+		}
+	}
+	return ll
+}
+
+// Probabilities returns probability of every class of X
+func (s *SoftmaxRegression) Probabilities(X []float64) []float64 {
+	return softmaxProbabilities(s.Theta, X)
+}
+
+// Estimate returns the most probable class of X
+func (s *SoftmaxRegression) Estimate(X []float64) float64 {
+	return float64(floats.MaxIdx(s.Probabilities(X)))
+}
+
+// Coefficients returns thetas of classes 1 to
+// Classes-1, in order of CoefficientCovariance
+func (s *SoftmaxRegression) Coefficients() []float64 {
+	var x []float64
+	for _, t := range s.Theta[1:] {
+		x = append(x, t...)
+	}
+	return x
+}
+
+// CoefficientCovariance returns covariance of Coefficients,
+// the inverse observed information of the training rows
+func (s *SoftmaxRegression) CoefficientCovariance() ([][]float64, error) {
+	if s.covariance == nil {
+		return nil, fmt.Errorf("ml: covariance needs a model fitted with a regular information matrix")
+	}
+	return s.covariance, nil
+}
+
+// LogLikelihood returns multinomial log-likelihood
+// of the training data
+func (s *SoftmaxRegression) LogLikelihood() float64 {
+	return s.loglik
+}
+
+// NumParams returns the number of thetas
+// of classes but the reference
+func (s *SoftmaxRegression) NumParams() int {
+	return (s.Classes - 1) * len(s.Theta[0])
+}
+
+// NumSamples returns the number of training rows
+func (s *SoftmaxRegression) NumSamples() int {
+	return s.samples
+}
@@ -0,0 +1,105 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// CovarianceMatrix returns the sample covariance
+// matrix of the columns of features
+func CovarianceMatrix(features [][]float64) [][]float64 {
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, denseOf(features), nil)
+	return symToSlices(&cov)
+}
+
+// CorrelationMatrix returns the Pearson correlation
+// matrix of the columns of features
+func CorrelationMatrix(features [][]float64) [][]float64 {
+	var corr mat.SymDense
+	stat.CorrelationMatrix(&corr, denseOf(features), nil)
+	return symToSlices(&corr)
+}
+
+// VIF returns variance inflation factor of every column
+// of features, that is 1/(1-R²) of regressing the column
+// on all others. Values above 10 are commonly taken as a
+// sign of multicollinearity. Constant columns, such as the
+// bias column, are skipped and get NaN.
+func VIF(features [][]float64) ([]float64, error) {
+	if len(features) == 0 {
+		return nil, fmt.Errorf("ml: cannot compute VIF of empty features")
+	}
+
+	vif := make([]float64, len(features[0]))
+	var cols []int
+	for j := range vif {
+		vif[j] = math.NaN()
+		if stat.Variance(column(features, j), nil) > 0 {
+			cols = append(cols, j)
+		}
+	}
+
+	if len(cols) == 0 {
+		return vif, nil
+	}
+	if len(cols) == 1 {
+		vif[cols[0]] = 1
+		return vif, nil
+	}
+
+	var corr mat.SymDense
+	stat.CorrelationMatrix(&corr, denseOf(selectColumns(features, cols)), nil)
+
+	var inv mat.Dense
+	if err := inv.Inverse(&corr); err != nil {
+		return nil, fmt.Errorf("ml: features are perfectly collinear: %v", err)
+	}
+
+	for i, j := range cols {
+		vif[j] = inv.At(i, i)
+	}
+
+	return vif, nil
+}
+
+// denseOf copies features into a dense matrix
+func denseOf(features [][]float64) *mat.Dense {
+	if len(features) == 0 {
+		return &mat.Dense{}
+	}
+
+	d := mat.NewDense(len(features), len(features[0]), nil)
+	for i, row := range features {
+		d.SetRow(i, row)
+	}
+	return d
+}
+
+func symToSlices(s *mat.SymDense) [][]float64 {
+	n := s.Symmetric()
+	out := make([][]float64, n)
+	for i := range out {
+		out[i] = make([]float64, n)
+		for j := range out[i] {
+			out[i][j] = s.At(i, j)
+		}
+	}
+	return out
+}
+
+// selectColumns returns a copy of features
+// keeping only the given columns in order
+func selectColumns(features [][]float64, cols []int) [][]float64 {
+	out := make([][]float64, len(features))
+	for i, row := range features {
+		out[i] = make([]float64, len(cols))
+		for k, j := range cols {
+			out[i][k] = row[j]
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+)
+
+// Stepwise selects columns by adding (forward) or removing
+// (backward) the column improving Criterion most, such as
+// AIC or BIC, until no column improves it. Bidirectional
+// also tries removing columns when going forward and adding
+// them when going backward. Stepwise is an Estimator and a
+// Transformer.
+type Stepwise struct {
+	// NewEstimator must return a LikelihoodModel
+	NewEstimator func() Estimator
+	// Criterion defaults to AIC
+	Criterion     Criterion
+	Backward      bool
+	Bidirectional bool
+	// Keep are columns always selected,
+	// such as the bias column
+	Keep []int
+	// MaxSteps limits the steps, zero is no limit
+	MaxSteps int
+
+	// Selected are the chosen columns, ascending
+	Selected []int
+	// Path holds the start and every step
+	Path []StepwiseStep
+	// Estimator is fitted on selected columns
+	Estimator Estimator
+}
+
+// StepwiseStep is a step of Stepwise, Added or
+// Removed is -1 when no column was
+type StepwiseStep struct {
+	Columns   []int
+	Criterion float64
+	Added     int
+	Removed   int
+}
+
+// NewStepwise returns new pointer of Stepwise doing
+// forward selection by AIC
+func NewStepwise(newEstimator func() Estimator) *Stepwise {
+	return &Stepwise{
+		NewEstimator: newEstimator,
+		Criterion:    AIC,
+	}
+}
+
+// Fit selects columns of features and fits the
+// estimator on them
+func (s *Stepwise) Fit(features [][]float64, output []float64) error {
+	if len(features) == 0 {
+		return fmt.Errorf("ml: cannot fit empty features")
+	}
+	criterion := s.Criterion
+	if criterion == nil {
+		criterion = AIC
+	}
+
+	n := len(features[0])
+	keep := make(map[int]bool, len(s.Keep))
+	for _, j := range s.Keep {
+		if j < 0 || j >= n {
+			return fmt.Errorf("ml: keep column %d of %d columns", j, n)
+		}
+		keep[j] = true
+	}
+
+	var current []int
+	if s.Backward {
+		for j := 0; j < n; j++ {
+			current = append(current, j)
+		}
+	} else {
+		for _, j := range s.Keep {
+			if !contains(current, j) {
+				current = toggle(current, j)
+			}
+		}
+	}
+
+	// an empty model cannot be fitted and
+	// is worse than any other
+	model, score := Estimator(nil), math.Inf(1)
+	if len(current) > 0 {
+		var err error
+		if model, score, err = s.score(features, output, current, criterion); err != nil {
+			return err
+		}
+	}
+	s.Path = []StepwiseStep{{Columns: current, Criterion: score, Added: -1, Removed: -1}}
+
+	for step := 0; s.MaxSteps <= 0 || step < s.MaxSteps; step++ {
+		var (
+			best      []int
+			bestModel Estimator
+			bestScore = score
+			toggled   = -1
+		)
+		for j := 0; j < n; j++ {
+			if keep[j] {
+				continue
+			}
+			in := contains(current, j)
+			if in && !s.Backward && !s.Bidirectional || !in && s.Backward && !s.Bidirectional {
+				continue
+			}
+			if in && len(current) == 1 {
+				continue
+			}
+			cols := toggle(current, j)
+			m, c, err := s.score(features, output, cols, criterion)
+			if err != nil {
+				return err
+			}
+			if c < bestScore {
+				best, bestModel, bestScore, toggled = cols, m, c, j
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		st := StepwiseStep{Columns: best, Criterion: bestScore, Added: toggled, Removed: -1}
+		if contains(current, toggled) {
+			st.Added, st.Removed = -1, toggled
+		}
+		current, model, score = best, bestModel, bestScore
+		s.Path = append(s.Path, st)
+	}
+
+	if model == nil {
+		return fmt.Errorf("ml: no column improves the criterion")
+	}
+	s.Selected = current
+	s.Estimator = model
+	return nil
+}
+
+// score fits columns cols and returns the model and criterion
+func (s *Stepwise) score(features [][]float64, output []float64, cols []int, criterion Criterion) (Estimator, float64, error) {
+	model := s.NewEstimator()
+	if err := model.Fit(selectColumns(features, cols), output); err != nil {
+		return nil, 0, fmt.Errorf("ml: columns %v: %v", cols, err)
+	}
+	lm, ok := model.(LikelihoodModel)
+	if !ok {
+		return nil, 0, fmt.Errorf("ml: stepwise needs a LikelihoodModel, got %T", model)
+	}
+	if err := checkLikelihood(lm); err != nil {
+		return nil, 0, fmt.Errorf("ml: columns %v: %v", cols, err)
+	}
+	c := criterion(lm)
+	if math.IsNaN(c) {
+		c = math.Inf(1)
+	}
+	return model, c, nil
+}
+
+// Transform returns features keeping only selected columns
+func (s *Stepwise) Transform(features [][]float64) [][]float64 {
+	return selectColumns(features, s.Selected)
+}
+
+// Estimate returns estimate of X using selected columns
+func (s *Stepwise) Estimate(X []float64) float64 {
+	return s.Estimator.Estimate(selectColumns([][]float64{X}, s.Selected)[0])
+}
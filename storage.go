@@ -0,0 +1,163 @@
+package ml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is wrapped by errors of missing keys
+var ErrNotFound = errors.New("ml: not found")
+
+// Storage stores artifacts such as saved models under
+// slash separated keys. Cloud object stores implement it
+// with their streaming readers and writers, e.g. an S3
+// upload fed by an io.Pipe or a GCS object writer.
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// Create returns a writer of key, the object is
+	// stored when Close returns nil
+	Create(key string) (io.WriteCloser, error)
+	// Open returns a reader of key, with an error
+	// wrapping ErrNotFound for missing keys
+	Open(key string) (io.ReadCloser, error)
+	// List returns keys starting with prefix, sorted
+	List(prefix string) ([]string, error)
+}
+
+// SaveTo writes model to key of storage
+func SaveTo(storage Storage, key string, model interface{}) error {
+	w, err := storage.Create(key)
+	if err != nil {
+		return err
+	}
+	if err = Save(w, model); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// LoadFrom reads a model from key of storage
+func LoadFrom(storage Storage, key string) (interface{}, error) {
+	r, err := storage.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return Load(r)
+}
+
+// FileStorage stores artifacts as files under Root
+type FileStorage struct {
+	Root string
+}
+
+// NewFileStorage returns new pointer of FileStorage
+func NewFileStorage(root string) *FileStorage {
+	return &FileStorage{Root: root}
+}
+
+func (f *FileStorage) path(key string) (string, error) {
+	clean := filepath.ToSlash(filepath.Clean("/" + key))[1:]
+	if key == "" || clean != key {
+		return "", fmt.Errorf("ml: invalid storage key %q", key)
+	}
+	return filepath.Join(f.Root, filepath.FromSlash(key)), nil
+}
+
+// Create writes a temporary file renamed to key on
+// Close, so readers never see a partial artifact
+func (f *FileStorage) Create(key string) (io.WriteCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{File: tmp, path: path}, nil
+}
+
+// fileWriter renames its temporary file on Close
+// unless a write failed
+type fileWriter struct {
+	*os.File
+	path string
+	err  error
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, err := w.File.Write(p)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *fileWriter) Close() error {
+	err := w.err
+	if err == nil {
+		err = w.File.Sync()
+	}
+	if cerr := w.File.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(w.File.Name(), w.path)
+	}
+	if err != nil {
+		os.Remove(w.File.Name())
+	}
+	return err
+}
+
+// Open opens the file of key
+func (f *FileStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return file, err
+}
+
+// List walks Root for keys starting with prefix
+func (f *FileStorage) List(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.Walk(f.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == f.Root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.Root, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
@@ -0,0 +1,159 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/tabwriter"
+)
+
+// Summary returns a table of named coefficients,
+// metrics on training rows, optimizer status
+// and settings of the model
+func (l *LinearRegression) Summary() string {
+	var metrics [][2]string
+	if output, estimates := l.trainingEstimates(l.Estimate); output != nil {
+		mse := MeanSquaredError(output, estimates)
+		metrics = [][2]string{
+			{"r2", fmt.Sprintf("%.6g", R2(output, estimates))},
+			{"mse", fmt.Sprintf("%.6g", mse)},
+			{"rmse", fmt.Sprintf("%.6g", math.Sqrt(mse))},
+		}
+	}
+	return l.summary("LinearRegression", metrics, nil)
+}
+
+// String returns Summary
+func (l *LinearRegression) String() string {
+	return l.Summary()
+}
+
+// Summary returns a table of named coefficients,
+// metrics on training rows, optimizer status
+// and settings of the model
+func (l *LogisticRegression) Summary() string {
+	threshold := l.DecisionThreshold()
+
+	var metrics [][2]string
+	if output, estimates := l.trainingEstimates(l.Estimate); output != nil {
+		labels := make([]float64, len(estimates))
+		for i, p := range estimates {
+			if p >= threshold {
+				labels[i] = 1
+			}
+		}
+		metrics = [][2]string{
+			{"log loss", fmt.Sprintf("%.6g", logLoss(output, estimates))},
+			{"accuracy", fmt.Sprintf("%.6g", Accuracy(output, labels))},
+			{"brier", fmt.Sprintf("%.6g", BrierScore(output, estimates))},
+		}
+	}
+	settings := [][2]string{{"threshold", fmt.Sprintf("%g", threshold)}}
+	return l.summary("LogisticRegression", metrics, settings)
+}
+
+// String returns Summary
+func (l *LogisticRegression) String() string {
+	return l.Summary()
+}
+
+// summary writes the table of Summary with metrics
+// and extra settings of the model kind
+func (l *Linear) summary(kind string, metrics, settings [][2]string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "%s\n", kind)
+	if len(l.Theta) == 0 {
+		fmt.Fprintf(w, "not fitted\n")
+	} else {
+		if !l.IsLinear() {
+			fmt.Fprintf(w, "custom hypothesis\n")
+		}
+		fmt.Fprintf(w, "\nfeature\tcoefficient\n")
+		for _, c := range l.NamedCoefficients() {
+			fmt.Fprintf(w, "%s\t% .6g\n", c.Name, c.Value)
+		}
+	}
+
+	if len(metrics) > 0 {
+		fmt.Fprintf(w, "\ntraining\t%d rows\n", l.rows())
+		for _, m := range metrics {
+			fmt.Fprintf(w, "%s\t%s\n", m[0], m[1])
+		}
+	}
+
+	if r := l.Result; r != nil {
+		fmt.Fprintf(w, "\noptimizer\t%v\n", r.Status)
+		fmt.Fprintf(w, "loss\t%.6g\n", r.F)
+		fmt.Fprintf(w, "iterations\t%d\n", r.MajorIterations)
+		fmt.Fprintf(w, "evaluations\t%d\n", r.FuncEvaluations)
+		fmt.Fprintf(w, "runtime\t%v\n", r.Runtime)
+	}
+	if c := l.convergence; c != nil {
+		if l.Result == nil {
+			fmt.Fprintf(w, "\ntraining\t%s\n", c.StopReason)
+			fmt.Fprintf(w, "iterations\t%d\n", c.Iterations)
+		}
+		fmt.Fprintf(w, "gradient norm\t%.3g\n", c.GradientNorm)
+		fmt.Fprintf(w, "converged\t%t\n", c.Converged)
+	}
+
+	fmt.Fprintf(w, "\nlearning rate\t%g\n", l.LearningRate)
+	if s := l.Setting; s != nil {
+		fmt.Fprintf(w, "max iterations\t%d\n", s.MajorIteration)
+		fmt.Fprintf(w, "gradient threshold\t%g\n", s.Threshod)
+		if s.MaxDuration > 0 {
+			fmt.Fprintf(w, "max duration\t%v\n", s.MaxDuration)
+		}
+	} else {
+		fmt.Fprintf(w, "setting\tgonum default\n")
+	}
+	if l.WarmStart {
+		fmt.Fprintf(w, "warm start\ttrue\n")
+	}
+	if l.Loss != nil {
+		fmt.Fprintf(w, "custom loss\t%+v\n", l.Loss)
+	}
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\n", s[0], s[1])
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+// trainingEstimates returns output and estimates of
+// the training rows, nil when they are not kept
+func (l *Linear) trainingEstimates(estimate func([]float64) float64) ([]float64, []float64) {
+	if len(l.Theta) == 0 {
+		return nil, nil
+	}
+	switch {
+	case l.Features != nil:
+		estimates := make([]float64, len(l.Features))
+		for i, x := range l.Features {
+			estimates[i] = estimate(x)
+		}
+		return l.Output, estimates
+	case l.features32 != nil:
+		estimates := make([]float64, len(l.features32))
+		for i, x := range l.features32 {
+			estimates[i] = estimate(Float64s(x))
+		}
+		return Float64s(l.output32), estimates
+	}
+	return nil, nil
+}
+
+// logLoss returns mean negative log likelihood
+// of probabilities, lower is better
+func logLoss(output, probabilities []float64) float64 {
+	const eps = 1e-15
+	sum := 0.0
+	for i, y := range output {
+		p := math.Min(math.Max(probabilities[i], eps), 1-eps)
+		sum -= y*math.Log(p) + (1-y)*math.Log(1-p)
+	}
+	return sum / float64(len(output))
+}
@@ -0,0 +1,278 @@
+package survival
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// CoxPH is the Cox proportional hazards model, hazard
+// h(t|x) = h0(t) exp(Coefficients·x), fitted by Breslow's
+// partial likelihood. It has no intercept, so features
+// should not include a bias column.
+type CoxPH struct {
+	Coefficients []float64
+	StdErrors    []float64
+	// LogLikelihood is the log partial likelihood
+	LogLikelihood float64
+	Result        *optimize.Result
+
+	// BaselineTimes and BaselineHazard are Breslow's
+	// cumulative baseline hazard at every event time
+	BaselineTimes  []float64
+	BaselineHazard []float64
+
+	features      [][]float64
+	times, events []float64
+	order         []int
+}
+
+// NewCoxPH returns new pointer of CoxPH
+func NewCoxPH() *CoxPH {
+	return &CoxPH{}
+}
+
+// Fit trains the model on features with survival
+// times and events
+func (c *CoxPH) Fit(features [][]float64, times, events []float64) error {
+	if err := check(times, events); err != nil {
+		return err
+	}
+	if len(features) != len(times) {
+		return fmt.Errorf("survival: got %d rows of features and %d times", len(features), len(times))
+	}
+	n := len(features[0])
+	for i, x := range features {
+		if len(x) != n {
+			return fmt.Errorf("survival: row %d has %d columns, expected %d", i, len(x), n)
+		}
+	}
+
+	c.features, c.times, c.events = features, times, events
+	c.order = sortedByTime(times)
+
+	prob := optimize.Problem{
+		Func: func(beta []float64) float64 {
+			ll, _, _ := c.partial(beta, false)
+			return -ll
+		},
+		Grad: func(grad, beta []float64) {
+			_, g, _ := c.partial(beta, false)
+			for j := range grad {
+				grad[j] = -g[j]
+			}
+		},
+	}
+
+	result, err := optimize.Minimize(prob, make([]float64, n), nil, &optimize.BFGS{})
+	if numopt.Stalled(result, err) {
+		err = numopt.CheckStall(result, err)
+	} else if err == nil {
+		err = result.Status.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("survival: cox: %v", err)
+	}
+
+	c.Result = result
+	c.Coefficients = result.X
+
+	ll, _, info := c.partial(c.Coefficients, true)
+	c.LogLikelihood = ll
+	c.StdErrors = make([]float64, n)
+	var inv mat.Dense
+	if err := inv.Inverse(info); err == nil {
+		for j := range c.StdErrors {
+			c.StdErrors[j] = math.Sqrt(inv.At(j, j))
+		}
+	} else {
+		for j := range c.StdErrors {
+			c.StdErrors[j] = math.NaN()
+		}
+	}
+
+	c.baseline()
+	return nil
+}
+
+// partial returns log partial likelihood of beta, its
+// gradient and, when information is set, the observed
+// information matrix
+func (c *CoxPH) partial(beta []float64, information bool) (float64, []float64, *mat.Dense) {
+	n := len(beta)
+	grad := make([]float64, n)
+	var info *mat.Dense
+	if information {
+		info = mat.NewDense(n, n, nil)
+	}
+
+	s0 := 0.0
+	s1 := make([]float64, n)
+	s2 := make([][]float64, n)
+	for j := range s2 {
+		s2[j] = make([]float64, n)
+	}
+
+	// walk times backwards so the risk set grows
+	ll := 0.0
+	for end := len(c.order); end > 0; {
+		start := end
+		t := c.times[c.order[end-1]]
+		for start > 0 && c.times[c.order[start-1]] == t {
+			start--
+		}
+
+		d := 0.0
+		eventSum := make([]float64, n)
+		for _, i := range c.order[start:end] {
+			x := c.features[i]
+			eta := dot(beta, x)
+			r := math.Exp(eta)
+			s0 += r
+			for j := range x {
+				s1[j] += r * x[j]
+				if information {
+					for k := range x {
+						s2[j][k] += r * x[j] * x[k]
+					}
+				}
+			}
+			if c.events[i] == 1 {
+				d++
+				ll += eta
+				for j := range x {
+					eventSum[j] += x[j]
+				}
+			}
+		}
+
+		if d > 0 {
+			ll -= d * math.Log(s0)
+			for j := range grad {
+				grad[j] += eventSum[j] - d*s1[j]/s0
+			}
+			if information {
+				for j := 0; j < n; j++ {
+					for k := 0; k < n; k++ {
+						v := s2[j][k]/s0 - s1[j]*s1[k]/(s0*s0)
+						info.Set(j, k, info.At(j, k)+d*v)
+					}
+				}
+			}
+		}
+		end = start
+	}
+
+	return ll, grad, info
+}
+
+// baseline computes Breslow's cumulative baseline hazard
+func (c *CoxPH) baseline() {
+	c.BaselineTimes, c.BaselineHazard = nil, nil
+
+	risk := make([]float64, len(c.times))
+	for i, x := range c.features {
+		risk[i] = math.Exp(dot(c.Coefficients, x))
+	}
+
+	atRisk := sum(risk)
+	hazard := 0.0
+	for start := 0; start < len(c.order); {
+		end := start
+		t := c.times[c.order[start]]
+		d, leaving := 0.0, 0.0
+		for end < len(c.order) && c.times[c.order[end]] == t {
+			d += c.events[c.order[end]]
+			leaving += risk[c.order[end]]
+			end++
+		}
+		if d > 0 {
+			hazard += d / atRisk
+			c.BaselineTimes = append(c.BaselineTimes, t)
+			c.BaselineHazard = append(c.BaselineHazard, hazard)
+		}
+		atRisk -= leaving
+		start = end
+	}
+}
+
+// HazardRatios returns exp of coefficients, the change
+// in hazard of one unit increase of every feature
+func (c *CoxPH) HazardRatios() []float64 {
+	hr := make([]float64, len(c.Coefficients))
+	for j, b := range c.Coefficients {
+		hr[j] = math.Exp(b)
+	}
+	return hr
+}
+
+// PValues returns Wald test p-values of coefficients
+func (c *CoxPH) PValues() []float64 {
+	p := make([]float64, len(c.Coefficients))
+	for j, b := range c.Coefficients {
+		p[j] = 2 * distuv.UnitNormal.Survival(math.Abs(b/c.StdErrors[j]))
+	}
+	return p
+}
+
+// Risk returns relative hazard exp(Coefficients·x) of X
+func (c *CoxPH) Risk(X []float64) float64 {
+	return math.Exp(dot(c.Coefficients, X))
+}
+
+// Survival returns probability of X surviving past t
+func (c *CoxPH) Survival(X []float64, t float64) float64 {
+	h := 0.0
+	for i, bt := range c.BaselineTimes {
+		if bt > t {
+			break
+		}
+		h = c.BaselineHazard[i]
+	}
+	return math.Exp(-h * c.Risk(X))
+}
+
+// Concordance returns Harrell's C-index of the model on
+// features, the fraction of comparable pairs whose
+// predicted risks are ordered like their times
+func (c *CoxPH) Concordance(features [][]float64, times, events []float64) float64 {
+	risk := make([]float64, len(features))
+	for i, x := range features {
+		risk[i] = c.Risk(x)
+	}
+
+	concordant, pairs := 0.0, 0.0
+	for i := range times {
+		if events[i] != 1 {
+			continue
+		}
+		for j := range times {
+			if times[j] <= times[i] {
+				continue
+			}
+			pairs++
+			switch {
+			case risk[i] > risk[j]:
+				concordant++
+			case risk[i] == risk[j]:
+				concordant += 0.5
+			}
+		}
+	}
+	if pairs == 0 {
+		return math.NaN()
+	}
+	return concordant / pairs
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
@@ -0,0 +1,235 @@
+// Package survival provides time-to-event models for
+// censored data, such as churn or failure times. Events
+// are 1 when the event was observed and 0 when the
+// observation was censored.
+package survival
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// KaplanMeier is the product-limit estimate of the
+// survival function, with a step at every event time
+type KaplanMeier struct {
+	Times    []float64
+	Survival []float64
+	AtRisk   []float64
+	Events   []float64
+	// Variance is Greenwood's variance of Survival
+	Variance []float64
+}
+
+// NewKaplanMeier returns new pointer of KaplanMeier
+// estimated from times and events
+func NewKaplanMeier(times, events []float64) (*KaplanMeier, error) {
+	if err := check(times, events); err != nil {
+		return nil, err
+	}
+
+	k := &KaplanMeier{}
+	s, greenwood := 1.0, 0.0
+	for _, g := range groupTimes(times, events) {
+		if g.events == 0 {
+			continue
+		}
+		s *= 1 - g.events/g.atRisk
+		if g.atRisk > g.events {
+			greenwood += g.events / (g.atRisk * (g.atRisk - g.events))
+		}
+
+		k.Times = append(k.Times, g.time)
+		k.Survival = append(k.Survival, s)
+		k.AtRisk = append(k.AtRisk, g.atRisk)
+		k.Events = append(k.Events, g.events)
+		k.Variance = append(k.Variance, s*s*greenwood)
+	}
+
+	return k, nil
+}
+
+// At returns estimated probability of surviving past t
+func (k *KaplanMeier) At(t float64) float64 {
+	i := sort.Search(len(k.Times), func(i int) bool { return k.Times[i] > t })
+	if i == 0 {
+		return 1
+	}
+	return k.Survival[i-1]
+}
+
+// Median returns the first time survival drops to 0.5
+// or below, NaN when it never does
+func (k *KaplanMeier) Median() float64 {
+	for i, s := range k.Survival {
+		if s <= 0.5 {
+			return k.Times[i]
+		}
+	}
+	return math.NaN()
+}
+
+// Confidence returns pointwise log-log confidence bounds
+// of Survival at level, e.g. 0.95
+func (k *KaplanMeier) Confidence(level float64) (lower, upper []float64) {
+	z := distuv.UnitNormal.Quantile(0.5 + level/2)
+	lower = make([]float64, len(k.Survival))
+	upper = make([]float64, len(k.Survival))
+	for i, s := range k.Survival {
+		if s <= 0 || s >= 1 {
+			lower[i], upper[i] = s, s
+			continue
+		}
+		se := math.Sqrt(k.Variance[i]) / (s * math.Abs(math.Log(s)))
+		lower[i] = math.Pow(s, math.Exp(z*se))
+		upper[i] = math.Pow(s, math.Exp(-z*se))
+	}
+	return lower, upper
+}
+
+// LogRank tests whether survival differs between groups,
+// returning a chi-squared statistic with one degree of
+// freedom less than the number of groups
+func LogRank(times, events []float64, groups []int) (*ml.StatTest, error) {
+	if err := check(times, events); err != nil {
+		return nil, err
+	}
+	if len(groups) != len(times) {
+		return nil, fmt.Errorf("survival: got %d times and %d groups", len(times), len(groups))
+	}
+
+	labels := map[int]int{}
+	for _, g := range groups {
+		if _, ok := labels[g]; !ok {
+			labels[g] = len(labels)
+		}
+	}
+	k := len(labels)
+	if k < 2 {
+		return nil, fmt.Errorf("survival: log-rank needs at least 2 groups")
+	}
+
+	order := sortedByTime(times)
+	atRisk := make([]float64, k)
+	for _, g := range groups {
+		atRisk[labels[g]]++
+	}
+
+	observed := make([]float64, k)
+	expected := make([]float64, k)
+	cov := mat.NewDense(k-1, k-1, nil)
+
+	for start := 0; start < len(order); {
+		end := start
+		deaths := make([]float64, k)
+		leaving := make([]float64, k)
+		for end < len(order) && times[order[end]] == times[order[start]] {
+			i := order[end]
+			g := labels[groups[i]]
+			deaths[g] += events[i]
+			leaving[g]++
+			end++
+		}
+
+		n, d := sum(atRisk), sum(deaths)
+		if d > 0 {
+			for g := 0; g < k; g++ {
+				observed[g] += deaths[g]
+				expected[g] += d * atRisk[g] / n
+			}
+			if n > 1 {
+				for g := 0; g < k-1; g++ {
+					for h := 0; h < k-1; h++ {
+						v := -atRisk[g] * atRisk[h] / (n * n)
+						if g == h {
+							v += atRisk[g] / n
+						}
+						cov.Set(g, h, cov.At(g, h)+v*d*(n-d)/(n-1))
+					}
+				}
+			}
+		}
+
+		for g := range atRisk {
+			atRisk[g] -= leaving[g]
+		}
+		start = end
+	}
+
+	diff := mat.NewVecDense(k-1, nil)
+	for g := 0; g < k-1; g++ {
+		diff.SetVec(g, observed[g]-expected[g])
+	}
+	var solved mat.VecDense
+	if err := solved.SolveVec(cov, diff); err != nil {
+		return nil, fmt.Errorf("survival: log-rank: %v", err)
+	}
+	statistic := mat.Dot(diff, &solved)
+
+	return &ml.StatTest{
+		Statistic: statistic,
+		DF:        float64(k - 1),
+		PValue:    distuv.ChiSquared{K: float64(k - 1)}.Survival(statistic),
+	}, nil
+}
+
+// timeGroup holds subjects sharing a time
+type timeGroup struct {
+	time, atRisk, events float64
+}
+
+// groupTimes returns distinct times in increasing order
+// with number at risk and events of each
+func groupTimes(times, events []float64) []timeGroup {
+	order := sortedByTime(times)
+	var groups []timeGroup
+	atRisk := float64(len(times))
+	for start := 0; start < len(order); {
+		g := timeGroup{time: times[order[start]], atRisk: atRisk}
+		end := start
+		for end < len(order) && times[order[end]] == g.time {
+			g.events += events[order[end]]
+			end++
+		}
+		atRisk -= float64(end - start)
+		groups = append(groups, g)
+		start = end
+	}
+	return groups
+}
+
+func sortedByTime(times []float64) []int {
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return times[order[a]] < times[order[b]] })
+	return order
+}
+
+func check(times, events []float64) error {
+	if len(times) == 0 {
+		return fmt.Errorf("survival: no observations")
+	}
+	if len(times) != len(events) {
+		return fmt.Errorf("survival: got %d times and %d events", len(times), len(events))
+	}
+	for i, e := range events {
+		if e != 0 && e != 1 {
+			return fmt.Errorf("survival: event %d is %v, expected 0 or 1", i, e)
+		}
+	}
+	return nil
+}
+
+func sum(x []float64) float64 {
+	s := 0.0
+	for _, v := range x {
+		s += v
+	}
+	return s
+}
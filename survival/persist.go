@@ -0,0 +1,49 @@
+package survival
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func init() {
+	ml.RegisterModel("survival.CoxPH", &CoxPH{})
+}
+
+// coxState is the saved state of CoxPH, training
+// data and optimizer result are not saved
+type coxState struct {
+	Coefficients   []float64
+	StdErrors      []float64
+	LogLikelihood  float64
+	BaselineTimes  []float64
+	BaselineHazard []float64
+}
+
+// GobEncode saves the fitted model
+func (c *CoxPH) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(coxState{
+		Coefficients:   c.Coefficients,
+		StdErrors:      c.StdErrors,
+		LogLikelihood:  c.LogLikelihood,
+		BaselineTimes:  c.BaselineTimes,
+		BaselineHazard: c.BaselineHazard,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode restores a saved CoxPH
+func (c *CoxPH) GobDecode(data []byte) error {
+	var s coxState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	c.Coefficients = s.Coefficients
+	c.StdErrors = s.StdErrors
+	c.LogLikelihood = s.LogLikelihood
+	c.BaselineTimes = s.BaselineTimes
+	c.BaselineHazard = s.BaselineHazard
+	return nil
+}
@@ -0,0 +1,104 @@
+package survival
+
+import (
+	"math"
+	"testing"
+)
+
+// leukemia returns remission times in weeks of Freireich's
+// 6-MP trial (Gehan, 1965), group 1 of 6-MP and group 0
+// of placebo
+func leukemia() (times, events []float64, groups []int) {
+	treated := []float64{6, 6, 6, 6, 7, 9, 10, 10, 11, 13, 16, 17, 19, 20, 22, 23, 25, 32, 32, 34, 35}
+	censored := map[int]bool{3: true, 5: true, 7: true, 8: true, 11: true, 12: true, 13: true, 16: true, 17: true, 18: true, 19: true, 20: true}
+	for i, t := range treated {
+		times = append(times, t)
+		groups = append(groups, 1)
+		if censored[i] {
+			events = append(events, 0)
+		} else {
+			events = append(events, 1)
+		}
+	}
+	for _, t := range []float64{1, 1, 2, 2, 3, 4, 4, 5, 5, 8, 8, 8, 8, 11, 11, 12, 12, 15, 17, 22, 23} {
+		times = append(times, t)
+		events = append(events, 1)
+		groups = append(groups, 0)
+	}
+	return times, events, groups
+}
+
+func TestKaplanMeierOfLeukemia(t *testing.T) {
+	times, events, groups := leukemia()
+	km, err := NewKaplanMeier(times[:21], events[:21])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the product-limit table of the 6-MP group
+	wantTimes := []float64{6, 7, 10, 13, 16, 22, 23}
+	wantSurvival := []float64{0.8571, 0.8067, 0.7529, 0.6902, 0.6275, 0.5378, 0.4482}
+	if len(km.Times) != len(wantTimes) {
+		t.Fatalf("steps at %v, want %v", km.Times, wantTimes)
+	}
+	for i := range wantTimes {
+		if km.Times[i] != wantTimes[i] || math.Abs(km.Survival[i]-wantSurvival[i]) > 1e-4 {
+			t.Errorf("S(%v) = %v, want S(%v) = %v", km.Times[i], km.Survival[i], wantTimes[i], wantSurvival[i])
+		}
+	}
+	if se := math.Sqrt(km.Variance[0]); math.Abs(se-0.0764) > 1e-4 {
+		t.Errorf("Greenwood standard error %v at 6 weeks, want 0.0764", se)
+	}
+	if got := km.Median(); got != 23 {
+		t.Errorf("median %v, want 23", got)
+	}
+	if got := km.At(12); got != km.Survival[2] {
+		t.Errorf("S(12) = %v, want %v of the last step", got, km.Survival[2])
+	}
+
+	test, err := LogRank(times, events, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(test.Statistic-16.79) > 0.01 || test.DF != 1 || test.PValue > 1e-4 {
+		t.Errorf("log-rank %+v, want chi-squared 16.79 of 1 degree", test)
+	}
+}
+
+func TestCoxPHOfLeukemia(t *testing.T) {
+	// Breslow's estimate of placebo against 6-MP is
+	// 1.509 with standard error 0.410
+	times, events, groups := leukemia()
+	features := make([][]float64, len(groups))
+	for i, g := range groups {
+		features[i] = []float64{float64(1 - g)}
+	}
+
+	c := NewCoxPH()
+	if err := c.Fit(features, times, events); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(c.Coefficients[0]-1.509) > 1e-3 || math.Abs(c.StdErrors[0]-0.410) > 1e-3 {
+		t.Errorf("coefficient %v of standard error %v, want 1.509 of 0.410", c.Coefficients[0], c.StdErrors[0])
+	}
+	if hr := c.HazardRatios()[0]; math.Abs(hr-4.523) > 5e-3 {
+		t.Errorf("hazard ratio %v, want 4.523", hr)
+	}
+
+	// a higher risk dies no later in most pairs
+	if ci := c.Concordance(features, times, events); ci < 0.5 || ci > 1 {
+		t.Errorf("concordance %v", ci)
+	}
+	if s0, s1 := c.Survival([]float64{0}, 10), c.Survival([]float64{1}, 10); s1 >= s0 {
+		t.Errorf("placebo survival %v not below 6-MP %v", s1, s0)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if _, err := NewKaplanMeier([]float64{1, 2}, []float64{1, 2}); err == nil {
+		t.Error("no error of event 2")
+	}
+	if _, err := LogRank([]float64{1, 2}, []float64{1, 1}, []int{0, 0}); err == nil {
+		t.Error("no error of a single group")
+	}
+}
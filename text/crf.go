@@ -0,0 +1,434 @@
+package text
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/maxrafiandy/ml/internal/numopt"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Template returns observation features of the token
+// at position i of tokens, such as "word=paris"
+type Template func(tokens []string, i int) []string
+
+// Templates are the templates of a CRF
+type Templates []Template
+
+// WordTemplate returns a Template of the lowercased
+// token at offset from the position, or of the
+// sentence boundary beyond it
+func WordTemplate(offset int) Template {
+	name := fmt.Sprintf("word[%d]=", offset)
+	return func(tokens []string, i int) []string {
+		j := i + offset
+		if j < 0 {
+			return []string{name + "<s>"}
+		}
+		if j >= len(tokens) {
+			return []string{name + "</s>"}
+		}
+		return []string{name + strings.ToLower(tokens[j])}
+	}
+}
+
+// SuffixTemplate returns a Template of the last n
+// letters of the lowercased token
+func SuffixTemplate(n int) Template {
+	name := fmt.Sprintf("suffix%d=", n)
+	return func(tokens []string, i int) []string {
+		r := []rune(strings.ToLower(tokens[i]))
+		return []string{name + string(r[max(0, len(r)-n):])}
+	}
+}
+
+// PrefixTemplate returns a Template of the first n
+// letters of the lowercased token
+func PrefixTemplate(n int) Template {
+	name := fmt.Sprintf("prefix%d=", n)
+	return func(tokens []string, i int) []string {
+		r := []rune(strings.ToLower(tokens[i]))
+		return []string{name + string(r[:min(n, len(r))])}
+	}
+}
+
+// ShapeTemplate is a Template of the shape of the token,
+// upper, lower letters and digits as X, x and d with
+// repeats collapsed, so "McDonald's" is "XxXx'x"
+func ShapeTemplate(tokens []string, i int) []string {
+	var b strings.Builder
+	var last rune
+	for _, c := range tokens[i] {
+		s := c
+		switch {
+		case unicode.IsUpper(c):
+			s = 'X'
+		case unicode.IsLetter(c):
+			s = 'x'
+		case unicode.IsDigit(c):
+			s = 'd'
+		}
+		if s != last {
+			b.WriteRune(s)
+			last = s
+		}
+	}
+	return []string{"shape=" + b.String()}
+}
+
+// DefaultTemplates returns templates of the token and its
+// neighbors, its prefixes and suffixes of up to 3 letters
+// and its shape, a common baseline of tagging and NER
+func DefaultTemplates() []Template {
+	return []Template{
+		WordTemplate(-1), WordTemplate(0), WordTemplate(1),
+		PrefixTemplate(1), PrefixTemplate(2), PrefixTemplate(3),
+		SuffixTemplate(1), SuffixTemplate(2), SuffixTemplate(3),
+		ShapeTemplate,
+	}
+}
+
+// CRF is a linear-chain conditional random field labelling
+// every token of a sentence, such as part of speech or named
+// entity tags. Features of Templates of every token are
+// weighted per label, with weights of transitions between
+// labels, trained by L-BFGS on the L2 penalized likelihood.
+//
+// Templates are funcs, so Save leaves them out. Empty
+// Templates are DefaultTemplates, set custom ones again
+// after Load.
+type CRF struct {
+	Templates Templates
+	// L2 penalty of weights, defaults to 1
+	L2 float64
+	// MaxIterations of L-BFGS, defaults to 100
+	MaxIterations int
+
+	// Labels of the columns of weights
+	Labels []string
+	// Features maps features of training
+	// sentences to rows of Weights
+	Features map[string]int
+	// Weights[f][y] of feature f and label y,
+	// Transitions[y][y'] from y to y', and
+	// Start[y] of the first label
+	Weights     [][]float64
+	Transitions [][]float64
+	Start       []float64
+}
+
+// NewCRF returns new pointer of CRF of DefaultTemplates
+func NewCRF() *CRF {
+	return &CRF{Templates: DefaultTemplates(), L2: 1, MaxIterations: 100}
+}
+
+// templates returns Templates, or
+// DefaultTemplates when empty
+func (c *CRF) templates() []Template {
+	if len(c.Templates) == 0 {
+		return DefaultTemplates()
+	}
+	return c.Templates
+}
+
+// observe returns rows of known features of every token
+func (c *CRF) observe(tokens []string, add bool) [][]int {
+	obs := make([][]int, len(tokens))
+	for i := range tokens {
+		for _, t := range c.templates() {
+			for _, f := range t(tokens, i) {
+				id, ok := c.Features[f]
+				if !ok && add {
+					id, ok = len(c.Features), true
+					c.Features[f] = id
+				}
+				if ok {
+					obs[i] = append(obs[i], id)
+				}
+			}
+		}
+	}
+	return obs
+}
+
+// crfParams are views of weights of a parameter vector
+type crfParams struct {
+	labels             int
+	weights, trans, st []float64
+}
+
+func (c *CRF) params(x []float64) crfParams {
+	L, F := len(c.Labels), len(c.Features)
+	return crfParams{
+		labels:  L,
+		weights: x[:F*L],
+		trans:   x[F*L : F*L+L*L],
+		st:      x[F*L+L*L:],
+	}
+}
+
+// scores returns scores of every label of every token
+func (p crfParams) scores(obs [][]int) [][]float64 {
+	L := p.labels
+	s := make([][]float64, len(obs))
+	for t, fs := range obs {
+		s[t] = make([]float64, L)
+		for _, f := range fs {
+			floats.Add(s[t], p.weights[f*L:(f+1)*L])
+		}
+	}
+	return s
+}
+
+// forwardBackward returns log forward and backward
+// messages of scores and the log partition
+func (p crfParams) forwardBackward(s [][]float64) ([][]float64, [][]float64, float64) {
+	L, n := p.labels, len(s)
+	alpha := make([][]float64, n)
+	beta := make([][]float64, n)
+	buf := make([]float64, L)
+	alpha[0] = make([]float64, L)
+	floats.AddTo(alpha[0], p.st, s[0])
+	for t := 1; t < n; t++ {
+		alpha[t] = make([]float64, L)
+		for j := 0; j < L; j++ {
+			for i := 0; i < L; i++ {
+				buf[i] = alpha[t-1][i] + p.trans[i*L+j]
+			}
+			alpha[t][j] = floats.LogSumExp(buf) + s[t][j]
+		}
+	}
+	beta[n-1] = make([]float64, L)
+	for t := n - 2; t >= 0; t-- {
+		beta[t] = make([]float64, L)
+		for i := 0; i < L; i++ {
+			for j := 0; j < L; j++ {
+				buf[j] = p.trans[i*L+j] + s[t+1][j] + beta[t+1][j]
+			}
+			beta[t][i] = floats.LogSumExp(buf)
+		}
+	}
+	return alpha, beta, floats.LogSumExp(alpha[n-1])
+}
+
+// Fit trains the CRF on sentences of tokens
+// and labels of every token
+func (c *CRF) Fit(sentences, labels [][]string) error {
+	if len(sentences) == 0 {
+		return fmt.Errorf("text: cannot fit empty sentences")
+	}
+	if len(sentences) != len(labels) {
+		return fmt.Errorf("text: got %d sentences and %d label sequences", len(sentences), len(labels))
+	}
+	if len(c.Templates) == 0 {
+		c.Templates = DefaultTemplates()
+	}
+	l2 := c.L2
+	if l2 <= 0 {
+		l2 = 1
+	}
+	iterations := c.MaxIterations
+	if iterations <= 0 {
+		iterations = 100
+	}
+
+	index := map[string]int{}
+	c.Labels = nil
+	c.Features = map[string]int{}
+	var obs [][][]int
+	var ys [][]int
+	for k, sentence := range sentences {
+		if len(sentence) != len(labels[k]) {
+			return fmt.Errorf("text: sentence %d has %d tokens and %d labels", k, len(sentence), len(labels[k]))
+		}
+		if len(sentence) == 0 {
+			continue
+		}
+		y := make([]int, len(sentence))
+		for t, l := range labels[k] {
+			id, ok := index[l]
+			if !ok {
+				id = len(c.Labels)
+				index[l] = id
+				c.Labels = append(c.Labels, l)
+			}
+			y[t] = id
+		}
+		obs = append(obs, c.observe(sentence, true))
+		ys = append(ys, y)
+	}
+	if len(c.Labels) < 2 {
+		return fmt.Errorf("text: CRF needs at least 2 labels, got %d", len(c.Labels))
+	}
+	L := len(c.Labels)
+	dim := len(c.Features)*L + L*L + L
+
+	// empirical counts of every parameter
+	empirical := make([]float64, dim)
+	e := c.params(empirical)
+	for k, y := range ys {
+		e.st[y[0]]++
+		for t, label := range y {
+			for _, f := range obs[k][t] {
+				e.weights[f*L+label]++
+			}
+			if t > 0 {
+				e.trans[y[t-1]*L+label]++
+			}
+		}
+	}
+
+	scale := 1 / float64(len(ys))
+	// nll returns the penalized negative log likelihood
+	// per sentence, and its gradient when grad is not nil
+	nll := func(x, grad []float64) float64 {
+		p := c.params(x)
+		var g crfParams
+		if grad != nil {
+			copy(grad, empirical)
+			floats.Scale(-1, grad)
+			g = c.params(grad)
+		}
+		loglik := 0.0
+		for k, y := range ys {
+			s := p.scores(obs[k])
+			alpha, beta, logZ := p.forwardBackward(s)
+			score := p.st[y[0]]
+			for t, label := range y {
+				score += s[t][label]
+				if t > 0 {
+					score += p.trans[y[t-1]*L+label]
+				}
+			}
+			loglik += score - logZ
+			if grad == nil {
+				continue
+			}
+			// expected counts of the model
+			for t := range s {
+				for j := 0; j < L; j++ {
+					m := math.Exp(alpha[t][j] + beta[t][j] - logZ)
+					if t == 0 {
+						g.st[j] += m
+					}
+					for _, f := range obs[k][t] {
+						g.weights[f*L+j] += m
+					}
+					if t > 0 {
+						for i := 0; i < L; i++ {
+							g.trans[i*L+j] += math.Exp(alpha[t-1][i] + p.trans[i*L+j] + s[t][j] + beta[t][j] - logZ)
+						}
+					}
+				}
+			}
+		}
+		if grad != nil {
+			floats.AddScaled(grad, l2, x)
+			floats.Scale(scale, grad)
+		}
+		return (-loglik + l2*floats.Dot(x, x)/2) * scale
+	}
+
+	prob := optimize.Problem{
+		Func: func(x []float64) float64 { return nll(x, nil) },
+		Grad: func(grad, x []float64) { nll(x, grad) },
+	}
+	settings := &optimize.Settings{MajorIterations: iterations}
+	result, err := optimize.Minimize(prob, make([]float64, dim), settings, &optimize.LBFGS{})
+	if numopt.Stalled(result, err) {
+		err = numopt.CheckStall(result, err)
+	} else if err == nil && result.Status != optimize.IterationLimit {
+		err = result.Status.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("text: CRF training: %v", err)
+	}
+
+	p := c.params(result.X)
+	c.Weights = make([][]float64, len(c.Features))
+	for f := range c.Weights {
+		c.Weights[f] = p.weights[f*L : (f+1)*L]
+	}
+	c.Transitions = make([][]float64, L)
+	for i := range c.Transitions {
+		c.Transitions[i] = p.trans[i*L : (i+1)*L]
+	}
+	c.Start = p.st
+	return nil
+}
+
+// fitted returns parameters of the fitted weights
+func (c *CRF) fitted() crfParams {
+	L := len(c.Labels)
+	p := crfParams{labels: L, st: c.Start}
+	for _, w := range c.Weights {
+		p.weights = append(p.weights, w...)
+	}
+	for _, t := range c.Transitions {
+		p.trans = append(p.trans, t...)
+	}
+	return p
+}
+
+// Predict returns the most likely labels of tokens
+// by Viterbi decoding, and their log probability
+func (c *CRF) Predict(tokens []string) ([]string, float64) {
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+	p := c.fitted()
+	s := p.scores(c.observe(tokens, false))
+	L := p.labels
+
+	delta := make([]float64, L)
+	floats.AddTo(delta, p.st, s[0])
+	back := make([][]int, len(s))
+	for t := 1; t < len(s); t++ {
+		next := make([]float64, L)
+		back[t] = make([]int, L)
+		for j := 0; j < L; j++ {
+			best, arg := math.Inf(-1), 0
+			for i := 0; i < L; i++ {
+				if v := delta[i] + p.trans[i*L+j]; v > best {
+					best, arg = v, i
+				}
+			}
+			next[j] = best + s[t][j]
+			back[t][j] = arg
+		}
+		delta = next
+	}
+
+	path := make([]int, len(s))
+	path[len(s)-1] = floats.MaxIdx(delta)
+	for t := len(s) - 1; t > 0; t-- {
+		path[t-1] = back[t][path[t]]
+	}
+	_, _, logZ := p.forwardBackward(s)
+	out := make([]string, len(path))
+	for t, y := range path {
+		out[t] = c.Labels[y]
+	}
+	return out, floats.Max(delta) - logZ
+}
+
+// Marginals returns probability of every label
+// of every token of tokens
+func (c *CRF) Marginals(tokens []string) [][]float64 {
+	if len(tokens) == 0 {
+		return nil
+	}
+	p := c.fitted()
+	alpha, beta, logZ := p.forwardBackward(p.scores(c.observe(tokens, false)))
+	out := make([][]float64, len(tokens))
+	for t := range out {
+		out[t] = make([]float64, p.labels)
+		for j := range out[t] {
+			out[t][j] = math.Exp(alpha[t][j] + beta[t][j] - logZ)
+		}
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCRFTagsSentences(t *testing.T) {
+	sentences := [][]string{
+		{"the", "dog", "runs"},
+		{"a", "cat", "sleeps"},
+		{"the", "cat", "runs"},
+		{"a", "dog", "sleeps"},
+	}
+	labels := make([][]string, len(sentences))
+	for i := range labels {
+		labels[i] = []string{"DET", "NOUN", "VERB"}
+	}
+
+	c := NewCRF()
+	if err := c.Fit(sentences, labels); err != nil {
+		t.Fatal(err)
+	}
+	tokens := []string{"the", "cat", "sleeps"}
+	tags, logp := c.Predict(tokens)
+	if want := []string{"DET", "NOUN", "VERB"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags %v, want %v", tags, want)
+	}
+
+	// score every label sequence of the sentence to
+	// get the partition, marginals and the best one
+	p := c.fitted()
+	s := p.scores(c.observe(tokens, false))
+	L := len(c.Labels)
+	scores := map[[3]int]float64{}
+	z := 0.0
+	for a := 0; a < L; a++ {
+		for b := 0; b < L; b++ {
+			for d := 0; d < L; d++ {
+				v := math.Exp(p.st[a] + s[0][a] + p.trans[a*L+b] + s[1][b] + p.trans[b*L+d] + s[2][d])
+				scores[[3]int{a, b, d}] = v
+				z += v
+			}
+		}
+	}
+
+	best := 0.0
+	marginals := [3][]float64{make([]float64, L), make([]float64, L), make([]float64, L)}
+	for y, v := range scores {
+		best = math.Max(best, v)
+		for i := range y {
+			marginals[i][y[i]] += v / z
+		}
+	}
+	if math.Abs(logp-math.Log(best/z)) > 1e-9 {
+		t.Errorf("log probability %v, want %v", logp, math.Log(best/z))
+	}
+	for i, m := range c.Marginals(tokens) {
+		for y := range m {
+			if math.Abs(m[y]-marginals[i][y]) > 1e-9 {
+				t.Errorf("marginals %v of token %d, want %v", m, i, marginals[i])
+				break
+			}
+		}
+	}
+
+	if err := c.Fit(sentences, labels[:1]); err == nil {
+		t.Error("no error of missing labels")
+	}
+}
@@ -0,0 +1,150 @@
+package text
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// Embeddings are vectors of words, of Word2Vec
+// or pretrained ones
+type Embeddings struct {
+	// Vocabulary maps words to rows of Vectors
+	Vocabulary map[string]int
+	Vectors    [][]float64
+}
+
+// NewEmbeddings returns new pointer of Embeddings
+// of words and their vectors
+func NewEmbeddings(words []string, vectors [][]float64) *Embeddings {
+	e := &Embeddings{Vocabulary: make(map[string]int, len(words)), Vectors: vectors}
+	for i, w := range words {
+		e.Vocabulary[w] = i
+	}
+	return e
+}
+
+// Dimensions returns length of the vectors
+func (e *Embeddings) Dimensions() int {
+	if len(e.Vectors) == 0 {
+		return 0
+	}
+	return len(e.Vectors[0])
+}
+
+// Words returns words of every row of Vectors
+func (e *Embeddings) Words() []string {
+	words := make([]string, len(e.Vectors))
+	for w, i := range e.Vocabulary {
+		words[i] = w
+	}
+	return words
+}
+
+// Vector returns vector of word, false when
+// word is not in Vocabulary
+func (e *Embeddings) Vector(word string) ([]float64, bool) {
+	i, ok := e.Vocabulary[word]
+	if !ok {
+		return nil, false
+	}
+	return e.Vectors[i], true
+}
+
+// Similarity returns cosine similarity of vectors of words,
+// zero when either is not in Vocabulary
+func (e *Embeddings) Similarity(a, b string) float64 {
+	u, ok := e.Vector(a)
+	v, ok2 := e.Vector(b)
+	if !ok || !ok2 {
+		return 0
+	}
+	return cosine(u, v)
+}
+
+// MostSimilar returns at most k words of vectors most
+// similar to vector of word, most similar first
+func (e *Embeddings) MostSimilar(word string, k int) []string {
+	u, ok := e.Vector(word)
+	if !ok {
+		return nil
+	}
+	var words []string
+	similarity := map[string]float64{}
+	for w, i := range e.Vocabulary {
+		if w != word {
+			words = append(words, w)
+			similarity[w] = cosine(u, e.Vectors[i])
+		}
+	}
+	sort.Slice(words, func(a, b int) bool {
+		if similarity[words[a]] != similarity[words[b]] {
+			return similarity[words[a]] > similarity[words[b]]
+		}
+		return words[a] < words[b]
+	})
+	return words[:min(k, len(words))]
+}
+
+// Embed returns mean vector of tokens in Vocabulary,
+// zeros when none is
+func (e *Embeddings) Embed(tokens []string) []float64 {
+	return embed(e, tokens)
+}
+
+// Transform returns features of docs, averaged vectors of
+// their tokens by analyzer, for the classifiers of package ml
+func (e *Embeddings) Transform(analyzer *Analyzer, docs []string) [][]float64 {
+	return transform(e, analyzer, docs)
+}
+
+// Embedder is a source of word vectors,
+// Embeddings or MappedEmbeddings
+type Embedder interface {
+	Vector(word string) ([]float64, bool)
+	Dimensions() int
+}
+
+// embed returns mean vector of tokens of e
+func embed(e Embedder, tokens []string) []float64 {
+	mean := make([]float64, e.Dimensions())
+	n := 0
+	for _, t := range tokens {
+		if v, ok := e.Vector(t); ok {
+			floats.Add(mean, v)
+			n++
+		}
+	}
+	if n > 0 {
+		floats.Scale(1/float64(n), mean)
+	}
+	return mean
+}
+
+func transform(e Embedder, analyzer *Analyzer, docs []string) [][]float64 {
+	features := make([][]float64, len(docs))
+	for i, doc := range docs {
+		features[i] = embed(e, analyzer.Analyze(doc))
+	}
+	return features
+}
+
+func cosine(u, v []float64) float64 {
+	norm := floats.Norm(u, 2) * floats.Norm(v, 2)
+	if norm == 0 {
+		return 0
+	}
+	return floats.Dot(u, v) / norm
+}
+
+// sigmoid of x, clamped for stability
+func sigmoid(x float64) float64 {
+	if x > 20 {
+		return 1
+	}
+	if x < -20 {
+		return 0
+	}
+	return 1 / (1 + math.Exp(-x))
+}
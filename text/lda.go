@@ -0,0 +1,222 @@
+package text
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// LDA is Latent Dirichlet Allocation of count features,
+// such as of a Vectorizer without TFIDF, into Topics,
+// fitted by collapsed Gibbs sampling (Griffiths and
+// Steyvers, 2004). Only nonzero counts are visited.
+type LDA struct {
+	Topics int
+	// Alpha is the Dirichlet prior of topics of a
+	// document, defaults to 50/Topics
+	Alpha float64
+	// Beta is the Dirichlet prior of words of
+	// a topic, defaults to 0.01
+	Beta float64
+	// Iterations of sampling, defaults to 500
+	// for Fit and 50 for Transform
+	Iterations int
+	// Rand samples the topics, nil uses the global source
+	Rand *rand.Rand
+
+	// TopicWords[k][w] is probability of word w in topic k
+	TopicWords [][]float64
+	// DocumentTopics[d][k] is probability of topic k in
+	// training document d
+	DocumentTopics [][]float64
+}
+
+// NewLDA returns new pointer of LDA of topics
+func NewLDA(topics int) *LDA {
+	return &LDA{Topics: topics, Alpha: 50 / float64(topics), Beta: 0.01}
+}
+
+// tokens returns a word per count of every row
+// of counts, checking they are whole numbers
+func tokens(counts [][]float64, words int) ([][]int, error) {
+	docs := make([][]int, len(counts))
+	for d, row := range counts {
+		if len(row) != words {
+			return nil, fmt.Errorf("text: row %d has %d columns, expected %d", d, len(row), words)
+		}
+		for w, c := range row {
+			if c < 0 || c != math.Floor(c) {
+				return nil, fmt.Errorf("text: count %v of row %d column %d is not a whole number", c, d, w)
+			}
+			for ; c > 0; c-- {
+				docs[d] = append(docs[d], w)
+			}
+		}
+	}
+	return docs, nil
+}
+
+func (l *LDA) priors() (float64, float64) {
+	alpha, beta := l.Alpha, l.Beta
+	if alpha <= 0 {
+		alpha = 50 / float64(l.Topics)
+	}
+	if beta <= 0 {
+		beta = 0.01
+	}
+	return alpha, beta
+}
+
+// Fit samples topics of every count of counts, rows
+// being documents and columns words
+func (l *LDA) Fit(counts [][]float64) error {
+	if len(counts) == 0 {
+		return fmt.Errorf("text: cannot fit empty counts")
+	}
+	if l.Topics < 1 {
+		return fmt.Errorf("text: LDA needs at least 1 topic, got %d", l.Topics)
+	}
+	words := len(counts[0])
+	docs, err := tokens(counts, words)
+	if err != nil {
+		return err
+	}
+	iterations := l.Iterations
+	if iterations <= 0 {
+		iterations = 500
+	}
+	alpha, beta := l.priors()
+	rng := ml.RandOf(l.Rand)
+	K := l.Topics
+
+	// counts of topics of documents, of words of
+	// topics and of topics
+	docTopic := make([][]float64, len(docs))
+	topicWord := make([][]float64, K)
+	for k := range topicWord {
+		topicWord[k] = make([]float64, words)
+	}
+	topic := make([]float64, K)
+	z := make([][]int, len(docs))
+	for d, doc := range docs {
+		docTopic[d] = make([]float64, K)
+		z[d] = make([]int, len(doc))
+		for i, w := range doc {
+			k := rng.Intn(K)
+			z[d][i] = k
+			docTopic[d][k]++
+			topicWord[k][w]++
+			topic[k]++
+		}
+	}
+
+	prob := make([]float64, K)
+	vbeta := float64(words) * beta
+	for it := 0; it < iterations; it++ {
+		for d, doc := range docs {
+			for i, w := range doc {
+				k := z[d][i]
+				docTopic[d][k]--
+				topicWord[k][w]--
+				topic[k]--
+				k = sample(rng, prob, func(k int) float64 {
+					return (docTopic[d][k] + alpha) * (topicWord[k][w] + beta) / (topic[k] + vbeta)
+				})
+				z[d][i] = k
+				docTopic[d][k]++
+				topicWord[k][w]++
+				topic[k]++
+			}
+		}
+	}
+
+	l.TopicWords = make([][]float64, K)
+	for k := range topicWord {
+		l.TopicWords[k] = make([]float64, words)
+		for w, c := range topicWord[k] {
+			l.TopicWords[k][w] = (c + beta) / (topic[k] + vbeta)
+		}
+	}
+	l.DocumentTopics = distributions(docTopic, docs, alpha)
+	return nil
+}
+
+// sample draws an index of unnormalized probabilities
+// weight(k), written to prob
+func sample(rng *rand.Rand, prob []float64, weight func(k int) float64) int {
+	total := 0.0
+	for k := range prob {
+		total += weight(k)
+		prob[k] = total
+	}
+	return sort.SearchFloat64s(prob, rng.Float64()*total)
+}
+
+// distributions returns topic distributions of documents
+// of topic counts docTopic
+func distributions(docTopic [][]float64, docs [][]int, alpha float64) [][]float64 {
+	out := make([][]float64, len(docTopic))
+	for d, c := range docTopic {
+		out[d] = make([]float64, len(c))
+		total := float64(len(docs[d])) + float64(len(c))*alpha
+		for k, n := range c {
+			out[d][k] = (n + alpha) / total
+		}
+	}
+	return out
+}
+
+// Transform returns topic distributions of documents of
+// counts, sampling their topics with TopicWords fixed
+func (l *LDA) Transform(counts [][]float64) ([][]float64, error) {
+	if l.TopicWords == nil {
+		return nil, fmt.Errorf("text: LDA is not fitted")
+	}
+	docs, err := tokens(counts, len(l.TopicWords[0]))
+	if err != nil {
+		return nil, err
+	}
+	iterations := l.Iterations
+	if iterations <= 0 {
+		iterations = 50
+	}
+	alpha, _ := l.priors()
+	rng := ml.RandOf(l.Rand)
+	K := l.Topics
+
+	docTopic := make([][]float64, len(docs))
+	prob := make([]float64, K)
+	for d, doc := range docs {
+		docTopic[d] = make([]float64, K)
+		z := make([]int, len(doc))
+		for i := range doc {
+			z[i] = rng.Intn(K)
+			docTopic[d][z[i]]++
+		}
+		for it := 0; it < iterations; it++ {
+			for i, w := range doc {
+				docTopic[d][z[i]]--
+				z[i] = sample(rng, prob, func(k int) float64 {
+					return (docTopic[d][k] + alpha) * l.TopicWords[k][w]
+				})
+				docTopic[d][z[i]]++
+			}
+		}
+	}
+	return distributions(docTopic, docs, alpha), nil
+}
+
+// TopWords returns columns of the n most
+// probable words of topic, most probable first
+func (l *LDA) TopWords(topic, n int) []int {
+	words := make([]int, len(l.TopicWords[topic]))
+	for w := range words {
+		words[w] = w
+	}
+	p := l.TopicWords[topic]
+	sort.SliceStable(words, func(a, b int) bool { return p[words[a]] > p[words[b]] })
+	return words[:min(n, len(words))]
+}
@@ -0,0 +1,64 @@
+package text
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestLDARecoversPlantedTopics(t *testing.T) {
+	// 3 topics of 4 words each, every document
+	// drawing 40 words of one topic
+	rnd := rand.New(rand.NewSource(1))
+	counts := make([][]float64, 30)
+	for d := range counts {
+		counts[d] = make([]float64, 12)
+		for i := 0; i < 40; i++ {
+			counts[d][4*(d%3)+rnd.Intn(4)]++
+		}
+	}
+
+	l := NewLDA(3)
+	l.Iterations = 200
+	if err := l.Fit(counts); err != nil {
+		t.Fatal(err)
+	}
+
+	// every fitted topic is one planted topic, and
+	// documents of a planted topic share it
+	seen := map[int]bool{}
+	for k := 0; k < 3; k++ {
+		top := l.TopWords(k, 4)
+		sort.Ints(top)
+		planted := top[0] / 4
+		for i, w := range top {
+			if w != 4*planted+i {
+				t.Fatalf("top words %v of topic %d, want a planted topic", top, k)
+			}
+		}
+		seen[planted] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("fitted topics cover %d planted topics, want 3", len(seen))
+	}
+
+	topics, err := l.Transform(counts[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for d, p := range append(topics, l.DocumentTopics[:3]...) {
+		best := 0
+		for k := range p {
+			if p[k] > p[best] {
+				best = k
+			}
+		}
+		if top := l.TopWords(best, 1)[0] / 4; top != d%3 {
+			t.Errorf("document %d has most probable topic of planted topic %d, want %d", d, top, d%3)
+		}
+	}
+
+	if _, err := l.Transform([][]float64{{0.5}}); err == nil {
+		t.Error("no error of counts of the wrong width")
+	}
+}
@@ -0,0 +1,201 @@
+package text
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Format is a file format of word vectors
+type Format int
+
+const (
+	// Text is a line of a word and its values per word, as
+	// GloVe writes. A first line of the number of words and
+	// dimensions, as word2vec and fastText .vec files
+	// start with, is skipped.
+	Text Format = iota
+	// Binary is the binary format of word2vec: a line of the
+	// number of words and dimensions, then every word, a
+	// space and its values as little endian float32
+	Binary
+)
+
+// ReadEmbeddings reads word vectors of format from r
+func ReadEmbeddings(r io.Reader, format Format) (*Embeddings, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	var words []string
+	var vectors [][]float64
+	var err error
+	switch format {
+	case Text:
+		err = readText(br, func(word string, values []float64) {
+			words = append(words, word)
+			vectors = append(vectors, values)
+		})
+	case Binary:
+		err = readBinary(br, func(word string, values []float64) {
+			words = append(words, word)
+			vectors = append(vectors, values)
+		})
+	default:
+		return nil, fmt.Errorf("text: unknown format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("text: no word vectors")
+	}
+	return NewEmbeddings(words, vectors), nil
+}
+
+// LoadEmbeddings reads word vectors of format from
+// the file at path, see OpenMappedEmbeddings for files
+// larger than memory
+func LoadEmbeddings(path string, format Format) (*Embeddings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	e, err := ReadEmbeddings(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("text: %s: %v", path, strings.TrimPrefix(err.Error(), "text: "))
+	}
+	return e, nil
+}
+
+// header returns the number of words and dimensions of
+// fields of a word2vec header line, ok false otherwise
+func header(fields []string) (int, int, bool) {
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	dims, err := strconv.Atoi(fields[1])
+	if err != nil || n < 0 || dims <= 0 {
+		return 0, 0, false
+	}
+	return n, dims, true
+}
+
+// parseLine returns the word and values of fields of a
+// text line of dims values, words may contain spaces
+func parseLine(fields []string, dims int) (string, []float64, error) {
+	if len(fields) < dims+1 {
+		return "", nil, fmt.Errorf("got %d values, expected %d", len(fields)-1, dims)
+	}
+	k := len(fields) - dims
+	values := make([]float64, dims)
+	for d, f := range fields[k:] {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return "", nil, err
+		}
+		values[d] = v
+	}
+	return strings.Join(fields[:k], " "), values, nil
+}
+
+func readText(r *bufio.Reader, add func(string, []float64)) error {
+	dims := 0
+	for line := 1; ; line++ {
+		s, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		fields := strings.Fields(s)
+		if len(fields) > 0 {
+			if line == 1 {
+				if _, d, ok := header(fields); ok {
+					dims = d
+					continue
+				}
+			}
+			if dims == 0 {
+				dims = len(fields) - 1
+			}
+			word, values, perr := parseLine(fields, dims)
+			if perr != nil {
+				return fmt.Errorf("text: line %d: %v", line, perr)
+			}
+			add(word, values)
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func readBinary(r *bufio.Reader, add func(string, []float64)) error {
+	s, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("text: reading header: %v", err)
+	}
+	n, dims, ok := header(strings.Fields(s))
+	if !ok {
+		return fmt.Errorf("text: invalid header %q", strings.TrimSpace(s))
+	}
+	buf := make([]byte, 4*dims)
+	for i := 0; i < n; i++ {
+		word, err := r.ReadString(' ')
+		if err != nil {
+			return fmt.Errorf("text: word %d: %v", i, err)
+		}
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return fmt.Errorf("text: word %d: %v", i, err)
+		}
+		add(strings.TrimSpace(word), decodeFloat32s(buf))
+	}
+	return nil
+}
+
+// decodeFloat32s returns values of little endian float32s
+func decodeFloat32s(buf []byte) []float64 {
+	values := make([]float64, len(buf)/4)
+	for d := range values {
+		values[d] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*d:])))
+	}
+	return values
+}
+
+// WriteEmbeddings writes e to w in format, with the
+// header line of word2vec also for Text. Words of Binary
+// cannot contain spaces.
+func WriteEmbeddings(w io.Writer, e *Embeddings, format Format) error {
+	if format != Text && format != Binary {
+		return fmt.Errorf("text: unknown format %d", format)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d\n", len(e.Vectors), e.Dimensions())
+	var buf [4]byte
+	for i, word := range e.Words() {
+		if format == Binary && strings.ContainsAny(word, " \n") {
+			return fmt.Errorf("text: binary word %q contains a space", word)
+		}
+		bw.WriteString(word)
+		if format == Binary {
+			bw.WriteByte(' ')
+		}
+		for _, v := range e.Vectors[i] {
+			if format == Text {
+				bw.WriteByte(' ')
+				bw.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+				continue
+			}
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(v)))
+			bw.Write(buf[:])
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
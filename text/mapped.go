@@ -0,0 +1,156 @@
+package text
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/maxrafiandy/ml/internal/mmap"
+)
+
+// MappedEmbeddings are word vectors of a file mapped into
+// memory, so it can be larger than RAM: only an index of
+// words is held and vectors are parsed from the mapped file
+// when looked up. It is safe for concurrent use until Close.
+type MappedEmbeddings struct {
+	data   []byte
+	format Format
+	dims   int
+	// offsets of values of every word
+	offsets map[string]int
+	close   func() error
+}
+
+// OpenMappedEmbeddings maps the word vectors file
+// of format at path
+func OpenMappedEmbeddings(path string, format Format) (*MappedEmbeddings, error) {
+	data, closer, err := mmap.Map(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &MappedEmbeddings{data: data, format: format, close: closer}
+	switch format {
+	case Text:
+		err = m.indexText()
+	case Binary:
+		err = m.indexBinary()
+	default:
+		err = fmt.Errorf("unknown format %d", format)
+	}
+	if err == nil && len(m.offsets) == 0 {
+		err = fmt.Errorf("no word vectors")
+	}
+	if err != nil {
+		closer()
+		return nil, fmt.Errorf("text: %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// line returns the line of data at offset without
+// its newline, and the offset of the next line
+func (m *MappedEmbeddings) line(offset int) ([]byte, int) {
+	end := bytes.IndexByte(m.data[offset:], '\n')
+	if end < 0 {
+		return m.data[offset:], len(m.data)
+	}
+	return m.data[offset : offset+end], offset + end + 1
+}
+
+func (m *MappedEmbeddings) indexText() error {
+	m.offsets = map[string]int{}
+	for offset, n := 0, 1; offset < len(m.data); n++ {
+		b, next := m.line(offset)
+		fields := strings.Fields(string(b))
+		if n == 1 {
+			if _, dims, ok := header(fields); ok {
+				m.dims = dims
+				offset = next
+				continue
+			}
+		}
+		if len(fields) > 0 {
+			if m.dims == 0 {
+				m.dims = len(fields) - 1
+			}
+			if len(fields) < m.dims+1 {
+				return fmt.Errorf("line %d: got %d values, expected %d", n, len(fields)-1, m.dims)
+			}
+			m.offsets[strings.Join(fields[:len(fields)-m.dims], " ")] = offset
+		}
+		offset = next
+	}
+	return nil
+}
+
+func (m *MappedEmbeddings) indexBinary() error {
+	b, offset := m.line(0)
+	n, dims, ok := header(strings.Fields(string(b)))
+	if !ok {
+		return fmt.Errorf("invalid header %q", strings.TrimSpace(string(b)))
+	}
+	m.dims = dims
+	m.offsets = make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		space := bytes.IndexByte(m.data[offset:], ' ')
+		if space < 0 || offset+space+1+4*dims > len(m.data) {
+			return fmt.Errorf("word %d: unexpected end of file", i)
+		}
+		word := strings.TrimSpace(string(m.data[offset : offset+space]))
+		offset += space + 1
+		m.offsets[word] = offset
+		offset += 4 * dims
+	}
+	return nil
+}
+
+// Dimensions returns length of the vectors
+func (m *MappedEmbeddings) Dimensions() int {
+	return m.dims
+}
+
+// Len returns number of words
+func (m *MappedEmbeddings) Len() int {
+	return len(m.offsets)
+}
+
+// Vector returns a copy of vector of word, false when
+// word is not in the file
+func (m *MappedEmbeddings) Vector(word string) ([]float64, bool) {
+	offset, ok := m.offsets[word]
+	if !ok || m.data == nil {
+		return nil, false
+	}
+	if m.format == Binary {
+		return decodeFloat32s(m.data[offset : offset+4*m.dims]), true
+	}
+	b, _ := m.line(offset)
+	_, values, err := parseLine(strings.Fields(string(b)), m.dims)
+	if err != nil {
+		return nil, false
+	}
+	return values, true
+}
+
+// Embed returns mean vector of tokens in the file,
+// zeros when none is
+func (m *MappedEmbeddings) Embed(tokens []string) []float64 {
+	return embed(m, tokens)
+}
+
+// Transform returns features of docs, averaged vectors of
+// their tokens by analyzer, for the classifiers of package ml
+func (m *MappedEmbeddings) Transform(analyzer *Analyzer, docs []string) [][]float64 {
+	return transform(m, analyzer, docs)
+}
+
+// Close unmaps the file, vectors read before
+// stay valid
+func (m *MappedEmbeddings) Close() error {
+	if m.close == nil {
+		return nil
+	}
+	err := m.close()
+	m.close, m.data = nil, nil
+	return err
+}
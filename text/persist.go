@@ -0,0 +1,61 @@
+package text
+
+import (
+	"encoding/gob"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// models are registered so they can be saved, their
+// Rand and the Templates of CRF are not saved
+func init() {
+	ml.RegisterModel("text.CRF", &CRF{})
+	ml.RegisterModel("text.LDA", &LDA{})
+	ml.RegisterModel("text.Vectorizer", &Vectorizer{})
+	ml.RegisterModel("text.Word2Vec", &Word2Vec{})
+
+	gob.RegisterName("text.Porter", Porter{})
+}
+
+// GobEncode encodes the model without Rand
+func (l *LDA) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(l)
+}
+
+// GobDecode decodes a model of GobEncode
+func (l *LDA) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(l, data)
+}
+
+// GobEncode encodes the model without Rand
+func (w *Word2Vec) GobEncode() ([]byte, error) {
+	return ml.GobEncodeModel(w)
+}
+
+// GobDecode decodes a model of GobEncode
+func (w *Word2Vec) GobDecode(data []byte) error {
+	return ml.GobDecodeModel(w, data)
+}
+
+// GobEncode encodes nothing, Porter has no state, but
+// gob cannot encode structs without exported fields
+func (Porter) GobEncode() ([]byte, error) {
+	return nil, nil
+}
+
+// GobDecode decodes a Porter of GobEncode
+func (*Porter) GobDecode([]byte) error {
+	return nil
+}
+
+// GobEncode encodes nothing, funcs cannot be saved,
+// but gob cannot encode slices of funcs either
+func (Templates) GobEncode() ([]byte, error) {
+	return nil, nil
+}
+
+// GobDecode decodes Templates of GobEncode, leaving
+// them empty
+func (*Templates) GobDecode([]byte) error {
+	return nil
+}
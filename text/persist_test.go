@@ -0,0 +1,95 @@
+package text
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/maxrafiandy/ml"
+)
+
+func roundTrip(t *testing.T, model interface{}) interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ml.Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ml.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSaveVectorizer(t *testing.T) {
+	analyzer, err := NewAnalyzer("english", Porter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVectorizer(analyzer, true)
+	docs := []string{"running dogs bark", "a dog runs", "cats sleep"}
+	if err := v.Fit(docs); err != nil {
+		t.Fatal(err)
+	}
+	loaded := roundTrip(t, v).(*Vectorizer)
+	if _, ok := loaded.Analyzer.Stemmer.(Porter); !ok {
+		t.Errorf("loaded stemmer %T, want Porter", loaded.Analyzer.Stemmer)
+	}
+	if !reflect.DeepEqual(loaded.Transform(docs), v.Transform(docs)) {
+		t.Error("loaded vectorizer transforms differently")
+	}
+}
+
+func TestSaveLDA(t *testing.T) {
+	lda := NewLDA(2)
+	lda.Iterations = 20
+	lda.Rand = rand.New(rand.NewSource(1))
+	if err := lda.Fit([][]float64{{3, 0, 1}, {0, 4, 1}, {2, 1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	loaded := roundTrip(t, lda).(*LDA)
+	if loaded.Rand != nil || !reflect.DeepEqual(loaded.TopicWords, lda.TopicWords) {
+		t.Errorf("loaded %+v, saved %+v", loaded, lda)
+	}
+}
+
+func TestSaveWord2Vec(t *testing.T) {
+	w := NewWord2Vec(4)
+	w.MinCount, w.Epochs = 1, 1
+	w.Rand = rand.New(rand.NewSource(1))
+	if err := w.Fit([][]string{{"the", "dog", "runs"}, {"the", "cat", "sleeps"}}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded := roundTrip(t, w).(*Word2Vec); loaded.Rand != nil || !reflect.DeepEqual(loaded.Embeddings, w.Embeddings) {
+		t.Errorf("loaded %+v, saved %+v", loaded.Embeddings, w.Embeddings)
+	}
+}
+
+func TestSaveCRF(t *testing.T) {
+	// CRF is saved without Templates, empty ones
+	// are DefaultTemplates
+	crf := NewCRF()
+	sentences := [][]string{{"the", "dog", "runs"}, {"a", "cat", "sleeps"}}
+	labels := [][]string{{"DET", "NOUN", "VERB"}, {"DET", "NOUN", "VERB"}}
+	if err := crf.Fit(sentences, labels); err != nil {
+		t.Fatal(err)
+	}
+	tokens := []string{"the", "cat", "runs"}
+	tags, score := crf.Predict(tokens)
+	loaded := roundTrip(t, crf).(*CRF)
+	if got, s := loaded.Predict(tokens); loaded.Templates != nil || !reflect.DeepEqual(got, tags) || s != score {
+		t.Errorf("loaded CRF tags %v of %v, fitted %v of %v", got, s, tags, score)
+	}
+
+	// custom Templates are set again after Load
+	custom := &CRF{Templates: []Template{WordTemplate(0), SuffixTemplate(2)}}
+	if err := custom.Fit(sentences, labels); err != nil {
+		t.Fatal(err)
+	}
+	loaded = roundTrip(t, custom).(*CRF)
+	loaded.Templates = custom.Templates
+	if got, want := loaded.Marginals(tokens), custom.Marginals(tokens); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded CRF marginals %v, fitted %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package text
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stopWords are lists of common words of every language
+var stopWords = map[string]string{
+	"english": `a about above after again against all am an and any are as at be because been
+before being below between both but by can could did do does doing down during each few
+for from further had has have having he her here hers herself him himself his how i if in
+into is it its itself just me more most my myself no nor not now of off on once only or
+other our ours ourselves out over own same she should so some such than that the their
+theirs them themselves then there these they this those through to too under until up
+very was we were what when where which while who whom why will with would you your yours
+yourself yourselves`,
+	"indonesian": `ada adalah agar akan aku anda antara apa apabila atau bagaimana bagi bahwa
+banyak belum bisa boleh dalam dan dapat dari daripada dengan di dia ia ialah ini itu jadi
+jika juga kalau kami kamu karena ke kepada ketika kita lagi lain lalu maka mana masih
+mereka meski namun oleh pada para pun saat saja sampai sangat saya se sebagai sebelum
+sedang sehingga sejak sekarang seperti serta setelah sudah supaya tanpa telah tentang
+tetapi tidak untuk walau yaitu yakni yang`,
+	"spanish": `a al algo algunos ante antes como con contra cual cuando de del desde donde
+durante e el ella ellas ellos en entre era es esa ese eso esta estaba estar este esto
+estos fue ha han hasta hay la las le les lo los mas me mi mucho muy nada ni no nos o os
+otra otro para pero poco por porque que quien se sea ser si sin sobre son su sus también
+te tiene todo tu un una uno unos y ya yo`,
+	"french": `à au aux avec ce ces cette dans de des du elle en est et eux il ils je la le
+les leur lui ma mais me même mes moi mon ne nos notre nous on ou où par pas pour qu que
+qui sa se ses son sur ta te tes toi ton tu un une vos votre vous y été être avoir a ont
+sont était`,
+	"german": `aber alle als also am an auch auf aus bei bin bis bist da damit dann das dass
+dein dem den der des dich die dir doch du durch ein eine einem einen einer eines er es
+für hat hatte ich ihr im in ist ja jede kann kein mich mir mit muss nach nicht noch nur
+oder ohne sein sich sie sind so über um und uns unter von vor war was weil wenn wer wie
+wir wird zu zum zur`,
+}
+
+// StopWords returns stop words of language, one of
+// Languages, as a set for Analyzer
+func StopWords(language string) (map[string]bool, error) {
+	list, ok := stopWords[strings.ToLower(language)]
+	if !ok {
+		return nil, fmt.Errorf("text: no stop words of %q, languages are %v", language, Languages())
+	}
+	words := map[string]bool{}
+	for _, w := range strings.Fields(list) {
+		words[w] = true
+	}
+	return words, nil
+}
+
+// Languages returns languages of StopWords, sorted
+func Languages() []string {
+	var languages []string
+	for l := range stopWords {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+	return languages
+}
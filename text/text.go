@@ -0,0 +1,117 @@
+// Package text turns documents into tokens and features for
+// the models of package ml: tokenization, lowercasing, stop
+// word removal, stemming and n-grams by an Analyzer, and
+// count or TF-IDF features of tokens by a Vectorizer.
+package text
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultPattern matches runs of letters and digits
+const DefaultPattern = `[\p{L}\p{N}]+`
+
+// Analyzer turns a document into tokens: it finds tokens
+// by Pattern, lowercases them, drops StopWords, stems the
+// rest and joins them into n-grams of MinN to MaxN tokens
+type Analyzer struct {
+	// Pattern matches tokens, defaults to DefaultPattern
+	Pattern   string
+	Lowercase bool
+	// StopWords are dropped, after lowercasing
+	StopWords map[string]bool
+	// Stemmer, when set, stems tokens
+	Stemmer Stemmer
+	// MinN and MaxN are the sizes of n-grams,
+	// both default to 1, single tokens
+	MinN int
+	MaxN int
+
+	pattern *regexp.Regexp
+}
+
+// NewAnalyzer returns new pointer of Analyzer of lowercased
+// tokens of DefaultPattern without stop words of language
+// and stems of stemmer, which may be nil
+func NewAnalyzer(language string, stemmer Stemmer) (*Analyzer, error) {
+	a := &Analyzer{Lowercase: true, Stemmer: stemmer}
+	if language != "" {
+		words, err := StopWords(language)
+		if err != nil {
+			return nil, err
+		}
+		a.StopWords = words
+	}
+	return a, a.Compile()
+}
+
+// Tokenize returns tokens of doc matching Pattern,
+// lowercased when Lowercase is set
+func (a *Analyzer) Tokenize(doc string) []string {
+	pattern := a.pattern
+	if pattern == nil || pattern.String() != a.patternString() {
+		pattern = regexp.MustCompile(a.patternString())
+	}
+	if a.Lowercase {
+		doc = strings.ToLower(doc)
+	}
+	return pattern.FindAllString(doc, -1)
+}
+
+// Compile compiles Pattern, returning an error when
+// it is invalid. Tokenize panics on invalid patterns.
+func (a *Analyzer) Compile() error {
+	pattern, err := regexp.Compile(a.patternString())
+	if err != nil {
+		return fmt.Errorf("text: token pattern: %v", err)
+	}
+	a.pattern = pattern
+	return nil
+}
+
+func (a *Analyzer) patternString() string {
+	if a.Pattern == "" {
+		return DefaultPattern
+	}
+	return a.Pattern
+}
+
+// Analyze returns n-grams of tokens of doc
+// without stop words, stemmed
+func (a *Analyzer) Analyze(doc string) []string {
+	var tokens []string
+	for _, t := range a.Tokenize(doc) {
+		if a.StopWords[t] {
+			continue
+		}
+		if a.Stemmer != nil {
+			t = a.Stemmer.Stem(t)
+		}
+		tokens = append(tokens, t)
+	}
+	minN, maxN := a.MinN, a.MaxN
+	if minN <= 0 {
+		minN = 1
+	}
+	if maxN < minN {
+		maxN = minN
+	}
+	return NGrams(tokens, minN, maxN)
+}
+
+// NGrams returns every run of minN to maxN consecutive
+// tokens joined by a space, shorter n-grams first
+func NGrams(tokens []string, minN, maxN int) []string {
+	if minN == 1 && maxN == 1 {
+		return tokens
+	}
+	var grams []string
+	for n := minN; n <= maxN; n++ {
+		for i := 0; i+n <= len(tokens); i++ {
+			grams = append(grams, strings.Join(tokens[i:i+n], " "))
+		}
+	}
+	return grams
+}
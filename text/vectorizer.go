@@ -0,0 +1,136 @@
+package text
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Vectorizer turns documents into features of counts of
+// their tokens, or TF-IDF weights of them, one column per
+// token of Vocabulary, for the models of package ml
+type Vectorizer struct {
+	Analyzer *Analyzer
+	// MinDF drops tokens of fewer documents
+	MinDF int
+	// MaxFeatures, when set, keeps the tokens
+	// of the most documents
+	MaxFeatures int
+	// TFIDF weights counts by the smoothed inverse document
+	// frequency ln((1+n)/(1+df))+1 and scales every row to
+	// unit length
+	TFIDF bool
+
+	// Vocabulary maps tokens to columns,
+	// columns are tokens in sorted order
+	Vocabulary map[string]int
+	// IDF of every column, when TFIDF is set
+	IDF []float64
+}
+
+// NewVectorizer returns new pointer of Vectorizer
+// of tokens of analyzer
+func NewVectorizer(analyzer *Analyzer, tfidf bool) *Vectorizer {
+	return &Vectorizer{Analyzer: analyzer, MinDF: 1, TFIDF: tfidf}
+}
+
+// Fit learns the vocabulary of docs
+func (v *Vectorizer) Fit(docs []string) error {
+	if len(docs) == 0 {
+		return fmt.Errorf("text: cannot fit empty documents")
+	}
+	if v.Analyzer == nil {
+		v.Analyzer = &Analyzer{Lowercase: true}
+	}
+	if err := v.Analyzer.Compile(); err != nil {
+		return err
+	}
+
+	df := map[string]int{}
+	for _, doc := range docs {
+		seen := map[string]bool{}
+		for _, t := range v.Analyzer.Analyze(doc) {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+	var tokens []string
+	for t, n := range df {
+		if n >= v.MinDF {
+			tokens = append(tokens, t)
+		}
+	}
+	if v.MaxFeatures > 0 && len(tokens) > v.MaxFeatures {
+		sort.Slice(tokens, func(a, b int) bool {
+			if df[tokens[a]] != df[tokens[b]] {
+				return df[tokens[a]] > df[tokens[b]]
+			}
+			return tokens[a] < tokens[b]
+		})
+		tokens = tokens[:v.MaxFeatures]
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("text: no token is left of documents")
+	}
+	sort.Strings(tokens)
+
+	v.Vocabulary = make(map[string]int, len(tokens))
+	v.IDF = nil
+	n := float64(len(docs))
+	for j, t := range tokens {
+		v.Vocabulary[t] = j
+		if v.TFIDF {
+			v.IDF = append(v.IDF, math.Log((1+n)/(1+float64(df[t])))+1)
+		}
+	}
+	return nil
+}
+
+// Transform returns features of docs, tokens
+// not in Vocabulary are ignored
+func (v *Vectorizer) Transform(docs []string) [][]float64 {
+	features := make([][]float64, len(docs))
+	for i, doc := range docs {
+		row := make([]float64, len(v.Vocabulary))
+		for _, t := range v.Analyzer.Analyze(doc) {
+			if j, ok := v.Vocabulary[t]; ok {
+				row[j]++
+			}
+		}
+		if v.TFIDF {
+			norm := 0.0
+			for j := range row {
+				row[j] *= v.IDF[j]
+				norm += row[j] * row[j]
+			}
+			if norm > 0 {
+				norm = math.Sqrt(norm)
+				for j := range row {
+					row[j] /= norm
+				}
+			}
+		}
+		features[i] = row
+	}
+	return features
+}
+
+// FitTransform learns the vocabulary of docs
+// and returns their features
+func (v *Vectorizer) FitTransform(docs []string) ([][]float64, error) {
+	if err := v.Fit(docs); err != nil {
+		return nil, err
+	}
+	return v.Transform(docs), nil
+}
+
+// Tokens returns tokens of every column
+func (v *Vectorizer) Tokens() []string {
+	tokens := make([]string, len(v.Vocabulary))
+	for t, j := range v.Vocabulary {
+		tokens[j] = t
+	}
+	return tokens
+}
@@ -0,0 +1,196 @@
+package text
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/maxrafiandy/ml"
+)
+
+// Word2Vec trains word vectors by skip-gram with negative
+// sampling (Mikolov et al., 2013): every word predicts the
+// words within Window of it against Negative words drawn by
+// frequency to the power 0.75
+type Word2Vec struct {
+	// Dimensions of vectors, defaults to 100
+	Dimensions int
+	// Window defaults to 5, each word sees a random
+	// window of 1 to Window words on both sides
+	Window int
+	// Negative samples per pair, defaults to 5
+	Negative int
+	// MinCount drops words of fewer occurrences,
+	// defaults to 5
+	MinCount int
+	// Epochs defaults to 5
+	Epochs int
+	// LearningRate decays linearly from it to near zero,
+	// defaults to 0.025
+	LearningRate float64
+	// Subsample drops occurrences of frequent words, of
+	// frequency f, with probability 1-√(t/f)-t/f,
+	// zero keeps all
+	Subsample float64
+	// Rand draws the initial vectors, negative samples
+	// and subsampling, nil uses the global source
+	Rand *rand.Rand
+
+	Embeddings *Embeddings
+}
+
+// NewWord2Vec returns new pointer of Word2Vec
+// of vectors of dimensions
+func NewWord2Vec(dimensions int) *Word2Vec {
+	return &Word2Vec{
+		Dimensions:   dimensions,
+		Window:       5,
+		Negative:     5,
+		MinCount:     5,
+		Epochs:       5,
+		LearningRate: 0.025,
+		Subsample:    1e-3,
+	}
+}
+
+// Fit trains vectors of words of sentences,
+// tokens such as of Analyzer.Analyze
+func (w *Word2Vec) Fit(sentences [][]string) error {
+	dims := defaultInt(w.Dimensions, 100)
+	window := defaultInt(w.Window, 5)
+	negative := defaultInt(w.Negative, 5)
+	minCount := defaultInt(w.MinCount, 5)
+	epochs := defaultInt(w.Epochs, 5)
+	rate := w.LearningRate
+	if rate <= 0 {
+		rate = 0.025
+	}
+	rng := ml.RandOf(w.Rand)
+
+	counts := map[string]int{}
+	for _, s := range sentences {
+		for _, t := range s {
+			counts[t]++
+		}
+	}
+	var words []string
+	for t, c := range counts {
+		if c >= minCount {
+			words = append(words, t)
+		}
+	}
+	if len(words) < 2 {
+		return fmt.Errorf("text: word2vec needs at least 2 words of %d occurrences, got %d", minCount, len(words))
+	}
+	sort.Slice(words, func(a, b int) bool {
+		if counts[words[a]] != counts[words[b]] {
+			return counts[words[a]] > counts[words[b]]
+		}
+		return words[a] < words[b]
+	})
+	index := make(map[string]int, len(words))
+	total := 0
+	for i, t := range words {
+		index[t] = i
+		total += counts[t]
+	}
+
+	// keep[i] is the probability of keeping an
+	// occurrence of word i
+	keep := make([]float64, len(words))
+	for i, t := range words {
+		keep[i] = 1
+		if w.Subsample > 0 {
+			f := float64(counts[t]) / float64(total) / w.Subsample
+			keep[i] = math.Min(1, math.Sqrt(1/f)+1/f)
+		}
+	}
+	// cumulative unigram^0.75 of negative sampling
+	noise := make([]float64, len(words))
+	sum := 0.0
+	for i, t := range words {
+		sum += math.Pow(float64(counts[t]), 0.75)
+		noise[i] = sum
+	}
+	drawNoise := func() int {
+		return sort.SearchFloat64s(noise, rng.Float64()*sum)
+	}
+
+	input := make([][]float64, len(words))
+	output := make([][]float64, len(words))
+	for i := range input {
+		input[i] = make([]float64, dims)
+		for d := range input[i] {
+			input[i][d] = (rng.Float64() - 0.5) / float64(dims)
+		}
+		output[i] = make([]float64, dims)
+	}
+
+	steps := float64(epochs * total)
+	done := 0
+	grad := make([]float64, dims)
+	ids := make([]int, 0, 64)
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, s := range sentences {
+			ids = ids[:0]
+			for _, t := range s {
+				i, ok := index[t]
+				if !ok {
+					continue
+				}
+				done++
+				if keep[i] < 1 && rng.Float64() > keep[i] {
+					continue
+				}
+				ids = append(ids, i)
+			}
+			alpha := math.Max(rate*(1-float64(done)/steps), rate*1e-4)
+			for pos, center := range ids {
+				b := 1 + rng.Intn(window)
+				for c := pos - b; c <= pos+b; c++ {
+					if c < 0 || c >= len(ids) || c == pos {
+						continue
+					}
+					// the context word predicts the center word,
+					// as in the reference implementation
+					v := input[ids[c]]
+					for d := range grad {
+						grad[d] = 0
+					}
+					for k := 0; k <= negative; k++ {
+						target, label := center, 1.0
+						if k > 0 {
+							target, label = drawNoise(), 0
+							if target == center {
+								continue
+							}
+						}
+						u := output[target]
+						dot := 0.0
+						for d := range v {
+							dot += v[d] * u[d]
+						}
+						g := alpha * (label - sigmoid(dot))
+						for d := range v {
+							grad[d] += g * u[d]
+							u[d] += g * v[d]
+						}
+					}
+					for d := range v {
+						v[d] += grad[d]
+					}
+				}
+			}
+		}
+	}
+	w.Embeddings = NewEmbeddings(words, input)
+	return nil
+}
+
+func defaultInt(v, d int) int {
+	if v <= 0 {
+		return d
+	}
+	return v
+}
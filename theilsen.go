@@ -0,0 +1,210 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// TheilSen is a robust linear regression for small datasets
+// with outliers. With one varying column and a bias column
+// the slope is the median of slopes between pairs of rows
+// and the bias the median of y - slope·x. Otherwise thetas
+// are the spatial median of exact fits on subsets of as many
+// rows as columns.
+type TheilSen struct {
+	// MaxSubpopulation limits the pairs or subsets, random
+	// ones are drawn when there are more, defaults to 10000
+	MaxSubpopulation int
+	// Rand draws the pairs and subsets, nil uses the global
+	// source. It is not saved.
+	Rand *rand.Rand
+
+	Theta []float64
+}
+
+// NewTheilSen returns new pointer of TheilSen
+func NewTheilSen() *TheilSen {
+	return &TheilSen{MaxSubpopulation: 10000}
+}
+
+// Fit trains the model on features and output
+func (t *TheilSen) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	n := len(features)
+	if n < p {
+		return fmt.Errorf("ml: Theil-Sen needs at least %d rows, got %d", p, n)
+	}
+	limit := t.MaxSubpopulation
+	if limit <= 0 {
+		limit = 10000
+	}
+	rng := RandOf(t.Rand)
+
+	var varying, constant []int
+	for j := 0; j < p; j++ {
+		if stat.Variance(column(features, j), nil) > 0 {
+			varying = append(varying, j)
+		} else if features[0][j] != 0 {
+			constant = append(constant, j)
+		}
+	}
+	if len(varying) == 1 && len(constant) == 1 {
+		t.Theta = t.pairwise(column(features, varying[0]), output, varying[0], constant[0], p, limit, rng)
+		t.Theta[constant[0]] /= features[0][constant[0]]
+		return nil
+	}
+
+	// all subsets when they are few, random ones otherwise
+	var subsets [][]int
+	if combinations(n, p) <= float64(limit) {
+		subsets = allSubsets(n, p)
+	} else {
+		for k := 0; k < limit; k++ {
+			subsets = append(subsets, rng.Perm(n)[:p])
+		}
+	}
+	var fits [][]float64
+	for _, idx := range subsets {
+		X, y := subset(features, output, idx)
+		var theta mat.VecDense
+		if err := theta.SolveVec(denseOf(X), mat.NewVecDense(p, y)); err != nil {
+			// singular subsets have no exact fit
+			continue
+		}
+		fits = append(fits, theta.RawVector().Data)
+	}
+	if len(fits) == 0 {
+		return fmt.Errorf("ml: Theil-Sen found no subset of full rank")
+	}
+	t.Theta = spatialMedian(fits)
+	return nil
+}
+
+// pairwise returns thetas of the median slope of pairs of x
+// and y, and the median intercept for the bias column
+func (t *TheilSen) pairwise(x, y []float64, slope, bias, p, limit int, rng *rand.Rand) []float64 {
+	n := len(x)
+	var slopes []float64
+	add := func(i, k int) {
+		if x[i] != x[k] {
+			slopes = append(slopes, (y[k]-y[i])/(x[k]-x[i]))
+		}
+	}
+	if combinations(n, 2) <= float64(limit) {
+		for i := 0; i < n; i++ {
+			for k := i + 1; k < n; k++ {
+				add(i, k)
+			}
+		}
+	} else {
+		for len(slopes) < limit {
+			add(rng.Intn(n), rng.Intn(n))
+		}
+	}
+	sort.Float64s(slopes)
+
+	theta := make([]float64, p)
+	theta[slope] = quantile(0.5, slopes)
+	intercepts := make([]float64, n)
+	for i := range x {
+		intercepts[i] = y[i] - theta[slope]*x[i]
+	}
+	sort.Float64s(intercepts)
+	theta[bias] = quantile(0.5, intercepts)
+	return theta
+}
+
+// combinations returns n choose k
+func combinations(n, k int) float64 {
+	c := 1.0
+	for i := 0; i < k; i++ {
+		c = c * float64(n-i) / float64(i+1)
+	}
+	return c
+}
+
+// allSubsets returns every k of n rows
+func allSubsets(n, k int) [][]int {
+	var subsets [][]int
+	idx := make([]int, k)
+	var walk func(at, from int)
+	walk = func(at, from int) {
+		if at == k {
+			subsets = append(subsets, append([]int(nil), idx...))
+			return
+		}
+		for i := from; i <= n-k+at; i++ {
+			idx[at] = i
+			walk(at+1, i+1)
+		}
+	}
+	walk(0, 0)
+	return subsets
+}
+
+// spatialMedian returns the point of least summed
+// distances to points, by Weiszfeld's iterations
+func spatialMedian(points [][]float64) []float64 {
+	dim := len(points[0])
+	median := make([]float64, dim)
+	for _, q := range points {
+		floats.Add(median, q)
+	}
+	floats.Scale(1/float64(len(points)), median)
+
+	next := make([]float64, dim)
+	for it := 0; it < 300; it++ {
+		for j := range next {
+			next[j] = 0
+		}
+		weights := 0.0
+		for _, q := range points {
+			d := floats.Distance(q, median, 2)
+			if d < 1e-12 {
+				// points at the median pull nowhere
+				continue
+			}
+			floats.AddScaled(next, 1/d, q)
+			weights += 1 / d
+		}
+		if weights == 0 {
+			break
+		}
+		floats.Scale(1/weights, next)
+		moved := floats.Distance(next, median, 2)
+		copy(median, next)
+		if moved <= 1e-10*math.Max(1, floats.Norm(median, 2)) {
+			break
+		}
+	}
+	return median
+}
+
+// Estimate returns predicted value of X
+func (t *TheilSen) Estimate(X []float64) float64 {
+	return floats.Dot(X, t.Theta)
+}
+
+// Coefficients returns thetas
+func (t *TheilSen) Coefficients() []float64 {
+	return t.Theta
+}
+
+// GobEncode encodes the model without Rand
+func (t *TheilSen) GobEncode() ([]byte, error) {
+	return GobEncodeModel(t)
+}
+
+// GobDecode decodes a model of GobEncode
+func (t *TheilSen) GobDecode(data []byte) error {
+	return GobDecodeModel(t, data)
+}
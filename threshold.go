@@ -0,0 +1,125 @@
+package ml
+
+import (
+	"math"
+	"sort"
+)
+
+// Confusion holds counts of a binary confusion matrix
+type Confusion struct {
+	TruePositive  float64
+	FalsePositive float64
+	TrueNegative  float64
+	FalseNegative float64
+}
+
+// NewConfusion counts scores at least threshold as
+// true predictions against binary output
+func NewConfusion(output, scores []float64, threshold float64) Confusion {
+	var c Confusion
+	for i, y := range output {
+		switch positive := scores[i] >= threshold; {
+		case positive && y == 1:
+			c.TruePositive++
+		case positive:
+			c.FalsePositive++
+		case y == 1:
+			c.FalseNegative++
+		default:
+			c.TrueNegative++
+		}
+	}
+	return c
+}
+
+// ThresholdMetric scores a confusion matrix, higher is better
+type ThresholdMetric func(c Confusion) float64
+
+// F1 returns harmonic mean of precision and recall
+func F1(c Confusion) float64 {
+	d := 2*c.TruePositive + c.FalsePositive + c.FalseNegative
+	if d == 0 {
+		return 0
+	}
+	return 2 * c.TruePositive / d
+}
+
+// YoudenJ returns sensitivity + specificity - 1
+func YoudenJ(c Confusion) float64 {
+	sensitivity, specificity := 0.0, 0.0
+	if p := c.TruePositive + c.FalseNegative; p > 0 {
+		sensitivity = c.TruePositive / p
+	}
+	if n := c.TrueNegative + c.FalsePositive; n > 0 {
+		specificity = c.TrueNegative / n
+	}
+	return sensitivity + specificity - 1
+}
+
+// CostMetric returns a ThresholdMetric of negated expected
+// cost per row, given the cost of a false positive and of
+// a false negative
+func CostMetric(falsePositive, falseNegative float64) ThresholdMetric {
+	return func(c Confusion) float64 {
+		n := c.TruePositive + c.FalsePositive + c.TrueNegative + c.FalseNegative
+		return -(falsePositive*c.FalsePositive + falseNegative*c.FalseNegative) / n
+	}
+}
+
+// OptimizeThreshold sweeps every distinct score as the
+// threshold predicting true and returns the one where
+// metric is the highest, with its score
+func OptimizeThreshold(output, scores []float64, metric ThresholdMetric) (threshold, score float64) {
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+
+	// start above every score, predicting all false
+	var c Confusion
+	for _, y := range output {
+		if y == 1 {
+			c.FalseNegative++
+		} else {
+			c.TrueNegative++
+		}
+	}
+
+	threshold = math.Inf(1)
+	if len(idx) > 0 {
+		threshold = math.Nextafter(scores[idx[0]], math.Inf(1))
+	}
+	score = metric(c)
+
+	for k := 0; k < len(idx); {
+		t := scores[idx[k]]
+		for ; k < len(idx) && scores[idx[k]] == t; k++ {
+			if output[idx[k]] == 1 {
+				c.FalseNegative--
+				c.TruePositive++
+			} else {
+				c.TrueNegative--
+				c.FalsePositive++
+			}
+		}
+
+		if s := metric(c); s > score {
+			threshold, score = t, s
+		}
+	}
+
+	return threshold, score
+}
+
+// TuneThreshold sets TrueDegree to the probability threshold
+// maximizing metric on validation features and output, and
+// returns the metric score
+func (l *LogisticRegression) TuneThreshold(features [][]float64, output []float64, metric ThresholdMetric) float64 {
+	threshold, score := OptimizeThreshold(output, estimateAll(l, features), metric)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.TrueDegree = threshold
+	l.trueDegreeSet = true
+	return score
+}
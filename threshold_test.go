@@ -0,0 +1,88 @@
+package ml
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestOptimizeThreshold(t *testing.T) {
+	output := []float64{0, 0, 1, 0, 1, 1}
+	scores := []float64{0.1, 0.3, 0.4, 0.5, 0.8, 0.9}
+
+	// at 0.4 one false positive is left, F1 = 6/7
+	threshold, score := OptimizeThreshold(output, scores, F1)
+	if threshold != 0.4 || math.Abs(score-6.0/7) > 1e-12 {
+		t.Errorf("threshold %v of F1 %v, want 0.4 of %v", threshold, score, 6.0/7)
+	}
+	// a false positive costs 10, so the true row
+	// of 0.4 is left false
+	threshold, _ = OptimizeThreshold(output, scores, CostMetric(10, 1))
+	if threshold != 0.8 {
+		t.Errorf("threshold %v of costly false positives, want 0.8", threshold)
+	}
+}
+
+func TestDecisionThresholdOfZero(t *testing.T) {
+	// a struct literal falls back to 0.5
+	var literal LogisticRegression
+	if got := literal.DecisionThreshold(); got != 0.5 {
+		t.Errorf("threshold %v of a zero TrueDegree never set, want 0.5", got)
+	}
+
+	// sigmoid of -800 underflows to 0, so the only
+	// threshold predicting the true row is 0
+	model := NewLogisticRegression()
+	model.Theta = []float64{0, 1}
+	features := [][]float64{{1, -800}, {1, -900}}
+	model.TuneThreshold(features, []float64{1, 1}, F1)
+	if got := model.DecisionThreshold(); got != 0 {
+		t.Fatalf("tuned threshold %v, want 0", got)
+	}
+	if !model.Predict(features[0]) {
+		t.Error("threshold of 0 predicts false")
+	}
+
+	var buf bytes.Buffer
+	if err := Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	v, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.(*LogisticRegression).DecisionThreshold(); got != 0 {
+		t.Errorf("loaded threshold %v, want 0", got)
+	}
+	if got := model.Clone().(*LogisticRegression).DecisionThreshold(); got != 0 {
+		t.Errorf("cloned threshold %v, want 0", got)
+	}
+}
+
+// TestTuneThresholdConcurrent runs under go test -race
+func TestTuneThresholdConcurrent(t *testing.T) {
+	features, output := lineData(50, 1)
+	for i := range output {
+		output[i] = float64(i % 2)
+	}
+	model := NewLogisticRegression()
+	model.Theta = []float64{0, 1}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if g == 0 {
+					model.TuneThreshold(features, output, YoudenJ)
+				} else {
+					model.Predict(features[i])
+					model.DecisionThreshold()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,87 @@
+package ml
+
+import "fmt"
+
+// ExpandingWindow splits time ordered rows into K folds
+// whose test rows are consecutive blocks at the end of the
+// series and whose train rows are all rows before them, so
+// no fold trains on the future
+type ExpandingWindow struct {
+	K int
+	// TestSize is the number of test rows of every
+	// fold, zero uses n/(K+1)
+	TestSize int
+	// Gap rows are left out between train and test
+	// rows, to avoid leaking through lag features
+	Gap int
+}
+
+// Split returns K folds of n time ordered rows,
+// folds without train rows are left out
+func (e ExpandingWindow) Split(n int) ([]Fold, error) {
+	return windowFolds(n, e.K, e.TestSize, e.Gap, 0)
+}
+
+// SlidingWindow is like ExpandingWindow but trains on at
+// most TrainSize rows right before the test rows, so old
+// behaviour of the series is forgotten
+type SlidingWindow struct {
+	K         int
+	TrainSize int
+	TestSize  int
+	Gap       int
+}
+
+// Split returns K folds of n time ordered rows,
+// folds without train rows are left out
+func (s SlidingWindow) Split(n int) ([]Fold, error) {
+	return windowFolds(n, s.K, s.TestSize, s.Gap, s.TrainSize)
+}
+
+// windowFolds returns folds with test blocks at the end of
+// n rows and train rows ending gap rows before them, at
+// most trainSize of them when it is positive
+func windowFolds(n, k, testSize, gap, trainSize int) ([]Fold, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("ml: need at least 1 fold, got %d", k)
+	}
+	if testSize <= 0 {
+		testSize = n / (k + 1)
+	}
+
+	var folds []Fold
+	for f := 0; f < k; f++ {
+		start := n - (k-f)*testSize
+		end := start + testSize
+		if start < 0 || testSize == 0 {
+			continue
+		}
+
+		trainEnd := start - gap
+		trainStart := 0
+		if trainSize > 0 && trainEnd-trainSize > 0 {
+			trainStart = trainEnd - trainSize
+		}
+		if trainEnd <= trainStart {
+			continue
+		}
+
+		folds = append(folds, Fold{
+			Train: indexRange(trainStart, trainEnd),
+			Test:  indexRange(start, end),
+		})
+	}
+	if len(folds) == 0 {
+		return nil, fmt.Errorf("ml: no fold of %d rows has train rows", n)
+	}
+
+	return folds, nil
+}
+
+func indexRange(start, end int) []int {
+	idx := make([]int, end-start)
+	for i := range idx {
+		idx[i] = start + i
+	}
+	return idx
+}
@@ -0,0 +1,120 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// TotalLeastSquares is an errors-in-variables regression
+// for features measured with noise, e.g. comparing two
+// instruments in calibration, where least squares biases
+// slopes towards zero. It minimizes orthogonal distances
+// to the fitted hyperplane after scaling the output by
+// 1/√Delta. With one feature it is Deming regression.
+//
+// The intercept goes to the theta of the first constant
+// column, such as the bias column; without one the
+// hyperplane passes through the origin.
+type TotalLeastSquares struct {
+	// Delta is the ratio of variance of output errors to
+	// variance of feature errors, defaults to 1, which is
+	// orthogonal regression
+	Delta float64
+	Theta []float64
+}
+
+// NewTotalLeastSquares returns new pointer of
+// TotalLeastSquares of orthogonal regression
+func NewTotalLeastSquares() *TotalLeastSquares {
+	return &TotalLeastSquares{Delta: 1}
+}
+
+// NewDeming returns new pointer of TotalLeastSquares
+// with delta, the ratio of variance of output errors
+// to variance of feature errors
+func NewDeming(delta float64) *TotalLeastSquares {
+	return &TotalLeastSquares{Delta: delta}
+}
+
+// Fit trains the model on features and output
+func (t *TotalLeastSquares) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	delta := t.Delta
+	if delta == 0 {
+		delta = 1
+	}
+	if !(delta > 0) {
+		return fmt.Errorf("ml: delta %v should be positive", t.Delta)
+	}
+
+	var varying []int
+	bias := -1
+	for j := 0; j < p; j++ {
+		if stat.Variance(column(features, j), nil) > 0 {
+			varying = append(varying, j)
+		} else if bias < 0 && features[0][j] != 0 {
+			bias = j
+		}
+	}
+	if len(varying) == 0 {
+		return fmt.Errorf("ml: features have no varying columns")
+	}
+
+	// center when there is an intercept
+	k := len(varying)
+	means := make([]float64, k+1)
+	if bias >= 0 {
+		for c, j := range varying {
+			means[c] = stat.Mean(column(features, j), nil)
+		}
+		means[k] = stat.Mean(output, nil)
+	}
+	scale := math.Sqrt(delta)
+	Z := mat.NewDense(len(features), k+1, nil)
+	for i, x := range features {
+		for c, j := range varying {
+			Z.Set(i, c, x[j]-means[c])
+		}
+		Z.Set(i, k, (output[i]-means[k])/scale)
+	}
+
+	var svd mat.SVD
+	if !svd.Factorize(Z, mat.SVDThin) {
+		return fmt.Errorf("ml: SVD of features and output failed")
+	}
+	var V mat.Dense
+	svd.VTo(&V)
+	// right singular vector of the smallest singular value
+	last := V.ColView(k)
+	if math.Abs(last.AtVec(k)) < 1e-12 {
+		return fmt.Errorf("ml: output is orthogonal to features, no finite fit")
+	}
+
+	t.Theta = make([]float64, p)
+	intercept := means[k]
+	for c, j := range varying {
+		t.Theta[j] = -last.AtVec(c) / last.AtVec(k) * scale
+		intercept -= t.Theta[j] * means[c]
+	}
+	if bias >= 0 {
+		t.Theta[bias] = intercept / features[0][bias]
+	}
+	return nil
+}
+
+// Estimate returns predicted value of X
+func (t *TotalLeastSquares) Estimate(X []float64) float64 {
+	return floats.Dot(X, t.Theta)
+}
+
+// Coefficients returns thetas
+func (t *TotalLeastSquares) Coefficients() []float64 {
+	return t.Theta
+}
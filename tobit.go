@@ -0,0 +1,207 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Tobit is a censored linear regression, for outputs such
+// as spending capped at a reporting limit. The latent
+// output θ·x + ε, ε ~ N(0, σ²), is observed as Lower when
+// at or below it and as Upper when at or above it. Thetas
+// and Sigma maximize the likelihood together.
+type Tobit struct {
+	// Lower and Upper are the censoring limits,
+	// infinite limits are no censoring
+	Lower float64
+	Upper float64
+
+	Theta []float64
+	Sigma float64
+	// StdErrors of thetas and SigmaStdErr come from
+	// the observed information of the likelihood
+	StdErrors   []float64
+	SigmaStdErr float64
+
+	loglik     float64
+	samples    int
+	covariance [][]float64
+}
+
+// NewTobit returns new pointer of Tobit censored
+// at lower and upper
+func NewTobit(lower, upper float64) *Tobit {
+	return &Tobit{Lower: lower, Upper: upper}
+}
+
+// Fit trains the model on features and output
+func (t *Tobit) Fit(features [][]float64, output []float64) error {
+	p, err := checkRows(features, output)
+	if err != nil {
+		return err
+	}
+	if !(t.Lower < t.Upper) {
+		return fmt.Errorf("ml: lower limit %v is not below upper limit %v", t.Lower, t.Upper)
+	}
+	for i, y := range output {
+		if y < t.Lower || y > t.Upper {
+			return fmt.Errorf("ml: output %d is %v, outside limits %v and %v", i, y, t.Lower, t.Upper)
+		}
+	}
+
+	// start from least squares ignoring censoring
+	init, err := leastSquares(features, output)
+	if err != nil {
+		return err
+	}
+	residuals := make([]float64, len(output))
+	for i, x := range features {
+		residuals[i] = output[i] - floats.Dot(x, init)
+	}
+	init = append(init, math.Log(math.Max(stat.StdDev(residuals, nil), 1e-8)))
+
+	// parameters are thetas and log Sigma
+	ll := func(x, grad []float64) float64 {
+		return t.likelihood(features, output, x, grad)
+	}
+	x, err := maximizeLikelihood(ll, init, len(output))
+	if err != nil {
+		return fmt.Errorf("ml: tobit: %v", err)
+	}
+	t.Theta = append([]float64(nil), x[:p]...)
+	t.Sigma = math.Exp(x[p])
+	t.loglik = ll(x, nil)
+	t.samples = len(output)
+
+	cov, err := observedCovariance(ll, x)
+	if err != nil {
+		t.covariance = nil
+		t.StdErrors, t.SigmaStdErr = nil, math.NaN()
+		return nil
+	}
+	t.covariance = make([][]float64, p)
+	t.StdErrors = make([]float64, p)
+	for j := 0; j < p; j++ {
+		t.covariance[j] = cov[j][:p]
+		t.StdErrors[j] = math.Sqrt(cov[j][j])
+	}
+	// delta method from log Sigma
+	t.SigmaStdErr = t.Sigma * math.Sqrt(cov[p][p])
+	return nil
+}
+
+// likelihood returns log-likelihood of thetas and log
+// Sigma of x, filling grad when not nil
+func (t *Tobit) likelihood(features [][]float64, output, x, grad []float64) float64 {
+	p := len(x) - 1
+	sigma := math.Exp(x[p])
+	for j := range grad {
+		grad[j] = 0
+	}
+	ll := 0.0
+	for i, row := range features {
+		y := output[i]
+		mu := floats.Dot(row, x[:p])
+		switch {
+		case y <= t.Lower, y >= t.Upper:
+			// probability of the latent output beyond
+			// the limit, Φ(z)
+			z := (t.Lower - mu) / sigma
+			sign := -1.0
+			if y >= t.Upper {
+				z, sign = (mu-t.Upper)/sigma, 1
+			}
+			ll += logNormalCDF(z)
+			if grad != nil {
+				mills := millsRatio(z)
+				floats.AddScaled(grad[:p], sign*mills/sigma, row)
+				grad[p] -= mills * z
+			}
+		default:
+			e := (y - mu) / sigma
+			ll += -0.5*e*e - 0.5*math.Log(2*math.Pi) - x[p]
+			if grad != nil {
+				floats.AddScaled(grad[:p], e/sigma, row)
+				grad[p] += e*e - 1
+			}
+		}
+	}
+	return ll
+}
+
+// logNormalCDF returns log Φ(z), asymptotic far
+// in the lower tail where Φ underflows
+func logNormalCDF(z float64) float64 {
+	if z < -30 {
+		return -z*z/2 - 0.5*math.Log(2*math.Pi) - math.Log(-z)
+	}
+	return math.Log(0.5 * math.Erfc(-z/math.Sqrt2))
+}
+
+// millsRatio returns φ(z)/Φ(z)
+func millsRatio(z float64) float64 {
+	if z < -30 {
+		return -z
+	}
+	return math.Exp(-z*z/2-0.5*math.Log(2*math.Pi)) / (0.5 * math.Erfc(-z/math.Sqrt2))
+}
+
+// Estimate returns the latent output θ·X
+func (t *Tobit) Estimate(X []float64) float64 {
+	return floats.Dot(X, t.Theta)
+}
+
+// Expected returns the expected observed output of X,
+// accounting for censoring at the limits
+func (t *Tobit) Expected(X []float64) float64 {
+	mu := t.Estimate(X)
+	a, b := (t.Lower-mu)/t.Sigma, (t.Upper-mu)/t.Sigma
+	pa, pb := 0.5*math.Erfc(-a/math.Sqrt2), 0.5*math.Erfc(-b/math.Sqrt2)
+	phi := func(z float64) float64 {
+		if math.IsInf(z, 0) {
+			return 0
+		}
+		return math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	}
+	y := mu*(pb-pa) + t.Sigma*(phi(a)-phi(b))
+	if pa > 0 {
+		y += t.Lower * pa
+	}
+	if pb < 1 {
+		y += t.Upper * (1 - pb)
+	}
+	return y
+}
+
+// Coefficients returns thetas
+func (t *Tobit) Coefficients() []float64 {
+	return t.Theta
+}
+
+// CoefficientCovariance returns covariance of thetas,
+// the inverse observed information of the training rows
+func (t *Tobit) CoefficientCovariance() ([][]float64, error) {
+	if t.covariance == nil {
+		return nil, fmt.Errorf("ml: covariance needs a model fitted with a regular information matrix")
+	}
+	return t.covariance, nil
+}
+
+// LogLikelihood returns censored normal
+// log-likelihood of the training data
+func (t *Tobit) LogLikelihood() float64 {
+	return t.loglik
+}
+
+// NumParams returns the number of thetas plus Sigma
+func (t *Tobit) NumParams() int {
+	return len(t.Theta) + 1
+}
+
+// NumSamples returns the number of training rows
+func (t *Tobit) NumSamples() int {
+	return t.samples
+}
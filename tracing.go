@@ -0,0 +1,75 @@
+package ml
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracer starts spans of training, cross-validation folds
+// and prediction batches. It keeps tracing libraries out of
+// the module: an OpenTelemetry adapter wraps a
+// trace.Tracer, starting a span and returning a Span that
+// converts key value pairs to attributes.
+type Tracer interface {
+	// Start starts span name as a child of the span of
+	// ctx, with attributes as key value pairs
+	Start(ctx context.Context, name string, attrs ...any) (context.Context, Span)
+}
+
+// Span is a started span
+type Span interface {
+	SetAttributes(attrs ...any)
+	RecordError(err error)
+	End()
+}
+
+// ContextFitter is an Estimator fitting with a context,
+// so training spans join the trace of the caller
+type ContextFitter interface {
+	FitContext(ctx context.Context, features [][]float64, output []float64) error
+}
+
+var packageTracer = struct {
+	sync.RWMutex
+	Tracer
+}{Tracer: nopTracer{}}
+
+// SetTracer sets the tracer of the package, nil
+// disables tracing. Tracing is disabled by default.
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = nopTracer{}
+	}
+	packageTracer.Lock()
+	packageTracer.Tracer = t
+	packageTracer.Unlock()
+}
+
+// StartSpan starts span name with the tracer of SetTracer
+func StartSpan(ctx context.Context, name string, attrs ...any) (context.Context, Span) {
+	packageTracer.RLock()
+	t := packageTracer.Tracer
+	packageTracer.RUnlock()
+	return t.Start(ctx, name, attrs...)
+}
+
+// fitContext fits estimator with ctx when it is
+// a ContextFitter
+func fitContext(ctx context.Context, estimator Estimator, features [][]float64, output []float64) error {
+	if f, ok := estimator.(ContextFitter); ok {
+		return f.FitContext(ctx, features, output)
+	}
+	return estimator.Fit(features, output)
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, name string, attrs ...any) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(attrs ...any) {}
+func (nopSpan) RecordError(err error)      {}
+func (nopSpan) End()                       {}
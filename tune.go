@@ -0,0 +1,142 @@
+package ml
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Param is a hyperparameter in [Min, Max]. Log params
+// are searched on a log scale, e.g. learning rates, and
+// Integer params are rounded, e.g. numbers of iterations.
+type Param struct {
+	Name    string
+	Min     float64
+	Max     float64
+	Log     bool
+	Integer bool
+}
+
+// SearchSpace declares hyperparameters of a search
+type SearchSpace []Param
+
+// Params are values of hyperparameters by name
+type Params map[string]float64
+
+// Objective scores params, higher is better
+type Objective func(p Params) (float64, error)
+
+// Trial is an evaluation of an objective
+type Trial struct {
+	Params Params
+	Score  float64
+}
+
+// CVObjective returns an Objective of mean metric score of
+// CrossValidate with estimators of newEstimator
+func CVObjective(newEstimator func(p Params) Estimator, features [][]float64, output []float64, splitter Splitter, metric Metric) Objective {
+	return func(p Params) (float64, error) {
+		scores, err := CrossValidate(func() Estimator { return newEstimator(p) }, features, output, splitter, metric)
+		if err != nil {
+			return 0, err
+		}
+		return mean(scores), nil
+	}
+}
+
+// check returns an error of invalid params
+func (s SearchSpace) check() error {
+	if len(s) == 0 {
+		return fmt.Errorf("ml: empty search space")
+	}
+	names := map[string]bool{}
+	for _, p := range s {
+		switch {
+		case names[p.Name]:
+			return fmt.Errorf("ml: param %q appears twice", p.Name)
+		case !(p.Min <= p.Max):
+			return fmt.Errorf("ml: param %q has min %v above max %v", p.Name, p.Min, p.Max)
+		case p.Log && p.Min <= 0:
+			return fmt.Errorf("ml: log param %q needs a positive min", p.Name)
+		}
+		names[p.Name] = true
+	}
+	return nil
+}
+
+// unit maps a point of the unit cube to params
+func (s SearchSpace) unit(u []float64) Params {
+	p := make(Params, len(s))
+	for j, param := range s {
+		var v float64
+		if param.Log {
+			lo, hi := math.Log(param.Min), math.Log(param.Max)
+			v = math.Exp(lo + u[j]*(hi-lo))
+		} else {
+			v = param.Min + u[j]*(param.Max-param.Min)
+		}
+		if param.Integer {
+			v = math.Round(v)
+		}
+		p[param.Name] = math.Min(math.Max(v, param.Min), param.Max)
+	}
+	return p
+}
+
+// toUnit maps params to a point of the unit cube
+func (s SearchSpace) toUnit(p Params) []float64 {
+	u := make([]float64, len(s))
+	for j, param := range s {
+		v := p[param.Name]
+		switch {
+		case param.Max == param.Min:
+			u[j] = 0
+		case param.Log:
+			u[j] = (math.Log(v) - math.Log(param.Min)) / (math.Log(param.Max) - math.Log(param.Min))
+		default:
+			u[j] = (v - param.Min) / (param.Max - param.Min)
+		}
+	}
+	return u
+}
+
+// Sample returns uniformly random params,
+// nil r uses the global source
+func (s SearchSpace) Sample(r *rand.Rand) Params {
+	r = RandOf(r)
+	u := make([]float64, len(s))
+	for j := range u {
+		u[j] = r.Float64()
+	}
+	return s.unit(u)
+}
+
+// RandomSearch evaluates objective on trials random
+// params and returns the trials, best first
+func RandomSearch(space SearchSpace, objective Objective, trials int, r *rand.Rand) ([]Trial, error) {
+	if err := space.check(); err != nil {
+		return nil, err
+	}
+	r = RandOf(r)
+
+	history := make([]Trial, 0, trials)
+	for t := 0; t < trials; t++ {
+		p := space.Sample(r)
+		score, err := objective(p)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, Trial{Params: p, Score: score})
+	}
+	sortTrials(history)
+	return history, nil
+}
+
+// sortTrials sorts trials best first, NaN scores last
+func sortTrials(trials []Trial) {
+	sort.SliceStable(trials, func(i, j int) bool {
+		a, b := trials[i].Score, trials[j].Score
+		return a > b || (!math.IsNaN(a) && math.IsNaN(b))
+	})
+}